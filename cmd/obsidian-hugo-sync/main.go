@@ -4,14 +4,23 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"obsidian-hugo-sync/internal/config"
 	"obsidian-hugo-sync/internal/daemon"
+	"obsidian-hugo-sync/internal/doctor"
+	"obsidian-hugo-sync/internal/errors"
 	"obsidian-hugo-sync/internal/logging"
 	"obsidian-hugo-sync/internal/process"
+	"obsidian-hugo-sync/internal/profiling"
+	"obsidian-hugo-sync/internal/webhook"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 var (
@@ -24,70 +33,257 @@ func main() {
 		vault           = flag.String("vault", "", "Path to Obsidian vault (required)")
 		repo            = flag.String("repo", "", "Path to Hugo site directory (required)")
 		contentDir      = flag.String("content-dir", "content/docs", "Target directory for Hugo content (e.g., 'content', 'content/docs', 'content/blog')")
+		rootSection     = flag.String("root-section", "posts", "Section for vault-root notes under the content dir ('' places them at the content root)")
 		autoWeight      = flag.Bool("auto-weight", true, "Auto-assign weights to notes and folders")
+		weightStep      = flag.Int("weight-step", 10, "Weight gap between sibling notes in alphabetical order")
+		numberPrefix    = flag.String("number-prefix", "keep", "Leading filename numbers ('01 Intro.md'): 'keep', 'weight' (order by number) or 'strip' (order and remove from slug/title)")
+		dateFromName    = flag.String("date-from-filename", "", "Go date layout of a date leading note filenames, e.g. 2006-01-02: sets the page date unless the note has one and is dropped from the slug")
+		slugStyle       = flag.String("slug-style", "", "Case style of generated slugs and URL folders: 'kebab' (my-note), 'snake' (my_note) or 'preserve' (My-Note) (default 'kebab')")
+		maxSectionDepth = flag.Int("max-section-depth", 0, "Deepest section nesting below the content dir; notes in deeper folders move up with the folder names in their slug (0 = unlimited)")
+		indexTemplate   = flag.String("index-template", "", "Go text/template file rendered into generated section _index.md files ({{.Title}}, {{.Description}}, {{.Section}}, {{.Path}}, {{.Weight}}); may start with --- front-matter")
+		indexNotes      = flag.Bool("index-notes", false, "Publish a folder's landing note (_index.md, index.md or one named after the folder) as the section's _index.md")
+		transformCmd    = flag.String("transform-cmd", "", "Shell command each converted note body is piped through (stdin to stdout) before it is written")
+		transformTime   = flag.String("transform-timeout", "", "Longest a --transform-cmd run may take before it is killed and the note fails (0 = no limit; default 10s)")
 		linkFormat      = flag.String("link-format", "relref", "Link format: 'relref' or 'md'")
-		unpublishedLink = flag.String("unpublished-link", "text", "How to handle unpublished links: 'text' or 'hash'")
+		unpublishedLink = flag.String("unpublished-link", "text", "How to handle unpublished links: 'text', 'hash' or 'stub'")
+		baseURL         = flag.String("base-url", "", "Site base URL or path (e.g. '/repo/') that prefixes 'md' links for sites served under a subpath")
+		frontMatterFmt  = flag.String("front-matter-format", "yaml", "Front-matter format for Hugo content: 'yaml', 'toml' or 'json'")
+		sourceEncoding  = flag.String("source-encoding", "utf-8", "Encoding of vault notes: 'utf-8', 'windows-1252', 'latin-1' or 'auto'")
+		uidKeys         = flag.String("uid-keys", "", "Comma-separated front-matter keys checked in order for an existing note UID when noteUid is missing (default 'noteUid'; e.g. 'uid,id,guid')")
+		publishFolders  = flag.String("publish-by-folder", "", "Comma-separated vault folders whose notes are published without a publish key or tag (e.g. 'Published')")
+		mdExtensions    = flag.String("markdown-extensions", "", "Comma-separated extensions of vault files read as notes (default '.md'; e.g. '.md,.markdown,.mdx')")
+		excludeTags     = flag.String("exclude-tag", "", "Comma-separated tags that keep a note unpublished, even with #publish or in a publish folder (e.g. 'wip,noindex')")
+		preserveFiles   = flag.String("preserve-hugo-files", "", "Comma-separated globs relative to the content directory that repair and cleanup never delete (e.g. '_index.md,manual/**')")
+		followSymlinks  = flag.Bool("follow-symlinks", false, "Scan and watch folders symlinked into the vault (each real folder once, so link cycles are safe)")
+		renameScan      = flag.Bool("rename-scan", false, "On full sync, move the Hugo files of notes renamed while the daemon was stopped instead of republishing them")
+		attachmentsDir  = flag.String("attachments-dir", "", "Vault folder holding attachments; ![[file]] embeds not found beside the note are looked up there")
+		attachmentsSub  = flag.String("attachments-subdir", "", "Folder beside each note holding its attachments (e.g. '_attachments'); ![[file]] embeds are looked up there after the note's own folder")
+		timestampsUTC   = flag.Bool("timestamps-utc", false, "Emit all front-matter dates in UTC")
+		mermaidCode     = flag.String("mermaid-shortcode", "", "Wrap ```mermaid blocks in this theme shortcode (e.g. 'mermaid')")
+		mathMode        = flag.String("math-mode", "keep", "Display math ($$ blocks): 'keep' or 'shortcode'")
+		mathShortcode   = flag.String("math-shortcode", "math", "Shortcode used for display math when --math-mode=shortcode")
+		cleanTasks      = flag.String("clean-tasks", "", "Tasks plugin metadata on checkbox items (due dates, priorities): 'keep', 'strip' or 'suffix' (default 'keep')")
+		nestedTagMode   = flag.String("nested-tag-mode", "", "Nested tags like 'project/alpha' in the emitted Hugo tags: 'keep', 'expand' to add each ancestor, or 'split' to keep the final segment (default 'keep')")
+		stripPublishTag = flag.Bool("strip-publish-tag", false, "Remove inline #publish tags from published note bodies")
+		stripH1         = flag.String("strip-h1", "", "First H1 of a note body: 'keep', 'match' to remove it when it repeats the title, or 'always' (default 'keep')")
+		respectDraft    = flag.Bool("respect-draft", true, "Never publish notes marked 'draft: true' (unpublishes them if already live)")
+		emitDraft       = flag.Bool("emit-draft", false, "Publish drafts with 'draft: true' in the front-matter and let Hugo decide")
+		enforceSchedule = flag.Bool("enforce-schedule", false, "Only publish notes between their 'publishDate' and 'expiryDate', re-checking as time passes")
+		autoDescription = flag.Bool("auto-description", false, "Derive a description from the first paragraph of notes without one")
+		descriptionLen  = flag.Int("description-length", 160, "Maximum length of derived descriptions")
+		readingStats    = flag.Bool("emit-reading-stats", false, "Emit 'wordCount' and 'readingTime' front-matter computed from the published body")
+		readingWPM      = flag.Int("reading-wpm", 0, "Words per minute for 'readingTime' (default 200; 0 in the config file omits it)")
+		optimizeImages  = flag.Bool("optimize-images", false, "Downscale JPEG/PNG images larger than --image-max-dimension before copying")
+		imageMaxDim     = flag.Int("image-max-dimension", 2048, "Longest image side in pixels when optimizing images")
+		imageQuality    = flag.Int("image-quality", 85, "JPEG quality (1-100) for optimized images")
+		imageWorkers    = flag.Int("image-workers", 4, "Number of images copied at once")
+		imageOutputDir  = flag.String("image-output-dir", "", "Copy all images into this directory of the Hugo repo (e.g. 'static/images') instead of beside their notes")
+		inlineSVGUnder  = flag.Int("inline-svg-under", 0, "Inline SVG images smaller than this many bytes into the page as sanitized markup instead of copying them (0 = never)")
+		gitAutoCommit   = flag.Bool("git-auto-commit", false, "Commit Hugo changes to the site's git repository")
+		gitCommitMin    = flag.Int("git-commit-threshold", 1, "Commit once at least this many files changed")
+		gitCommitDelay  = flag.String("git-commit-max-delay", "5m", "Commit pending changes after this long even below the threshold (0 disables)")
+		gitAuthorName   = flag.String("git-author-name", "obsidian-hugo-sync", "Author name for sync commits")
+		gitAuthorEmail  = flag.String("git-author-email", "obsidian-hugo-sync@automated", "Author email for sync commits")
+		gitPush         = flag.Bool("git-push", false, "Push the current branch to origin after each auto-commit")
+		gitPushRebase   = flag.Bool("git-push-rebase", false, "When origin rejects a push because it moved on, rebase the sync commits onto it and push again")
+		lastmodFromGit  = flag.Bool("lastmod-from-git", false, "Set each page's lastmod to the time of the last vault commit touching its note (file mtime when uncommitted)")
+		gitCommitTmpl   = flag.String("git-commit-template", "", "Go text/template for commit messages ({{.Added}}, {{.Modified}}, {{.Deleted}}, {{.Timestamp}})")
 		interval        = flag.String("interval", "30s", "Scan interval when fsnotify is unavailable")
+		debounce        = flag.String("debounce", "300ms", "Quiet window for coalescing bursts of file events (0 disables)")
+		settleDelay     = flag.String("settle-delay", "", "Longest wait for a changed note to stop growing before it is read (0 reads it at once; default 1s)")
+		shutdownTimeout = flag.String("shutdown-timeout", "", "Longest wait on shutdown for the running sync to finish and save its state (0 waits indefinitely; default 30s)")
 		logLevel        = flag.String("log-level", "info", "Log level: debug, info, warn, error")
+		logFile         = flag.String("log-file", "", "Write logs to this file instead of stdout, rotating it by size")
+		logMaxSize      = flag.Int("log-max-size", 10, "Size in MB at which the log file is rotated")
+		logBackups      = flag.Int("log-backups", 3, "Number of rotated log files to keep")
+		logStdout       = flag.Bool("log-stdout", false, "Also write logs to stdout when --log-file is set")
 		dryRun          = flag.Bool("dry-run", false, "Preview changes without writing files")
+		noDelete        = flag.Bool("no-delete", false, "Write changes as usual but never delete Hugo files or images, only log and report them")
+		pprofAddr       = flag.String("pprof-addr", "", "Serve pprof profiling endpoints on this address (e.g. ':6060', localhost only unless a host is given)")
+		httpAddr        = flag.String("http-addr", "", "Serve the POST /sync webhook on this address (e.g. ':8080'); requires --webhook-secret")
+		webhookSecret   = flag.String("webhook-secret", "", "Shared secret webhook requests must send in the X-Webhook-Secret header (or set OBSIDIAN_HUGO_SYNC_WEBHOOK_SECRET)")
+		linkReport      = flag.String("link-report", "", "Write dead links found during each sync to this JSON file")
+		report          = flag.String("report", "", "Write a JSON summary of each sync run (counts, errors, changed Hugo files, dead links) to this file")
+		reportAppend    = flag.Bool("report-append", false, "Append each sync's report to --report as a JSON line instead of replacing the file")
+		manifest        = flag.String("manifest", "", "Write a JSON manifest of the files generated for each note after every full sync")
+		once            = flag.Bool("once", false, "Run a single full sync and exit instead of watching the vault")
+		since           = flag.String("since", "", "With --once, only process notes modified after this cutoff: a duration back from now (e.g. '24h'), an RFC 3339 timestamp or a date (2006-01-02)")
+		cacheDir        = flag.String("cache-dir", "", "Directory for the vault's state cache (default: a per-vault folder under $XDG_CACHE_HOME/obsidian-hugo-sync)")
 		configFile      = flag.String("config", "", "Path to configuration file")
 		showVersion     = flag.Bool("version", false, "Show version information")
 	)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Obsidian → Hugo Sync Daemon\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [command] [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  (none)\tRun the sync daemon\n")
+		fmt.Fprintf(os.Stderr, "  purge\tDelete all Hugo files generated from the vault and reset the state\n")
+		fmt.Fprintf(os.Stderr, "  doctor\tCheck the setup for common problems and suggest fixes\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 
-	flag.Parse()
+	// The command may be given before or after the options
+	args := os.Args[1:]
+	var command string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	flag.CommandLine.Parse(args)
+	if command == "" && flag.NArg() > 0 {
+		command = flag.Arg(0)
+	}
+	if command != "" && command != "purge" && command != "doctor" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
 
 	if *showVersion {
 		fmt.Printf("obsidian-hugo-sync %s (commit %s)\n", version, commit)
 		os.Exit(0)
 	}
 
+	// Only flags given on the command line override the config file. An
+	// explicitly empty --root-section or --respect-draft=false must too.
+	set := setFlags(flag.CommandLine)
+	var rootSectionOpt *string
+	var respectDraftOpt *bool
+	if set["root-section"] {
+		rootSectionOpt = rootSection
+	}
+	if set["respect-draft"] {
+		respectDraftOpt = respectDraft
+	}
+
 	// Initialize logging first
-	logger := logging.NewLogger(*logLevel)
+	var logOutput io.Writer = os.Stdout
+	if *logFile != "" {
+		rotating, err := logging.NewRotatingFile(*logFile, int64(*logMaxSize)*1024*1024, *logBackups)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer rotating.Close()
+		
+		logOutput = rotating
+		if *logStdout {
+			logOutput = io.MultiWriter(os.Stdout, rotating)
+		}
+	}
+	logger := logging.NewLoggerTo(*logLevel, logOutput)
 	slog.SetDefault(logger)
 
-	// Load and validate configuration
-	cfg, err := config.Load(&config.Options{
-		Vault:           *vault,
-		Repo:            *repo,
-		ContentDir:      *contentDir,
-		AutoWeight:      *autoWeight,
-		LinkFormat:      *linkFormat,
-		UnpublishedLink: *unpublishedLink,
-		Interval:        *interval,
-		LogLevel:        *logLevel,
-		DryRun:          *dryRun,
-		ConfigFile:      *configFile,
+	// Load and validate configuration (one per [[sync]] table in the config file)
+	cfgs, err := config.LoadAll(&config.Options{
+		Vault:              given(set, "vault", *vault),
+		Repo:               given(set, "repo", *repo),
+		ContentDir:         given(set, "content-dir", *contentDir),
+		RootSection:        rootSectionOpt,
+		AutoWeight:         given(set, "auto-weight", *autoWeight),
+		WeightStep:         given(set, "weight-step", *weightStep),
+		NumberPrefix:       given(set, "number-prefix", *numberPrefix),
+		DateFromFilename:   given(set, "date-from-filename", *dateFromName),
+		SlugStyle:          given(set, "slug-style", *slugStyle),
+		MaxSectionDepth:    given(set, "max-section-depth", *maxSectionDepth),
+		IndexTemplate:      given(set, "index-template", *indexTemplate),
+		IndexNotes:         given(set, "index-notes", *indexNotes),
+		TransformCmd:       given(set, "transform-cmd", *transformCmd),
+		TransformTimeout:   given(set, "transform-timeout", *transformTime),
+		LinkFormat:         given(set, "link-format", *linkFormat),
+		UnpublishedLink:    given(set, "unpublished-link", *unpublishedLink),
+		BaseURL:            given(set, "base-url", *baseURL),
+		FrontMatterFormat:  given(set, "front-matter-format", *frontMatterFmt),
+		SourceEncoding:     given(set, "source-encoding", *sourceEncoding),
+		UIDKeys:            splitList(given(set, "uid-keys", *uidKeys)),
+		MarkdownExtensions: splitList(given(set, "markdown-extensions", *mdExtensions)),
+		AttachmentsDir:     given(set, "attachments-dir", *attachmentsDir),
+		AttachmentsSubdir:  given(set, "attachments-subdir", *attachmentsSub),
+		PublishByFolder:    splitList(given(set, "publish-by-folder", *publishFolders)),
+		ExcludeTags:        splitList(given(set, "exclude-tag", *excludeTags)),
+		PreservePatterns:   splitList(given(set, "preserve-hugo-files", *preserveFiles)),
+		RenameScan:         given(set, "rename-scan", *renameScan),
+		FollowSymlinks:     given(set, "follow-symlinks", *followSymlinks),
+		TimestampsUTC:      given(set, "timestamps-utc", *timestampsUTC),
+		MermaidShortcode:   given(set, "mermaid-shortcode", *mermaidCode),
+		MathMode:           given(set, "math-mode", *mathMode),
+		MathShortcode:      given(set, "math-shortcode", *mathShortcode),
+		CleanTasks:         given(set, "clean-tasks", *cleanTasks),
+		NestedTagMode:      given(set, "nested-tag-mode", *nestedTagMode),
+		StripPublishTag:    given(set, "strip-publish-tag", *stripPublishTag),
+		StripH1:            given(set, "strip-h1", *stripH1),
+		RespectDraft:       respectDraftOpt,
+		EmitDraft:          given(set, "emit-draft", *emitDraft),
+		EnforceSchedule:    given(set, "enforce-schedule", *enforceSchedule),
+		AutoDescription:    given(set, "auto-description", *autoDescription),
+		DescriptionLength:  given(set, "description-length", *descriptionLen),
+		EmitReadingStats:   given(set, "emit-reading-stats", *readingStats),
+		ReadingWPM:         given(set, "reading-wpm", *readingWPM),
+		OptimizeImages:     given(set, "optimize-images", *optimizeImages),
+		ImageMaxDimension:  given(set, "image-max-dimension", *imageMaxDim),
+		ImageQuality:       given(set, "image-quality", *imageQuality),
+		ImageWorkers:       given(set, "image-workers", *imageWorkers),
+		ImageOutputDir:     given(set, "image-output-dir", *imageOutputDir),
+		InlineSVGUnder:     given(set, "inline-svg-under", *inlineSVGUnder),
+		GitAutoCommit:      given(set, "git-auto-commit", *gitAutoCommit),
+		GitCommitThreshold: given(set, "git-commit-threshold", *gitCommitMin),
+		GitCommitMaxDelay:  given(set, "git-commit-max-delay", *gitCommitDelay),
+		GitAuthorName:      given(set, "git-author-name", *gitAuthorName),
+		GitAuthorEmail:     given(set, "git-author-email", *gitAuthorEmail),
+		GitCommitTemplate:  given(set, "git-commit-template", *gitCommitTmpl),
+		GitPush:            given(set, "git-push", *gitPush),
+		GitPushRebase:      given(set, "git-push-rebase", *gitPushRebase),
+		LastmodFromGit:     given(set, "lastmod-from-git", *lastmodFromGit),
+		Interval:           given(set, "interval", *interval),
+		Debounce:           given(set, "debounce", *debounce),
+		SettleDelay:        given(set, "settle-delay", *settleDelay),
+		ShutdownTimeout:    given(set, "shutdown-timeout", *shutdownTimeout),
+		LogLevel:           given(set, "log-level", *logLevel),
+		DryRun:             given(set, "dry-run", *dryRun),
+		NoDelete:           given(set, "no-delete", *noDelete),
+		PprofAddr:          given(set, "pprof-addr", *pprofAddr),
+		HTTPAddr:           given(set, "http-addr", *httpAddr),
+		WebhookSecret:      given(set, "webhook-secret", *webhookSecret),
+		LinkReport:         given(set, "link-report", *linkReport),
+		Manifest:           given(set, "manifest", *manifest),
+		Report:             given(set, "report", *report),
+		ReportAppend:       given(set, "report-append", *reportAppend),
+		CacheDir:           given(set, "cache-dir", *cacheDir),
+		Once:               given(set, "once", *once),
+		Since:              given(set, "since", *since),
+		ConfigFile:         given(set, "config", *configFile),
 	})
-	if err != nil {
+	if err != nil && command == "doctor" {
+		errors.New(errors.ErrorTypeConfig, "loading configuration", err).PrintUserError()
+		os.Exit(1)
+	} else if err != nil {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
 
-	slog.Info("Starting Obsidian → Hugo Sync Daemon",
-		"version", version,
-		"vault", cfg.Vault,
-		"hugo_dir", cfg.Repo,
-		"dry_run", cfg.DryRun,
-	)
-
-	// Check for existing process and create lock file
-	lockFile, err := process.AcquireLock(cfg.Vault)
-	if err != nil {
-		slog.Error("Failed to acquire process lock", "error", err)
-		os.Exit(1)
+	// Purging is nothing but deletions
+	for _, cfg := range cfgs {
+		if command == "purge" && cfg.NoDelete {
+			slog.Error("--no-delete cannot be combined with the purge command", "vault", cfg.Vault)
+			os.Exit(1)
+		}
 	}
-	defer func() {
-		if err := process.ReleaseLock(lockFile); err != nil {
-			slog.Error("Failed to release process lock", "error", err)
+
+	if command == "doctor" {
+		failed := false
+		for _, cfg := range cfgs {
+			fmt.Printf("Checking %s → %s\n", cfg.Vault, cfg.Repo)
+			results := doctor.Run(cfg)
+			doctor.Print(os.Stdout, results)
+			fmt.Println()
+			failed = failed || doctor.Failed(results)
 		}
-	}()
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Set up graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -98,24 +294,200 @@ func main() {
 
 	go func() {
 		sig := <-sigChan
-		slog.Info("Received shutdown signal", "signal", sig)
+		slog.Info("Received shutdown signal, finishing the running sync", "signal", sig)
 		cancel()
+		
+		// A second signal skips the wait
+		sig = <-sigChan
+		slog.Warn("Received second shutdown signal, exiting immediately", "signal", sig)
+		os.Exit(1)
 	}()
 
-	// Initialize and start the daemon
-	daemon, err := daemon.New(cfg)
-	if err != nil {
-		slog.Error("Failed to create daemon", "error", err)
-		os.Exit(1)
+	// Create a daemon per vault, each holding its vault's lock
+	daemons := make([]*daemon.Daemon, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		slog.Info("Starting Obsidian → Hugo Sync Daemon",
+			"version", version,
+			"vault", cfg.Vault,
+			"hugo_dir", cfg.Repo,
+			"dry_run", cfg.DryRun,
+			"no_delete", cfg.NoDelete,
+		)
+
+		// Check for existing process and create lock file
+		lockFile, err := process.AcquireLock(cfg.LockDir())
+		if err != nil {
+			slog.Error("Failed to acquire process lock", "vault", cfg.Vault, "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := process.ReleaseLock(lockFile); err != nil {
+				slog.Error("Failed to release process lock", "error", err)
+			}
+		}()
+
+		d, err := daemon.New(cfg)
+		if err != nil {
+			slog.Error("Failed to create daemon", "vault", cfg.Vault, "error", err)
+			os.Exit(1)
+		}
+		daemons = append(daemons, d)
+	}
+
+	// A manual resync (SIGUSR1 or POST /resync) applies to every vault
+	requestResync := func() {
+		for _, d := range daemons {
+			d.RequestResync()
+		}
+	}
+
+	// Expose profiling endpoints when requested (sync tables may share the address)
+	pprofStarted := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if cfg.PprofAddr != "" && !pprofStarted[cfg.PprofAddr] {
+			if _, err := profiling.Start(ctx, cfg.PprofAddr, requestResync); err != nil {
+				slog.Error("Failed to start pprof endpoint", "error", err)
+				os.Exit(1)
+			}
+			pprofStarted[cfg.PprofAddr] = true
+		}
+	}
+
+	if command == "purge" {
+		for i, d := range daemons {
+			if err := d.Purge(); err != nil {
+				slog.Error("Purge failed", "vault", cfgs[i].Vault, "error", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if *once {
+		for i, d := range daemons {
+			if err := d.SyncOnce(); err != nil {
+				slog.Error("Sync failed", "vault", cfgs[i].Vault, "error", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	slog.Info("Daemon initialization complete", "vaults", len(daemons))
+
+	resyncChan := make(chan os.Signal, 1)
+	notifyResync(resyncChan)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-resyncChan:
+				slog.Info("Received resync signal", "signal", sig)
+				requestResync()
+			}
+		}
+	}()
+
+	// Serve the sync webhook once the daemons run (sync tables may share the address)
+	webhookStarted := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if cfg.HTTPAddr != "" && !webhookStarted[cfg.HTTPAddr] {
+			var syncers []webhook.Syncer
+			for i, d := range daemons {
+				if cfgs[i].HTTPAddr == cfg.HTTPAddr {
+					syncers = append(syncers, d)
+				}
+			}
+			if _, err := webhook.Start(ctx, cfg.HTTPAddr, webhook.Handler(cfg.WebhookSecret, syncers...)); err != nil {
+				slog.Error("Failed to start webhook endpoint", "error", err)
+				os.Exit(1)
+			}
+			webhookStarted[cfg.HTTPAddr] = true
+		}
 	}
 
-	slog.Info("Daemon initialization complete")
-	
-	// Start the daemon
-	if err := daemon.Start(ctx); err != nil {
-		slog.Error("Daemon failed", "error", err)
+	// Run all daemons under the shared context; one failing stops the others
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	for i, d := range daemons {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Start(ctx); err != nil {
+				slog.Error("Daemon failed", "vault", cfgs[i].Vault, "error", err)
+				failed.Store(true)
+				cancel()
+			}
+		}()
+	}
+	// Once shutdown begins, give the daemons time to finish their syncs
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		var timeout <-chan time.Time
+		if limit := shutdownLimit(cfgs); limit > 0 {
+			timeout = time.After(limit)
+		}
+		select {
+		case <-stopped:
+		case <-timeout:
+			slog.Error("Timed out waiting for syncs to finish", "timeout", shutdownLimit(cfgs))
+			os.Exit(1)
+		}
+	}
+
+	if failed.Load() {
 		os.Exit(1)
 	}
-	
+
 	slog.Info("Shutting down gracefully")
-} 
\ No newline at end of file
+}
+
+// shutdownLimit returns the longest shutdown timeout of the sync tables, or 0
+// when any of them waits indefinitely
+func shutdownLimit(cfgs []*config.Config) time.Duration {
+	var limit time.Duration
+	for _, cfg := range cfgs {
+		if cfg.ShutdownTimeout == 0 {
+			return 0
+		}
+		limit = max(limit, cfg.ShutdownTimeout)
+	}
+	return limit
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setFlags returns the names of the flags given on the command line
+func setFlags(flags *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// given returns a flag's value when it was set on the command line and the
+// zero value otherwise, which leaves the setting to the config file
+func given[T any](set map[string]bool, name string, value T) T {
+	if !set[name] {
+		var zero T
+		return zero
+	}
+	return value
+}
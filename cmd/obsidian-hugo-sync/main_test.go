@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"obsidian-hugo-sync/internal/config"
+)
+
+func TestUnsetFlagsKeepSyncTables(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("OBSIDIAN_VAULT", "")
+	t.Setenv("HUGO_REPO", "")
+	
+	docsVault, blogVault, repo := t.TempDir(), t.TempDir(), t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configFile := fmt.Sprintf(`[[sync]]
+vault = %q
+repo = %q
+content_dir = "content/docs"
+
+[[sync]]
+vault = %q
+repo = %q
+content_dir = "content/blog"
+link_format = "md"
+`, docsVault, repo, blogVault, repo)
+	if err := os.WriteFile(configPath, []byte(configFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	// The flags as main defines them, defaults included
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	contentDir := flags.String("content-dir", "content/docs", "")
+	linkFormat := flags.String("link-format", "relref", "")
+	unpublishedLink := flags.String("unpublished-link", "text", "")
+	weightStep := flags.Int("weight-step", 10, "")
+	if err := flags.Parse([]string{"--unpublished-link", "hash"}); err != nil {
+		t.Fatal(err)
+	}
+	set := setFlags(flags)
+	
+	cfgs, err := config.LoadAll(&config.Options{
+		ContentDir:      given(set, "content-dir", *contentDir),
+		LinkFormat:      given(set, "link-format", *linkFormat),
+		UnpublishedLink: given(set, "unpublished-link", *unpublishedLink),
+		WeightStep:      given(set, "weight-step", *weightStep),
+		ConfigFile:      configPath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfgs) != 2 {
+		t.Fatalf("Expected 2 sync configs, got %d", len(cfgs))
+	}
+	
+	// Flag defaults leave the tables alone; flags that were given apply to all
+	if cfgs[0].ContentDir != "content/docs" || cfgs[0].LinkFormat != "relref" {
+		t.Errorf("Expected docs table with relref links, got %q and %q", cfgs[0].ContentDir, cfgs[0].LinkFormat)
+	}
+	if cfgs[1].ContentDir != "content/blog" || cfgs[1].LinkFormat != "md" {
+		t.Errorf("Expected blog table with md links, got %q and %q", cfgs[1].ContentDir, cfgs[1].LinkFormat)
+	}
+	for i, cfg := range cfgs {
+		if cfg.UnpublishedLink != "hash" {
+			t.Errorf("Expected --unpublished-link to apply to table %d, got %q", i+1, cfg.UnpublishedLink)
+		}
+		if cfg.WeightStep != 10 {
+			t.Errorf("Expected the default weight step for table %d, got %d", i+1, cfg.WeightStep)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResync relays SIGUSR1, which requests a full vault resync, to ch
+func notifyResync(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
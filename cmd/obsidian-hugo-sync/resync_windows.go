@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+)
+
+// notifyResync does nothing on Windows, which has no SIGUSR1; a resync is
+// requested with POST /resync on the --pprof-addr endpoint instead
+func notifyResync(ch chan<- os.Signal) {}
@@ -2,10 +2,18 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"obsidian-hugo-sync/internal/git"
+	"obsidian-hugo-sync/internal/hugo"
+	"obsidian-hugo-sync/internal/vault"
+
 	"github.com/BurntSushi/toml"
 )
 
@@ -16,87 +24,381 @@ type Config struct {
 	Repo       string `toml:"repo"`
 	ContentDir string `toml:"content_dir"`
 
+	// RootSection is the section for vault-root notes under ContentDir ("" for ContentDir itself)
+	RootSection string `toml:"root_section"`
+
+	// SectionRoutes sends vault folders to their own content directories,
+	// e.g. "Blog" = "content/posts" (config file only)
+	SectionRoutes map[string]string `toml:"section_routes"`
+
+	// FrontMatterMap renames front-matter keys on output, Obsidian key to
+	// Hugo key, e.g. "created" = "date" (config file only)
+	FrontMatterMap map[string]string `toml:"front_matter_map"`
+
 	// Behavior settings
-	AutoWeight      bool   `toml:"auto_weight"`
-	LinkFormat      string `toml:"link_format"`
-	UnpublishedLink string `toml:"unpublished_link"`
+	AutoWeight        bool     `toml:"auto_weight"`
+	WeightStep        int      `toml:"weight_step"`       // Weight gap between sibling notes
+	NumberPrefix      string   `toml:"number_prefix"`     // Leading filename numbers: keep, weight or strip
+	SlugStyle         string   `toml:"slug_style"`        // Slug case: kebab, snake or preserve
+	MaxSectionDepth   int      `toml:"max_section_depth"` // Deepest section nesting; deeper notes are flattened, 0 is unlimited
+	LinkFormat        string   `toml:"link_format"`
+	UnpublishedLink   string   `toml:"unpublished_link"`
+	BaseURL           string   `toml:"base_url"` // Site base URL whose path prefixes md links
+	FrontMatterFormat string   `toml:"front_matter_format"`
+	SourceEncoding    string   `toml:"source_encoding"`
+	UIDKeys           []string `toml:"uid_keys"`           // Front-matter keys checked in order for an existing UID
+	AttachmentsDir    string   `toml:"attachments_dir"`    // Vault folder ![[file]] embeds fall back to
+	AttachmentsSubdir string   `toml:"attachments_subdir"` // Folder beside each note ![[file]] embeds are looked up in
+	PublishByFolder   []string `toml:"publish_by_folder"`  // Vault folders whose notes are always published
+	ExcludeTags       []string `toml:"exclude_tags"`       // Tags that keep a note unpublished
+	PreservePatterns  []string `toml:"preserve_patterns"`  // Content-relative globs repair and cleanup never delete
+	RenameScan        bool     `toml:"rename_scan"`        // Move Hugo files of notes renamed while stopped
+	FollowSymlinks    bool     `toml:"follow_symlinks"`    // Scan and watch symlinked vault folders
+	TimestampsUTC     bool     `toml:"timestamps_utc"`
+	MermaidShortcode  string   `toml:"mermaid_shortcode"` // Empty keeps mermaid fences as code
+	MathMode          string   `toml:"math_mode"`         // keep or shortcode
+	MathShortcode     string   `toml:"math_shortcode"`
+	CleanTasks        string   `toml:"clean_tasks"`       // Tasks plugin metadata: keep, strip or suffix
+	NestedTagMode     string   `toml:"nested_tag_mode"`   // Nested tags: keep, expand or split
+	StripPublishTag   bool     `toml:"strip_publish_tag"` // Remove inline #publish from note bodies
+	StripH1           string   `toml:"strip_h1"`          // First H1 repeating the title: keep, match or always
+	RespectDraft      bool     `toml:"respect_draft"`     // Never publish notes marked draft: true
+	EmitDraft         bool     `toml:"emit_draft"`        // Publish drafts with draft: true for Hugo to handle
+	EnforceSchedule   bool     `toml:"enforce_schedule"`  // Hold back notes outside their publishDate/expiryDate
+	AutoDescription   bool     `toml:"auto_description"`  // Derive descriptions from the first paragraph
+	DescriptionLength int      `toml:"description_length"`
+	EmitReadingStats  bool     `toml:"emit_reading_stats"` // Emit wordCount and readingTime front-matter
+	ReadingWPM        int      `toml:"reading_wpm"`        // Words per minute for readingTime; 0 omits it
+
+	// DateFromFilename is the Go time layout of a date leading note filenames,
+	// e.g. "2006-01-02": it sets the page date and is dropped from the slug ("" disables)
+	DateFromFilename string `toml:"date_from_filename"`
+
+	// MarkdownExtensions are the extensions of vault files read as notes,
+	// e.g. ".md" and ".markdown"; generated Hugo files always use .md
+	MarkdownExtensions []string `toml:"markdown_extensions"`
+
+	// IndexTemplate is a text/template file rendered into each generated
+	// section _index.md ("" writes front-matter only)
+	IndexTemplate string `toml:"index_template"`
+
+	// IndexNotes publishes a folder's landing note (_index, index or the
+	// folder's own name) as its section _index.md instead of a page
+	IndexNotes bool `toml:"index_notes"`
+
+	// TransformCmd is a shell command every converted note body is piped
+	// through before it is written, killed after TransformTimeout ("" disables)
+	TransformCmd     string        `toml:"transform_cmd"`
+	TransformTimeout time.Duration `toml:"transform_timeout"`
+
+	// Image optimization
+	OptimizeImages    bool   `toml:"optimize_images"`     // Downscale and re-encode large JPEG/PNG images
+	ImageMaxDimension int    `toml:"image_max_dimension"` // Longest side in pixels before downscaling
+	ImageQuality      int    `toml:"image_quality"`       // JPEG quality (1-100)
+	ImageWorkers      int    `toml:"image_workers"`       // Images copied at once
+	ImageOutputDir    string `toml:"image_output_dir"`    // Single directory for all images (e.g. "static/images"); empty mirrors vault folders
+	InlineSVGUnder    int    `toml:"inline_svg_under"`    // SVGs below this many bytes are inlined into pages; 0 copies all
+
+	// Git integration
+	GitAutoCommit      bool          `toml:"git_auto_commit"`
+	GitCommitThreshold int           `toml:"git_commit_threshold"` // Files changed before committing
+	GitCommitMaxDelay  time.Duration `toml:"git_commit_max_delay"` // Commit below the threshold after this long; 0 disables
+	GitAuthorName      string        `toml:"git_author_name"`
+	GitAuthorEmail     string        `toml:"git_author_email"`
+	GitCommitTemplate  string        `toml:"git_commit_template"` // text/template for commit messages
+	GitPush            bool          `toml:"git_push"`            // Push the current branch to origin after each commit
+	GitPushRebase      bool          `toml:"git_push_rebase"`     // Rebase onto origin and retry when a push is rejected
+	LastmodFromGit     bool          `toml:"lastmod_from_git"`    // Hugo lastmod from the vault's last commit of each note
 
 	// Timing and performance
-	Interval time.Duration `toml:"-"` // Parsed from string
-	interval string        `toml:"interval"`
+	// Durations are written like "30s" or "5m" in the config file
+	Interval    time.Duration `toml:"interval"`
+	Debounce    time.Duration `toml:"debounce"`     // Quiet window for coalescing bursts of file events
+	SettleDelay time.Duration `toml:"settle_delay"` // Longest wait for a changed note to stop growing
+
+	// ShutdownTimeout is how long a shutdown waits for the running sync to
+	// finish and save its state (0 waits as long as it takes)
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
 
 	// Logging and debugging
-	LogLevel string `toml:"log_level"`
-	DryRun   bool   `toml:"dry_run"`
+	LogLevel  string `toml:"log_level"`
+	DryRun    bool   `toml:"dry_run"`
+	NoDelete  bool   `toml:"no_delete"`  // Write as usual but keep files that would be deleted
+	PprofAddr string `toml:"pprof_addr"` // Empty disables the pprof endpoint
+
+	// HTTPAddr serves POST /sync, which runs a full sync for requests carrying
+	// WebhookSecret ("" disables)
+	HTTPAddr      string `toml:"http_addr"`
+	WebhookSecret string `toml:"webhook_secret"`
+
+	// LinkReport is a JSON file listing dead links after each sync ("" disables)
+	LinkReport string `toml:"link_report"`
+
+	// Manifest is a JSON file mapping note UIDs to their generated files,
+	// rewritten after each full sync ("" disables)
+	Manifest string `toml:"manifest"`
+
+	// Report is a JSON file summarizing each sync run, replaced each time or,
+	// with ReportAppend, appended to as JSON Lines ("" disables)
+	Report       string `toml:"report"`
+	ReportAppend bool   `toml:"report_append"`
+
+	// CacheDir holds the vault's state cache (and a remote vault's checkout);
+	// empty derives a per-vault directory under the XDG cache directory
+	CacheDir string `toml:"cache_dir"`
+
+	// Once runs a single full sync and exits instead of watching the vault;
+	// a non-zero Since then skips notes not modified after it
+	Once  bool      `toml:"-"`
+	Since time.Time `toml:"-"`
 
 	// Internal paths (computed)
-	CacheDir   string `toml:"-"`
+	VaultURL   string `toml:"-"` // Remote vault (git+ssh/git+https); Vault is then its checkout
 	ConfigFile string `toml:"-"`
 }
 
 // Options represents command-line and environment variable inputs
 type Options struct {
-	Vault           string
-	Repo            string
-	ContentDir      string
-	AutoWeight      bool
-	LinkFormat      string
-	UnpublishedLink string
-	Interval        string
-	LogLevel        string
-	DryRun          bool
-	ConfigFile      string
+	Vault              string
+	Repo               string
+	ContentDir         string
+	RootSection        *string // Nil when not given, so an explicit "" can override
+	AutoWeight         bool
+	WeightStep         int
+	NumberPrefix       string
+	DateFromFilename   string
+	SlugStyle          string
+	MaxSectionDepth    int
+	IndexTemplate      string
+	IndexNotes         bool
+	TransformCmd       string
+	TransformTimeout   string
+	LinkFormat         string
+	UnpublishedLink    string
+	BaseURL            string
+	FrontMatterFormat  string
+	SourceEncoding     string
+	UIDKeys            []string
+	MarkdownExtensions []string
+	AttachmentsDir     string
+	AttachmentsSubdir  string
+	PublishByFolder    []string
+	ExcludeTags        []string
+	PreservePatterns   []string
+	RenameScan         bool
+	FollowSymlinks     bool
+	TimestampsUTC      bool
+	MermaidShortcode   string
+	MathMode           string
+	MathShortcode      string
+	CleanTasks         string
+	NestedTagMode      string
+	StripPublishTag    bool
+	StripH1            string
+	RespectDraft       *bool // Nil when not given, so an explicit false can override
+	EmitDraft          bool
+	EnforceSchedule    bool
+	AutoDescription    bool
+	DescriptionLength  int
+	EmitReadingStats   bool
+	ReadingWPM         int
+	OptimizeImages     bool
+	ImageMaxDimension  int
+	ImageQuality       int
+	ImageWorkers       int
+	ImageOutputDir     string
+	InlineSVGUnder     int
+	GitAutoCommit      bool
+	GitCommitThreshold int
+	GitCommitMaxDelay  string
+	GitAuthorName      string
+	GitAuthorEmail     string
+	GitCommitTemplate  string
+	GitPush            bool
+	GitPushRebase      bool
+	LastmodFromGit     bool
+	Interval           string
+	Debounce           string
+	SettleDelay        string
+	ShutdownTimeout    string
+	LogLevel           string
+	DryRun             bool
+	NoDelete           bool
+	PprofAddr          string
+	HTTPAddr           string
+	WebhookSecret      string
+	LinkReport         string
+	Manifest           string
+	Report             string
+	ReportAppend       bool
+	CacheDir           string
+	Once               bool
+	Since              string // Duration back from now or a timestamp
+	ConfigFile         string
 }
 
 // Load creates a Config by merging CLI flags, config file, and environment variables
 func Load(opts *Options) (*Config, error) {
-	cfg := &Config{
-		// Set defaults
-		ContentDir:      "content/docs",
-		AutoWeight:      true,
-		LinkFormat:      "relref",
-		UnpublishedLink: "text",
-		interval:        "30s",
-		LogLevel:        "info",
-		DryRun:          false,
-	}
+	cfg := defaultConfig()
 
 	// Load config file if specified or exists in default location
-	configPath := opts.ConfigFile
-	if configPath == "" {
-		configPath = getDefaultConfigPath()
+	configPath := configFilePath(opts)
+	if _, err := os.Stat(configPath); err == nil {
+		if err := loadConfigFile(cfg, configPath); err != nil {
+			return nil, fmt.Errorf("loading config file %s: %w", configPath, err)
+		}
 	}
 
+	applyEnvironment(cfg, opts)
+	if err := finalize(cfg, opts, configPath); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadAll creates one Config per [[sync]] table in the config file, so a
+// single process can sync several vaults. Each table inherits the file's
+// top-level settings and the CLI flags, except --vault and --repo, which
+// only apply without [[sync]] tables. Without any tables it returns the
+// single Config that Load would.
+func LoadAll(opts *Options) ([]*Config, error) {
+	base := defaultConfig()
+
+	configPath := configFilePath(opts)
+	var syncTables []toml.Primitive
+	var meta toml.MetaData
 	if _, err := os.Stat(configPath); err == nil {
-		if err := loadConfigFile(cfg, configPath); err != nil {
+		if err := loadConfigFile(base, configPath); err != nil {
+			return nil, fmt.Errorf("loading config file %s: %w", configPath, err)
+		}
+
+		var file struct {
+			Sync []toml.Primitive `toml:"sync"`
+		}
+		if meta, err = toml.DecodeFile(configPath, &file); err != nil {
 			return nil, fmt.Errorf("loading config file %s: %w", configPath, err)
 		}
+		syncTables = file.Sync
+	}
+
+	if len(syncTables) == 0 {
+		applyEnvironment(base, opts)
+		if err := finalize(base, opts, configPath); err != nil {
+			return nil, err
+		}
+		return []*Config{base}, nil
+	}
+
+	if opts.Vault != "" || opts.Repo != "" {
+		return nil, fmt.Errorf("--vault and --repo cannot be combined with [[sync]] tables in %s", configPath)
+	}
+
+	configs := make([]*Config, 0, len(syncTables))
+	vaults := make(map[string]int, len(syncTables))
+	caches := make(map[string]int, len(syncTables))
+	for i, table := range syncTables {
+		cfg := *base
+		cfg.SectionRoutes = maps.Clone(base.SectionRoutes) // Tables must not share the base maps
+		cfg.FrontMatterMap = maps.Clone(base.FrontMatterMap)
+		if err := meta.PrimitiveDecode(table, &cfg); err != nil {
+			return nil, fmt.Errorf("loading sync table %d: %w", i+1, err)
+		}
+		if err := finalize(&cfg, opts, configPath); err != nil {
+			return nil, fmt.Errorf("sync table %d: %w", i+1, err)
+		}
+
+		// Each vault has a single lock and state cache
+		vaultAbs, _ := filepath.Abs(cfg.Vault)
+		if previous, exists := vaults[vaultAbs]; exists {
+			return nil, fmt.Errorf("sync tables %d and %d both use vault %q", previous, i+1, cfg.Vault)
+		}
+		vaults[vaultAbs] = i + 1
+		cacheAbs, _ := filepath.Abs(cfg.CacheDir)
+		if previous, exists := caches[cacheAbs]; exists {
+			return nil, fmt.Errorf("sync tables %d and %d both use cache directory %q", previous, i+1, cfg.CacheDir)
+		}
+		caches[cacheAbs] = i + 1
+
+		configs = append(configs, &cfg)
 	}
 
-	// Override with CLI flags and environment variables
+	return configs, nil
+}
+
+// defaultConfig returns a Config holding the default settings
+func defaultConfig() *Config {
+	return &Config{
+		// Set defaults
+		ContentDir:         "content/docs",
+		RootSection:        "posts",
+		AutoWeight:         true,
+		WeightStep:         10,
+		NumberPrefix:       "keep",
+		SlugStyle:          "kebab",
+		LinkFormat:         "relref",
+		UnpublishedLink:    "text",
+		FrontMatterFormat:  "yaml",
+		SourceEncoding:     "utf-8",
+		UIDKeys:            []string{"noteUid"},
+		MarkdownExtensions: []string{".md"},
+		MathMode:           "keep",
+		CleanTasks:         "keep",
+		NestedTagMode:      "keep",
+		StripH1:            "keep",
+		MathShortcode:      "math",
+		RespectDraft:       true,
+		DescriptionLength:  160,
+		ReadingWPM:         200,
+		ImageMaxDimension:  2048,
+		ImageQuality:       85,
+		ImageWorkers:       4,
+		GitCommitThreshold: 1,
+		GitCommitMaxDelay:  5 * time.Minute,
+		GitAuthorName:      "obsidian-hugo-sync",
+		GitAuthorEmail:     "obsidian-hugo-sync@automated",
+		Interval:           30 * time.Second,
+		Debounce:           300 * time.Millisecond,
+		SettleDelay:        time.Second,
+		ShutdownTimeout:    30 * time.Second,
+		TransformTimeout:   10 * time.Second,
+		LogLevel:           "info",
+		DryRun:             false,
+	}
+}
+
+// configFilePath returns the config file given on the command line or the default location
+func configFilePath(opts *Options) string {
+	if opts.ConfigFile != "" {
+		return opts.ConfigFile
+	}
+	return getDefaultConfigPath()
+}
+
+// finalize applies CLI overrides to a loaded Config, validates it and sets
+// computed paths
+func finalize(cfg *Config, opts *Options, configPath string) error {
+	// Override with CLI flags
 	if err := applyOverrides(cfg, opts); err != nil {
-		return nil, fmt.Errorf("applying configuration overrides: %w", err)
+		return fmt.Errorf("applying configuration overrides: %w", err)
 	}
 
-	// Parse interval string to duration
-	interval, err := time.ParseDuration(cfg.interval)
-	if err != nil {
-		return nil, fmt.Errorf("invalid interval %q: %w", cfg.interval, err)
+	// A remote vault is synced from its checkout in the cache directory
+	if git.IsRemoteVault(cfg.Vault) {
+		cfg.VaultURL = cfg.Vault
 	}
-	cfg.Interval = interval
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
 	// Set computed paths
 	if err := cfg.setComputedPaths(); err != nil {
-		return nil, fmt.Errorf("setting computed paths: %w", err)
+		return fmt.Errorf("setting computed paths: %w", err)
 	}
 
 	cfg.ConfigFile = configPath
-	return cfg, nil
+	return nil
 }
 
 // Validate checks that all required configuration is present and valid
@@ -108,11 +410,13 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("hugo directory path is required")
 	}
 
-	// Check that vault exists
-	if stat, err := os.Stat(c.Vault); err != nil {
-		return fmt.Errorf("vault path %q: %w", c.Vault, err)
-	} else if !stat.IsDir() {
-		return fmt.Errorf("vault path %q is not a directory", c.Vault)
+	// Check that vault exists (remote vaults are cloned on startup)
+	if c.VaultURL == "" {
+		if stat, err := os.Stat(c.Vault); err != nil {
+			return fmt.Errorf("vault path %q: %w", c.Vault, err)
+		} else if !stat.IsDir() {
+			return fmt.Errorf("vault path %q is not a directory", c.Vault)
+		}
 	}
 
 	// Check that hugo directory exists
@@ -122,14 +426,134 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("hugo directory path %q is not a directory", c.Repo)
 	}
 
+	// Validate root section
+	if filepath.IsAbs(c.RootSection) || strings.Contains(filepath.ToSlash(c.RootSection), "..") {
+		return fmt.Errorf("root-section must be a relative section name, got %q", c.RootSection)
+	}
+
+	// Validate attachments folder
+	if filepath.IsAbs(c.AttachmentsDir) || strings.Contains(filepath.ToSlash(c.AttachmentsDir), "..") {
+		return fmt.Errorf("attachments-dir must be a folder inside the vault, got %q", c.AttachmentsDir)
+	}
+	if c.AttachmentsSubdir != "" && (strings.ContainsAny(c.AttachmentsSubdir, `/\`) || c.AttachmentsSubdir == "." || c.AttachmentsSubdir == "..") {
+		return fmt.Errorf("attachments-subdir must be a single folder name, got %q", c.AttachmentsSubdir)
+	}
+
+	// Validate publish folders
+	for _, folder := range c.PublishByFolder {
+		if folder == "" || filepath.IsAbs(folder) || strings.Contains(filepath.ToSlash(folder), "..") {
+			return fmt.Errorf("publish-by-folder must list folders inside the vault, got %q", folder)
+		}
+	}
+
+	// Validate exclude tags
+	for _, tag := range c.ExcludeTags {
+		if strings.Trim(tag, "# ") == "" {
+			return fmt.Errorf("exclude-tag must not contain empty tags, got %q", c.ExcludeTags)
+		}
+	}
+
+	// Validate preserve patterns
+	for _, pattern := range c.PreservePatterns {
+		if strings.TrimSpace(pattern) == "" || filepath.IsAbs(pattern) || strings.Contains(filepath.ToSlash(pattern), "..") {
+			return fmt.Errorf("preserve-hugo-files must list globs inside the content directory, got %q", pattern)
+		}
+	}
+
+	// Validate section routes
+	for folder, contentDir := range c.SectionRoutes {
+		folder = strings.Trim(filepath.ToSlash(folder), "/")
+		if folder == "" || folder == "." || strings.Contains(folder, "..") {
+			return fmt.Errorf("section_routes folder must be a relative vault folder, got %q", folder)
+		}
+		if contentDir == "" || filepath.IsAbs(contentDir) || strings.Contains(filepath.ToSlash(contentDir), "..") {
+			return fmt.Errorf("section_routes path for %q must be relative to the Hugo repo, got %q", folder, contentDir)
+		}
+	}
+
+	// Validate the front-matter map; the sync's own keys identify its files
+	for from, to := range c.FrontMatterMap {
+		if from == "" || to == "" {
+			return fmt.Errorf("front_matter_map keys and values cannot be empty, got %q = %q", from, to)
+		}
+		for _, key := range []string{from, to} {
+			if key == "noteUid" || key == "lastUpdated" {
+				return fmt.Errorf("front_matter_map cannot rename %s", key)
+			}
+		}
+	}
+
+	// Validate weight step
+	if c.WeightStep < 1 {
+		return fmt.Errorf("weight-step must be at least 1, got %d", c.WeightStep)
+	}
+
+	// Validate number prefix handling
+	if c.NumberPrefix != "keep" && c.NumberPrefix != "weight" && c.NumberPrefix != "strip" {
+		return fmt.Errorf("number-prefix must be 'keep', 'weight' or 'strip', got %q", c.NumberPrefix)
+	}
+
+	// Validate the filename date layout
+	if c.DateFromFilename != "" && !hugo.ValidDateLayout(c.DateFromFilename) {
+		return fmt.Errorf("date-from-filename must be a Go date layout with year, month and day, e.g. 2006-01-02, got %q", c.DateFromFilename)
+	}
+
+	// Validate slug style
+	if c.SlugStyle != "kebab" && c.SlugStyle != "snake" && c.SlugStyle != "preserve" {
+		return fmt.Errorf("slug-style must be 'kebab', 'snake' or 'preserve', got %q", c.SlugStyle)
+	}
+
+	if c.MaxSectionDepth < 0 {
+		return fmt.Errorf("max-section-depth must not be negative, got %d", c.MaxSectionDepth)
+	}
+
+	// Validate section index template
+	if c.IndexTemplate != "" {
+		if _, err := hugo.LoadIndexTemplate(c.IndexTemplate); err != nil {
+			return fmt.Errorf("index-template is invalid: %w", err)
+		}
+	}
+
 	// Validate link format
 	if c.LinkFormat != "relref" && c.LinkFormat != "md" {
 		return fmt.Errorf("link-format must be 'relref' or 'md', got %q", c.LinkFormat)
 	}
 
 	// Validate unpublished link handling
-	if c.UnpublishedLink != "text" && c.UnpublishedLink != "hash" {
-		return fmt.Errorf("unpublished-link must be 'text' or 'hash', got %q", c.UnpublishedLink)
+	if c.UnpublishedLink != "text" && c.UnpublishedLink != "hash" && c.UnpublishedLink != "stub" {
+		return fmt.Errorf("unpublished-link must be 'text', 'hash' or 'stub', got %q", c.UnpublishedLink)
+	}
+
+	// Validate the site base URL
+	if _, err := url.Parse(c.BaseURL); err != nil {
+		return fmt.Errorf("base-url must be a URL or path, got %q", c.BaseURL)
+	}
+
+	// Validate front-matter format
+	if c.FrontMatterFormat != "yaml" && c.FrontMatterFormat != "toml" && c.FrontMatterFormat != "json" {
+		return fmt.Errorf("front-matter-format must be 'yaml', 'toml' or 'json', got %q", c.FrontMatterFormat)
+	}
+
+	// Validate math handling
+	if c.MathMode != "keep" && c.MathMode != "shortcode" {
+		return fmt.Errorf("math-mode must be 'keep' or 'shortcode', got %q", c.MathMode)
+	}
+	if c.CleanTasks != "keep" && c.CleanTasks != "strip" && c.CleanTasks != "suffix" {
+		return fmt.Errorf("clean-tasks must be 'keep', 'strip' or 'suffix', got %q", c.CleanTasks)
+	}
+	if c.NestedTagMode != "keep" && c.NestedTagMode != "expand" && c.NestedTagMode != "split" {
+		return fmt.Errorf("nested-tag-mode must be 'keep', 'expand' or 'split', got %q", c.NestedTagMode)
+	}
+	if c.StripH1 != "keep" && c.StripH1 != "match" && c.StripH1 != "always" {
+		return fmt.Errorf("strip-h1 must be 'keep', 'match' or 'always', got %q", c.StripH1)
+	}
+	if c.MathMode == "shortcode" && c.MathShortcode == "" {
+		return fmt.Errorf("math-shortcode is required when math-mode is 'shortcode'")
+	}
+
+	// Validate source encoding
+	if !vault.IsSupportedEncoding(c.SourceEncoding) {
+		return fmt.Errorf("source-encoding must be 'utf-8', 'windows-1252', 'latin-1' or 'auto', got %q", c.SourceEncoding)
 	}
 
 	// Validate log level
@@ -150,35 +574,177 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("interval must be at least 1 second, got %v", c.Interval)
 	}
 
+	// Validate debounce window
+	if c.Debounce < 0 {
+		return fmt.Errorf("debounce must not be negative, got %v", c.Debounce)
+	}
+	if c.SettleDelay < 0 {
+		return fmt.Errorf("settle-delay must not be negative, got %v", c.SettleDelay)
+	}
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown-timeout must not be negative, got %v", c.ShutdownTimeout)
+	}
+	if c.TransformTimeout < 0 {
+		return fmt.Errorf("transform-timeout must not be negative, got %v", c.TransformTimeout)
+	}
+
+	// Validate UID keys
+	if len(c.UIDKeys) == 0 {
+		return fmt.Errorf("uid-keys must list at least one front-matter key")
+	}
+	for _, key := range c.UIDKeys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("uid-keys must not contain empty keys, got %q", c.UIDKeys)
+		}
+	}
+
+	// Validate markdown extensions; Hugo output is always .md
+	if len(c.MarkdownExtensions) == 0 {
+		return fmt.Errorf("markdown-extensions must list at least one extension")
+	}
+	for _, ext := range c.MarkdownExtensions {
+		if len(ext) < 2 || ext[0] != '.' || strings.ContainsAny(ext[1:], `./\`) {
+			return fmt.Errorf("markdown-extensions must be extensions like .md or .markdown, got %q", ext)
+		}
+	}
+
+	// Validate derived description length
+	if c.DescriptionLength < 1 {
+		return fmt.Errorf("description-length must be at least 1, got %d", c.DescriptionLength)
+	}
+	if c.ReadingWPM < 0 {
+		return fmt.Errorf("reading-wpm must not be negative, got %d", c.ReadingWPM)
+	}
+
+	// Validate image optimization
+	if c.ImageMaxDimension < 1 {
+		return fmt.Errorf("image-max-dimension must be at least 1, got %d", c.ImageMaxDimension)
+	}
+	if c.ImageQuality < 1 || c.ImageQuality > 100 {
+		return fmt.Errorf("image-quality must be between 1 and 100, got %d", c.ImageQuality)
+	}
+	if c.ImageWorkers < 1 {
+		return fmt.Errorf("image-workers must be at least 1, got %d", c.ImageWorkers)
+	}
+	if c.InlineSVGUnder < 0 {
+		return fmt.Errorf("inline-svg-under must not be negative, got %d", c.InlineSVGUnder)
+	}
+	if c.ImageOutputDir != "" && (filepath.IsAbs(c.ImageOutputDir) || strings.Contains(filepath.ToSlash(c.ImageOutputDir), "..")) {
+		return fmt.Errorf("image-output-dir must be relative to the Hugo repo, got %q", c.ImageOutputDir)
+	}
+
+	// Validate git commit batching
+	if c.GitCommitThreshold < 1 {
+		return fmt.Errorf("git-commit-threshold must be at least 1, got %d", c.GitCommitThreshold)
+	}
+	if c.GitCommitMaxDelay < 0 {
+		return fmt.Errorf("git-commit-max-delay must not be negative, got %v", c.GitCommitMaxDelay)
+	}
+	if c.GitAuthorName == "" || c.GitAuthorEmail == "" {
+		return fmt.Errorf("git-author-name and git-author-email must not be empty")
+	}
+	if c.GitPush && !c.GitAutoCommit {
+		return fmt.Errorf("git-push requires git-auto-commit")
+	}
+	if c.GitPushRebase && !c.GitPush {
+		return fmt.Errorf("git-push-rebase requires git-push")
+	}
+	if _, err := git.NewCommitMessage(c.GitCommitTemplate); err != nil {
+		return fmt.Errorf("git-commit-template is invalid: %w", err)
+	}
+
+	// Skipping notes by modification time would leave a running daemon
+	// without their pages after later syncs
+	if !c.Since.IsZero() && !c.Once {
+		return fmt.Errorf("since requires once")
+	}
+
+	// Validate pprof address
+	if c.PprofAddr != "" {
+		if _, _, err := net.SplitHostPort(c.PprofAddr); err != nil {
+			return fmt.Errorf("pprof-addr must be host:port, got %q", c.PprofAddr)
+		}
+	}
+
+	// Validate the cache directory override
+	if c.CacheDir != "" {
+		if err := checkWritableDir(c.CacheDir); err != nil {
+			return fmt.Errorf("cache-dir %q is not writable: %w", c.CacheDir, err)
+		}
+	}
+
+	// Validate webhook address; the endpoint is never served unauthenticated
+	if c.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HTTPAddr); err != nil {
+			return fmt.Errorf("http-addr must be host:port, got %q", c.HTTPAddr)
+		}
+		if c.WebhookSecret == "" {
+			return fmt.Errorf("webhook-secret is required when http-addr is set")
+		}
+	}
+
 	return nil
 }
 
 // setComputedPaths calculates derived paths like cache directory
 func (c *Config) setComputedPaths() error {
-	// Create cache directory based on vault path hash
-	vaultAbs, err := filepath.Abs(c.Vault)
-	if err != nil {
-		return fmt.Errorf("getting absolute vault path: %w", err)
-	}
+	// Without an override, create cache directory based on vault path (or remote URL) hash
+	if c.CacheDir == "" {
+		vaultKey := c.VaultURL
+		if vaultKey == "" {
+			vaultAbs, err := filepath.Abs(c.Vault)
+			if err != nil {
+				return fmt.Errorf("getting absolute vault path: %w", err)
+			}
+			vaultKey = vaultAbs
+		}
 
-	vaultHash := hashString(vaultAbs)
-	c.CacheDir = getCacheDir(vaultHash)
+		vaultHash := hashString(vaultKey)
+		c.CacheDir = getCacheDir(vaultHash)
+	}
 
 	// Ensure cache directory exists
 	if err := os.MkdirAll(c.CacheDir, 0755); err != nil {
 		return fmt.Errorf("creating cache directory %s: %w", c.CacheDir, err)
 	}
 
+	// Remote vaults are checked out next to the state cache
+	if c.VaultURL != "" {
+		c.Vault = filepath.Join(c.CacheDir, "vault")
+	}
+
 	return nil
 }
 
+// checkWritableDir creates dir if needed and checks a file can be written in it
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// LockDir returns the directory holding the process lock: the vault itself,
+// or the cache directory for a remote vault whose checkout may not exist yet
+func (c *Config) LockDir() string {
+	if c.VaultURL != "" {
+		return c.CacheDir
+	}
+	return c.Vault
+}
+
 // loadConfigFile reads and parses a TOML configuration file
 func loadConfigFile(cfg *Config, path string) error {
 	_, err := toml.DecodeFile(path, cfg)
 	return err
 }
 
-// applyOverrides applies CLI flags and environment variables over config file values
+// applyOverrides applies CLI flags over config file values
 func applyOverrides(cfg *Config, opts *Options) error {
 	// Apply CLI flags (they override everything)
 	if opts.Vault != "" {
@@ -190,31 +756,262 @@ func applyOverrides(cfg *Config, opts *Options) error {
 	if opts.ContentDir != "" {
 		cfg.ContentDir = opts.ContentDir
 	}
+	if opts.RootSection != nil {
+		cfg.RootSection = *opts.RootSection
+	}
+	if opts.WeightStep != 0 {
+		cfg.WeightStep = opts.WeightStep
+	}
+	if opts.NumberPrefix != "" {
+		cfg.NumberPrefix = opts.NumberPrefix
+	}
+	if opts.DateFromFilename != "" {
+		cfg.DateFromFilename = opts.DateFromFilename
+	}
+	if opts.SlugStyle != "" {
+		cfg.SlugStyle = opts.SlugStyle
+	}
+	if opts.MaxSectionDepth != 0 {
+		cfg.MaxSectionDepth = opts.MaxSectionDepth
+	}
+	if opts.IndexTemplate != "" {
+		cfg.IndexTemplate = opts.IndexTemplate
+	}
+	if opts.IndexNotes {
+		cfg.IndexNotes = opts.IndexNotes
+	}
+	if opts.TransformCmd != "" {
+		cfg.TransformCmd = opts.TransformCmd
+	}
+	if opts.TransformTimeout != "" {
+		duration, err := time.ParseDuration(opts.TransformTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid transform timeout %q: %w", opts.TransformTimeout, err)
+		}
+		cfg.TransformTimeout = duration
+	}
 	if opts.LinkFormat != "" {
 		cfg.LinkFormat = opts.LinkFormat
 	}
 	if opts.UnpublishedLink != "" {
 		cfg.UnpublishedLink = opts.UnpublishedLink
 	}
+	if opts.BaseURL != "" {
+		cfg.BaseURL = opts.BaseURL
+	}
+	if opts.FrontMatterFormat != "" {
+		cfg.FrontMatterFormat = opts.FrontMatterFormat
+	}
+	if opts.SourceEncoding != "" {
+		cfg.SourceEncoding = opts.SourceEncoding
+	}
+	if len(opts.UIDKeys) > 0 {
+		cfg.UIDKeys = opts.UIDKeys
+	}
+	if len(opts.MarkdownExtensions) > 0 {
+		cfg.MarkdownExtensions = opts.MarkdownExtensions
+	}
+	if opts.MermaidShortcode != "" {
+		cfg.MermaidShortcode = opts.MermaidShortcode
+	}
+	if opts.MathMode != "" {
+		cfg.MathMode = opts.MathMode
+	}
+	if opts.CleanTasks != "" {
+		cfg.CleanTasks = opts.CleanTasks
+	}
+	if opts.NestedTagMode != "" {
+		cfg.NestedTagMode = opts.NestedTagMode
+	}
+	if opts.StripH1 != "" {
+		cfg.StripH1 = opts.StripH1
+	}
+	if opts.MathShortcode != "" {
+		cfg.MathShortcode = opts.MathShortcode
+	}
+	if opts.DescriptionLength != 0 {
+		cfg.DescriptionLength = opts.DescriptionLength
+	}
+	if opts.ReadingWPM != 0 {
+		cfg.ReadingWPM = opts.ReadingWPM
+	}
+	if opts.ImageMaxDimension != 0 {
+		cfg.ImageMaxDimension = opts.ImageMaxDimension
+	}
+	if opts.ImageQuality != 0 {
+		cfg.ImageQuality = opts.ImageQuality
+	}
+	if opts.ImageWorkers != 0 {
+		cfg.ImageWorkers = opts.ImageWorkers
+	}
+	if opts.ImageOutputDir != "" {
+		cfg.ImageOutputDir = opts.ImageOutputDir
+	}
+	if opts.InlineSVGUnder != 0 {
+		cfg.InlineSVGUnder = opts.InlineSVGUnder
+	}
+	if opts.GitCommitThreshold != 0 {
+		cfg.GitCommitThreshold = opts.GitCommitThreshold
+	}
+	if opts.GitCommitMaxDelay != "" {
+		duration, err := time.ParseDuration(opts.GitCommitMaxDelay)
+		if err != nil {
+			return fmt.Errorf("invalid git commit max delay %q: %w", opts.GitCommitMaxDelay, err)
+		}
+		cfg.GitCommitMaxDelay = duration
+	}
+	if opts.GitAuthorName != "" {
+		cfg.GitAuthorName = opts.GitAuthorName
+	}
+	if opts.GitAuthorEmail != "" {
+		cfg.GitAuthorEmail = opts.GitAuthorEmail
+	}
+	if opts.GitCommitTemplate != "" {
+		cfg.GitCommitTemplate = opts.GitCommitTemplate
+	}
 	if opts.Interval != "" {
-		cfg.interval = opts.Interval
+		duration, err := time.ParseDuration(opts.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q: %w", opts.Interval, err)
+		}
+		cfg.Interval = duration
+	}
+	if opts.Debounce != "" {
+		duration, err := time.ParseDuration(opts.Debounce)
+		if err != nil {
+			return fmt.Errorf("invalid debounce %q: %w", opts.Debounce, err)
+		}
+		cfg.Debounce = duration
+	}
+	if opts.SettleDelay != "" {
+		duration, err := time.ParseDuration(opts.SettleDelay)
+		if err != nil {
+			return fmt.Errorf("invalid settle delay %q: %w", opts.SettleDelay, err)
+		}
+		cfg.SettleDelay = duration
+	}
+	if opts.ShutdownTimeout != "" {
+		duration, err := time.ParseDuration(opts.ShutdownTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid shutdown timeout %q: %w", opts.ShutdownTimeout, err)
+		}
+		cfg.ShutdownTimeout = duration
 	}
 	if opts.LogLevel != "" {
 		cfg.LogLevel = opts.LogLevel
 	}
+	if opts.PprofAddr != "" {
+		cfg.PprofAddr = opts.PprofAddr
+	}
+	if opts.HTTPAddr != "" {
+		cfg.HTTPAddr = opts.HTTPAddr
+	}
+	if opts.WebhookSecret != "" {
+		cfg.WebhookSecret = opts.WebhookSecret
+	}
+	if opts.AttachmentsDir != "" {
+		cfg.AttachmentsDir = opts.AttachmentsDir
+	}
+	if opts.AttachmentsSubdir != "" {
+		cfg.AttachmentsSubdir = opts.AttachmentsSubdir
+	}
+	if len(opts.PublishByFolder) > 0 {
+		cfg.PublishByFolder = opts.PublishByFolder
+	}
+	if len(opts.ExcludeTags) > 0 {
+		cfg.ExcludeTags = opts.ExcludeTags
+	}
+	if len(opts.PreservePatterns) > 0 {
+		cfg.PreservePatterns = opts.PreservePatterns
+	}
+	if opts.RenameScan {
+		cfg.RenameScan = opts.RenameScan
+	}
+	if opts.FollowSymlinks {
+		cfg.FollowSymlinks = opts.FollowSymlinks
+	}
+	if opts.LinkReport != "" {
+		cfg.LinkReport = opts.LinkReport
+	}
+	if opts.Manifest != "" {
+		cfg.Manifest = opts.Manifest
+	}
+	if opts.Report != "" {
+		cfg.Report = opts.Report
+	}
+	if opts.CacheDir != "" {
+		cfg.CacheDir = opts.CacheDir
+	}
+	if opts.Once {
+		cfg.Once = opts.Once
+	}
+	if opts.Since != "" {
+		since, err := ParseSince(opts.Since, time.Now())
+		if err != nil {
+			return err
+		}
+		cfg.Since = since
+	}
+	if opts.ReportAppend {
+		cfg.ReportAppend = opts.ReportAppend
+	}
+	if opts.TimestampsUTC {
+		cfg.TimestampsUTC = opts.TimestampsUTC
+	}
+	if opts.StripPublishTag {
+		cfg.StripPublishTag = opts.StripPublishTag
+	}
+	if opts.RespectDraft != nil {
+		cfg.RespectDraft = *opts.RespectDraft
+	}
+	if opts.EmitDraft {
+		cfg.EmitDraft = opts.EmitDraft
+	}
+	if opts.EnforceSchedule {
+		cfg.EnforceSchedule = opts.EnforceSchedule
+	}
+	if opts.AutoDescription {
+		cfg.AutoDescription = opts.AutoDescription
+	}
+	if opts.EmitReadingStats {
+		cfg.EmitReadingStats = opts.EmitReadingStats
+	}
+	if opts.OptimizeImages {
+		cfg.OptimizeImages = opts.OptimizeImages
+	}
+	if opts.GitAutoCommit {
+		cfg.GitAutoCommit = opts.GitAutoCommit
+	}
+	if opts.GitPush {
+		cfg.GitPush = opts.GitPush
+	}
+	if opts.GitPushRebase {
+		cfg.GitPushRebase = opts.GitPushRebase
+	}
+	if opts.LastmodFromGit {
+		cfg.LastmodFromGit = opts.LastmodFromGit
+	}
 	if opts.DryRun {
 		cfg.DryRun = opts.DryRun
 	}
+	if opts.NoDelete {
+		cfg.NoDelete = opts.NoDelete
+	}
+
+	return nil
+}
 
-	// Check for environment variable overrides
+// applyEnvironment applies environment variable overrides not already given as flags
+func applyEnvironment(cfg *Config, opts *Options) {
 	if vault := os.Getenv("OBSIDIAN_VAULT"); vault != "" && opts.Vault == "" {
 		cfg.Vault = vault
 	}
 	if repo := os.Getenv("HUGO_REPO"); repo != "" && opts.Repo == "" {
 		cfg.Repo = repo
 	}
-
-	return nil
+	if secret := os.Getenv("OBSIDIAN_HUGO_SYNC_WEBHOOK_SECRET"); secret != "" && opts.WebhookSecret == "" {
+		cfg.WebhookSecret = secret
+	}
 }
 
 // getDefaultConfigPath returns the platform-specific default config file location
@@ -252,4 +1049,22 @@ func hashString(s string) string {
 		h *= 16777619
 	}
 	return fmt.Sprintf("%08x", h)
-} 
\ No newline at end of file
+} 
+
+// ParseSince parses the --since cutoff: a duration back from now (e.g. "24h"),
+// an RFC 3339 timestamp or a local date (2006-01-02)
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration < 0 {
+			return time.Time{}, fmt.Errorf("invalid since %q: duration must not be negative", value)
+		}
+		return now.Add(-duration), nil
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	if since, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return since, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: expected a duration like 24h, an RFC 3339 timestamp or a date like 2006-01-02", value)
+}
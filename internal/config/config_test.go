@@ -0,0 +1,293 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOptions returns options for an existing vault and Hugo repo that ignore any user config file
+func testOptions(t *testing.T) *Options {
+	t.Helper()
+	return &Options{
+		Vault:      t.TempDir(),
+		Repo:       t.TempDir(),
+		ConfigFile: filepath.Join(t.TempDir(), "missing.toml"),
+	}
+}
+
+func TestCacheDirOverride(t *testing.T) {
+	opts := testOptions(t)
+	opts.CacheDir = filepath.Join(t.TempDir(), "state", "vault")
+
+	cfg, err := Load(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.CacheDir != opts.CacheDir {
+		t.Errorf("Expected cache dir %s, got %s", opts.CacheDir, cfg.CacheDir)
+	}
+	if info, err := os.Stat(cfg.CacheDir); err != nil || !info.IsDir() {
+		t.Errorf("Expected cache dir to be created, got %v", err)
+	}
+}
+
+func TestCacheDirDefaultsToVaultHash(t *testing.T) {
+	xdgCache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", xdgCache)
+	opts := testOptions(t)
+
+	cfg, err := Load(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	vaultAbs, _ := filepath.Abs(opts.Vault)
+	expected := filepath.Join(xdgCache, "obsidian-hugo-sync", hashString(vaultAbs))
+	if cfg.CacheDir != expected {
+		t.Errorf("Expected cache dir %s, got %s", expected, cfg.CacheDir)
+	}
+}
+
+func TestCacheDirMustBeWritable(t *testing.T) {
+	// A directory can't be created below a regular file
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testOptions(t)
+	opts.CacheDir = filepath.Join(file, "cache")
+	if _, err := Load(opts); err == nil {
+		t.Error("Expected an unwritable cache dir to be rejected")
+	}
+}
+
+func TestAttachmentsSubdirMustBeFolderName(t *testing.T) {
+	tests := []struct {
+		subdir string
+		valid  bool
+	}{
+		{"_attachments", true},
+		{"assets", true},
+		{"notes/_attachments", false},
+		{"..", false},
+		{".", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subdir, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.CacheDir = t.TempDir()
+			opts.AttachmentsSubdir = tt.subdir
+
+			_, err := Load(opts)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be accepted, got %v", tt.subdir, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be rejected", tt.subdir)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"24h", time.Date(2024, 5, 9, 12, 0, 0, 0, time.UTC)},
+		{"90m", time.Date(2024, 5, 10, 10, 30, 0, 0, time.UTC)},
+		{"2024-05-01T08:00:00Z", time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			since, err := ParseSince(tt.value, now)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !since.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, since)
+			}
+		})
+	}
+
+	for _, value := range []string{"yesterday", "-1h", "05/01/2024"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestSinceRequiresOnce(t *testing.T) {
+	opts := testOptions(t)
+	opts.CacheDir = t.TempDir()
+	opts.Since = "24h"
+	if _, err := Load(opts); err == nil {
+		t.Error("Expected since without once to be rejected")
+	}
+
+	opts.Once = true
+	cfg, err := Load(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Since.IsZero() || time.Since(cfg.Since) < 24*time.Hour {
+		t.Errorf("Expected a cutoff 24h back, got %v", cfg.Since)
+	}
+}
+
+func TestFrontMatterMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		valid bool
+	}{
+		{"renames", "created = \"date\"\nsummary = \"description\"\n", true},
+		{"empty target", "created = \"\"\n", false},
+		{"uid key", "id = \"noteUid\"\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.CacheDir = t.TempDir()
+			opts.ConfigFile = filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(opts.ConfigFile, []byte("[front_matter_map]\n"+tt.table), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(opts)
+			if tt.valid && err != nil {
+				t.Fatalf("Expected the map to be accepted, got %v", err)
+			}
+			if !tt.valid {
+				if err == nil {
+					t.Error("Expected the map to be rejected")
+				}
+				return
+			}
+			if cfg.FrontMatterMap["created"] != "date" || cfg.FrontMatterMap["summary"] != "description" {
+				t.Errorf("Expected the map to be loaded, got %v", cfg.FrontMatterMap)
+			}
+		})
+	}
+}
+
+func TestDateFromFilenameMustBeDateLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		valid  bool
+	}{
+		{"2006-01-02", true},
+		{"20060102", true},
+		{"2 January 2006", true},
+		{"2006-01", false},
+		{"YYYY-MM-DD", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.CacheDir = t.TempDir()
+			opts.DateFromFilename = tt.layout
+
+			_, err := Load(opts)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be accepted, got %v", tt.layout, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be rejected", tt.layout)
+			}
+		})
+	}
+}
+
+func TestMarkdownExtensionsMustBeExtensions(t *testing.T) {
+	tests := []struct {
+		extensions []string
+		valid      bool
+	}{
+		{[]string{".md"}, true},
+		{[]string{".md", ".markdown", ".mdx"}, true},
+		{[]string{"md"}, false},
+		{[]string{".md.txt"}, false},
+		{[]string{"."}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.extensions, ","), func(t *testing.T) {
+			opts := testOptions(t)
+			opts.CacheDir = t.TempDir()
+			opts.MarkdownExtensions = tt.extensions
+
+			_, err := Load(opts)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be accepted, got %v", tt.extensions, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be rejected", tt.extensions)
+			}
+		})
+	}
+}
+
+func TestDurationsFromConfigFile(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		duration func(*Config) time.Duration
+		expected time.Duration
+	}{
+		{"interval", "2m", func(c *Config) time.Duration { return c.Interval }, 2 * time.Minute},
+		{"debounce", "2s", func(c *Config) time.Duration { return c.Debounce }, 2 * time.Second},
+		{"settle_delay", "0", func(c *Config) time.Duration { return c.SettleDelay }, 0},
+		{"shutdown_timeout", "1m30s", func(c *Config) time.Duration { return c.ShutdownTimeout }, 90 * time.Second},
+		{"transform_timeout", "500ms", func(c *Config) time.Duration { return c.TransformTimeout }, 500 * time.Millisecond},
+		{"git_commit_max_delay", "1h", func(c *Config) time.Duration { return c.GitCommitMaxDelay }, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.CacheDir = t.TempDir()
+			opts.ConfigFile = filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(opts.ConfigFile, []byte(fmt.Sprintf("%s = %q\n", tt.key, tt.value)), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if got := tt.duration(cfg); got != tt.expected {
+				t.Errorf("Expected %s = %v, got %v", tt.key, tt.expected, got)
+			}
+		})
+	}
+
+	// Flags still override the config file, and bad values are reported
+	opts := testOptions(t)
+	opts.CacheDir = t.TempDir()
+	opts.ConfigFile = filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(opts.ConfigFile, []byte("debounce = \"2s\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Debounce = "1s"
+	if cfg, err := Load(opts); err != nil || cfg.Debounce != time.Second {
+		t.Errorf("Expected --debounce to override the config file, got %v (%v)", cfg, err)
+	}
+	if err := os.WriteFile(opts.ConfigFile, []byte("debounce = \"soon\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Debounce = ""
+	if _, err := Load(opts); err == nil {
+		t.Error("Expected an invalid duration in the config file to be rejected")
+	}
+}
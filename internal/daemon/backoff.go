@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	stderrors "errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"obsidian-hugo-sync/internal/errors"
+)
+
+// Cooldowns after a write fails on a full or read-only file system, doubling
+// with every failed attempt up to the maximum
+const (
+	minWriteBackoff = 30 * time.Second
+	maxWriteBackoff = 10 * time.Minute
+)
+
+// errWritesPaused is returned for writes skipped during a cooldown
+var errWritesPaused = stderrors.New("writes to the Hugo repository are paused")
+
+// writeBackoff tracks the pause in Hugo writes after a file system failure
+type writeBackoff struct {
+	mu    sync.Mutex
+	until time.Time     // Writes are skipped until then
+	delay time.Duration // Current cooldown, zero when writes are healthy
+}
+
+// writesPaused reports whether Hugo writes are paused after a file system failure
+func (d *Daemon) writesPaused() bool {
+	d.writeBackoff.mu.Lock()
+	defer d.writeBackoff.mu.Unlock()
+	return time.Now().Before(d.writeBackoff.until)
+}
+
+// checkWrite records the outcome of a write to the Hugo repository. Failures
+// from a full disk, a read-only file system or missing permissions pause
+// writes for a growing cooldown and come back as a FileSystem error; the
+// first successful write afterwards ends the pause.
+func (d *Daemon) checkWrite(operation string, err error) error {
+	backoff := &d.writeBackoff
+	backoff.mu.Lock()
+	defer backoff.mu.Unlock()
+
+	if err == nil {
+		if backoff.delay > 0 {
+			slog.Info("Hugo repository is writable again, resuming syncs")
+			backoff.until, backoff.delay = time.Time{}, 0
+		}
+		return nil
+	}
+
+	fsErr := errors.ClassifyWriteError(operation, err)
+	if fsErr == nil {
+		return err
+	}
+
+	backoff.delay = min(max(backoff.delay*2, minWriteBackoff), maxWriteBackoff)
+	backoff.until = time.Now().Add(backoff.delay)
+
+	slog.Error(fsErr.UserMessage+", pausing writes to the Hugo repository",
+		"retry_in", backoff.delay,
+		"suggestion", fsErr.Suggestions[0],
+		"error", err)
+	return fsErr
+}
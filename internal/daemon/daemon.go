@@ -2,12 +2,19 @@ package daemon
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"obsidian-hugo-sync/internal/config"
+	"obsidian-hugo-sync/internal/diff"
+	"obsidian-hugo-sync/internal/errors"
+	"obsidian-hugo-sync/internal/git"
 	"obsidian-hugo-sync/internal/hugo"
 	"obsidian-hugo-sync/internal/images"
 	"obsidian-hugo-sync/internal/state"
+	"obsidian-hugo-sync/internal/transform"
 	"obsidian-hugo-sync/internal/vault"
 	"obsidian-hugo-sync/internal/watcher"
 	"os"
@@ -16,6 +23,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/BurntSushi/toml"
 )
 
 // Daemon orchestrates the sync process between Obsidian vault and Hugo repository
@@ -23,44 +32,258 @@ type Daemon struct {
 	config       *config.Config
 	stateManager *state.Manager
 	hugoGen      *hugo.Generator
+	transform    *transform.Command // Nil without --transform-cmd
 	imageManager *images.Manager
 	watcher      *watcher.Watcher
+	parseOptions vault.ParseOptions
+	scanOptions  vault.ScanOptions
+	preserve     *vault.IgnoreMatcher // Content-relative files never deleted, nil if none
+	gitRepo      *git.Repository      // Nil unless git auto-commit is enabled
+	vaultRepo    *git.VaultCheckout   // Nil unless the vault is a git remote
+	commitBatch  *git.CommitBatch
+	commitMsg    *git.CommitMessage
+	diffOutput   io.Writer // Receives dry-run content diffs
+	readRetry    *errors.RetryConfig
+	resync       chan struct{}        // Pending manual full resync requests
+	syncNow      chan chan SyncResult // Full syncs requested by SyncNow, answered on the channel sent
+	syncErrors   *errors.Collector    // Errors of the full sync in progress, nil otherwise
+	claimedUIDs  map[string]string    // UID -> note path claiming it in the full sync in progress, nil otherwise
+	dirLocks     dirLocks             // Serializes writes and pruning per content directory
+	writeFile    func(name string, data []byte, perm os.FileMode) error
+	removeFile   func(name string) error
+	writeBackoff writeBackoff         // Pause in Hugo writes after a full or read-only file system
+	settled      chan watcher.Event   // Note events whose files have stopped growing
+	settling     map[string]bool      // Notes waiting to settle, owned by the event loop
+	schedule     map[string]time.Time // Note path -> next publishDate or expiryDate to re-evaluate it at
 	
 	// Internal state
 	isRunning       bool
 	lastSync        time.Time
 	needsLinkUpdate bool
+	pendingFullSync bool        // A full sync is owed once writes resume
+	lastFullSync    SyncResult  // Counts of the last completed full sync
+	report          *syncReport // Report of the sync in progress for --report, nil until something is recorded
+}
+
+// SyncResult reports the outcome of a full sync requested through SyncNow
+type SyncResult struct {
+	Vault     string `json:"vault"`
+	Processed int    `json:"processed"`
+	Published int    `json:"published"`
+	Failed    int    `json:"failed"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
 }
 
 // New creates a new daemon instance
 func New(cfg *config.Config) (*Daemon, error) {
+	// Remote vaults are cloned before anything reads the checkout
+	var vaultRepo *git.VaultCheckout
+	if cfg.VaultURL != "" {
+		var err error
+		vaultRepo, err = git.OpenVault(cfg.VaultURL, cfg.Vault)
+		if err != nil {
+			return nil, fmt.Errorf("opening remote vault: %w", err)
+		}
+	}
+
 	// Initialize state manager
 	stateManager, err := state.NewManager(cfg.CacheDir, cfg.Vault)
 	if err != nil {
 		return nil, fmt.Errorf("creating state manager: %w", err)
 	}
 
-	// No Git repository needed - just copy files to Hugo directory
+	// Git repository is only needed when auto-commit is enabled
+	var gitRepo *git.Repository
+	if cfg.GitAutoCommit {
+		gitRepo, err = git.NewRepository(cfg.Repo, "", "", cfg.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("opening hugo git repository: %w", err)
+		}
+		gitRepo.SetAuthor(cfg.GitAuthorName, cfg.GitAuthorEmail)
+		gitRepo.SetRebaseOnConflict(cfg.GitPushRebase)
+	}
+	commitMsg, err := git.NewCommitMessage(cfg.GitCommitTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("creating commit message template: %w", err)
+	}
 
 	// Initialize Hugo generator
 	hugoGen := hugo.NewGenerator(cfg.Vault, cfg.ContentDir, cfg.LinkFormat, cfg.UnpublishedLink)
+	hugoGen.SetBaseURL(cfg.BaseURL)
+	hugoGen.SetFrontMatterFormat(cfg.FrontMatterFormat)
+	hugoGen.SetTimestampsUTC(cfg.TimestampsUTC)
+	hugoGen.SetNumberPrefix(cfg.NumberPrefix)
+	hugoGen.SetDateFromFilename(cfg.DateFromFilename)
+	hugoGen.SetSlugStyle(cfg.SlugStyle)
+	hugoGen.SetMaxSectionDepth(cfg.MaxSectionDepth)
+	hugoGen.SetRootSection(cfg.RootSection)
+	hugoGen.SetSectionRoutes(cfg.SectionRoutes)
+	hugoGen.SetFrontMatterMap(cfg.FrontMatterMap)
+	hugoGen.SetNoteExtensions(cfg.MarkdownExtensions)
+	hugoGen.SetIndexNotes(cfg.IndexNotes)
+	hugoGen.SetMermaidShortcode(cfg.MermaidShortcode)
+	hugoGen.SetStripPublishTag(cfg.StripPublishTag)
+	hugoGen.SetTaskMetadata(cfg.CleanTasks)
+	hugoGen.SetNestedTagMode(cfg.NestedTagMode)
+	hugoGen.SetStripH1(cfg.StripH1)
+	hugoGen.SetEmitDraft(cfg.EmitDraft)
+	if cfg.AutoDescription {
+		hugoGen.SetAutoDescription(cfg.DescriptionLength)
+	}
+	if cfg.EmitReadingStats {
+		hugoGen.SetReadingStats(cfg.ReadingWPM)
+	}
+	if cfg.MathMode == "shortcode" {
+		hugoGen.SetMathShortcode(cfg.MathShortcode)
+	}
+	if cfg.IndexTemplate != "" {
+		indexTemplate, err := hugo.LoadIndexTemplate(cfg.IndexTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("loading index template: %w", err)
+		}
+		hugoGen.SetIndexTemplate(indexTemplate)
+	}
 
-	// Initialize image manager
+	// Take lastmod from the vault's commit history, falling back to file times
+	if cfg.LastmodFromGit {
+		history, err := git.OpenHistory(cfg.Vault)
+		if err != nil {
+			return nil, fmt.Errorf("lastmod-from-git needs the vault in a git repository: %w", err)
+		}
+		hugoGen.SetLastmod(func(note *vault.Note) time.Time {
+			if when, ok := history.LastCommitTime(note.Path); ok {
+				return when
+			}
+			return note.ModTime
+		})
+	}
+
+	// Initialize image manager, seeding content hashes of previously copied images
 	imageManager := images.NewManager(cfg.Vault, cfg.Repo, cfg.ContentDir, cfg.DryRun)
+	if cfg.OptimizeImages {
+		imageManager.SetOptimize(cfg.ImageMaxDimension, cfg.ImageQuality)
+	}
+	imageManager.SetWorkers(cfg.ImageWorkers)
+	if cfg.InlineSVGUnder > 0 {
+		imageManager.SetInlineSVGUnder(int64(cfg.InlineSVGUnder))
+		hugoGen.SetInlineSVG(imageManager.InlineSVG)
+	}
+	if cfg.ImageOutputDir != "" {
+		imageManager.SetOutputDir(cfg.ImageOutputDir)
+		hugoGen.SetImageURL(imageManager.SiteURL)
+	}
+	for imagePath, image := range stateManager.GetAllImages() {
+		if image.Optimized {
+			imageManager.RegisterOptimizedImage(imagePath, image.Hash, image.OutputHash)
+			continue
+		}
+		imageManager.RegisterImageHash(imagePath, image.Hash)
+	}
+
+	parseOptions := vault.ParseOptions{
+		SourceEncoding:  cfg.SourceEncoding,
+		PublishDrafts:   !cfg.RespectDraft || cfg.EmitDraft,
+		UIDKeys:         cfg.UIDKeys,
+		ExcludeTags:     cfg.ExcludeTags,
+		EnforceSchedule: cfg.EnforceSchedule,
+	}
+	if cfg.AttachmentsDir != "" {
+		parseOptions.AttachmentsDir = filepath.Join(cfg.Vault, cfg.AttachmentsDir)
+	}
+	parseOptions.AttachmentsSubdir = cfg.AttachmentsSubdir
+	for _, folder := range cfg.PublishByFolder {
+		parseOptions.PublishFolders = append(parseOptions.PublishFolders, filepath.Join(cfg.Vault, folder))
+	}
+	hugoGen.SetParseOptions(parseOptions)
 
 	// Initialize file watcher
-	fileWatcher, err := watcher.New(cfg.Vault, cfg.Interval)
+	// A Hugo repository kept inside the vault is never scanned or watched
+	scanOptions := vault.ScanOptions{
+		SkipDirs:       []string{cfg.Repo},
+		FollowSymlinks: cfg.FollowSymlinks,
+		Extensions:     cfg.MarkdownExtensions,
+	}
+	fileWatcher, err := watcher.New(cfg.Vault, cfg.Interval, cfg.Debounce, scanOptions)
 	if err != nil {
 		return nil, fmt.Errorf("creating file watcher: %w", err)
 	}
 
-	return &Daemon{
+	d := &Daemon{
 		config:       cfg,
 		stateManager: stateManager,
 		hugoGen:      hugoGen,
 		imageManager: imageManager,
 		watcher:      fileWatcher,
-	}, nil
+		parseOptions: parseOptions,
+		scanOptions:  scanOptions,
+		gitRepo:      gitRepo,
+		vaultRepo:    vaultRepo,
+		commitBatch:  git.NewCommitBatch(cfg.GitCommitThreshold, cfg.GitCommitMaxDelay),
+		commitMsg:    commitMsg,
+		diffOutput:   os.Stdout,
+		readRetry:    noteReadRetryConfig(),
+		resync:       make(chan struct{}, 1),
+		syncNow:      make(chan chan SyncResult),
+		writeFile:    os.WriteFile,
+		removeFile:   os.Remove,
+		settled:      make(chan watcher.Event),
+		settling:     make(map[string]bool),
+		schedule:     make(map[string]time.Time),
+	}
+	if cfg.TransformCmd != "" {
+		d.transform = transform.New(cfg.TransformCmd, cfg.TransformTimeout)
+	}
+	if cfg.NoDelete {
+		imageManager.SetKeepUnused(d.keepDeletion)
+	}
+	if len(cfg.PreservePatterns) > 0 {
+		d.preserve = vault.ParseIgnorePatterns(cfg.PreservePatterns)
+		imageManager.SetPreserve(func(path string) bool {
+			return d.isPreserved(path, false)
+		})
+	}
+	return d, nil
+}
+
+// RequestResync asks the running daemon for a full vault resync. It never
+// blocks: requests made while one is already pending are coalesced.
+func (d *Daemon) RequestResync() {
+	select {
+	case d.resync <- struct{}{}:
+	default:
+	}
+}
+
+// SyncNow runs a full sync on the running daemon's event loop, so it never
+// overlaps other syncs, and waits for its result
+func (d *Daemon) SyncNow(ctx context.Context) SyncResult {
+	reply := make(chan SyncResult, 1)
+	select {
+	case d.syncNow <- reply:
+	case <-ctx.Done():
+		return SyncResult{Vault: d.config.Vault, Error: ctx.Err().Error()}
+	}
+	
+	select {
+	case result := <-reply:
+		return result
+	case <-ctx.Done():
+		return SyncResult{Vault: d.config.Vault, Error: ctx.Err().Error()}
+	}
+}
+
+// SyncOnce runs a single full sync and commits its changes, for runs that
+// exit afterwards instead of watching the vault
+func (d *Daemon) SyncOnce() error {
+	if err := d.performFullSync(); err != nil {
+		return err
+	}
+	d.flushGitChanges()
+	if d.commitBatch.Pending() > 0 {
+		d.commitGitChanges()
+	}
+	return nil
 }
 
 // Start begins the daemon operation
@@ -70,13 +293,20 @@ func (d *Daemon) Start(ctx context.Context) error {
 
 	slog.Info("Starting daemon", "vault", d.config.Vault, "hugo_dir", d.config.Repo)
 
-	// Perform initial full sync
-	if err := d.performFullSync(); err != nil {
+	// Perform initial full sync; if the Hugo repository can't be written
+	// right now, it is retried once the write pause ends
+	if err := d.performFullSync(); err != nil && !d.writesPaused() {
 		return fmt.Errorf("initial sync failed: %w", err)
+	} else if err != nil {
+		d.pendingFullSync = true
 	}
+	d.flushGitChanges()
 
-	// Start file watcher
-	if err := d.watcher.Start(ctx); err != nil {
+	// Start file watcher; remote vaults only change through pulls, which the
+	// periodic sync does instead
+	if d.vaultRepo != nil {
+		slog.Info("Polling remote vault", "url", git.RedactURL(d.config.VaultURL), "interval", d.config.Interval)
+	} else if err := d.watcher.Start(ctx); err != nil {
 		return fmt.Errorf("starting file watcher: %w", err)
 	}
 
@@ -90,25 +320,178 @@ func (d *Daemon) eventLoop(ctx context.Context) error {
 	syncTicker := time.NewTicker(d.config.Interval)
 	defer syncTicker.Stop()
 
+	// Commit timer for the max-delay trigger (nil channel when git is disabled)
+	var commitTick <-chan time.Time
+	if d.gitRepo != nil {
+		commitTicker := time.NewTicker(time.Second)
+		defer commitTicker.Stop()
+		commitTick = commitTicker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
+			// Cases run one at a time, so any sync in progress when the
+			// shutdown began has finished by now
 			slog.Info("Daemon stopping")
 			d.watcher.Stop()
+			
+			// Changes handled from file events are only saved by the next
+			// periodic sync, so save them before exiting
+			if err := d.stateManager.Save(); err != nil {
+				slog.Error("Error saving state", "error", err)
+			}
+			if d.commitBatch.Pending() > 0 {
+				d.commitGitChanges()
+			}
 			return nil
 
 		case event := <-d.watcher.Events():
+			if d.writesPaused() {
+				// The full sync after the pause picks the change up
+				slog.Debug("Hugo writes paused, deferring file event", "event", event)
+				d.pendingFullSync = true
+				continue
+			}
+			if d.settleFileEvent(ctx, event) {
+				continue
+			}
+			if err := d.handleFileEvent(event); err != nil {
+				slog.Error("Error handling file event", "event", event, "error", err)
+			}
+			d.flushGitChanges()
+		
+		case event := <-d.settled:
+			delete(d.settling, event.Path)
+			if d.writesPaused() {
+				slog.Debug("Hugo writes paused, deferring file event", "event", event)
+				d.pendingFullSync = true
+				continue
+			}
+			if _, err := os.Stat(event.Path); os.IsNotExist(err) {
+				// Removed while settling; its remove event has been handled
+				continue
+			}
 			if err := d.handleFileEvent(event); err != nil {
 				slog.Error("Error handling file event", "event", event, "error", err)
 			}
+			d.flushGitChanges()
 
 		case err := <-d.watcher.Errors():
 			slog.Error("File watcher error", "error", err)
 
 		case <-syncTicker.C:
+			if d.writesPaused() {
+				slog.Debug("Hugo writes paused, skipping periodic sync")
+				continue
+			}
+			if d.pendingFullSync {
+				d.pendingFullSync = false
+				if err := d.performFullSync(); err != nil {
+					slog.Error("Full sync after write pause failed", "error", err)
+					d.pendingFullSync = d.writesPaused()
+				}
+				d.flushGitChanges()
+				continue
+			}
+			d.pullVault()
 			if err := d.performIncrementalSync(); err != nil {
 				slog.Error("Incremental sync failed", "error", err)
 			}
+			d.flushGitChanges()
+		
+		case <-d.resync:
+			// Runs in the event loop, so it never overlaps event handling
+			slog.Info("Manual resync requested", "vault", d.config.Vault)
+			if err := d.performFullSync(); err != nil {
+				slog.Error("Manual resync failed", "error", err)
+				d.pendingFullSync = d.writesPaused()
+			} else {
+				slog.Info("Manual resync completed", "vault", d.config.Vault)
+			}
+			d.flushGitChanges()
+
+		case reply := <-d.syncNow:
+			slog.Info("Sync requested through webhook", "vault", d.config.Vault)
+			d.lastFullSync = SyncResult{}
+			err := d.performFullSync()
+			result := d.lastFullSync
+			result.Vault = d.config.Vault
+			if err != nil {
+				slog.Error("Requested sync failed", "error", err)
+				result.Error = err.Error()
+				d.pendingFullSync = d.writesPaused()
+			}
+			reply <- result
+			d.flushGitChanges()
+
+		case <-commitTick:
+			if d.commitBatch.Due(time.Now()) {
+				d.commitGitChanges()
+			}
+		}
+	}
+}
+
+// pullVault brings a remote vault's checkout up to date. A failed pull is
+// logged and the sync carries on with the last checked-out state.
+func (d *Daemon) pullVault() {
+	if d.vaultRepo == nil {
+		return
+	}
+	if _, err := d.vaultRepo.Pull(); err != nil {
+		slog.Warn("Failed to pull remote vault, syncing last checkout", "url", git.RedactURL(d.config.VaultURL), "error", err)
+	}
+}
+
+// flushGitChanges records uncommitted Hugo changes and commits them once the
+// commit batch threshold or max delay is reached
+func (d *Daemon) flushGitChanges() {
+	if d.gitRepo == nil {
+		return
+	}
+
+	status, err := d.gitRepo.GetStatus()
+	if err != nil {
+		slog.Error("Error reading git status", "error", err)
+		return
+	}
+
+	now := time.Now()
+	d.commitBatch.Observe(len(status), now)
+	if d.commitBatch.Due(now) {
+		d.commitGitChanges()
+	}
+}
+
+// commitGitChanges commits all pending Hugo changes
+func (d *Daemon) commitGitChanges() {
+	status, err := d.gitRepo.GetStatus()
+	if err != nil {
+		slog.Error("Error reading git status", "error", err)
+		return
+	}
+
+	added, modified, deleted := git.CountChanges(status)
+	message, err := d.commitMsg.Render(git.CommitInfo{
+		Added:     added,
+		Modified:  modified,
+		Deleted:   deleted,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		slog.Error("Error rendering commit message", "error", err)
+		return
+	}
+	if err := d.gitRepo.CommitChanges(message); err != nil {
+		slog.Error("Error committing changes", "error", err)
+		return
+	}
+	d.commitBatch.Reset()
+
+	if d.config.GitPush {
+		if err := d.gitRepo.Push(); err != nil {
+			slog.Error("Error pushing changes", "error", err)
 		}
 	}
 }
@@ -118,15 +501,19 @@ func (d *Daemon) handleFileEvent(event watcher.Event) error {
 	slog.Debug("Processing file event", "path", event.Path, "operation", event.Operation)
 
 	// Only process markdown files
-	if filepath.Ext(event.Path) != ".md" {
+	if !d.scanOptions.IsNoteFile(event.Path) {
 		return nil
 	}
 
+	// Folder notes configure their section index rather than being published
+	if vault.IsFolderNote(event.Path) {
+		return d.refreshFolderIndex(event.Path)
+	}
+
 	switch event.Operation {
 	case watcher.Create, watcher.Write:
-		// Add small delay to let Obsidian finish writing
-		time.Sleep(100 * time.Millisecond)
-		_, err := d.processNote(event.Path)
+		note, err := d.processNote(event.Path)
+		d.reportNote(event.Path, note, err)
 		return err
 	case watcher.Remove:
 		return d.handleNoteRemoval(event.Path)
@@ -143,40 +530,98 @@ func (d *Daemon) handleFileEvent(event watcher.Event) error {
 func (d *Daemon) performFullSync() error {
 	slog.Info("Performing full vault sync")
 	startTime := time.Now()
+	d.activeReport()
+	d.pullVault()
 
 	// Scan vault for all notes
-	notePaths, err := vault.ScanVault(d.config.Vault)
+	notePaths, err := vault.ScanVaultWithOptions(d.config.Vault, d.scanOptions)
 	if err != nil {
 		return fmt.Errorf("scanning vault: %w", err)
 	}
 
 	slog.Info("Found notes in vault", "count", len(notePaths))
 
+	// Move the Hugo files of notes renamed while stopped before syncing them
+	if d.config.RenameScan {
+		if moved := d.reconcileRenames(notePaths); moved > 0 {
+			slog.Info("Relocated renamed notes", "count", moved)
+		}
+	}
+
+	// Gather this sync's errors for the summary at the end
+	d.syncErrors = errors.NewCollector()
+	defer func() { d.syncErrors = nil }()
+	d.claimedUIDs = make(map[string]string)
+	defer func() { d.claimedUIDs = nil }()
+
 	// Process each note
-	var processed, published, errors int
+	var processed, published, failed, skipped int
+	var invalidNotes []*errors.DaemonError
 	publishedNotes := make(map[string]*vault.Note)
+	
+	// Notes skipped by --since keep their pages and count as published for
+	// links and the orphan repair, but only processed notes are regenerated
+	regenerate := publishedNotes
+	if !d.config.Since.IsZero() {
+		regenerate = make(map[string]*vault.Note)
+	}
 
 	for _, notePath := range notePaths {
+		if vault.IsFolderNote(notePath) {
+			if err := d.refreshFolderIndex(notePath); err != nil {
+				slog.Error("Error refreshing section index", "path", notePath, "error", err)
+			}
+			continue
+		}
+		
+		if d.modifiedBeforeSince(notePath) {
+			skipped++
+			if note := d.skippedPublishedNote(notePath); note != nil {
+				publishedNotes[note.UID] = note
+			}
+			continue
+		}
+		
 		note, err := d.processNote(notePath)
+		d.reportNote(notePath, note, err)
 		if err != nil {
 			slog.Error("Error processing note", "path", notePath, "error", err)
-			errors++
+			d.recordSyncError(errors.ErrorTypeVault, "processing note", err).WithContext("path", notePath)
+			failed++
+			if invalid := invalidFrontMatter(err); invalid != nil {
+				invalidNotes = append(invalidNotes, invalid)
+			}
 			continue
 		}
 
 		processed++
 		if note != nil && note.Published {
 			publishedNotes[note.UID] = note
+			regenerate[note.UID] = note
 			published++
 		}
 	}
 
+	// Notes that couldn't be written would look unpublished to the repairs
+	// below, so stop here and sync again once writes resume
+	if d.writesPaused() {
+		d.syncErrors.LogSummary()
+		return fmt.Errorf("full sync incomplete: %w", errWritesPaused)
+	}
+
 	// Update Hugo generator's slug map
 	d.hugoGen.UpdateSlugMap(publishedNotes)
 
-	// Process all published notes again for wikilink conversion
-	if err := d.regeneratePublishedContent(publishedNotes); err != nil {
-		return fmt.Errorf("regenerating published content: %w", err)
+	// Process all published notes again for wikilink conversion. Notes that
+	// fail are returned once the rest of the sync has been recorded.
+	regenerateErr := d.regeneratePublishedContent(regenerate)
+	if regenerateErr != nil {
+		slog.Error("Error regenerating published content", "error", regenerateErr)
+	}
+
+	// Unpublished links in stub mode need their target page
+	if err := d.ensureStubPage(); err != nil {
+		slog.Error("Error writing unpublished stub page", "error", err)
 	}
 
 	// Clean up unused images
@@ -200,22 +645,60 @@ func (d *Daemon) performFullSync() error {
 		slog.Error("Error saving state", "error", err)
 	}
 
+	if d.config.Manifest != "" {
+		if err := d.writeManifest(); err != nil {
+			slog.Error("Error writing manifest", "error", err)
+		}
+	}
+
 	d.lastSync = time.Now()
 	duration := time.Since(startTime)
+	d.lastFullSync = SyncResult{
+		Processed: processed,
+		Published: published,
+		Failed:    failed,
+		Duration:  duration.String(),
+	}
+	if err := d.finishReport(ReportFull); err != nil {
+		slog.Error("Error writing sync report", "error", err)
+	}
 
 	slog.Info("Full sync completed",
 		"duration", duration,
 		"processed", processed,
 		"published", published,
-		"errors", errors)
+		"errors", failed)
+	if skipped > 0 {
+		slog.Info("Skipped notes not modified since the cutoff", "count", skipped, "since", d.config.Since)
+	}
+	
+	d.logInvalidFrontMatter(invalidNotes)
+	d.syncErrors.LogSummary()
 
+	if regenerateErr != nil {
+		return fmt.Errorf("regenerating published content: %w", regenerateErr)
+	}
 	return nil
 }
 
+// recordSyncError adds an error to the running full sync's report. Outside a
+// full sync the error is only wrapped, as callers log it themselves.
+func (d *Daemon) recordSyncError(errType errors.ErrorType, operation string, err error) *errors.DaemonError {
+	if d.syncErrors == nil {
+		return errors.WrapError(errType, operation, err)
+	}
+	return d.syncErrors.Add(errType, operation, err)
+}
+
 // performIncrementalSync checks for changes and syncs only modified files
 func (d *Daemon) performIncrementalSync() error {
 	slog.Debug("Performing incremental sync")
 
+	// Catch changes the watcher missed or, when polling, never reported
+	if err := d.rescanVault(); err != nil {
+		slog.Error("Error rescanning vault", "error", err)
+	}
+
 	// Check if we need to regenerate content due to link updates (file renames)
 	if d.needsLinkUpdate {
 		slog.Info("Regenerating all published content due to file renames")
@@ -224,7 +707,7 @@ func (d *Daemon) performIncrementalSync() error {
 		publishedNotes := make(map[string]*vault.Note)
 		for uid, stateNote := range d.stateManager.GetAllNotes() {
 			if stateNote.Published {
-				note, err := vault.ParseNote(stateNote.SourcePath)
+				note, err := d.parseNote(stateNote.SourcePath)
 				if err != nil {
 					slog.Error("Error parsing note for link update", "path", stateNote.SourcePath, "error", err)
 					continue
@@ -233,8 +716,7 @@ func (d *Daemon) performIncrementalSync() error {
 			}
 		}
 		
-		// Update slug map and regenerate all published content
-		d.hugoGen.UpdateSlugMap(publishedNotes)
+		// Regenerate all published content (the slug map is kept current per note)
 		if err := d.regeneratePublishedContent(publishedNotes); err != nil {
 			slog.Error("Error regenerating published content", "error", err)
 		} else {
@@ -249,30 +731,151 @@ func (d *Daemon) performIncrementalSync() error {
 		slog.Error("Error saving state", "error", err)
 	}
 
+	if err := d.finishReport(ReportIncremental); err != nil {
+		slog.Error("Error writing sync report", "error", err)
+	}
+
 	return nil
 }
 
+// rescanVault walks the vault and processes notes modified since they were
+// last synced, then removes notes whose files are gone. Unchanged notes are
+// detected from their modification time alone, without being read.
+func (d *Daemon) rescanVault() error {
+	scanStart := time.Now()
+	
+	notePaths, err := vault.ScanVaultWithOptions(d.config.Vault, d.scanOptions)
+	if err != nil {
+		return fmt.Errorf("scanning vault: %w", err)
+	}
+	
+	// Index the state by source path for the modification check
+	statePaths := make(map[string]string)
+	for uid, stateNote := range d.stateManager.GetAllNotes() {
+		statePaths[stateNote.SourcePath] = uid
+	}
+	
+	var changed int
+	onDisk := make(map[string]bool, len(notePaths))
+	for _, notePath := range notePaths {
+		onDisk[notePath] = true
+		
+		info, err := os.Stat(notePath)
+		if err != nil {
+			continue // Removed since the scan, handled on the next pass
+		}
+		
+		if vault.IsFolderNote(notePath) {
+			if info.ModTime().After(d.lastSync) {
+				if err := d.refreshFolderIndex(notePath); err != nil {
+					slog.Error("Error refreshing section index", "path", notePath, "error", err)
+				}
+			}
+			continue
+		}
+		
+		if uid, known := statePaths[notePath]; known {
+			stateNote := d.stateManager.GetNote(uid)
+			if !d.stateManager.NeedsSync(uid, notePath, info.ModTime(), stateNote.ContentHash) && !d.scheduleDue(notePath, scanStart) {
+				continue
+			}
+		}
+		
+		changed++
+		note, err := d.processNote(notePath)
+		d.reportNote(notePath, note, err)
+		if err != nil {
+			slog.Error("Error processing note", "path", notePath, "error", err)
+		}
+	}
+	
+	// Notes still recorded at a path that no longer holds them were deleted
+	// (renamed notes were moved to their new path by processNote above)
+	var removed int
+	for _, stateNote := range d.stateManager.GetAllNotes() {
+		if !onDisk[stateNote.SourcePath] {
+			if err := d.handleNoteRemoval(stateNote.SourcePath); err != nil {
+				slog.Error("Error removing deleted note", "path", stateNote.SourcePath, "error", err)
+			}
+			removed++
+		}
+	}
+	
+	if changed > 0 || removed > 0 {
+		slog.Info("Rescan found vault changes", "changed", changed, "removed", removed)
+	}
+	
+	d.lastSync = scanStart
+	return nil
+}
+
+// invalidFrontMatter returns the note parse error when err was caused by
+// malformed front-matter, or nil otherwise
+func invalidFrontMatter(err error) *errors.DaemonError {
+	var daemonErr *errors.DaemonError
+	var fmErr *vault.FrontMatterError
+	if stderrors.As(err, &daemonErr) && stderrors.As(err, &fmErr) {
+		return daemonErr
+	}
+	return nil
+}
+
+// logInvalidFrontMatter summarizes the notes a sync skipped because of
+// malformed front-matter, so authors know which files to fix
+func (d *Daemon) logInvalidFrontMatter(invalidNotes []*errors.DaemonError) {
+	if len(invalidNotes) == 0 {
+		return
+	}
+	
+	slog.Warn("Notes with invalid front-matter were not synced", "count", len(invalidNotes))
+	for _, invalid := range invalidNotes {
+		attrs := []interface{}{"path", invalid.Context["path"]}
+		for _, key := range []string{"line", "column"} {
+			if value, ok := invalid.Context[key]; ok {
+				attrs = append(attrs, key, value)
+			}
+		}
+		var fmErr *vault.FrontMatterError
+		if stderrors.As(invalid.Err, &fmErr) {
+			attrs = append(attrs, "error", fmErr.Err)
+		}
+		slog.Warn("Invalid front-matter", attrs...)
+	}
+}
+
 // processNote parses and processes a single note
 func (d *Daemon) processNote(notePath string) (*vault.Note, error) {
-	note, err := vault.ParseNote(notePath)
+	note, err := d.parseNote(notePath)
 	if err != nil {
-		return nil, fmt.Errorf("parsing note: %w", err)
+		return nil, err
 	}
 
-	// Ensure note has UID
-	uidChanged := note.EnsureUID()
+	// Ensure note has UID of its own. A remote vault's checkout is never
+	// written to, as local edits would stop pulls, so its notes keep the UID
+	// the state recorded for their path instead.
+	if d.vaultRepo != nil {
+		d.recallUID(note, notePath)
+	}
+	uidChanged := note.EnsureUID() && d.vaultRepo == nil
+	if d.claimUID(note, notePath) {
+		uidChanged = true
+	}
+	d.trackSchedule(note)
 
 	// Calculate content hash
 	contentHash := state.CalculateContentHash(note.Raw)
 
-	// Check if sync is needed
-	if !d.stateManager.NeedsSync(note.UID, notePath, note.ModTime, contentHash) && !uidChanged {
+	// Check if sync is needed; a note can also go live or expire unchanged
+	oldNote := d.stateManager.GetNote(note.UID)
+	publishChanged := oldNote != nil && oldNote.Published != note.Published
+	if !d.stateManager.NeedsSync(note.UID, notePath, note.ModTime, contentHash) && !uidChanged && !publishChanged {
 		return note, nil // No changes
 	}
 
 	// Check if this is a file rename (path changed but UID exists)
-	oldNote := d.stateManager.GetNote(note.UID)
 	isRenamed := oldNote != nil && oldNote.SourcePath != notePath
+	hugoPath := d.calculateHugoPath(note)
+	previousHugoPaths := d.previousHugoPaths(note, hugoPath)
 
 	// Update front-matter if needed
 	var frontMatterChanged bool
@@ -284,13 +887,19 @@ func (d *Daemon) processNote(notePath string) (*vault.Note, error) {
 		}
 	}
 
-	// Write back to vault if front-matter changed
-	if uidChanged || frontMatterChanged {
+	// Write back to vault if front-matter changed, then hash what we wrote so
+	// our own edit isn't mistaken for an author change on the next scan
+	if (uidChanged || frontMatterChanged) && d.vaultRepo == nil {
 		if err := d.writeNoteToVault(note); err != nil {
 			slog.Error("Error updating note front-matter", "path", notePath, "error", err)
+		} else {
+			contentHash = state.CalculateContentHash(note.Raw)
 		}
 	}
 
+	// Keep wikilink resolution current for this note (full rebuilds happen on full sync)
+	d.hugoGen.UpdateSlugMapEntry(note)
+
 	// Process based on publish status
 	if note.Published {
 		if err := d.publishNote(note); err != nil {
@@ -302,21 +911,25 @@ func (d *Daemon) processNote(notePath string) (*vault.Note, error) {
 		}
 	}
 
-	// Handle file rename cleanup
-	if isRenamed && oldNote.Published {
+	// Handle file rename cleanup (a rename that keeps the slug reuses the same file)
+	if isRenamed && oldNote.Published && oldNote.HugoPath != hugoPath {
 		// Remove old Hugo file
 		oldHugoPath := oldNote.HugoPath
 		oldFullPath := filepath.Join(d.config.Repo, oldHugoPath)
 		
 		if _, err := os.Stat(oldFullPath); err == nil {
 			if d.config.DryRun {
-				slog.Info("DRY RUN: Would delete old Hugo file after rename", "old_path", oldHugoPath, "new_path", d.calculateHugoPath(note))
+				slog.Info("DRY RUN: Would delete old Hugo file after rename", "old_path", oldHugoPath, "new_path", hugoPath)
+			} else if d.config.NoDelete {
+				d.keepDeletion(oldHugoPath)
 			} else {
-				if err := os.Remove(oldFullPath); err != nil {
+				if err := d.removeFile(oldFullPath); err != nil {
 					slog.Error("Error removing old Hugo file after rename", "path", oldHugoPath, "error", err)
 				} else {
-					slog.Info("Removed old Hugo file after rename", "old_path", oldHugoPath, "new_path", d.calculateHugoPath(note))
+					d.reportChange(oldHugoPath, changeDeleted)
+					slog.Info("Removed old Hugo file after rename", "old_path", oldHugoPath, "new_path", hugoPath)
 					d.removeEmptyDirs(filepath.Dir(oldFullPath))
+					d.restoreSectionIndex(oldHugoPath)
 				}
 			}
 		}
@@ -331,40 +944,102 @@ func (d *Daemon) processNote(notePath string) (*vault.Note, error) {
 
 	// Update state
 	d.stateManager.SetNote(note.UID, &state.Note{
-		SourcePath:   notePath,
-		HugoPath:     d.calculateHugoPath(note),
-		LastModified: note.ModTime,
-		LastSync:     time.Now(),
-		Published:    note.Published,
-		ContentHash:  contentHash,
+		SourcePath:        notePath,
+		HugoPath:          hugoPath,
+		LastModified:      note.ModTime,
+		LastSync:          time.Now(),
+		Published:         note.Published,
+		ContentHash:       contentHash,
+		PreviousHugoPaths: previousHugoPaths,
 	})
 
 	return note, nil
 }
 
+// noteReadRetryConfig bounds retries for notes caught mid-save by Obsidian
+func noteReadRetryConfig() *errors.RetryConfig {
+	return &errors.RetryConfig{
+		MaxAttempts: 4,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    500 * time.Millisecond,
+		Backoff:     2.0,
+	}
+}
+
+// parseNote reads and parses a note, retrying briefly when the file is empty or
+// its front-matter fails to parse, as happens while an editor is still saving.
+// A note that stays empty through every attempt is returned as empty.
+func (d *Daemon) parseNote(notePath string) (*vault.Note, error) {
+	var note, emptyNote *vault.Note
+	
+	err := errors.Retry(d.readRetry, "reading note", func() error {
+		emptyNote = nil
+		parsed, err := vault.ParseNoteWithOptions(notePath, d.parseOptions)
+		if err != nil {
+			readErr := errors.New(errors.ErrorTypeVault, "parsing note", err).WithContext("path", notePath)
+			var fmErr *vault.FrontMatterError
+			if stderrors.As(err, &fmErr) {
+				// Retrying won't fix a syntax error, only the author can
+				readErr.SetRecoverable(false).
+					WithUserMessage("Invalid front-matter").
+					WithSuggestions(
+						"Check that the front-matter is closed with a --- line",
+						"Indent YAML with spaces, not tabs",
+						"Quote values containing ': ' or starting with special characters",
+					)
+				if fmErr.Line > 0 {
+					readErr.WithContext("line", fmErr.Line)
+				}
+				if fmErr.Column > 0 {
+					readErr.WithContext("column", fmErr.Column)
+				}
+			} else if _, statErr := os.Stat(notePath); os.IsNotExist(statErr) {
+				readErr.SetRecoverable(false) // Deleted files don't come back by retrying
+			}
+			return readErr
+		}
+		
+		if len(parsed.Raw) == 0 {
+			emptyNote = parsed
+			return errors.New(errors.ErrorTypeVault, "parsing note", fmt.Errorf("empty file")).WithContext("path", notePath)
+		}
+		
+		note = parsed
+		return nil
+	})
+	
+	switch {
+	case note != nil:
+		return note, nil
+	case emptyNote != nil:
+		return emptyNote, nil // Still empty after retrying, so it really is empty
+	default:
+		return nil, err
+	}
+}
+
 // publishNote converts and writes a note to the Hugo repository
 func (d *Daemon) publishNote(note *vault.Note) error {
 	// Calculate weight
 	weight := d.calculateNoteWeight(note.Path)
 	
 	// Generate Hugo content
-	hugoContent, err := d.hugoGen.GenerateContent(note, weight)
+	hugoContent, err := d.generateContent(note, weight)
 	if err != nil {
 		return fmt.Errorf("generating hugo content: %w", err)
 	}
 
-	// Write to Hugo directory
-	fullPath := filepath.Join(d.config.Repo, hugoContent.Path)
-	if d.config.DryRun {
-		slog.Info("DRY RUN: Would write Hugo file", "path", hugoContent.Path)
-	} else {
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			return fmt.Errorf("creating directory: %w", err)
-		}
-		if err := os.WriteFile(fullPath, []byte(hugoContent.Serialize()), 0644); err != nil {
-			return fmt.Errorf("writing hugo file: %w", err)
+	// Write to Hugo directory, which is skipped when only lastUpdated would
+	// change (as when the vault file changed through our own UID or weight
+	// write-back)
+	content := hugoContent.Serialize()
+	if !d.config.DryRun {
+		if err := d.writeHugoFile(hugoContent.Path, content); err != nil {
+			return err
 		}
+	} else if !outputUnchanged(filepath.Join(d.config.Repo, hugoContent.Path), content) {
+		slog.Info("DRY RUN: Would write Hugo file", "path", hugoContent.Path)
+		d.showDryRunDiff(hugoContent.Path, content)
 	}
 
 	// Process images
@@ -381,6 +1056,56 @@ func (d *Daemon) publishNote(note *vault.Note) error {
 	return nil
 }
 
+// writeHugoFile writes a note to the Hugo repository, holding the locks of its
+// directory and their ancestors so a concurrent prune can't remove them midway.
+// A file that already holds the content, apart from lastUpdated, is left alone
+// so Hugo doesn't rebuild (and CDNs don't re-fetch) untouched pages.
+func (d *Daemon) writeHugoFile(hugoPath, content string) error {
+	unlock := d.dirLocks.lockTree(filepath.Dir(hugoPath))
+	defer unlock()
+	
+	fullPath := filepath.Join(d.config.Repo, hugoPath)
+	if outputUnchanged(fullPath, content) {
+		slog.Debug("Hugo file unchanged, skipping write", "path", hugoPath)
+		return nil
+	}
+	if d.writesPaused() {
+		return errWritesPaused
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return d.checkWrite("creating directory", fmt.Errorf("creating directory: %w", err))
+	}
+	_, statErr := os.Stat(fullPath)
+	if err := d.writeFile(fullPath, []byte(content), 0644); err != nil {
+		return d.checkWrite("writing hugo file", fmt.Errorf("writing hugo file: %w", err))
+	}
+	d.reportWritten(hugoPath, statErr == nil)
+	return d.checkWrite("writing hugo file", nil)
+}
+
+// outputUnchanged reports whether a file in the Hugo repository already holds
+// the generated content, apart from its lastUpdated timestamp, so rewriting it
+// would only churn the disk and the git history
+func outputUnchanged(fullPath, content string) bool {
+	existing, err := os.ReadFile(fullPath)
+	return err == nil && !hugo.ContentChanged(string(existing), content)
+}
+
+// showDryRunDiff prints a unified diff between a Hugo file on disk and the
+// content a dry run would write there (empty content means deletion)
+func (d *Daemon) showDryRunDiff(hugoPath, newContent string) {
+	oldContent, err := os.ReadFile(filepath.Join(d.config.Repo, hugoPath))
+	if err != nil && !os.IsNotExist(err) {
+		slog.Warn("Could not read Hugo file for dry-run diff", "path", hugoPath, "error", err)
+		return
+	}
+	
+	name := filepath.ToSlash(hugoPath)
+	if changes := diff.Unified("a/"+name, "b/"+name, string(oldContent), newContent); changes != "" {
+		fmt.Fprint(d.diffOutput, changes)
+	}
+}
+
 // unpublishNote removes a note from the Hugo repository
 func (d *Daemon) unpublishNote(note *vault.Note) error {
 	hugoPath := d.calculateHugoPath(note)
@@ -390,14 +1115,24 @@ func (d *Daemon) unpublishNote(note *vault.Note) error {
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 		// File doesn't exist, nothing to do
 		slog.Debug("Hugo file doesn't exist, skipping deletion", "path", hugoPath)
+	} else if uid, _ := d.extractNoteUidFromHugoFile(fullPath); isSectionIndex(hugoPath) && uid != note.UID {
+		// An unpublished index note leaves the section's own index alone
+		slog.Debug("Section index isn't the note's, skipping deletion", "path", hugoPath)
+	} else if d.isPreserved(fullPath, false) {
+		slog.Info("Keeping preserved Hugo file of unpublished note", "path", hugoPath)
 	} else if d.config.DryRun {
 		slog.Info("DRY RUN: Would delete Hugo file", "path", hugoPath)
+		d.showDryRunDiff(hugoPath, "")
+	} else if d.config.NoDelete {
+		d.keepDeletion(hugoPath)
 	} else {
-		if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("deleting hugo file: %w", err)
+		if err := d.removeFile(fullPath); err != nil && !os.IsNotExist(err) {
+			return d.checkWrite("deleting hugo file", fmt.Errorf("deleting hugo file: %w", err))
 		}
+		d.reportChange(hugoPath, changeDeleted)
 		// Remove empty directories
 		d.removeEmptyDirs(filepath.Dir(fullPath))
+		d.restoreSectionIndex(hugoPath)
 		slog.Info("Deleted Hugo file", "path", hugoPath)
 	}
 
@@ -414,17 +1149,28 @@ func (d *Daemon) handleNoteRemoval(notePath string) error {
 	for uid, stateNote := range d.stateManager.GetAllNotes() {
 		if stateNote.SourcePath == notePath {
 			// Remove from Hugo if it was published
-			if stateNote.Published {
+			if stateNote.Published && d.isPreserved(filepath.Join(d.config.Repo, stateNote.HugoPath), false) {
+				slog.Info("Keeping preserved Hugo file of deleted note", "path", stateNote.HugoPath)
+			} else if stateNote.Published && d.config.DryRun {
+				slog.Info("DRY RUN: Would delete Hugo file", "path", stateNote.HugoPath)
+			} else if stateNote.Published && d.config.NoDelete {
+				d.keepDeletion(stateNote.HugoPath)
+			} else if stateNote.Published {
 				fullPath := filepath.Join(d.config.Repo, stateNote.HugoPath)
-				if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				if err := d.removeFile(fullPath); err != nil && !os.IsNotExist(err) {
 					slog.Error("Error removing deleted note from Hugo", "path", stateNote.HugoPath, "error", err)
 				} else {
+					if err == nil {
+						d.reportChange(stateNote.HugoPath, changeDeleted)
+					}
 					d.removeEmptyDirs(filepath.Dir(fullPath))
+					d.restoreSectionIndex(stateNote.HugoPath)
 				}
 			}
 			
-			// Remove from state
+			// Remove from state and link resolution
 			d.stateManager.DeleteNote(uid)
+			d.hugoGen.RemoveSlugMapEntry(uid)
 			slog.Info("Removed deleted note", "path", notePath)
 			break
 		}
@@ -436,15 +1182,107 @@ func (d *Daemon) handleNoteRemoval(notePath string) error {
 // Helper methods
 
 func (d *Daemon) calculateHugoPath(note *vault.Note) string {
-	// This is simplified - should use the Hugo generator's path calculation
-	return filepath.Join(d.config.ContentDir, strings.TrimSuffix(filepath.Base(note.Path), ".md")+".md")
+	return d.hugoGen.HugoPath(note)
+}
+
+// generateContent converts a note to Hugo content, adding aliases for any
+// URLs the note was previously published under
+func (d *Daemon) generateContent(note *vault.Note, weight int) (*hugo.HugoContent, error) {
+	hugoContent, err := d.hugoGen.GenerateContent(note, weight)
+	if err != nil {
+		return nil, err
+	}
+	
+	if d.transform != nil {
+		source, _ := filepath.Rel(d.config.Vault, note.Path)
+		body, err := d.transform.Run(hugoContent.Content, transform.Note{
+			Path:     filepath.ToSlash(source),
+			HugoPath: hugoContent.Path,
+			UID:      note.UID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hugoContent.Content = body
+	}
+	
+	for _, previousPath := range d.previousHugoPaths(note, hugoContent.Path) {
+		hugoContent.AddAliases(d.hugoGen.URLForPath(previousPath))
+	}
+	
+	return hugoContent, nil
+}
+
+// previousHugoPaths returns the Hugo paths a note was published under before
+// being renamed or moved, excluding its current path
+func (d *Daemon) previousHugoPaths(note *vault.Note, hugoPath string) []string {
+	stateNote := d.stateManager.GetNote(note.UID)
+	if stateNote == nil {
+		return nil
+	}
+	
+	paths := append([]string{}, stateNote.PreviousHugoPaths...)
+	
+	// The slug only changes when the source file is renamed or moved
+	if stateNote.Published && stateNote.SourcePath != note.Path && stateNote.HugoPath != "" {
+		paths = append(paths, stateNote.HugoPath)
+	}
+	
+	var result []string
+	seen := map[string]bool{hugoPath: true}
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			result = append(result, path)
+		}
+	}
+	return result
 }
 
 func (d *Daemon) calculateNoteWeight(notePath string) int {
-	// Simplified weight calculation
+	// Base weight by depth, offset by the note's alphabetical position among siblings
 	relPath, _ := filepath.Rel(d.config.Vault, notePath)
 	depth := strings.Count(relPath, string(filepath.Separator))
-	return 100 + (depth * 10)
+	if d.config.MaxSectionDepth > 0 && depth > d.config.MaxSectionDepth {
+		// Flattened notes weigh in at their capped section's depth
+		depth = d.config.MaxSectionDepth
+	}
+	
+	// An explicit ordering prefix ("01 Introduction.md") takes precedence,
+	// but the year of a filename date is no ordering number
+	name := vault.NoteName(notePath)
+	if _, _, dated := hugo.ParseDatePrefix(name, d.config.DateFromFilename); dated {
+		name = ""
+	}
+	if d.config.NumberPrefix == hugo.NumberPrefixWeight || d.config.NumberPrefix == hugo.NumberPrefixStrip {
+		if number, _, ok := hugo.ParseNumberPrefix(name); ok {
+			return hugo.CalculateNoteWeightStep(100+(depth*10), number, d.config.WeightStep)
+		}
+	}
+	
+	return hugo.CalculateNoteWeightStep(100+(depth*10), siblingIndex(notePath, d.scanOptions), d.config.WeightStep)
+}
+
+// siblingIndex returns the alphabetical position of a note among the markdown
+// files in its directory
+func siblingIndex(notePath string, scan vault.ScanOptions) int {
+	entries, err := os.ReadDir(filepath.Dir(notePath))
+	if err != nil {
+		return 0
+	}
+	
+	name := filepath.Base(notePath)
+	index := 0
+	for _, entry := range entries { // ReadDir returns entries sorted by filename
+		if entry.IsDir() || !scan.IsNoteFile(entry.Name()) {
+			continue
+		}
+		if entry.Name() == name {
+			return index
+		}
+		index++
+	}
+	return 0
 }
 
 func (d *Daemon) writeNoteToVault(note *vault.Note) error {
@@ -458,21 +1296,47 @@ func (d *Daemon) writeNoteToVault(note *vault.Note) error {
 		return nil
 	}
 	
+	// Write in the note's source encoding, which the next read decodes again
+	data, err := note.EncodeForFile(content)
+	if err != nil {
+		return err
+	}
+	
 	// Write directly to vault file system, NOT to git repo
-	return os.WriteFile(note.Path, content, 0644)
+	if err := os.WriteFile(note.Path, data, 0644); err != nil {
+		return err
+	}
+	
+	// Track the written file so state records what is on disk now
+	note.Raw = content
+	if info, err := os.Stat(note.Path); err == nil {
+		note.ModTime = info.ModTime()
+	}
+	return nil
 }
 
 func (d *Daemon) processNoteImages(note *vault.Note) error {
 	imageRefs := note.ExtractImageReferences()
+	imagePaths := make([]string, len(imageRefs))
+	for i, imgRef := range imageRefs {
+		imagePaths[i] = imgRef.Path
+	}
 	
-	for _, imgRef := range imageRefs {
-		if _, err := d.imageManager.CopyImage(imgRef.Path, note.UID); err != nil {
-			slog.Error("Error copying image", "image", imgRef.Path, "error", err)
+	// Copy on the image worker pool, then track references in order
+	for _, result := range d.imageManager.CopyImages(imagePaths, note.UID) {
+		if result.Inlined {
+			continue
+		}
+		if result.Err != nil {
+			slog.Error("Error copying image", "image", result.VaultPath, "error", result.Err)
+			d.recordSyncError(errors.ErrorTypeImage, "copying image", result.Err).WithContext("note", note.Path)
 			continue
 		}
 		
 		// Track image reference
-		d.stateManager.AddImageReference(imgRef.Path, note.UID)
+		d.stateManager.AddImageReference(result.VaultPath, note.UID)
+		d.stateManager.SetImageHash(result.VaultPath, result.Info.Hash)
+		d.stateManager.SetImageOutput(result.VaultPath, result.Info.Optimized, result.Info.OutputHash)
 	}
 	
 	return nil
@@ -486,17 +1350,23 @@ func (d *Daemon) removeNoteImageReferences(note *vault.Note) {
 	}
 }
 
+// ensureSectionIndex creates the missing section indexes for a note's directory
+// and its ancestors
 func (d *Daemon) ensureSectionIndex(notePath string) error {
 	dir := filepath.Dir(notePath)
 	
+	unlock := d.dirLocks.lockTree(dir)
+	defer unlock()
+	
 	// Create index files for all directories in the path (excluding content root)
 	return d.ensureAllSectionIndexes(dir)
 }
 
-// ensureAllSectionIndexes recursively creates _index.md files for all directories in a path
+// ensureAllSectionIndexes recursively creates _index.md files for all directories
+// in a path; callers hold the directory locks (see ensureSectionIndex)
 func (d *Daemon) ensureAllSectionIndexes(dir string) error {
-	// Stop at content directory root
-	if dir == d.config.ContentDir || dir == "." || dir == "/" {
+	// Stop at the (default or routed) content directory root
+	if d.hugoGen.IsContentRoot(dir) || dir == "." || dir == "/" {
 		return nil
 	}
 	
@@ -512,56 +1382,124 @@ func (d *Daemon) ensureAllSectionIndexes(dir string) error {
 	
 	// Check if index already exists
 	if _, err := os.Stat(fullIndexPath); os.IsNotExist(err) {
-		// Create index file
-		weight := hugo.CalculateFolderWeight(dir)
-		indexContent := d.hugoGen.GenerateIndexFile(dir, weight)
-		
-		if d.config.DryRun {
-			slog.Info("DRY RUN: Would create section index", "path", indexContent.Path)
-		} else {
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(fullIndexPath), 0755); err != nil {
-				return fmt.Errorf("creating index directory: %w", err)
-			}
-			if err := os.WriteFile(fullIndexPath, []byte(indexContent.Serialize()), 0644); err != nil {
-				return fmt.Errorf("creating section index: %w", err)
-			}
-			slog.Info("Created section index", "path", indexPath)
-		}
+		return d.writeSectionIndex(dir)
 	}
 	
 	return nil
 }
 
+// ensureStubPage writes the page unpublished links point at in stub mode. It
+// is only created when missing, so it can be edited in the Hugo repository.
+func (d *Daemon) ensureStubPage() error {
+	if d.config.UnpublishedLink != "stub" {
+		return nil
+	}
+	
+	stub := d.hugoGen.GenerateStubPage()
+	if _, err := os.Stat(filepath.Join(d.config.Repo, stub.Path)); !os.IsNotExist(err) {
+		return nil
+	}
+	
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would write unpublished stub page", "path", stub.Path)
+		return nil
+	}
+	if err := d.writeHugoFile(stub.Path, stub.Serialize()); err != nil {
+		return err
+	}
+	slog.Info("Wrote unpublished stub page", "path", stub.Path)
+	return nil
+}
+
+// writeSectionIndex generates and writes the _index.md for a section directory.
+// An _index.md published from an index note is left to that note.
+func (d *Daemon) writeSectionIndex(dir string) error {
+	weight := hugo.CalculateFolderWeight(dir)
+	indexContent := d.hugoGen.GenerateIndexFile(dir, weight)
+	fullIndexPath := filepath.Join(d.config.Repo, indexContent.Path)
+	
+	if d.isManagedNote(fullIndexPath) {
+		return nil
+	}
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would write section index", "path", indexContent.Path)
+		return nil
+	}
+	if d.writesPaused() {
+		return errWritesPaused
+	}
+	content := indexContent.Serialize()
+	if outputUnchanged(fullIndexPath, content) {
+		slog.Debug("Section index unchanged, skipping write", "path", indexContent.Path)
+		return nil
+	}
+	
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(fullIndexPath), 0755); err != nil {
+		return d.checkWrite("creating index directory", fmt.Errorf("creating index directory: %w", err))
+	}
+	_, statErr := os.Stat(fullIndexPath)
+	if err := d.writeFile(fullIndexPath, []byte(content), 0644); err != nil {
+		return d.checkWrite("writing section index", fmt.Errorf("writing section index: %w", err))
+	}
+	d.reportWritten(indexContent.Path, statErr == nil)
+	slog.Info("Wrote section index", "path", indexContent.Path)
+	return nil
+}
+
+// refreshFolderIndex rewrites the _index.md of the section whose _folder.md changed
+func (d *Daemon) refreshFolderIndex(folderNotePath string) error {
+	relDir, err := filepath.Rel(d.config.Vault, filepath.Dir(folderNotePath))
+	if err != nil || relDir == "." {
+		return nil // The content root has no generated index
+	}
+	
+	// Routed folders map to a content root, which has no generated index
+	dir := d.hugoGen.SectionDir(relDir)
+	if d.hugoGen.IsContentRoot(dir) {
+		return nil
+	}
+	
+	unlock := d.dirLocks.lockTree(dir)
+	defer unlock()
+	
+	// Sections are only created once they hold published content
+	if _, err := os.Stat(filepath.Join(d.config.Repo, dir)); os.IsNotExist(err) {
+		return nil
+	}
+	
+	return d.writeSectionIndex(dir)
+}
+
 // repairMissingSectionIndexes scans Hugo content and creates missing _index.md files
 // This fixes installations that were created with older versions of the daemon
 func (d *Daemon) repairMissingSectionIndexes() error {
-	contentPath := filepath.Join(d.config.Repo, d.config.ContentDir)
-	
 	// Find all directories that contain .md files (but not _index.md files themselves)
 	contentDirs := make(map[string]bool)
 	
-	err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
-		// Skip if this is the content root
-		if path == contentPath {
+	for _, contentPath := range d.contentPaths() {
+		err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			
+			// Skip if this is the content root
+			if path == contentPath {
+				return nil
+			}
+			
+			// If this is a .md file (but not _index.md), mark its directory as needing an index
+			if !info.IsDir() && strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, "_index.md") {
+				dir := filepath.Dir(path)
+				contentDirs[dir] = true
+			}
+			
 			return nil
-		}
+		})
 		
-		// If this is a .md file (but not _index.md), mark its directory as needing an index
-		if !info.IsDir() && strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, "_index.md") {
-			dir := filepath.Dir(path)
-			contentDirs[dir] = true
+		if err != nil {
+			return fmt.Errorf("scanning content directory: %w", err)
 		}
-		
-		return nil
-	})
-	
-	if err != nil {
-		return fmt.Errorf("scanning content directory: %w", err)
 	}
 	
 	// Create missing _index.md files for all content directories
@@ -575,7 +1513,10 @@ func (d *Daemon) repairMissingSectionIndexes() error {
 		}
 		
 		// Ensure all parent directories have indexes
-		if err := d.ensureAllSectionIndexes(relDir); err != nil {
+		unlock := d.dirLocks.lockTree(relDir)
+		err = d.ensureAllSectionIndexes(relDir)
+		unlock()
+		if err != nil {
 			slog.Error("Error creating section indexes", "dir", relDir, "error", err)
 			continue
 		}
@@ -592,8 +1533,6 @@ func (d *Daemon) repairMissingSectionIndexes() error {
 // repairOrphanedHugoFiles scans Hugo content and removes orphaned files from previous buggy versions
 // This fixes files left behind from renames, duplicates, and broken links
 func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note) error {
-	contentPath := filepath.Join(d.config.Repo, d.config.ContentDir)
-	
 	// Use fresh publishedNotes data (just parsed from vault) instead of potentially stale state
 	// Also get all notes from state for notes that exist but aren't published
 	allStateNotes := d.stateManager.GetAllNotes()
@@ -609,13 +1548,22 @@ func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note)
 	orphanedFiles := make([]string, 0)
 	duplicateFiles := make(map[string][]string) // uid -> []file_paths
 	
-	err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
+	checkFile := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		
-		// Skip directories and _index.md files
-		if info.IsDir() || strings.HasSuffix(path, "_index.md") {
+		// Preserved files and directories are never repaired
+		if d.isPreserved(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		
+		// Skip directories; _index.md files are only repaired when
+		// published from an index note, as they alone carry a noteUid
+		if info.IsDir() {
 			return nil
 		}
 		
@@ -636,12 +1584,13 @@ func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note)
 			return nil
 		}
 		
-		// Get relative path from repo root
+		// Get relative path from repo root, slash-separated like generated Hugo paths
 		relPath, err := filepath.Rel(d.config.Repo, path)
 		if err != nil {
 			slog.Error("Error calculating relative path", "path", path, "error", err)
 			return nil
 		}
+		relPath = filepath.ToSlash(relPath)
 		
 		// Check if this UID corresponds to a currently published note (from fresh vault scan)
 		expectedHugoPath, isCurrentlyPublished := currentlyPublished[uid]
@@ -667,10 +1616,12 @@ func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note)
 		}
 		
 		return nil
-	})
+	}
 	
-	if err != nil {
-		return fmt.Errorf("scanning Hugo content for repair: %w", err)
+	for _, contentPath := range d.contentPaths() {
+		if err := filepath.Walk(contentPath, checkFile); err != nil {
+			return fmt.Errorf("scanning Hugo content for repair: %w", err)
+		}
 	}
 	
 	// Remove orphaned files
@@ -680,12 +1631,16 @@ func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note)
 		
 		if d.config.DryRun {
 			slog.Info("DRY RUN: Would remove orphaned Hugo file", "path", orphanPath)
+		} else if d.config.NoDelete {
+			d.keepDeletion(orphanPath)
 		} else {
-			if err := os.Remove(fullPath); err != nil {
+			if err := d.removeFile(fullPath); err != nil {
 				slog.Error("Error removing orphaned Hugo file", "path", orphanPath, "error", err)
 			} else {
 				slog.Info("Removed orphaned Hugo file", "path", orphanPath)
+				d.reportChange(orphanPath, changeDeleted)
 				d.removeEmptyDirs(filepath.Dir(fullPath))
+				d.restoreSectionIndex(orphanPath)
 				removed++
 			}
 		}
@@ -700,12 +1655,16 @@ func (d *Daemon) repairOrphanedHugoFiles(publishedNotes map[string]*vault.Note)
 				
 				if d.config.DryRun {
 					slog.Info("DRY RUN: Would remove duplicate Hugo file", "path", wrongPath, "correct_path", expectedPath, "uid", uid)
+				} else if d.config.NoDelete {
+					d.keepDeletion(wrongPath)
 				} else {
-					if err := os.Remove(fullPath); err != nil {
+					if err := d.removeFile(fullPath); err != nil {
 						slog.Error("Error removing duplicate Hugo file", "path", wrongPath, "error", err)
 					} else {
 						slog.Info("Removed duplicate Hugo file", "path", wrongPath, "correct_path", expectedPath, "uid", uid)
+						d.reportChange(wrongPath, changeDeleted)
 						d.removeEmptyDirs(filepath.Dir(fullPath))
+						d.restoreSectionIndex(wrongPath)
 						removed++
 					}
 				}
@@ -735,8 +1694,26 @@ func (d *Daemon) extractNoteUidFromHugoFile(filePath string) (string, error) {
 	
 	content := string(data)
 	
-	// Check for front-matter
-	if !strings.HasPrefix(content, "---\n") {
+	// JSON front-matter is a single object at the start of the file
+	if strings.HasPrefix(content, "{") {
+		var frontMatter map[string]interface{}
+		if err := json.NewDecoder(strings.NewReader(content)).Decode(&frontMatter); err != nil {
+			return "", nil // Malformed front-matter
+		}
+		if uid, ok := frontMatter["noteUid"].(string); ok {
+			return strings.TrimSpace(uid), nil
+		}
+		return "", nil
+	}
+	
+	// YAML front-matter is delimited by ---, TOML by +++
+	var delimiter string
+	switch {
+	case strings.HasPrefix(content, "---\n"):
+		delimiter = "---"
+	case strings.HasPrefix(content, "+++\n"):
+		delimiter = "+++"
+	default:
 		return "", nil // No front-matter
 	}
 	
@@ -744,7 +1721,7 @@ func (d *Daemon) extractNoteUidFromHugoFile(filePath string) (string, error) {
 	lines := strings.Split(content, "\n")
 	endIndex := -1
 	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == "---" {
+		if strings.TrimSpace(lines[i]) == delimiter {
 			endIndex = i
 			break
 		}
@@ -757,38 +1734,103 @@ func (d *Daemon) extractNoteUidFromHugoFile(filePath string) (string, error) {
 	// Parse front-matter to extract noteUid
 	frontMatterContent := strings.Join(lines[1:endIndex], "\n")
 	
+	if delimiter == "+++" {
+		var frontMatter map[string]interface{}
+		if _, err := toml.Decode(frontMatterContent, &frontMatter); err != nil {
+			return "", nil // Malformed front-matter
+		}
+		if uid, ok := frontMatter["noteUid"].(string); ok {
+			return strings.TrimSpace(uid), nil
+		}
+		return "", nil
+	}
+	
 	// Simple regex to extract noteUid (more robust than full YAML parsing)
 	noteUidRegex := regexp.MustCompile(`(?m)^noteUid:\s*(.+)$`)
 	matches := noteUidRegex.FindStringSubmatch(frontMatterContent)
 	
 	if len(matches) > 1 {
-		return strings.TrimSpace(matches[1]), nil
+		return strings.Trim(strings.TrimSpace(matches[1]), `"'`), nil
 	}
 	
 	return "", nil
 }
 
-// removeEmptyDirs recursively removes empty directories
+// contentPaths returns the full paths of the existing content directories
+// notes are written to (the default one and any routed ones)
+func (d *Daemon) contentPaths() []string {
+	var paths []string
+	for _, contentDir := range d.hugoGen.ContentDirs() {
+		contentPath := filepath.Join(d.config.Repo, contentDir)
+		if _, err := os.Stat(contentPath); err == nil {
+			paths = append(paths, contentPath)
+		}
+	}
+	return paths
+}
+
+// removeEmptyDirs recursively removes empty directories, including sections
+// left holding nothing but their generated _index.md
 func (d *Daemon) removeEmptyDirs(dir string) {
-	// Don't remove the Hugo repository root or content directory
-	hugoContentDir := filepath.Join(d.config.Repo, d.config.ContentDir)
-	if dir == d.config.Repo || dir == hugoContentDir {
+	// Don't remove the Hugo repository root or a content directory
+	if dir == d.config.Repo {
+		return
+	}
+	relDir, err := filepath.Rel(d.config.Repo, dir)
+	if err != nil || d.hugoGen.IsContentRoot(relDir) {
 		return
 	}
+	
+	// Hold the directory lock only while pruning this level, never a parent's
+	if !d.pruneDir(relDir) {
+		return
+	}
+	
+	// Recursively check parent directory
+	parent := filepath.Dir(dir)
+	if parent != dir { // Avoid infinite loop
+		d.removeEmptyDirs(parent)
+	}
+}
 
+// pruneDir removes a directory that is empty or only holds a generated
+// _index.md, and reports whether it was removed
+func (d *Daemon) pruneDir(relDir string) bool {
+	unlock := d.dirLocks.lock(relDir)
+	defer unlock()
+	
+	dir := filepath.Join(d.config.Repo, relDir)
+	if d.isPreserved(dir, true) {
+		return false
+	}
 	entries, err := os.ReadDir(dir)
-	if err != nil || len(entries) > 0 {
-		return // Directory not empty or error reading
+	if err != nil {
+		return false
 	}
-
-	// Directory is empty, remove it
-	if err := os.Remove(dir); err == nil {
-		// Recursively check parent directory
-		parent := filepath.Dir(dir)
-		if parent != dir { // Avoid infinite loop
-			d.removeEmptyDirs(parent)
+	
+	switch {
+	case len(entries) == 0:
+	case len(entries) == 1 && entries[0].Name() == "_index.md":
+		// The section has no published content left
+		indexPath := filepath.Join(dir, "_index.md")
+		if !d.isGeneratedSectionIndex(indexPath) || d.isManagedNote(indexPath) || d.isPreserved(indexPath, false) {
+			return false
+		}
+		if d.config.NoDelete {
+			d.keepDeletion(filepath.Join(relDir, "_index.md"))
+			return false
 		}
+		if err := d.removeFile(indexPath); err != nil {
+			return false
+		}
+		d.reportChange(filepath.Join(relDir, "_index.md"), changeDeleted)
+		slog.Debug("Removed section index", "path", filepath.Join(relDir, "_index.md"))
+	default:
+		return false // Directory not empty
 	}
+	
+	// Directory is empty, remove it
+	return os.Remove(dir) == nil
 }
 
 func (d *Daemon) regeneratePublishedContent(publishedNotes map[string]*vault.Note) error {
@@ -802,34 +1844,55 @@ func (d *Daemon) regeneratePublishedContent(publishedNotes map[string]*vault.Not
 		return notes[i].Path < notes[j].Path
 	})
 	
-	// Regenerate content with updated wikilinks
+	// Regenerate content with updated wikilinks; a failing note is recorded
+	// and skipped so the links of all others still get fixed
+	var deadLinks []DeadLink
+	var failed []error
 	for _, note := range notes {
 		weight := d.calculateNoteWeight(note.Path)
-		hugoContent, err := d.hugoGen.GenerateContent(note, weight)
+		hugoContent, err := d.generateContent(note, weight)
 		if err != nil {
-			return fmt.Errorf("regenerating content for %s: %w", note.Path, err)
+			d.recordSyncError(errors.ErrorTypeHugo, "regenerating content", err).WithContext("path", note.Path)
+			failed = append(failed, fmt.Errorf("regenerating content for %s: %w", note.Path, err))
+			continue
+		}
+		
+		source, _ := filepath.Rel(d.config.Vault, note.Path)
+		for _, target := range hugoContent.Unresolved {
+			deadLinks = append(deadLinks, DeadLink{Source: filepath.ToSlash(source), Target: target})
 		}
 		
-		fullPath := filepath.Join(d.config.Repo, hugoContent.Path)
+		// Files are left alone when only the timestamp would change
+		content := hugoContent.Serialize()
 		if d.config.DryRun {
-			slog.Info("DRY RUN: Would regenerate Hugo file", "path", hugoContent.Path)
-		} else {
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-				return fmt.Errorf("creating directory for regenerated content: %w", err)
-			}
-			if err := os.WriteFile(fullPath, []byte(hugoContent.Serialize()), 0644); err != nil {
-				return fmt.Errorf("writing regenerated content: %w", err)
+			if !outputUnchanged(filepath.Join(d.config.Repo, hugoContent.Path), content) {
+				slog.Info("DRY RUN: Would regenerate Hugo file", "path", hugoContent.Path)
+				d.showDryRunDiff(hugoContent.Path, content)
 			}
+		} else if err := d.writeHugoFile(hugoContent.Path, content); err != nil {
+			slog.Error("Error writing regenerated content", "path", hugoContent.Path, "error", err)
+			d.recordSyncError(errors.ErrorTypeHugo, "writing regenerated content", err).WithContext("path", note.Path)
+			failed = append(failed, fmt.Errorf("writing regenerated content for %s: %w", note.Path, err))
 		}
 	}
 	
+	if err := d.reportDeadLinks(deadLinks); err != nil {
+		slog.Error("Error reporting dead links", "error", err)
+	}
+	
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d notes failed: %w", len(failed), len(notes), stderrors.Join(failed...))
+	}
 	return nil
 }
 
 func (d *Daemon) cleanupImages() error {
 	allImages := d.stateManager.GetAllImages()
-	return d.imageManager.CleanupUnusedImages(allImages)
+	forgotten, err := d.imageManager.CleanupUnusedImages(allImages)
+	for _, imagePath := range forgotten {
+		d.stateManager.ForgetImage(imagePath)
+	}
+	return err
 }
 
 // No longer needed - user handles Git operations manually 
\ No newline at end of file
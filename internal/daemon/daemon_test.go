@@ -0,0 +1,1695 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"obsidian-hugo-sync/internal/config"
+	"obsidian-hugo-sync/internal/errors"
+	"obsidian-hugo-sync/internal/git"
+	"obsidian-hugo-sync/internal/state"
+	"obsidian-hugo-sync/internal/transform"
+	"obsidian-hugo-sync/internal/vault"
+	"obsidian-hugo-sync/internal/watcher"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExtractNoteUidFromHugoFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "yaml",
+			content:  "---\ntitle: \"Test\"\nnoteUid: \"uid-yaml\"\n---\n\nBody",
+			expected: "uid-yaml",
+		},
+		{
+			name:     "toml",
+			content:  "+++\ntitle = \"Test\"\nnoteUid = \"uid-toml\"\n+++\n\nBody",
+			expected: "uid-toml",
+		},
+		{
+			name:     "json",
+			content:  "{\n  \"title\": \"Test\",\n  \"noteUid\": \"uid-json\"\n}\n\nBody",
+			expected: "uid-json",
+		},
+		{
+			name:     "no front-matter",
+			content:  "Just some hand-written content",
+			expected: "",
+		},
+	}
+	
+	d := &Daemon{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "note.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+			
+			uid, err := d.extractNoteUidFromHugoFile(path)
+			if err != nil {
+				t.Fatalf("Failed to extract noteUid: %v", err)
+			}
+			
+			if uid != tt.expected {
+				t.Errorf("Expected noteUid %q, got %q", tt.expected, uid)
+			}
+		})
+	}
+}
+
+// newTestDaemon creates a daemon over temporary vault, repo and cache directories
+func newTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	
+	cfg := &config.Config{
+		Vault:             t.TempDir(),
+		Repo:              t.TempDir(),
+		ContentDir:        "content/docs",
+		RootSection:       "posts",
+		AutoWeight:        true,
+		WeightStep:        10,
+		NumberPrefix:      "keep",
+		LinkFormat:        "relref",
+		UnpublishedLink:   "text",
+		FrontMatterFormat: "yaml",
+		RespectDraft:      true,
+		Interval:          time.Minute,
+		LogLevel:          "info",
+		CacheDir:          t.TempDir(),
+	}
+	
+	d, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	return d
+}
+
+// writeVaultNote writes a note into the test vault and returns its path
+func writeVaultNote(t *testing.T, d *Daemon, relPath, content string) string {
+	t.Helper()
+	
+	path := filepath.Join(d.config.Vault, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create note directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	return path
+}
+
+func TestRenamedNoteGetsAlias(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	oldPath := writeVaultNote(t, d, "guides/Old Name.md", "---\npublish: true\nnoteUid: uid-1\naliases: [\"/custom/\"]\n---\n\nBody\n")
+	if _, err := d.processNote(oldPath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	// Rename the note in the vault, which changes its slug
+	newPath := filepath.Join(d.config.Vault, "guides", "New Name.md")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Failed to rename note: %v", err)
+	}
+	if _, err := d.processNote(newPath); err != nil {
+		t.Fatalf("Failed to process renamed note: %v", err)
+	}
+	
+	oldHugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "old-name.md")
+	if _, err := os.Stat(oldHugoFile); !os.IsNotExist(err) {
+		t.Error("Expected old Hugo file to be removed after rename")
+	}
+	
+	data, err := os.ReadFile(filepath.Join(d.config.Repo, "content", "docs", "guides", "new-name.md"))
+	if err != nil {
+		t.Fatalf("Failed to read renamed Hugo file: %v", err)
+	}
+	
+	if !strings.Contains(string(data), "aliases:\n  - \"/custom/\"\n  - \"/docs/guides/old-name/\"\n") {
+		t.Errorf("Expected author and previous-path aliases, got:\n%s", data)
+	}
+	
+	// The alias survives a later regeneration of published content
+	note, err := vault.ParseNote(newPath)
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+	if err := d.regeneratePublishedContent(map[string]*vault.Note{note.UID: note}); err != nil {
+		t.Fatalf("Failed to regenerate content: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(d.config.Repo, "content", "docs", "guides", "new-name.md"))
+	if !strings.Contains(string(data), "\"/docs/guides/old-name/\"") {
+		t.Errorf("Expected alias to survive regeneration, got:\n%s", data)
+	}
+}
+
+func TestSiblingNotesGetDistinctWeights(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.WeightStep = 5
+	
+	var paths []string
+	for _, name := range []string{"guides/charlie.md", "guides/alpha.md", "guides/bravo.md"} {
+		paths = append(paths, writeVaultNote(t, d, name, "# Note\n"))
+	}
+	// Non-markdown files and subfolders do not shift the ordering
+	if err := os.MkdirAll(filepath.Join(d.config.Vault, "guides", "aaa"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeVaultNote(t, d, "guides/aardvark.png", "")
+	
+	alpha := d.calculateNoteWeight(paths[1])
+	bravo := d.calculateNoteWeight(paths[2])
+	charlie := d.calculateNoteWeight(paths[0])
+	
+	if !(alpha < bravo && bravo < charlie) {
+		t.Errorf("Expected strictly increasing weights, got alpha=%d bravo=%d charlie=%d", alpha, bravo, charlie)
+	}
+	if alpha != 110 || bravo != 115 || charlie != 120 {
+		t.Errorf("Expected weights 110, 115, 120, got %d, %d, %d", alpha, bravo, charlie)
+	}
+}
+
+func TestDryRunShowsContentDiff(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.DryRun = true
+	var output strings.Builder
+	d.diffOutput = &output
+	
+	notePath := writeVaultNote(t, d, "guide.md", "---\npublish: true\nnoteUid: diff-uid\n---\n# Guide\n\nFirst line\nNew second line\n")
+	note, err := vault.ParseNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+	
+	hugoPath := d.calculateHugoPath(note)
+	fullPath := filepath.Join(d.config.Repo, hugoPath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldContent := "---\ntitle: \"Guide\"\n---\n\n# Guide\n\nFirst line\nOld second line\n"
+	if err := os.WriteFile(fullPath, []byte(oldContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	if err := d.publishNote(note); err != nil {
+		t.Fatalf("Failed to publish note: %v", err)
+	}
+	
+	diffText := output.String()
+	for _, expected := range []string{"--- a/" + filepath.ToSlash(hugoPath), "-Old second line", "+New second line", " First line"} {
+		if !strings.Contains(diffText, expected) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", expected, diffText)
+		}
+	}
+	
+	// Dry run leaves the file untouched
+	if content, _ := os.ReadFile(fullPath); string(content) != oldContent {
+		t.Errorf("Expected dry run not to modify file, got:\n%s", content)
+	}
+	
+	// Unpublishing shows the deletion
+	output.Reset()
+	if err := d.unpublishNote(note); err != nil {
+		t.Fatalf("Failed to unpublish note: %v", err)
+	}
+	if !strings.Contains(output.String(), "+++ /dev/null") || !strings.Contains(output.String(), "-Old second line") {
+		t.Errorf("Expected deletion diff, got:\n%s", output.String())
+	}
+}
+
+func TestNumberPrefixDrivesWeight(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.NumberPrefix = "weight"
+	
+	// Alphabetically "10 Wrap Up" sorts before "2 Setup"; the prefix wins
+	setup := writeVaultNote(t, d, "guides/2 Setup.md", "# Setup\n")
+	wrapUp := writeVaultNote(t, d, "guides/10 Wrap Up.md", "# Wrap Up\n")
+	plain := writeVaultNote(t, d, "guides/Appendix.md", "# Appendix\n")
+	
+	if weight := d.calculateNoteWeight(setup); weight != 130 {
+		t.Errorf("Expected weight 130 for prefix 2, got %d", weight)
+	}
+	if weight := d.calculateNoteWeight(wrapUp); weight != 210 {
+		t.Errorf("Expected weight 210 for prefix 10, got %d", weight)
+	}
+	
+	// Notes without a prefix fall back to sibling ordering
+	if weight := d.calculateNoteWeight(plain); weight != 130 {
+		t.Errorf("Expected sibling-index weight 130, got %d", weight)
+	}
+	
+	d.config.NumberPrefix = "keep"
+	if weight := d.calculateNoteWeight(wrapUp); weight != 110 {
+		t.Errorf("Expected prefix to be ignored in keep mode, got %d", weight)
+	}
+}
+
+func TestMaxSectionDepthFlattensDeepNotes(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.MaxSectionDepth = 2
+	d.hugoGen.SetMaxSectionDepth(2)
+	
+	deep := writeVaultNote(t, d, "a/b/c/d/e/Deep Note.md", "---\npublish: true\nnoteUid: uid-deep\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	docs := filepath.Join(d.config.Repo, "content", "docs")
+	for _, path := range []string{"a/b/c-d-e-deep-note.md", "a/_index.md", "a/b/_index.md"} {
+		if _, err := os.Stat(filepath.Join(docs, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected %s at the capped depth, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(docs, "a", "b", "c")); !os.IsNotExist(err) {
+		t.Errorf("Expected no section below the cap, got %v", err)
+	}
+	
+	// Weighted as a note of the capped section, not by its vault depth
+	if weight := d.calculateNoteWeight(deep); weight != 120 {
+		t.Errorf("Expected weight 120 at the capped depth, got %d", weight)
+	}
+}
+
+func TestParseNoteRetriesTransientEmptyRead(t *testing.T) {
+	d := newTestDaemon(t)
+	d.readRetry.BaseDelay = 20 * time.Millisecond
+	
+	// Obsidian mid-save: the file exists but is still empty
+	notePath := writeVaultNote(t, d, "saving.md", "")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(10 * time.Millisecond)
+		os.WriteFile(notePath, []byte("---\npublish: true\n---\n# Saved\n"), 0644)
+	}()
+	
+	note, err := d.parseNote(notePath)
+	<-done
+	if err != nil {
+		t.Fatalf("Expected transient empty read to be retried, got %v", err)
+	}
+	if !note.Published || !strings.Contains(note.Content, "# Saved") {
+		t.Errorf("Expected fully written note, got published=%v content=%q", note.Published, note.Content)
+	}
+	
+	// A note that stays empty is still returned
+	emptyPath := writeVaultNote(t, d, "empty.md", "")
+	if note, err := d.parseNote(emptyPath); err != nil || len(note.Raw) != 0 {
+		t.Errorf("Expected genuinely empty note to parse, got note=%v err=%v", note, err)
+	}
+	
+	// Missing files fail without retrying
+	if _, err := d.parseNote(filepath.Join(d.config.Vault, "missing.md")); err == nil {
+		t.Error("Expected error for missing note")
+	}
+}
+
+func TestRegenerationLeavesUnchangedNotesAlone(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	pathA := writeVaultNote(t, d, "guides/Alpha.md", "---\npublish: true\nnoteUid: uid-a\n---\n\nAlpha body\n")
+	pathB := writeVaultNote(t, d, "guides/Beta.md", "---\npublish: true\nnoteUid: uid-b\n---\n\nSee [[Alpha]]\n")
+	pathC := writeVaultNote(t, d, "guides/Gamma.md", "---\npublish: true\nnoteUid: uid-c\n---\n\nStandalone\n")
+	for _, path := range []string{pathA, pathB, pathC} {
+		if _, err := d.processNote(path); err != nil {
+			t.Fatalf("Failed to process note: %v", err)
+		}
+	}
+	
+	hugoB := filepath.Join(d.config.Repo, "content", "docs", "guides", "beta.md")
+	if dataB, _ := os.ReadFile(hugoB); !strings.Contains(string(dataB), `relref "docs/guides/alpha"`) {
+		t.Fatalf("Expected linking note to link Alpha, got:\n%s", dataB)
+	}
+	
+	// Backdate C's Hugo file so any rewrite is visible in its modification time
+	hugoC := filepath.Join(d.config.Repo, "content", "docs", "guides", "gamma.md")
+	before, err := os.ReadFile(hugoC)
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(hugoC, past, past); err != nil {
+		t.Fatal(err)
+	}
+	
+	// Rename A and regenerate everything, as a rename-triggered link update does
+	time.Sleep(1100 * time.Millisecond) // Let lastUpdated move on to a new second
+	renamedA := filepath.Join(d.config.Vault, "guides", "Alpha Renamed.md")
+	if err := os.Rename(pathA, renamedA); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.processNote(renamedA); err != nil {
+		t.Fatalf("Failed to process renamed note: %v", err)
+	}
+	
+	published := make(map[string]*vault.Note)
+	for _, path := range []string{renamedA, pathB, pathC} {
+		note, err := vault.ParseNote(path)
+		if err != nil {
+			t.Fatalf("Failed to parse note: %v", err)
+		}
+		published[note.UID] = note
+	}
+	if err := d.regeneratePublishedContent(published); err != nil {
+		t.Fatalf("Failed to regenerate content: %v", err)
+	}
+	
+	after, _ := os.ReadFile(hugoC)
+	if string(after) != string(before) {
+		t.Errorf("Expected unchanged note to keep its content, got:\n%s", after)
+	}
+	if info, err := os.Stat(hugoC); err != nil || !info.ModTime().Equal(past) {
+		t.Error("Expected unchanged note not to be rewritten")
+	}
+	
+	// B links A by its old name, so its link is rewritten
+	dataB, _ := os.ReadFile(hugoB)
+	if strings.Contains(string(dataB), "relref") {
+		t.Errorf("Expected linking note to be regenerated, got:\n%s", dataB)
+	}
+}
+
+func TestIncrementalSyncPicksUpMissedChanges(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	editedPath := writeVaultNote(t, d, "guides/Edited.md", "---\npublish: true\nnoteUid: uid-edited\n---\n\nOriginal body\n")
+	deletedPath := writeVaultNote(t, d, "guides/Deleted.md", "---\npublish: true\nnoteUid: uid-deleted\n---\n\nDoomed\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	
+	// Change the vault behind the watcher's back
+	writeVaultNote(t, d, "guides/Edited.md", "---\npublish: true\nnoteUid: uid-edited\n---\n\nUpdated body\n")
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(editedPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(deletedPath); err != nil {
+		t.Fatal(err)
+	}
+	writeVaultNote(t, d, "guides/Added.md", "---\npublish: true\nnoteUid: uid-added\n---\n\nNew note\n")
+	
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Failed to run incremental sync: %v", err)
+	}
+	
+	contentDir := filepath.Join(d.config.Repo, "content", "docs", "guides")
+	data, err := os.ReadFile(filepath.Join(contentDir, "edited.md"))
+	if err != nil {
+		t.Fatalf("Failed to read edited Hugo file: %v", err)
+	}
+	if !strings.Contains(string(data), "Updated body") {
+		t.Errorf("Expected edited note to be resynced, got:\n%s", data)
+	}
+	
+	if _, err := os.Stat(filepath.Join(contentDir, "added.md")); err != nil {
+		t.Errorf("Expected new note to be published: %v", err)
+	}
+	
+	if _, err := os.Stat(filepath.Join(contentDir, "deleted.md")); !os.IsNotExist(err) {
+		t.Error("Expected deleted note's Hugo file to be removed")
+	}
+	if d.stateManager.GetNote("uid-deleted") != nil {
+		t.Error("Expected deleted note to be dropped from state")
+	}
+}
+
+func TestIncrementalSyncSkipsUnchangedNotes(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	writeVaultNote(t, d, "guides/Stable.md", "---\npublish: true\nnoteUid: uid-stable\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	
+	before := d.stateManager.GetNote("uid-stable").LastSync
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Failed to run incremental sync: %v", err)
+	}
+	
+	if after := d.stateManager.GetNote("uid-stable").LastSync; !after.Equal(before) {
+		t.Error("Expected unchanged note not to be reprocessed")
+	}
+}
+
+func TestDaemonsFromMultiSyncConfig(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("OBSIDIAN_VAULT", "")
+	t.Setenv("HUGO_REPO", "")
+	
+	docsVault, blogVault, repo := t.TempDir(), t.TempDir(), t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configFile := fmt.Sprintf(`link_format = "md"
+
+[[sync]]
+vault = %q
+repo = %q
+content_dir = "content/docs"
+
+[[sync]]
+vault = %q
+repo = %q
+content_dir = "content/blog"
+link_format = "relref"
+`, docsVault, repo, blogVault, repo)
+	if err := os.WriteFile(configPath, []byte(configFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	cfgs, err := config.LoadAll(&config.Options{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfgs) != 2 {
+		t.Fatalf("Expected 2 sync configs, got %d", len(cfgs))
+	}
+	
+	// Tables inherit top-level settings and can override them
+	if cfgs[0].ContentDir != "content/docs" || cfgs[0].LinkFormat != "md" {
+		t.Errorf("Expected docs table with inherited md links, got %q and %q", cfgs[0].ContentDir, cfgs[0].LinkFormat)
+	}
+	if cfgs[1].ContentDir != "content/blog" || cfgs[1].LinkFormat != "relref" {
+		t.Errorf("Expected blog table with relref links, got %q and %q", cfgs[1].ContentDir, cfgs[1].LinkFormat)
+	}
+	if cfgs[0].CacheDir == cfgs[1].CacheDir {
+		t.Error("Expected each vault to get its own cache directory")
+	}
+	
+	// Each table drives its own daemon
+	for _, cfg := range cfgs {
+		d, err := New(cfg)
+		if err != nil {
+			t.Fatalf("Failed to create daemon for %s: %v", cfg.Vault, err)
+		}
+		t.Cleanup(d.watcher.Stop)
+		
+		writeVaultNote(t, d, "Note.md", "---\npublish: true\n---\n\nBody\n")
+		if err := d.performFullSync(); err != nil {
+			t.Fatalf("Failed to sync %s: %v", cfg.Vault, err)
+		}
+	}
+	
+	for _, contentDir := range []string{"docs", "blog"} {
+		if _, err := os.Stat(filepath.Join(repo, "content", contentDir, "posts", "note.md")); err != nil {
+			t.Errorf("Expected note synced into content/%s: %v", contentDir, err)
+		}
+	}
+	
+	// Vault flags are ambiguous with several tables
+	if _, err := config.LoadAll(&config.Options{ConfigFile: configPath, Vault: docsVault}); err == nil {
+		t.Error("Expected --vault to be rejected alongside [[sync]] tables")
+	}
+}
+
+func TestDraftNoteIsUnpublished(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	hugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")
+	if _, err := os.Stat(hugoFile); err != nil {
+		t.Fatalf("Expected published Hugo file: %v", err)
+	}
+	
+	// Marking the live note as a draft takes it down
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\ndraft: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process draft note: %v", err)
+	}
+	
+	if _, err := os.Stat(hugoFile); !os.IsNotExist(err) {
+		t.Error("Expected Hugo file to be removed once the note is a draft")
+	}
+}
+
+func TestIndexTemplateSectionIsPruned(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.IndexTemplate = filepath.Join(t.TempDir(), "index.tmpl")
+	if err := os.WriteFile(cfg.IndexTemplate, []byte("---\nlayout: section\n---\n\nAll {{.Title}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	sectionDir := filepath.Join(cfg.Repo, "content", "docs", "guides")
+	index, err := os.ReadFile(filepath.Join(sectionDir, "_index.md"))
+	if err != nil {
+		t.Fatalf("Expected a section index: %v", err)
+	}
+	if !strings.Contains(string(index), "layout: \"section\"\n---\n\nAll Guides\n") {
+		t.Errorf("Expected the templated section index, got:\n%s", index)
+	}
+	
+	// The templated index still counts as generated once its section empties
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: false\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process unpublished note: %v", err)
+	}
+	if _, err := os.Stat(sectionDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the emptied section to be removed, got %v", err)
+	}
+}
+
+func TestNoteMovedOutOfPublishFolderIsUnpublished(t *testing.T) {
+	d := newTestDaemon(t)
+	d.parseOptions.PublishFolders = []string{filepath.Join(d.config.Vault, "Published")}
+	
+	notePath := writeVaultNote(t, d, "Published/Note.md", "---\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	hugoFile := filepath.Join(d.config.Repo, "content", "docs", "Published", "note.md")
+	if _, err := os.Stat(hugoFile); err != nil {
+		t.Fatalf("Expected note in the publish folder to be published: %v", err)
+	}
+	
+	// Moving the note out of the publish folder takes it down
+	movedPath := filepath.Join(d.config.Vault, "Drafts", "Note.md")
+	if err := os.MkdirAll(filepath.Dir(movedPath), 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	if err := os.Rename(notePath, movedPath); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+	if _, err := d.processNote(movedPath); err != nil {
+		t.Fatalf("Failed to process moved note: %v", err)
+	}
+	
+	if _, err := os.Stat(hugoFile); !os.IsNotExist(err) {
+		t.Error("Expected Hugo file to be removed once the note left the publish folder")
+	}
+	if note := d.stateManager.GetNote("uid-1"); note == nil || note.Published {
+		t.Errorf("Expected state to record the note as unpublished, got %+v", note)
+	}
+}
+
+func TestEmitDraftPassthrough(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.EmitDraft = true
+	d.parseOptions.PublishDrafts = true
+	d.hugoGen.SetEmitDraft(true)
+	
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\ndraft: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	data, err := os.ReadFile(filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md"))
+	if err != nil {
+		t.Fatalf("Expected draft to be published: %v", err)
+	}
+	if !strings.Contains(string(data), "draft: true\n") {
+		t.Errorf("Expected draft: true in front-matter, got:\n%s", data)
+	}
+}
+
+func TestDeadLinkReport(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.LinkReport = filepath.Join(t.TempDir(), "dead-links.json")
+	
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\nSee [[Missing Note]], [[Secret]] and [[B]].\n")
+	writeVaultNote(t, d, "guides/B.md", "---\npublish: true\nnoteUid: uid-b\n---\n\nPublished\n")
+	writeVaultNote(t, d, "private/Secret.md", "---\npublish: false\n---\n\nNot for the site\n")
+	
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	data, err := os.ReadFile(d.config.LinkReport)
+	if err != nil {
+		t.Fatalf("Failed to read link report: %v", err)
+	}
+	var report LinkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode link report: %v", err)
+	}
+	
+	expected := []DeadLink{
+		{Source: "guides/A.md", Target: "Missing Note", Reason: DeadLinkMissing},
+		{Source: "guides/A.md", Target: "Secret", Reason: DeadLinkUnpublished},
+	}
+	if len(report.DeadLinks) != len(expected) {
+		t.Fatalf("Expected %d dead links, got %+v", len(expected), report.DeadLinks)
+	}
+	for i, link := range expected {
+		if report.DeadLinks[i] != link {
+			t.Errorf("Expected %+v, got %+v", link, report.DeadLinks[i])
+		}
+	}
+}
+
+func TestRegenerateContinuesPastFailingNote(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	published := make(map[string]*vault.Note)
+	for _, name := range []string{"A", "B", "C"} {
+		path := writeVaultNote(t, d, "guides/"+name+".md", "---\npublish: true\nnoteUid: uid-"+name+"\n---\n\nLinks to [[A]] and [[C]].\n")
+		note, err := vault.ParseNote(path)
+		if err != nil {
+			t.Fatalf("Failed to parse note: %v", err)
+		}
+		published[note.UID] = note
+	}
+	
+	// The second note in processing order fails to write
+	writeErr := stderrors.New("disk hiccup")
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		if filepath.Base(name) == "b.md" {
+			return writeErr
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	
+	err := d.regeneratePublishedContent(published)
+	if !stderrors.Is(err, writeErr) {
+		t.Fatalf("Expected the write error to be reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 of 3 notes failed") || !strings.Contains(err.Error(), "B.md") {
+		t.Errorf("Expected the failing note in the aggregate error, got %v", err)
+	}
+	
+	for _, name := range []string{"a.md", "c.md"} {
+		if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", name)); err != nil {
+			t.Errorf("Expected %s to be regenerated despite the failure: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", "b.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected failing note not to be written, got %v", err)
+	}
+}
+
+func TestFullSyncFinishesPastFailingNote(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.Manifest = filepath.Join(t.TempDir(), "manifest.json")
+	for _, name := range []string{"A", "B", "C"} {
+		writeVaultNote(t, d, "guides/"+name+".md", "---\npublish: true\nnoteUid: uid-"+name+"\n---\n\nLinks to [[A]] and [[C]].\n")
+	}
+	
+	// B is published, then fails every time it is regenerated
+	writeErr := stderrors.New("disk hiccup")
+	var bWrites int
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		if filepath.Base(name) == "b.md" {
+			if bWrites++; bWrites > 1 {
+				return writeErr
+			}
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	
+	if err := d.performFullSync(); !stderrors.Is(err, writeErr) {
+		t.Fatalf("Expected the regeneration error to be returned, got %v", err)
+	}
+	
+	// The bookkeeping after regeneration still ran
+	if _, err := os.Stat(filepath.Join(d.config.CacheDir, "state.json")); err != nil {
+		t.Errorf("Expected state to be saved: %v", err)
+	}
+	if _, err := os.Stat(d.config.Manifest); err != nil {
+		t.Errorf("Expected manifest to be written: %v", err)
+	}
+	if d.lastFullSync.Published != 3 {
+		t.Errorf("Expected the sync result to be recorded, got %+v", d.lastFullSync)
+	}
+}
+
+func TestStubModeWritesStubPageOnce(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.UnpublishedLink = "stub"
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\nSee [[Draft]]\n")
+	writeVaultNote(t, d, "guides/Draft.md", "---\nnoteUid: uid-draft\n---\n\nNot yet\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	stubPath := filepath.Join(cfg.Repo, "content/docs/unpublished.md")
+	if _, err := os.Stat(stubPath); err != nil {
+		t.Fatalf("Expected stub page to be written: %v", err)
+	}
+	if d.isManagedNote(stubPath) {
+		t.Error("Expected stub page not to be treated as a synced note")
+	}
+	note, err := os.ReadFile(filepath.Join(d.config.Repo, "content/docs/guides/a.md"))
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if !strings.Contains(string(note), `[Draft]({{< relref "docs/unpublished" >}})`) {
+		t.Errorf("Expected link to the stub page, got:\n%s", note)
+	}
+	
+	// The stub page is kept as edited on later syncs
+	if err := os.WriteFile(stubPath, []byte("custom"), 0644); err != nil {
+		t.Fatalf("Failed to edit stub page: %v", err)
+	}
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Second full sync failed: %v", err)
+	}
+	if data, _ := os.ReadFile(stubPath); string(data) != "custom" {
+		t.Errorf("Expected edited stub page to be kept, got %q", data)
+	}
+}
+
+func TestImageOutputDirFlattensImages(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.ImageOutputDir = "static/images"
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\n![[diagram.png]]\n")
+	writeVaultNote(t, d, "guides/diagram.png", "png")
+	writeVaultNote(t, d, "reference/diagram.png", "other")
+	writeVaultNote(t, d, "reference/B.md", "---\npublish: true\nnoteUid: uid-b\n---\n\n![Diagram](diagram.png)\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	tests := []struct {
+		hugoPath string
+		image    string
+		content  string
+		link     string
+	}{
+		{hugoPath: "content/docs/guides/a.md", image: "static/images/guides-diagram.png", content: "png", link: "![diagram.png](/images/guides-diagram.png)"},
+		{hugoPath: "content/docs/reference/b.md", image: "static/images/reference-diagram.png", content: "other", link: "![Diagram](/images/reference-diagram.png)"},
+	}
+	for _, tt := range tests {
+		image, err := os.ReadFile(filepath.Join(cfg.Repo, tt.image))
+		if err != nil || string(image) != tt.content {
+			t.Errorf("Expected %s to hold %q, got %q (%v)", tt.image, tt.content, image, err)
+		}
+		note, err := os.ReadFile(filepath.Join(cfg.Repo, tt.hugoPath))
+		if err != nil {
+			t.Fatalf("Failed to read note: %v", err)
+		}
+		if !strings.Contains(string(note), tt.link) {
+			t.Errorf("Expected %s to contain %s, got:\n%s", tt.hugoPath, tt.link, note)
+		}
+	}
+	
+	// Purging also reaches images outside the content directory
+	if err := d.Purge(); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "static/images/guides-diagram.png")); !os.IsNotExist(err) {
+		t.Error("Expected purge to remove the flattened image")
+	}
+}
+
+func TestEnforceScheduleSkipsFutureNote(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.EnforceSchedule = true
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/Launch.md", "---\npublish: true\nnoteUid: uid-launch\npublishDate: 2999-01-01\n---\n\nSoon\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "content/docs/guides/launch.md")); !os.IsNotExist(err) {
+		t.Error("Expected future-dated note not to be written")
+	}
+	if note := d.stateManager.GetNote("uid-launch"); note == nil || note.Published {
+		t.Errorf("Expected future-dated note to be recorded as unpublished, got %+v", note)
+	}
+}
+
+func TestEnforceScheduleUnpublishesExpiredNote(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.EnforceSchedule = true
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	expiry := time.Now().Add(300 * time.Millisecond).UTC().Format(time.RFC3339Nano)
+	writeVaultNote(t, d, "guides/Offer.md", "---\npublish: true\nnoteUid: uid-offer\nexpiryDate: "+expiry+"\n---\n\nLimited time\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	hugoFile := filepath.Join(cfg.Repo, "content/docs/guides/offer.md")
+	if _, err := os.Stat(hugoFile); err != nil {
+		t.Fatalf("Expected note to be published before its expiry: %v", err)
+	}
+	
+	// The unchanged note is re-evaluated once its expiry passes
+	time.Sleep(400 * time.Millisecond)
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	if _, err := os.Stat(hugoFile); !os.IsNotExist(err) {
+		t.Error("Expected expired note to be unpublished")
+	}
+	if note := d.stateManager.GetNote("uid-offer"); note == nil || note.Published {
+		t.Errorf("Expected expired note to be recorded as unpublished, got %+v", note)
+	}
+}
+
+func TestRepoNestedInVaultIsNotScanned(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.Repo = filepath.Join(cfg.Vault, "site")
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\nBody\n")
+	for i := 0; i < 2; i++ {
+		if err := d.performFullSync(); err != nil {
+			t.Fatalf("Full sync %d failed: %v", i+1, err)
+		}
+	}
+	
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "content/docs/guides/a.md")); err != nil {
+		t.Fatalf("Expected note to be published into the nested repo: %v", err)
+	}
+	notes := d.stateManager.GetAllNotes()
+	if len(notes) != 1 || notes["uid-a"] == nil || notes["uid-a"].SourcePath != filepath.Join(cfg.Vault, "guides/A.md") {
+		t.Errorf("Expected only the vault note in state, got %+v", notes)
+	}
+}
+
+func TestInlineSVGUnderInlinesSmallSVGs(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.InlineSVGUnder = 200
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	icon := `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16" onload="alert(1)">
+  <script>alert(1)</script>
+  <path d="M0 0h16v16z"/>
+</svg>
+`
+	large := `<svg xmlns="http://www.w3.org/2000/svg">` + strings.Repeat(`<rect width="1" height="1"/>`, 20) + `</svg>`
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\nIcon: ![[icon.svg]]\n\n![Logo](logo.svg)\n")
+	writeVaultNote(t, d, "guides/icon.svg", icon)
+	writeVaultNote(t, d, "guides/logo.svg", large)
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	note, err := os.ReadFile(filepath.Join(cfg.Repo, "content/docs/guides/a.md"))
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	inlined := `Icon: <svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><path d="M0 0h16v16z"></path></svg>`
+	if !strings.Contains(string(note), inlined) {
+		t.Errorf("Expected the small SVG inlined as %s, got:\n%s", inlined, note)
+	}
+	if !strings.Contains(string(note), "![Logo](logo.svg)") {
+		t.Errorf("Expected the large SVG to stay referenced, got:\n%s", note)
+	}
+	
+	// Only the large SVG is copied
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "content/docs/guides/icon.svg")); !os.IsNotExist(err) {
+		t.Errorf("Expected the inlined SVG not to be copied, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(cfg.Repo, "content/docs/guides/logo.svg")); err != nil || string(data) != large {
+		t.Errorf("Expected the large SVG to be copied, got %q (%v)", data, err)
+	}
+}
+
+func TestManifestListsNotesAndImages(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.Manifest = filepath.Join(t.TempDir(), "manifest.json")
+	
+	writeVaultNote(t, d, "guides/A.md", "---\npublish: true\nnoteUid: uid-a\n---\n\n![[diagram.png]]\n![[shared.png]]\n")
+	writeVaultNote(t, d, "guides/B.md", "---\npublish: true\nnoteUid: uid-b\n---\n\n![[shared.png]]\n")
+	writeVaultNote(t, d, "guides/diagram.png", "png")
+	writeVaultNote(t, d, "guides/shared.png", "png")
+	
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	data, err := os.ReadFile(d.config.Manifest)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	
+	if len(manifest.Notes) != 2 {
+		t.Fatalf("Expected 2 notes in manifest, got %+v", manifest.Notes)
+	}
+	
+	a := manifest.Notes["uid-a"]
+	if a.SourcePath != "guides/A.md" || a.HugoPath != "content/docs/guides/a.md" || !a.Published || a.ContentHash == "" {
+		t.Errorf("Unexpected manifest entry for A: %+v", a)
+	}
+	expectedImages := []ManifestImage{
+		{SourcePath: "guides/diagram.png", HugoPath: "content/docs/guides/diagram.png"},
+		{SourcePath: "guides/shared.png", HugoPath: "content/docs/guides/shared.png"},
+	}
+	if len(a.Images) != len(expectedImages) {
+		t.Fatalf("Expected images %+v, got %+v", expectedImages, a.Images)
+	}
+	for i, image := range expectedImages {
+		if a.Images[i] != image {
+			t.Errorf("Expected %+v, got %+v", image, a.Images[i])
+		}
+	}
+	
+	b := manifest.Notes["uid-b"]
+	if b.SourcePath != "guides/B.md" || len(b.Images) != 1 || b.Images[0] != expectedImages[1] {
+		t.Errorf("Unexpected manifest entry for B: %+v", b)
+	}
+}
+
+func TestLegacyUIDPromotedToNoteUid(t *testing.T) {
+	d := newTestDaemon(t)
+	d.parseOptions.UIDKeys = []string{"noteUid", "uid"}
+	
+	notePath := writeVaultNote(t, d, "guides/Legacy.md", "---\npublish: true\nuid: legacy-123\n---\n\nBody\n")
+	note, err := d.processNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	if note.UID != "legacy-123" {
+		t.Errorf("Expected UID 'legacy-123', got '%s'", note.UID)
+	}
+	
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if !strings.Contains(string(data), "noteUid: legacy-123") {
+		t.Errorf("Expected noteUid written back to the vault note, got:\n%s", data)
+	}
+	
+	hugoData, err := os.ReadFile(filepath.Join(d.config.Repo, "content", "docs", "guides", "legacy.md"))
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	if !strings.Contains(string(hugoData), "noteUid: \"legacy-123\"") {
+		t.Errorf("Expected Hugo file to carry the legacy UID, got:\n%s", hugoData)
+	}
+}
+
+func TestUIDWriteBackKeepsSourceEncoding(t *testing.T) {
+	d := newTestDaemon(t)
+	d.parseOptions.SourceEncoding = vault.EncodingLatin1
+	
+	// "Café" and "déjà vu" encoded as Latin-1, without a noteUid yet
+	notePath := writeVaultNote(t, d, "guides/Cafe.md", "---\ntitle: \"Caf\xe9\"\npublish: true\n---\n\nd\xe9j\xe0 vu\n")
+	note, err := d.processNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if !strings.Contains(string(data), "noteUid: "+note.UID) || !strings.Contains(string(data), "d\xe9j\xe0 vu") {
+		t.Errorf("Expected the noteUid written back in Latin-1, got %q", data)
+	}
+	
+	// A second read decodes the note as it was
+	reread, err := d.processNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to process note again: %v", err)
+	}
+	if reread.UID != note.UID || reread.Title != "Café" || !strings.Contains(reread.Content, "déjà vu") {
+		t.Errorf("Expected UID %q, title 'Café' and 'déjà vu', got %q, %q and %q", note.UID, reread.UID, reread.Title, reread.Content)
+	}
+}
+
+func TestRemoteVaultNotesAreNotWrittenTo(t *testing.T) {
+	d := newTestDaemon(t)
+	d.vaultRepo = &git.VaultCheckout{}
+	
+	content := "---\npublish: true\n---\n\nBody\n"
+	notePath := writeVaultNote(t, d, "guides/Remote.md", content)
+	note, err := d.processNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("Expected the checkout note to stay untouched, got:\n%s", data)
+	}
+	
+	// The UID is kept in state and recalled for the note's path
+	writeVaultNote(t, d, "guides/Remote.md", content+"More\n")
+	reread, err := d.processNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to process note again: %v", err)
+	}
+	if reread.UID != note.UID {
+		t.Errorf("Expected UID %q to be recalled, got %q", note.UID, reread.UID)
+	}
+	if len(d.stateManager.GetAllNotes()) != 1 {
+		t.Errorf("Expected one note in state, got %d", len(d.stateManager.GetAllNotes()))
+	}
+}
+
+func TestDuplicateUIDGetsFreshUID(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	// The original is synced before it is copied
+	original := writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-dup\n---\n\nOriginal\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	// The copy sorts first, so the state decides which note keeps the UID
+	copied := writeVaultNote(t, d, "guides/A Setup Copy.md", "---\npublish: true\nnoteUid: uid-dup\n---\n\nCopy\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	originalNote, err := vault.ParseNote(original)
+	if err != nil {
+		t.Fatalf("Failed to parse original: %v", err)
+	}
+	copiedNote, err := vault.ParseNote(copied)
+	if err != nil {
+		t.Fatalf("Failed to parse copy: %v", err)
+	}
+	if originalNote.UID != "uid-dup" {
+		t.Errorf("Expected the original to keep uid-dup, got %s", originalNote.UID)
+	}
+	if copiedNote.UID == "uid-dup" || copiedNote.UID == "" {
+		t.Errorf("Expected the copy to get a fresh UID, got %q", copiedNote.UID)
+	}
+	
+	// Both publish to their own files
+	for hugoPath, uid := range map[string]string{
+		"content/docs/guides/setup.md":        originalNote.UID,
+		"content/docs/guides/a-setup-copy.md": copiedNote.UID,
+	} {
+		data, err := os.ReadFile(filepath.Join(d.config.Repo, hugoPath))
+		if err != nil {
+			t.Fatalf("Expected %s to be published, got %v", hugoPath, err)
+		}
+		if !strings.Contains(string(data), "noteUid: \""+uid+"\"") {
+			t.Errorf("Expected %s to carry %s, got:\n%s", hugoPath, uid, data)
+		}
+	}
+	if stateNote := d.stateManager.GetNote("uid-dup"); stateNote == nil || stateNote.SourcePath != original {
+		t.Errorf("Expected the state to keep uid-dup for the original, got %+v", stateNote)
+	}
+}
+
+func TestDuplicateUIDInOneFullSync(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	first := writeVaultNote(t, d, "A.md", "---\npublish: true\nnoteUid: uid-dup\n---\n\nA\n")
+	second := writeVaultNote(t, d, "B.md", "---\npublish: true\nnoteUid: uid-dup\n---\n\nB\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	firstNote, _ := vault.ParseNote(first)
+	secondNote, _ := vault.ParseNote(second)
+	if firstNote == nil || secondNote == nil || firstNote.UID == secondNote.UID {
+		t.Fatalf("Expected distinct UIDs after the sync, got %+v and %+v", firstNote, secondNote)
+	}
+	if len(d.stateManager.GetAllNotes()) != 2 {
+		t.Errorf("Expected both notes in the state, got %d", len(d.stateManager.GetAllNotes()))
+	}
+}
+
+func TestInvalidFrontMatterReported(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	brokenPath := writeVaultNote(t, d, "guides/Broken.md", "---\ntitle: Broken\n\tpublish: true\n---\n\nBody\n")
+	writeVaultNote(t, d, "guides/Fine.md", "---\npublish: true\nnoteUid: uid-fine\n---\n\nBody\n")
+	
+	_, err := d.parseNote(brokenPath)
+	invalid := invalidFrontMatter(err)
+	if invalid == nil {
+		t.Fatalf("Expected invalid front-matter error, got %v", err)
+	}
+	if invalid.Type != errors.ErrorTypeVault {
+		t.Errorf("Expected vault error, got %s", invalid.Type)
+	}
+	if invalid.Context["path"] != brokenPath || invalid.Context["line"] != 3 {
+		t.Errorf("Expected path and line 3 in context, got %v", invalid.Context)
+	}
+	if invalid.Recoverable {
+		t.Error("Expected front-matter errors not to be retried")
+	}
+	
+	// The rest of the vault still syncs
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", "fine.md")); err != nil {
+		t.Errorf("Expected valid note to be published: %v", err)
+	}
+}
+
+func TestRequestResyncRunsFullSync(t *testing.T) {
+	d := newTestDaemon(t)
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	
+	// Requests made while one is pending are coalesced rather than blocking
+	d.RequestResync()
+	d.RequestResync()
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.eventLoop(ctx)
+	}()
+	
+	hugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(hugoFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Error("Expected resync to publish the note")
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Event loop failed: %v", err)
+	}
+}
+
+func TestSyncNowRunsOnEventLoop(t *testing.T) {
+	d := newTestDaemon(t)
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	writeVaultNote(t, d, "guides/Draft.md", "---\nnoteUid: uid-2\n---\n\nBody\n")
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.eventLoop(ctx)
+	}()
+	
+	syncCtx, syncCancel := context.WithTimeout(ctx, 5*time.Second)
+	result := d.SyncNow(syncCtx)
+	syncCancel()
+	if result.Error != "" {
+		t.Fatalf("Sync failed: %s", result.Error)
+	}
+	if result.Vault != d.config.Vault || result.Processed != 2 || result.Published != 1 {
+		t.Errorf("Expected 2 processed and 1 published in %s, got %+v", d.config.Vault, result)
+	}
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")); err != nil {
+		t.Errorf("Expected sync to publish the note: %v", err)
+	}
+	
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Event loop failed: %v", err)
+	}
+	
+	// Without a running event loop the request gives up with its context
+	stopped, stop := context.WithCancel(context.Background())
+	stop()
+	if result := d.SyncNow(stopped); result.Error == "" {
+		t.Error("Expected an error when the event loop isn't running")
+	}
+}
+
+func TestFreshUIDNoteDoesNotChurn(t *testing.T) {
+	d := newTestDaemon(t)
+	notePath := writeVaultNote(t, d, "guides/Fresh.md", "---\npublish: true\n---\n\nBody\n")
+	
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	// The state hash covers the UID and weight we wrote back, not the original file
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if !strings.Contains(string(data), "noteUid:") {
+		t.Fatalf("Expected noteUid written back, got:\n%s", data)
+	}
+	note, err := d.parseNote(notePath)
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+	if stateNote := d.stateManager.GetNote(note.UID); stateNote == nil || stateNote.ContentHash != state.CalculateContentHash(data) {
+		t.Errorf("Expected state hash of the written note, got %+v", stateNote)
+	}
+	
+	hugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "fresh.md")
+	marker := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(hugoFile, marker, marker); err != nil {
+		t.Fatal(err)
+	}
+	
+	// Neither the next sync nor a touch without edits rewrites the Hugo file
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(notePath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	
+	info, err := os.Stat(hugoFile)
+	if err != nil {
+		t.Fatalf("Failed to stat Hugo file: %v", err)
+	}
+	if !info.ModTime().Equal(marker) {
+		t.Error("Expected Hugo file (and its lastUpdated) to be left alone")
+	}
+}
+
+func TestConcurrentPublishAndUnpublishKeepSectionIndex(t *testing.T) {
+	d := newTestDaemon(t)
+	indexPath := filepath.Join(d.config.Repo, "content/docs/guides/_index.md")
+	
+	// Each worker repeatedly publishes and unpublishes its own note in the same
+	// folder, so the folder keeps getting emptied and indexed
+	worker := func(name string, wg *sync.WaitGroup) {
+		defer wg.Done()
+		
+		hugoPath := filepath.Join("content/docs/guides", name+".md")
+		fullPath := filepath.Join(d.config.Repo, hugoPath)
+		for i := 0; i < 1000; i++ {
+			if err := d.writeHugoFile(hugoPath, "---\nnoteUid: "+name+"\n---\n"); err != nil {
+				t.Errorf("Expected publish to succeed, got %v", err)
+				return
+			}
+			if err := d.ensureSectionIndex(hugoPath); err != nil {
+				t.Errorf("Expected section index to be ensured, got %v", err)
+				return
+			}
+			
+			// The note is still published, so its section must keep its index
+			if _, err := os.Stat(indexPath); err != nil {
+				t.Errorf("Expected _index.md while %s is published, got %v", name, err)
+				return
+			}
+			
+			if err := os.Remove(fullPath); err != nil {
+				t.Errorf("Expected unpublish to succeed, got %v", err)
+				return
+			}
+			d.removeEmptyDirs(filepath.Dir(fullPath))
+		}
+	}
+	
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go worker("first", &wg)
+	go worker("second", &wg)
+	wg.Wait()
+	
+	// Nothing is published any more, so the section is pruned entirely
+	if _, err := os.Stat(filepath.Dir(indexPath)); !os.IsNotExist(err) {
+		t.Errorf("Expected emptied section to be removed, got %v", err)
+	}
+	if len(d.dirLocks.locks) != 0 {
+		t.Errorf("Expected directory locks to be released, got %d", len(d.dirLocks.locks))
+	}
+}
+
+func TestIdenticalOutputIsNotRewritten(t *testing.T) {
+	d := newTestDaemon(t)
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	var written []string
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		rel, _ := filepath.Rel(d.config.Repo, name)
+		written = append(written, filepath.ToSlash(rel))
+		return os.WriteFile(name, data, perm)
+	}
+	
+	// A second full sync regenerates every page and index to the same bytes
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	hugoPath := filepath.Join("content", "docs", "guides", "note.md")
+	data, err := os.ReadFile(filepath.Join(d.config.Repo, hugoPath))
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	if err := d.writeHugoFile(hugoPath, string(data)); err != nil {
+		t.Fatalf("Failed to write Hugo file: %v", err)
+	}
+	if len(written) != 0 {
+		t.Errorf("Expected no writes for identical output, got %v", written)
+	}
+	
+	// Changed output is written
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nNew body\n")
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	if len(written) != 1 || written[0] != "content/docs/guides/note.md" {
+		t.Errorf("Expected only the changed note to be written, got %v", written)
+	}
+}
+
+func TestShutdownFinishesRunningSync(t *testing.T) {
+	d := newTestDaemon(t)
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	
+	// The shutdown signal arrives while the note is being written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		return os.WriteFile(name, data, perm)
+	}
+	
+	done := make(chan error, 1)
+	go func() {
+		done <- d.eventLoop(ctx)
+	}()
+	d.settled <- watcher.Event{Path: notePath, Operation: watcher.Create}
+	
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Event loop did not stop")
+	}
+	
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")); err != nil {
+		t.Errorf("Expected the running sync to finish writing, got %v", err)
+	}
+	
+	// The state on disk knows the note
+	saved, err := state.NewManager(d.config.CacheDir, d.config.Vault)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if saved.GetNote("uid-1") == nil {
+		t.Error("Expected the state to be saved on shutdown")
+	}
+}
+
+func TestReadOnlyRepoPausesWrites(t *testing.T) {
+	d := newTestDaemon(t)
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	
+	// Simulate the Hugo repository's file system being remounted read-only
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		return &fs.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+	}
+	
+	_, err := d.processNote(notePath)
+	var daemonErr *errors.DaemonError
+	if !stderrors.As(err, &daemonErr) || daemonErr.Type != errors.ErrorTypeFileSystem {
+		t.Fatalf("Expected a FileSystem error, got %v", err)
+	}
+	if !d.writesPaused() {
+		t.Fatal("Expected writes to be paused after a read-only failure")
+	}
+	if d.writeBackoff.delay != minWriteBackoff {
+		t.Errorf("Expected a %v cooldown, got %v", minWriteBackoff, d.writeBackoff.delay)
+	}
+	
+	// No writes are attempted during the cooldown, and a full sync stops
+	// short so the daemon retries it later rather than exiting
+	writes := 0
+	d.writeFile = func(name string, data []byte, perm os.FileMode) error {
+		writes++
+		return &fs.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+	}
+	if err := d.performFullSync(); !stderrors.Is(err, errWritesPaused) {
+		t.Errorf("Expected full sync to stop while writes are paused, got %v", err)
+	}
+	if writes != 0 {
+		t.Errorf("Expected no writes during the cooldown, got %d", writes)
+	}
+	
+	// A failed attempt after the cooldown doubles it
+	d.writeBackoff.until = time.Now()
+	if err := d.performFullSync(); err == nil {
+		t.Error("Expected full sync to fail on a read-only repository")
+	}
+	if d.writeBackoff.delay != 2*minWriteBackoff {
+		t.Errorf("Expected the cooldown to double to %v, got %v", 2*minWriteBackoff, d.writeBackoff.delay)
+	}
+	
+	// Once the file system recovers and the cooldown ends, writes resume
+	d.writeFile = os.WriteFile
+	d.writeBackoff.until = time.Now()
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed after recovery: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")); err != nil {
+		t.Errorf("Expected note to be published after recovery: %v", err)
+	}
+	if d.writesPaused() || d.writeBackoff.delay != 0 {
+		t.Errorf("Expected the write pause to be cleared, got cooldown %v", d.writeBackoff.delay)
+	}
+}
+
+func TestRenameScanRelocatesHugoFile(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.RenameScan = true
+	
+	oldPath := writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Initial sync failed: %v", err)
+	}
+	oldHugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "setup.md")
+	
+	// Move the note while the daemon isn't watching
+	newPath := filepath.Join(d.config.Vault, "tutorials", "Getting Started.md")
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+	
+	if moved := d.reconcileRenames([]string{newPath}); moved != 1 {
+		t.Fatalf("Expected 1 relocated note, got %d", moved)
+	}
+	
+	newHugoFile := filepath.Join(d.config.Repo, "content", "docs", "tutorials", "getting-started.md")
+	if _, err := os.Stat(newHugoFile); err != nil {
+		t.Fatalf("Expected Hugo file at the new path: %v", err)
+	}
+	if _, err := os.Stat(oldHugoFile); !os.IsNotExist(err) {
+		t.Error("Expected no Hugo file left at the old path")
+	}
+	if _, err := os.Stat(filepath.Dir(oldHugoFile)); !os.IsNotExist(err) {
+		t.Error("Expected the emptied section to be removed")
+	}
+	
+	stateNote := d.stateManager.GetNote("uid-1")
+	if stateNote.SourcePath != newPath || len(stateNote.PreviousHugoPaths) != 1 {
+		t.Errorf("Expected state to follow the move, got %+v", stateNote)
+	}
+	
+	// The sync that follows updates the moved file with the old URL as an alias
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Sync after move failed: %v", err)
+	}
+	data, err := os.ReadFile(newHugoFile)
+	if err != nil {
+		t.Fatalf("Failed to read moved Hugo file: %v", err)
+	}
+	if !strings.Contains(string(data), "/docs/guides/setup/") {
+		t.Errorf("Expected old URL as an alias, got:\n%s", data)
+	}
+}
+
+func TestTransformCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	d := newTestDaemon(t)
+	d.transform = transform.New(`tr a-z A-Z; printf '%s' "$OBSIDIAN_HUGO_SYNC_NOTE_PATH"`, 5*time.Second)
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nSome body\n")
+	
+	if _, err := d.processNote(notePath); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	hugoFile := filepath.Join(d.config.Repo, "content", "docs", "guides", "note.md")
+	data, err := os.ReadFile(hugoFile)
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	if !strings.Contains(string(data), "SOME BODY\nguides/Note.md") {
+		t.Errorf("Expected the transformed body, got %q", data)
+	}
+	if !strings.Contains(string(data), `noteUid: "uid-1"`) {
+		t.Errorf("Expected front-matter to be left alone, got %q", data)
+	}
+	
+	// A failing command fails the note and keeps its previous page
+	d.transform = transform.New("echo broken >&2; exit 3", 5*time.Second)
+	writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nNew body\n")
+	_, err = d.processNote(notePath)
+	var daemonErr *errors.DaemonError
+	if !stderrors.As(err, &daemonErr) || daemonErr.Type != errors.ErrorTypeProcess {
+		t.Fatalf("Expected a process error, got %v", err)
+	}
+	after, _ := os.ReadFile(hugoFile)
+	if string(after) != string(data) {
+		t.Errorf("Expected the previous page to be kept, got %q", after)
+	}
+}
+
+func TestMarkdownExtensions(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.MarkdownExtensions = []string{".md", ".markdown"}
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/Setup.markdown", "---\npublish: true\nnoteUid: uid-setup\n---\n\nSetup\n")
+	writeVaultNote(t, d, "guides/Intro.md", "---\npublish: true\nnoteUid: uid-intro\n---\n\nSee [[Setup.markdown]] and [setup](Setup.markdown)\n")
+	todo := writeVaultNote(t, d, "guides/Todo.txt", "---\npublish: true\nnoteUid: uid-todo\n---\n\nTodo\n")
+	
+	// Twice, so the second run's repair pass sees the first run's output
+	for i := 0; i < 2; i++ {
+		if err := d.performFullSync(); err != nil {
+			t.Fatalf("Full sync failed: %v", err)
+		}
+	}
+	
+	guides := filepath.Join(cfg.Repo, "content", "docs", "guides")
+	if _, err := os.Stat(filepath.Join(guides, "setup.md")); err != nil {
+		t.Errorf("Expected the .markdown note to be published as setup.md: %v", err)
+	}
+	intro, err := os.ReadFile(filepath.Join(guides, "intro.md"))
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	if strings.Count(string(intro), `relref "docs/guides/setup"`) != 2 {
+		t.Errorf("Expected both links to resolve to the setup page, got %q", intro)
+	}
+	
+	if err := d.handleFileEvent(watcher.Event{Path: todo, Operation: watcher.Write}); err != nil {
+		t.Fatalf("Failed to handle event: %v", err)
+	}
+	entries, _ := os.ReadDir(guides)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "todo") {
+			t.Errorf("Expected .txt files to be ignored, got %s", entry.Name())
+		}
+	}
+}
+
+func TestIndexNotes(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.IndexNotes = true
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	landing := writeVaultNote(t, d, "guides/Guides.md", "---\npublish: true\nnoteUid: uid-guides\n---\n\nStart with [[Setup]]\n")
+	writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-setup\n---\n\nBack to [[Guides]]\n")
+	writeVaultNote(t, d, "recipes/Soup.md", "---\npublish: true\nnoteUid: uid-soup\n---\n\nSoup\n")
+	
+	// Twice, so the second run's repair pass sees the first run's output
+	for i := 0; i < 2; i++ {
+		if err := d.performFullSync(); err != nil {
+			t.Fatalf("Full sync failed: %v", err)
+		}
+	}
+	
+	guides := filepath.Join(cfg.Repo, "content", "docs", "guides")
+	index, err := os.ReadFile(filepath.Join(guides, "_index.md"))
+	if err != nil {
+		t.Fatalf("Expected a section index: %v", err)
+	}
+	for _, want := range []string{`title: "Guides"`, `noteUid: "uid-guides"`, `Start with [Setup]({{< relref "docs/guides/setup" >}})`} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Expected the index note's content with %q, got:\n%s", want, index)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(guides, "guides.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected no separate page for the index note, got %v", err)
+	}
+	setup, err := os.ReadFile(filepath.Join(guides, "setup.md"))
+	if err != nil {
+		t.Fatalf("Failed to read Hugo file: %v", err)
+	}
+	if !strings.Contains(string(setup), `relref "docs/guides"`) {
+		t.Errorf("Expected links to the index note to point at the section, got:\n%s", setup)
+	}
+	
+	// A folder without an index note keeps the generated index
+	soup := filepath.Join(cfg.Repo, "content", "docs", "recipes", "_index.md")
+	if !isGeneratedIndex(soup) {
+		t.Errorf("Expected a generated section index without an index note")
+	}
+	
+	// Unpublishing the index note brings the generated index back
+	writeVaultNote(t, d, "guides/Guides.md", "---\npublish: false\nnoteUid: uid-guides\n---\n\nStart with [[Setup]]\n")
+	if _, err := d.processNote(landing); err != nil {
+		t.Fatalf("Failed to process unpublished note: %v", err)
+	}
+	if !isGeneratedIndex(filepath.Join(guides, "_index.md")) {
+		t.Errorf("Expected the generated section index once the index note is unpublished")
+	}
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dirLocks serializes changes to Hugo content directories, keyed by their
+// repo-relative path. Writing a note or section index and pruning an emptied
+// directory take the directory's lock, which keeps the invariant that a
+// directory containing any published note keeps its _index.md: a prune never
+// sees a directory between a note landing in it and its index being written.
+type dirLocks struct {
+	mu    sync.Mutex
+	locks map[string]*dirLock
+}
+
+// dirLock is a directory's mutex and the number of goroutines holding or
+// waiting for it, so unused entries can be dropped from the map
+type dirLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquires the lock for a single directory and returns its release func
+func (l *dirLocks) lock(dir string) func() {
+	dir = filepath.Clean(dir)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*dirLock)
+	}
+	entry, ok := l.locks[dir]
+	if !ok {
+		entry = &dirLock{}
+		l.locks[dir] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, dir)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// lockTree acquires the locks for a directory and all of its ancestors,
+// outermost first, so creating the directory can't race a parent's prune.
+// Pruning only ever holds one lock at a time, so the ordering can't deadlock.
+func (l *dirLocks) lockTree(dir string) func() {
+	dir = filepath.Clean(dir)
+
+	var dirs []string
+	for current := dir; current != "." && current != string(filepath.Separator); current = filepath.Dir(current) {
+		dirs = append(dirs, current)
+		if !strings.ContainsRune(current, filepath.Separator) {
+			break
+		}
+	}
+
+	unlocks := make([]func(), 0, len(dirs))
+	for i := len(dirs) - 1; i >= 0; i-- {
+		unlocks = append(unlocks, l.lock(dirs[i]))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
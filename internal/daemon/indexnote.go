@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// isSectionIndex reports whether a Hugo path is a section's _index.md
+func isSectionIndex(hugoPath string) bool {
+	return path.Base(filepath.ToSlash(hugoPath)) == "_index.md"
+}
+
+// restoreSectionIndex writes the generated _index.md back after the page of an
+// index note was deleted from a section that still holds other content
+func (d *Daemon) restoreSectionIndex(hugoPath string) {
+	if !isSectionIndex(hugoPath) || d.config.DryRun || d.config.NoDelete {
+		return
+	}
+	
+	dir := filepath.Dir(hugoPath)
+	unlock := d.dirLocks.lockTree(dir)
+	defer unlock()
+	
+	// Sections left empty were pruned along with the page
+	if _, err := os.Stat(filepath.Join(d.config.Repo, dir)); os.IsNotExist(err) {
+		return
+	}
+	if err := d.writeSectionIndex(dir); err != nil {
+		slog.Error("Error restoring section index", "path", hugoPath, "error", err)
+	}
+}
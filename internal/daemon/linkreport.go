@@ -0,0 +1,116 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// Reasons a link target didn't resolve
+const (
+	DeadLinkUnpublished = "unpublished" // Target note exists in the vault but isn't published
+	DeadLinkMissing     = "missing"     // No note in the vault matches the target
+)
+
+// DeadLink is a link in a published note that didn't resolve to a published note
+type DeadLink struct {
+	Source string `json:"source"` // Vault-relative path of the linking note
+	Target string `json:"target"` // Link target as written (folder-relative for markdown links)
+	Reason string `json:"reason"` // unpublished or missing
+}
+
+// LinkReport lists the dead links found while regenerating published content
+type LinkReport struct {
+	Generated time.Time  `json:"generated"`
+	DeadLinks []DeadLink `json:"dead_links"`
+}
+
+// reportDeadLinks categorizes dead links against the notes in the vault, logs
+// them and, when --link-report is set, writes them to that file as JSON
+func (d *Daemon) reportDeadLinks(deadLinks []DeadLink) error {
+	notePaths, err := vault.ScanVaultWithOptions(d.config.Vault, d.scanOptions)
+	if err != nil {
+		return fmt.Errorf("scanning vault: %w", err)
+	}
+
+	// Every name a link could use to point at an existing note
+	known := make(map[string]bool)
+	for _, notePath := range notePaths {
+		relPath, err := filepath.Rel(d.config.Vault, notePath)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+		withoutExt := strings.TrimSuffix(relPath, filepath.Ext(relPath))
+		filename := filepath.Base(withoutExt)
+
+		known[strings.ToLower(relPath)] = true
+		known[strings.ToLower(withoutExt)] = true
+		known[strings.ToLower(filename)] = true
+		if parent := filepath.Base(filepath.Dir(withoutExt)); parent != "." {
+			known[strings.ToLower(parent+"/"+filename)] = true
+		}
+	}
+
+	var unpublished, missing int
+	for i := range deadLinks {
+		if known[strings.ToLower(deadLinks[i].Target)] {
+			deadLinks[i].Reason = DeadLinkUnpublished
+			unpublished++
+		} else {
+			deadLinks[i].Reason = DeadLinkMissing
+			missing++
+		}
+		slog.Warn("Dead link",
+			"source", deadLinks[i].Source,
+			"target", deadLinks[i].Target,
+			"reason", deadLinks[i].Reason)
+	}
+
+	if len(deadLinks) > 0 {
+		slog.Info("Dead link report", "unpublished", unpublished, "missing", missing)
+	}
+	d.reportDeadLinksFound(deadLinks)
+
+	if d.config.LinkReport == "" {
+		return nil
+	}
+
+	sort.Slice(deadLinks, func(i, j int) bool {
+		if deadLinks[i].Source != deadLinks[j].Source {
+			return deadLinks[i].Source < deadLinks[j].Source
+		}
+		return deadLinks[i].Target < deadLinks[j].Target
+	})
+
+	report := LinkReport{
+		Generated: time.Now(),
+		DeadLinks: deadLinks,
+	}
+	if report.DeadLinks == nil {
+		report.DeadLinks = []DeadLink{}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding link report: %w", err)
+	}
+
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would write link report", "path", d.config.LinkReport, "dead_links", len(deadLinks))
+		return nil
+	}
+
+	if err := os.WriteFile(d.config.LinkReport, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing link report: %w", err)
+	}
+
+	return nil
+}
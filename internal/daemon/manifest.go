@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestImage is an image referenced by a note and where it was copied to
+type ManifestImage struct {
+	SourcePath string `json:"source_path"` // Vault-relative path of the image
+	HugoPath   string `json:"hugo_path"`   // Repo-relative path of the copied image
+}
+
+// ManifestEntry describes the Hugo output of a single note
+type ManifestEntry struct {
+	SourcePath  string          `json:"source_path"` // Vault-relative path of the note
+	HugoPath    string          `json:"hugo_path"`   // Repo-relative path of the generated file
+	Published   bool            `json:"published"`
+	ContentHash string          `json:"content_hash"` // SHA256 of the note source when last synced
+	Images      []ManifestImage `json:"images"`
+}
+
+// Manifest maps every tracked note UID to the files generated for it
+type Manifest struct {
+	Generated time.Time                `json:"generated"`
+	Notes     map[string]ManifestEntry `json:"notes"`
+}
+
+// buildManifest assembles the manifest from the sync state
+func (d *Daemon) buildManifest() Manifest {
+	// Invert the image references so each note lists its images
+	noteImages := make(map[string][]ManifestImage)
+	for imagePath, image := range d.stateManager.GetAllImages() {
+		entry := ManifestImage{
+			SourcePath: d.vaultRelative(imagePath),
+			HugoPath:   filepath.ToSlash(d.imageManager.HugoPath(imagePath)),
+		}
+		for _, uid := range image.Notes {
+			noteImages[uid] = append(noteImages[uid], entry)
+		}
+	}
+
+	manifest := Manifest{
+		Generated: time.Now(),
+		Notes:     make(map[string]ManifestEntry),
+	}
+	for uid, note := range d.stateManager.GetAllNotes() {
+		images := noteImages[uid]
+		sort.Slice(images, func(i, j int) bool { return images[i].SourcePath < images[j].SourcePath })
+		if images == nil {
+			images = []ManifestImage{}
+		}
+
+		manifest.Notes[uid] = ManifestEntry{
+			SourcePath:  d.vaultRelative(note.SourcePath),
+			HugoPath:    filepath.ToSlash(note.HugoPath),
+			Published:   note.Published,
+			ContentHash: note.ContentHash,
+			Images:      images,
+		}
+	}
+	return manifest
+}
+
+// writeManifest writes the manifest to the --manifest path as JSON
+func (d *Daemon) writeManifest() error {
+	manifest := d.buildManifest()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would write manifest", "path", d.config.Manifest, "notes", len(manifest.Notes))
+		return nil
+	}
+
+	if err := os.WriteFile(d.config.Manifest, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	slog.Debug("Wrote manifest", "path", d.config.Manifest, "notes", len(manifest.Notes))
+	return nil
+}
+
+// vaultRelative returns a path relative to the vault with forward slashes,
+// leaving paths outside the vault as they are
+func (d *Daemon) vaultRelative(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(d.config.Vault, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
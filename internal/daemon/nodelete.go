@@ -0,0 +1,17 @@
+package daemon
+
+import (
+	"log/slog"
+	"path/filepath"
+)
+
+// keepDeletion stands in for deleting a Hugo file or image with --no-delete:
+// the file stays in place and is logged and listed in the sync report for
+// manual review. hugoPath is relative to the Hugo repository.
+func (d *Daemon) keepDeletion(hugoPath string) {
+	hugoPath = filepath.ToSlash(hugoPath)
+	slog.Info("NO DELETE: Keeping file that would be deleted", "path", hugoPath)
+	if report := d.activeReport(); report != nil {
+		report.wouldDelete[hugoPath] = true
+	}
+}
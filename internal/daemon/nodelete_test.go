@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNoDeleteKeepsFiles(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.NoDelete = true
+	d.config.Report = filepath.Join(t.TempDir(), "report.json")
+	
+	hidden := writeVaultNote(t, d, "guides/Hidden.md", "---\npublish: true\nnoteUid: uid-hidden\n---\n\nHidden\n")
+	removed := writeVaultNote(t, d, "guides/Removed.md", "---\npublish: true\nnoteUid: uid-removed\n---\n\nRemoved\n")
+	kept := writeVaultNote(t, d, "guides/Kept.md", "---\npublish: true\nnoteUid: uid-kept\n---\n\nKept\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	var removeCalls []string
+	d.removeFile = func(name string) error {
+		removeCalls = append(removeCalls, name)
+		return os.Remove(name)
+	}
+	
+	// Unpublishing, deleting and orphaning would each delete a page
+	writeVaultNote(t, d, "guides/Hidden.md", "---\npublish: false\nnoteUid: uid-hidden\n---\n\nHidden\n")
+	if _, err := d.processNote(hidden); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.handleNoteRemoval(removed); err != nil {
+		t.Fatalf("Failed to handle removal: %v", err)
+	}
+	writeRepoFile(t, d, "content/docs/old/gone.md", "---\ntitle: Gone\nnoteUid: uid-gone\n---\n\nGone\n")
+	
+	// Writes still happen
+	writeVaultNote(t, d, "guides/Kept.md", "---\npublish: true\nnoteUid: uid-kept\n---\n\nEdited\n")
+	if _, err := d.processNote(kept); err != nil {
+		t.Fatalf("Failed to process note: %v", err)
+	}
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	data, err := os.ReadFile(filepath.Join(d.config.Repo, "content", "docs", "guides", "kept.md"))
+	if err != nil || !strings.Contains(string(data), "Edited") {
+		t.Errorf("Expected the edited note to be written, got %q (%v)", data, err)
+	}
+	if len(removeCalls) != 0 {
+		t.Errorf("Expected no files to be removed, got %v", removeCalls)
+	}
+	
+	wouldDelete := []string{
+		"content/docs/guides/hidden.md",
+		"content/docs/guides/removed.md",
+		"content/docs/old/gone.md",
+	}
+	for _, path := range wouldDelete {
+		if _, err := os.Stat(filepath.Join(d.config.Repo, path)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", path, err)
+		}
+	}
+	
+	// The last report lists what the full sync would have deleted
+	data, err = os.ReadFile(d.config.Report)
+	if err != nil {
+		t.Fatalf("Expected report to be written: %v", err)
+	}
+	var report SyncReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(report.Deleted) != 0 {
+		t.Errorf("Expected no deletions in the report, got %v", report.Deleted)
+	}
+	if strings.Join(report.WouldDelete, ",") != strings.Join(wouldDelete, ",") {
+		t.Errorf("Expected would_delete %v, got %v", wouldDelete, report.WouldDelete)
+	}
+}
@@ -0,0 +1,26 @@
+package daemon
+
+import (
+	"log/slog"
+	"path/filepath"
+)
+
+// isPreserved reports whether a file or directory in the Hugo repository
+// matches a preserve pattern. Patterns are relative to each content directory
+// and protect matches from every repair and cleanup pass.
+func (d *Daemon) isPreserved(fullPath string, isDir bool) bool {
+	if d.preserve == nil {
+		return false
+	}
+	for _, contentDir := range d.hugoGen.ContentDirs() {
+		relPath, err := filepath.Rel(filepath.Join(d.config.Repo, contentDir), fullPath)
+		if err != nil {
+			continue
+		}
+		if d.preserve.Match(relPath, isDir) {
+			slog.Debug("Keeping preserved Hugo file", "path", fullPath)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestRepairKeepsPreservedFiles(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.PreservePatterns = []string{"guides/_index.md", "manual/"}
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-setup\n---\n\nSetup\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	
+	// Pages whose notes are gone from the vault, one of them hand-maintained since
+	protected := writeRepoFile(t, d, "content/docs/manual/page.md", "---\ntitle: Page\nnoteUid: uid-copied\n---\n\nEdited in Hugo\n")
+	orphan := writeRepoFile(t, d, "content/docs/old/gone.md", "---\ntitle: Gone\nnoteUid: uid-gone\n---\n\nGone\n")
+	
+	// With no note published, the sweep removes setup.md and would prune its section
+	if err := d.repairOrphanedHugoFiles(map[string]*vault.Note{}); err != nil {
+		t.Fatalf("Failed to repair Hugo content: %v", err)
+	}
+	
+	for _, path := range []string{orphan, filepath.Join(cfg.Repo, "content", "docs", "guides", "setup.md")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+	for _, path := range []string{protected, filepath.Join(cfg.Repo, "content", "docs", "guides", "_index.md")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected preserved %s to survive repair: %v", path, err)
+		}
+	}
+}
+
+func TestPurgeKeepsPreservedFiles(t *testing.T) {
+	cfg := *newTestDaemon(t).config
+	cfg.PreservePatterns = []string{"guides/setup.md"}
+	d, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	t.Cleanup(d.watcher.Stop)
+	
+	writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-setup\n---\n\nSetup\n")
+	writeVaultNote(t, d, "guides/Other.md", "---\npublish: true\nnoteUid: uid-other\n---\n\nOther\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	if err := d.Purge(); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "content", "docs", "guides", "setup.md")); err != nil {
+		t.Errorf("Expected preserved page to survive purge: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Repo, "content", "docs", "guides", "other.md")); !os.IsNotExist(err) {
+		t.Error("Expected unprotected page to be purged")
+	}
+}
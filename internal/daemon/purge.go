@@ -0,0 +1,217 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"obsidian-hugo-sync/internal/hugo"
+)
+
+// generatedIndexKey matches the lastUpdated key every generated _index.md carries
+var generatedIndexKey = regexp.MustCompile(`(?m)^\s*"?lastUpdated"?\s*[:=]`)
+
+// purgeStats counts what a purge removed
+type purgeStats struct {
+	notes   int
+	indexes int
+	images  int
+}
+
+// Purge removes every Hugo file this tool generated for the vault: notes
+// carrying a noteUid, images tracked in the state and the generated _index.md
+// of sections left empty. Hand-authored content is never touched. The state
+// is cleared afterwards so the next sync starts fresh.
+func (d *Daemon) Purge() error {
+	if _, err := os.Stat(filepath.Join(d.config.Repo, d.config.ContentDir)); err != nil {
+		return fmt.Errorf("reading content directory: %w", err)
+	}
+	
+	// Tracked images, by their full path in the Hugo repository
+	trackedImages := make(map[string]bool)
+	for vaultPath := range d.stateManager.GetAllImages() {
+		trackedImages[filepath.Join(d.config.Repo, d.imageManager.HugoPath(vaultPath))] = true
+	}
+	
+	var stats purgeStats
+	for _, contentDir := range d.hugoGen.ContentDirs() {
+		contentPath := filepath.Join(d.config.Repo, contentDir)
+		if _, err := os.Stat(contentPath); os.IsNotExist(err) {
+			continue // Routed sections are only created once they hold content
+		}
+		if _, err := d.purgeDir(contentPath, contentPath, trackedImages, &stats); err != nil {
+			return fmt.Errorf("purging content directory %s: %w", contentDir, err)
+		}
+	}
+	
+	// Images copied to an image output directory lie outside the content directories
+	for imagePath := range trackedImages {
+		if _, err := os.Stat(imagePath); err == nil {
+			d.purgeFile(imagePath, "image")
+			stats.images++
+		}
+	}
+	
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would purge synced files",
+			"notes", stats.notes,
+			"indexes", stats.indexes,
+			"images", stats.images)
+		return nil
+	}
+	
+	d.stateManager.Reset()
+	if err := d.stateManager.Save(); err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	
+	slog.Info("Purged synced files",
+		"notes", stats.notes,
+		"indexes", stats.indexes,
+		"images", stats.images)
+	return nil
+}
+
+// purgeDir removes managed files below dir and reports whether dir is left
+// empty. Dry runs only log, but still report emptiness as if files were gone
+// so generated indexes of fully managed sections are listed too.
+func (d *Daemon) purgeDir(dir, contentPath string, trackedImages map[string]bool, stats *purgeStats) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	
+	var indexPath string
+	remaining := 0
+	for _, entry := range entries {
+		fullPath := filepath.Join(dir, entry.Name())
+		
+		switch {
+		case d.isPreserved(fullPath, entry.IsDir()):
+			remaining++
+		case entry.IsDir():
+			empty, err := d.purgeDir(fullPath, contentPath, trackedImages, stats)
+			if err != nil {
+				return false, err
+			}
+			if !empty {
+				remaining++
+			}
+		case entry.Name() == "_index.md" && !d.isManagedNote(fullPath):
+			indexPath = fullPath
+		case trackedImages[fullPath]:
+			d.purgeFile(fullPath, "image")
+			delete(trackedImages, fullPath)
+			stats.images++
+		case strings.HasSuffix(entry.Name(), ".md") && d.isManagedNote(fullPath):
+			d.purgeFile(fullPath, "note")
+			stats.notes++
+		default:
+			remaining++ // Hand-authored content stays
+		}
+	}
+	
+	// A section index only goes once nothing else in the section is left
+	if remaining == 0 && indexPath != "" {
+		if !d.isGeneratedSectionIndex(indexPath) {
+			return false, nil
+		}
+		d.purgeFile(indexPath, "section index")
+		stats.indexes++
+	}
+	if remaining > 0 {
+		return false, nil
+	}
+	
+	if dir != contentPath && !d.config.DryRun {
+		if err := os.Remove(dir); err != nil {
+			slog.Warn("Failed to remove empty directory", "path", dir, "error", err)
+		}
+	}
+	return true, nil
+}
+
+// isManagedNote reports whether a Hugo file was generated from a vault note
+func (d *Daemon) isManagedNote(path string) bool {
+	uid, err := d.extractNoteUidFromHugoFile(path)
+	return err == nil && uid != ""
+}
+
+// purgeFile deletes a single generated file
+func (d *Daemon) purgeFile(path, kind string) {
+	relPath, _ := filepath.Rel(d.config.Repo, path)
+	
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would delete "+kind, "path", relPath)
+		return
+	}
+	
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		slog.Error("Error deleting "+kind, "path", relPath, "error", err)
+		return
+	}
+	slog.Debug("Deleted "+kind, "path", relPath)
+}
+
+// isGeneratedSectionIndex reports whether an _index.md is one this tool wrote.
+// An index rendered from --index-template has a body, so it is recognized by
+// matching what the template renders for its section instead.
+func (d *Daemon) isGeneratedSectionIndex(indexPath string) bool {
+	if isGeneratedIndex(indexPath) {
+		return true
+	}
+	if d.config.IndexTemplate == "" {
+		return false
+	}
+
+	existing, err := os.ReadFile(indexPath)
+	if err != nil {
+		return false
+	}
+	relDir, err := filepath.Rel(d.config.Repo, filepath.Dir(indexPath))
+	if err != nil {
+		return false
+	}
+	generated := d.hugoGen.GenerateIndexFile(relDir, hugo.CalculateFolderWeight(relDir))
+	return !hugo.ContentChanged(string(existing), generated.Serialize())
+}
+
+// isGeneratedIndex reports whether an _index.md looks like one this tool wrote:
+// front-matter with a lastUpdated key and no body
+func isGeneratedIndex(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	content := string(data)
+	
+	var frontMatter, body string
+	switch {
+	case strings.HasPrefix(content, "{"):
+		decoder := json.NewDecoder(strings.NewReader(content))
+		var fields map[string]interface{}
+		if err := decoder.Decode(&fields); err != nil {
+			return false
+		}
+		if _, ok := fields["lastUpdated"]; !ok {
+			return false
+		}
+		return strings.TrimSpace(content[decoder.InputOffset():]) == ""
+	case strings.HasPrefix(content, "---\n"), strings.HasPrefix(content, "+++\n"):
+		delimiter := content[:3]
+		end := strings.Index(content[4:], "\n"+delimiter)
+		if end < 0 {
+			return false
+		}
+		frontMatter = content[4 : 4+end]
+		body = content[4+end+len(delimiter)+1:]
+	default:
+		return false
+	}
+	
+	return generatedIndexKey.MatchString(frontMatter) && strings.TrimSpace(body) == ""
+}
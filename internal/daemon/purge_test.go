@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRepoFile writes a file into the test Hugo repository and returns its path
+func writeRepoFile(t *testing.T, d *Daemon, relPath, content string) string {
+	t.Helper()
+	
+	path := filepath.Join(d.config.Repo, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestPurgeRemovesOnlyManagedFiles(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-setup\n---\n\n![](diagram.png)\n")
+	writeVaultNote(t, d, "api/Endpoints.md", "---\npublish: true\nnoteUid: uid-api\n---\n\nEndpoints\n")
+	writeVaultNote(t, d, "guides/diagram.png", "png")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	
+	// Hand-authored content next to synced notes
+	handWritten := writeRepoFile(t, d, "content/docs/api/overview.md", "---\ntitle: Overview\n---\n\nWritten in Hugo\n")
+	handIndex := writeRepoFile(t, d, "content/docs/about/_index.md", "---\ntitle: About\n---\n")
+	
+	managed := []string{
+		"content/docs/guides/setup.md",
+		"content/docs/guides/diagram.png",
+		"content/docs/guides/_index.md",
+		"content/docs/api/endpoints.md",
+	}
+	for _, relPath := range managed {
+		if _, err := os.Stat(filepath.Join(d.config.Repo, relPath)); err != nil {
+			t.Fatalf("Expected %s to exist after sync: %v", relPath, err)
+		}
+	}
+	
+	if err := d.Purge(); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	
+	for _, relPath := range managed {
+		if _, err := os.Stat(filepath.Join(d.config.Repo, relPath)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be purged", relPath)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(d.config.Repo, "content", "docs", "guides")); !os.IsNotExist(err) {
+		t.Error("Expected emptied section directory to be removed")
+	}
+	
+	// The api section keeps its index because hand-written content remains
+	for _, path := range []string{handWritten, handIndex, filepath.Join(d.config.Repo, "content", "docs", "api", "_index.md")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept: %v", path, err)
+		}
+	}
+	
+	if len(d.stateManager.GetAllNotes()) != 0 || len(d.stateManager.GetAllImages()) != 0 {
+		t.Error("Expected state to be cleared after purge")
+	}
+}
+
+func TestPurgeDryRunKeepsFiles(t *testing.T) {
+	d := newTestDaemon(t)
+	
+	writeVaultNote(t, d, "guides/Setup.md", "---\npublish: true\nnoteUid: uid-setup\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Failed to run full sync: %v", err)
+	}
+	
+	d.config.DryRun = true
+	if err := d.Purge(); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	
+	for _, relPath := range []string{"content/docs/guides/setup.md", "content/docs/guides/_index.md"} {
+		if _, err := os.Stat(filepath.Join(d.config.Repo, relPath)); err != nil {
+			t.Errorf("Expected %s to survive a dry-run purge: %v", relPath, err)
+		}
+	}
+	if d.stateManager.GetNote("uid-setup") == nil {
+		t.Error("Expected state to survive a dry-run purge")
+	}
+}
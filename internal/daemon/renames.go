@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// reconcileRenames finds notes moved while the daemon wasn't watching: a
+// vault note whose noteUid the state knows under a path that no longer
+// exists. Its Hugo file is moved to the new location up front, so the sync
+// that follows updates it in place instead of writing a second copy and
+// leaving the old one to the orphan repair. It returns the number moved.
+func (d *Daemon) reconcileRenames(notePaths []string) int {
+	knownPaths := make(map[string]bool)
+	for _, stateNote := range d.stateManager.GetAllNotes() {
+		knownPaths[stateNote.SourcePath] = true
+	}
+
+	moved := 0
+	for _, notePath := range notePaths {
+		if knownPaths[notePath] || vault.IsFolderNote(notePath) {
+			continue
+		}
+
+		note, err := vault.ParseNoteWithOptions(notePath, d.parseOptions)
+		if err != nil || note.UID == "" {
+			continue // Left for the sync to parse and report
+		}
+
+		stateNote := d.stateManager.GetNote(note.UID)
+		if stateNote == nil || !stateNote.Published || !note.Published {
+			continue
+		}
+		if _, err := os.Stat(stateNote.SourcePath); err == nil {
+			continue // Still at the old path, so this is a copy rather than a move
+		}
+
+		oldHugoPath := stateNote.HugoPath
+		newHugoPath := d.calculateHugoPath(note)
+		if oldHugoPath == newHugoPath {
+			continue
+		}
+		if !d.relocateHugoFile(oldHugoPath, newHugoPath) {
+			continue
+		}
+
+		// Record the move so the sync sees the note at its new path, with the
+		// old URL kept as an alias
+		relocated := *stateNote
+		relocated.SourcePath = notePath
+		relocated.HugoPath = newHugoPath
+		relocated.PreviousHugoPaths = slices.DeleteFunc(append(slices.Clone(stateNote.PreviousHugoPaths), oldHugoPath), func(path string) bool {
+			return path == newHugoPath
+		})
+		relocated.PreviousHugoPaths = slices.Compact(relocated.PreviousHugoPaths)
+		d.stateManager.SetNote(note.UID, &relocated)
+
+		slog.Info("Relocated renamed note", "old_path", stateNote.SourcePath, "new_path", notePath, "hugo_path", newHugoPath)
+		moved++
+	}
+	return moved
+}
+
+// relocateHugoFile moves a published note's Hugo file to its new path,
+// reporting whether it was moved. An existing file at the new path is never
+// replaced.
+func (d *Daemon) relocateHugoFile(oldHugoPath, newHugoPath string) bool {
+	oldFullPath := filepath.Join(d.config.Repo, oldHugoPath)
+	newFullPath := filepath.Join(d.config.Repo, newHugoPath)
+
+	if _, err := os.Stat(oldFullPath); err != nil {
+		return false
+	}
+	if _, err := os.Stat(newFullPath); err == nil {
+		return false
+	}
+
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would move Hugo file of renamed note", "old_path", oldHugoPath, "new_path", newHugoPath)
+		return false
+	}
+
+	unlock := d.dirLocks.lockTree(filepath.Dir(newHugoPath))
+	err := os.MkdirAll(filepath.Dir(newFullPath), 0755)
+	if err == nil {
+		err = os.Rename(oldFullPath, newFullPath)
+	}
+	unlock()
+	if err != nil {
+		slog.Error("Error moving Hugo file of renamed note", "old_path", oldHugoPath, "new_path", newHugoPath, "error", d.checkWrite("moving hugo file", err))
+		return false
+	}
+
+	d.reportChange(oldHugoPath, changeDeleted)
+	d.reportChange(newHugoPath, changeCreated)
+	d.removeEmptyDirs(filepath.Dir(oldFullPath))
+	d.restoreSectionIndex(oldHugoPath)
+	if err := d.ensureSectionIndex(newHugoPath); err != nil {
+		slog.Error("Error ensuring section index", "path", newHugoPath, "error", err)
+	}
+	return true
+}
@@ -0,0 +1,223 @@
+package daemon
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"obsidian-hugo-sync/internal/errors"
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// Kinds of sync run a report covers
+const (
+	ReportFull        = "full"
+	ReportIncremental = "incremental"
+)
+
+// Changes to a Hugo file recorded in the sync report
+const (
+	changeCreated = "created"
+	changeUpdated = "updated"
+	changeDeleted = "deleted"
+)
+
+// ReportError is a note that failed to sync
+type ReportError struct {
+	Path  string `json:"path"` // Vault-relative path of the note
+	Type  string `json:"type"` // Error category, e.g. Vault or FileSystem
+	Error string `json:"error"`
+}
+
+// SyncReport summarizes one sync run for --report. An incremental report also
+// covers the file events handled since the previous report.
+type SyncReport struct {
+	Sync        string        `json:"sync"` // full or incremental
+	Started     time.Time     `json:"started"`
+	Duration    string        `json:"duration"`
+	Processed   int           `json:"processed"`
+	Published   int           `json:"published"`
+	Failed      int           `json:"failed"`
+	Errors      []ReportError `json:"errors"`
+	Created     []string      `json:"created"` // Repo-relative Hugo paths
+	Updated     []string      `json:"updated"`
+	Deleted     []string      `json:"deleted"`
+	WouldDelete []string      `json:"would_delete"` // Files --no-delete kept instead of deleting
+	DeadLinks   []DeadLink    `json:"dead_links"`
+}
+
+// syncReport collects the report of the sync in progress
+type syncReport struct {
+	SyncReport
+	changes     map[string]string // Hugo path -> net change since the report started
+	wouldDelete map[string]bool   // Hugo paths kept by --no-delete
+}
+
+// activeReport returns the report collecting the current sync, starting one
+// when needed, or nil when --report is off
+func (d *Daemon) activeReport() *syncReport {
+	if d.config.Report == "" {
+		return nil
+	}
+	if d.report == nil {
+		d.report = &syncReport{
+			SyncReport:  SyncReport{Started: time.Now()},
+			changes:     make(map[string]string),
+			wouldDelete: make(map[string]bool),
+		}
+	}
+	return d.report
+}
+
+// reportNote records the outcome of processing a note
+func (d *Daemon) reportNote(notePath string, note *vault.Note, err error) {
+	report := d.activeReport()
+	if report == nil {
+		return
+	}
+
+	if err != nil {
+		errType := errors.ErrorTypeUnknown
+		var daemonErr *errors.DaemonError
+		if stderrors.As(err, &daemonErr) {
+			errType = daemonErr.Type
+		}
+		report.Failed++
+		report.Errors = append(report.Errors, ReportError{
+			Path:  d.vaultRelative(notePath),
+			Type:  errType.String(),
+			Error: err.Error(),
+		})
+		return
+	}
+
+	report.Processed++
+	if note != nil && note.Published {
+		report.Published++
+	}
+}
+
+// reportChange records a Hugo file being created, updated or deleted. Changes
+// to the same file within one report are merged into their net effect.
+func (d *Daemon) reportChange(hugoPath, change string) {
+	report := d.activeReport()
+	if report == nil {
+		return
+	}
+
+	hugoPath = filepath.ToSlash(hugoPath)
+	previous := report.changes[hugoPath]
+	switch {
+	case previous == changeCreated && change == changeUpdated:
+		// Still new to whoever reads the report
+	case previous == changeCreated && change == changeDeleted:
+		delete(report.changes, hugoPath)
+	case previous == changeDeleted && change == changeCreated:
+		report.changes[hugoPath] = changeUpdated
+	default:
+		report.changes[hugoPath] = change
+	}
+}
+
+// reportWritten records a Hugo file that was written, as updated when it
+// existed before and created otherwise
+func (d *Daemon) reportWritten(hugoPath string, existed bool) {
+	if existed {
+		d.reportChange(hugoPath, changeUpdated)
+	} else {
+		d.reportChange(hugoPath, changeCreated)
+	}
+}
+
+// reportDeadLinksFound records the dead links of the latest regeneration
+func (d *Daemon) reportDeadLinksFound(deadLinks []DeadLink) {
+	if report := d.activeReport(); report != nil {
+		report.DeadLinks = deadLinks
+	}
+}
+
+// finishReport writes the collected report to the --report path and starts
+// afresh. Incremental runs that changed nothing write no report.
+func (d *Daemon) finishReport(sync string) error {
+	if d.config.Report == "" {
+		return nil
+	}
+	if sync == ReportFull {
+		d.activeReport()
+	}
+	report := d.report
+	if report == nil {
+		return nil
+	}
+	d.report = nil
+
+	report.Sync = sync
+	report.Duration = time.Since(report.Started).String()
+	report.Created, report.Updated, report.Deleted = []string{}, []string{}, []string{}
+	for hugoPath, change := range report.changes {
+		switch change {
+		case changeCreated:
+			report.Created = append(report.Created, hugoPath)
+		case changeUpdated:
+			report.Updated = append(report.Updated, hugoPath)
+		case changeDeleted:
+			report.Deleted = append(report.Deleted, hugoPath)
+		}
+	}
+	sort.Strings(report.Created)
+	sort.Strings(report.Updated)
+	sort.Strings(report.Deleted)
+	report.WouldDelete = []string{}
+	for hugoPath := range report.wouldDelete {
+		report.WouldDelete = append(report.WouldDelete, hugoPath)
+	}
+	sort.Strings(report.WouldDelete)
+	if report.Errors == nil {
+		report.Errors = []ReportError{}
+	}
+	if report.DeadLinks == nil {
+		report.DeadLinks = []DeadLink{}
+	}
+	sort.Slice(report.DeadLinks, func(i, j int) bool {
+		if report.DeadLinks[i].Source != report.DeadLinks[j].Source {
+			return report.DeadLinks[i].Source < report.DeadLinks[j].Source
+		}
+		return report.DeadLinks[i].Target < report.DeadLinks[j].Target
+	})
+
+	if d.config.DryRun {
+		slog.Info("DRY RUN: Would write sync report", "path", d.config.Report, "sync", sync)
+		return nil
+	}
+
+	// Appended reports are JSON Lines, one run per line
+	if d.config.ReportAppend {
+		data, err := json.Marshal(report.SyncReport)
+		if err != nil {
+			return fmt.Errorf("encoding sync report: %w", err)
+		}
+		file, err := os.OpenFile(d.config.Report, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("opening sync report: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("writing sync report: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(report.SyncReport, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding sync report: %w", err)
+	}
+	if err := os.WriteFile(d.config.Report, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing sync report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncReportReflectsFullSync(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.Report = filepath.Join(t.TempDir(), "report.json")
+	
+	writeVaultNote(t, d, "guides/Fine.md", "---\npublish: true\nnoteUid: uid-fine\n---\n\nSee [[Nowhere]]\n")
+	writeVaultNote(t, d, "guides/Broken.md", "---\ntitle: Broken\n\tpublish: true\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	data, err := os.ReadFile(d.config.Report)
+	if err != nil {
+		t.Fatalf("Expected report to be written: %v", err)
+	}
+	var report SyncReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	
+	if report.Sync != ReportFull || report.Processed != 1 || report.Published != 1 || report.Failed != 1 {
+		t.Errorf("Expected 1 processed, 1 published and 1 failed full sync, got %+v", report)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Path != "guides/Broken.md" || report.Errors[0].Type != "Vault" {
+		t.Errorf("Expected the broken note's error, got %+v", report.Errors)
+	}
+	expectedCreated := []string{"content/docs/guides/_index.md", "content/docs/guides/fine.md"}
+	if strings.Join(report.Created, ",") != strings.Join(expectedCreated, ",") {
+		t.Errorf("Expected created %v, got %v", expectedCreated, report.Created)
+	}
+	if len(report.Updated) != 0 || len(report.Deleted) != 0 {
+		t.Errorf("Expected no updates or deletions, got %v and %v", report.Updated, report.Deleted)
+	}
+	if len(report.DeadLinks) != 1 || report.DeadLinks[0].Target != "Nowhere" || report.DeadLinks[0].Reason != DeadLinkMissing {
+		t.Errorf("Expected the missing link, got %+v", report.DeadLinks)
+	}
+}
+
+func TestSyncReportAppendsIncrementalRuns(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.Report = filepath.Join(t.TempDir(), "report.jsonl")
+	d.config.ReportAppend = true
+	
+	notePath := writeVaultNote(t, d, "guides/Fine.md", "---\npublish: true\nnoteUid: uid-fine\n---\n\nBody\n")
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	// A run that changed nothing adds no line
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	
+	if err := os.Remove(notePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.performIncrementalSync(); err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	
+	data, err := os.ReadFile(d.config.Report)
+	if err != nil {
+		t.Fatalf("Expected report to be written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 report lines, got %d:\n%s", len(lines), data)
+	}
+	
+	var report SyncReport
+	if err := json.Unmarshal([]byte(lines[1]), &report); err != nil {
+		t.Fatalf("Failed to decode report line: %v", err)
+	}
+	expectedDeleted := []string{"content/docs/guides/_index.md", "content/docs/guides/fine.md"}
+	if report.Sync != ReportIncremental || strings.Join(report.Deleted, ",") != strings.Join(expectedDeleted, ",") {
+		t.Errorf("Expected incremental report deleting %v, got %+v", expectedDeleted, report)
+	}
+}
@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"log/slog"
+	"time"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// trackSchedule remembers when a note's publishDate or expiryDate next
+// passes, so the periodic rescan re-evaluates it even if the file is unchanged
+func (d *Daemon) trackSchedule(note *vault.Note) {
+	if !d.config.EnforceSchedule {
+		return
+	}
+
+	next := note.NextScheduleChange(time.Now())
+	if next.IsZero() {
+		delete(d.schedule, note.Path)
+		return
+	}
+	d.schedule[note.Path] = next
+}
+
+// scheduleDue reports whether a note's schedule changed since it was last processed
+func (d *Daemon) scheduleDue(notePath string, now time.Time) bool {
+	next, ok := d.schedule[notePath]
+	if !ok || now.Before(next) {
+		return false
+	}
+	slog.Debug("Note schedule reached, re-evaluating", "path", notePath, "at", next)
+	return true
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"obsidian-hugo-sync/internal/vault"
+	"obsidian-hugo-sync/internal/watcher"
+)
+
+// settlePollInterval is the gap between the size checks of a changed note
+const settlePollInterval = 25 * time.Millisecond
+
+// settleFileEvent defers a note's create or write event until the file has
+// stopped growing, waiting on its own goroutine so the event loop keeps
+// serving other events. The event comes back through d.settled. It reports
+// false for events to handle right away.
+func (d *Daemon) settleFileEvent(ctx context.Context, event watcher.Event) bool {
+	if d.config.SettleDelay <= 0 || !d.scanOptions.IsNoteFile(event.Path) || vault.IsFolderNote(event.Path) {
+		return false
+	}
+	if event.Operation != watcher.Create && event.Operation != watcher.Write {
+		return false
+	}
+
+	// A note already settling is read once it is stable, picking up this change too
+	if d.settling[event.Path] {
+		return true
+	}
+	d.settling[event.Path] = true
+
+	go func() {
+		if !waitForStableFile(ctx, event.Path, d.config.SettleDelay) {
+			slog.Debug("Note still changing after settle delay, reading it anyway", "path", event.Path)
+		}
+		select {
+		case d.settled <- event:
+		case <-ctx.Done():
+		}
+	}()
+	return true
+}
+
+// waitForStableFile polls a file's size until two consecutive reads agree,
+// giving up once maxWait has passed. It reports whether the size settled; a
+// file that can't be read counts as settled, leaving the error to the reader.
+func waitForStableFile(ctx context.Context, path string, maxWait time.Duration) bool {
+	deadline := time.Now().Add(maxWait)
+	lastSize := int64(-1)
+
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			return true
+		}
+		if info.Size() == lastSize {
+			return true
+		}
+		lastSize = info.Size()
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		select {
+		case <-time.After(min(settlePollInterval, remaining)):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"obsidian-hugo-sync/internal/watcher"
+)
+
+func TestWaitForStableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	if err := os.WriteFile(path, []byte("---\npublish: true\n---\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	
+	start := time.Now()
+	if !waitForStableFile(context.Background(), path, time.Second) {
+		t.Error("Expected an unchanging file to settle")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected a stable file to settle quickly, took %v", elapsed)
+	}
+}
+
+func TestWaitForStableFileStillGrowing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	defer file.Close()
+	
+	// Keep appending faster than the size is polled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			file.WriteString("more text\n")
+			time.Sleep(settlePollInterval / 5)
+		}
+	}()
+	
+	start := time.Now()
+	if waitForStableFile(context.Background(), path, 200*time.Millisecond) {
+		t.Error("Expected a growing file not to settle")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected to wait out the settle delay, returned after %v", elapsed)
+	}
+}
+
+func TestNoteEventWaitsToSettle(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.SettleDelay = time.Second
+	notePath := writeVaultNote(t, d, "guides/Note.md", "---\npublish: true\nnoteUid: uid-1\n---\n\nBody\n")
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	event := watcher.Event{Path: notePath, Operation: watcher.Write}
+	if !d.settleFileEvent(ctx, event) {
+		t.Fatal("Expected a note write to wait for the file to settle")
+	}
+	if !d.settleFileEvent(ctx, event) {
+		t.Error("Expected a repeated write to join the pending wait")
+	}
+	if d.settleFileEvent(ctx, watcher.Event{Path: notePath, Operation: watcher.Remove}) {
+		t.Error("Expected removals to be handled right away")
+	}
+	
+	select {
+	case settled := <-d.settled:
+		if settled.Path != notePath {
+			t.Errorf("Expected settled event for %s, got %s", notePath, settled.Path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the note to settle")
+	}
+}
@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"log/slog"
+	"os"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// modifiedBeforeSince reports whether a note was last modified before the
+// --since cutoff, so a full sync can leave it alone
+func (d *Daemon) modifiedBeforeSince(notePath string) bool {
+	if d.config.Since.IsZero() {
+		return false
+	}
+	info, err := os.Stat(notePath)
+	return err == nil && info.ModTime().Before(d.config.Since)
+}
+
+// skippedPublishedNote parses a note skipped by --since without converting it,
+// returning it when it is published so links to it still resolve and its
+// page isn't removed as an orphan. It returns nil for any other note.
+func (d *Daemon) skippedPublishedNote(notePath string) *vault.Note {
+	note, err := d.parseNote(notePath)
+	if err != nil {
+		slog.Warn("Error parsing skipped note", "path", notePath, "error", err)
+		return nil
+	}
+	if !note.Published || note.UID == "" {
+		return nil
+	}
+	return note
+}
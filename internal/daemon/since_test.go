@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSinceSkipsOlderNotes(t *testing.T) {
+	d := newTestDaemon(t)
+	d.config.Once = true
+	oldPath := writeVaultNote(t, d, "guides/Old.md", "---\npublish: true\nnoteUid: uid-old\n---\n\nOld body\n")
+	writeVaultNote(t, d, "guides/New.md", "---\npublish: true\nnoteUid: uid-new\n---\n\nNew body\n")
+	
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+	d.config.Since = time.Now().Add(-24 * time.Hour)
+	
+	oldHugo := filepath.Join(d.config.Repo, "content", "docs", "guides", "old.md")
+	newHugo := filepath.Join(d.config.Repo, "content", "docs", "guides", "new.md")
+	
+	// Only the note modified after the cutoff is processed
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	if _, err := os.Stat(newHugo); err != nil {
+		t.Errorf("Expected the new note to be published, got %v", err)
+	}
+	if _, err := os.Stat(oldHugo); !os.IsNotExist(err) {
+		t.Errorf("Expected the old note to be skipped, got %v", err)
+	}
+	
+	// Publish both, then edit the old note without moving its modification time
+	d.config.Since = time.Time{}
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	writeVaultNote(t, d, "guides/Old.md", "---\npublish: true\nnoteUid: uid-old\n---\n\nEdited body\n")
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+	writeVaultNote(t, d, "guides/New.md", "---\npublish: true\nnoteUid: uid-new\n---\n\nSee [[Old]]\n")
+	
+	d.config.Since = time.Now().Add(-24 * time.Hour)
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	
+	// The skipped note keeps its page, and links to it still resolve
+	oldData, err := os.ReadFile(oldHugo)
+	if err != nil {
+		t.Fatalf("Expected the skipped note's page to be kept, got %v", err)
+	}
+	if strings.Contains(string(oldData), "Edited body") {
+		t.Error("Expected the skipped note not to be regenerated")
+	}
+	newData, _ := os.ReadFile(newHugo)
+	if !strings.Contains(string(newData), `relref "docs/guides/old"`) {
+		t.Errorf("Expected the link to the skipped note to resolve, got:\n%s", newData)
+	}
+	
+	// Deleted notes are still noticed
+	if err := os.Remove(oldPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.performFullSync(); err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	if _, err := os.Stat(oldHugo); !os.IsNotExist(err) {
+		t.Errorf("Expected the deleted note's page to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"log/slog"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// claimUID makes sure no other vault note holds note's UID, as happens when a
+// note is copied or a merge brings in a colliding noteUid. The note the state
+// knows under the UID keeps it while its file still carries it; failing that,
+// the first note to claim the UID in a full sync keeps it. A duplicate gets a
+// fresh UID, reported by returning true, so both notes publish separately.
+func (d *Daemon) claimUID(note *vault.Note, notePath string) bool {
+	owner, duplicate := d.uidOwner(note.UID, notePath)
+	if duplicate {
+		duplicateUID := note.UID
+		note.RegenerateUID()
+		slog.Warn("Note shares its noteUid with another note, assigning a new one",
+			"path", notePath,
+			"other", owner,
+			"duplicate_uid", duplicateUID,
+			"new_uid", note.UID)
+	}
+
+	if d.claimedUIDs != nil {
+		d.claimedUIDs[note.UID] = notePath
+	}
+	return duplicate
+}
+
+// recallUID gives a note without a UID of its own the one the state recorded
+// for its path, for vaults whose notes can't carry a written-back noteUid
+func (d *Daemon) recallUID(note *vault.Note, notePath string) {
+	if note.UID != "" {
+		return
+	}
+	for uid, stateNote := range d.stateManager.GetAllNotes() {
+		if stateNote.SourcePath == notePath {
+			note.UID = uid
+			return
+		}
+	}
+}
+
+// uidOwner returns the path of another note holding uid, if any
+func (d *Daemon) uidOwner(uid, notePath string) (string, bool) {
+	if stateNote := d.stateManager.GetNote(uid); stateNote != nil && stateNote.SourcePath != notePath {
+		// A note renamed away no longer holds it
+		if owner, err := vault.ParseNoteWithOptions(stateNote.SourcePath, d.parseOptions); err == nil && owner.UID == uid {
+			return stateNote.SourcePath, true
+		}
+	}
+
+	if owner, ok := d.claimedUIDs[uid]; ok && owner != notePath {
+		return owner, true
+	}
+	return "", false
+}
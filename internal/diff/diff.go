@@ -0,0 +1,217 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// contextLines is the number of unchanged lines shown around each change
+	contextLines = 3
+
+	// maxCells bounds the LCS table; larger inputs are shown as a full replacement
+	maxCells = 4_000_000
+)
+
+// opKind identifies a line operation in an edit script
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// lineOp is a single line of an edit script
+type lineOp struct {
+	kind opKind
+	text string
+}
+
+// Unified returns a unified diff turning oldText into newText. An empty
+// oldText is shown as a creation from /dev/null and an empty newText as a
+// deletion. Identical inputs produce an empty string.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	if oldText == "" {
+		oldName = "/dev/null"
+	}
+	if newText == "" {
+		newName = "/dev/null"
+	}
+
+	ops := lineDiff(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		b.WriteString(h)
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines without their trailing newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineDiff computes a line edit script using a longest common subsequence
+func lineDiff(a, b []string) []lineOp {
+	// Common prefix and suffix don't need the LCS table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []lineOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{opEqual, line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	if len(midA)*len(midB) > maxCells {
+		for _, line := range midA {
+			ops = append(ops, lineOp{opDelete, line})
+		}
+		for _, line := range midB {
+			ops = append(ops, lineOp{opInsert, line})
+		}
+	} else {
+		ops = append(ops, lcsDiff(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{opEqual, line})
+	}
+	return ops
+}
+
+// lcsDiff builds an edit script from the LCS table of a and b
+func lcsDiff(a, b []string) []lineOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []lineOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, lineOp{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, lineOp{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, lineOp{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, lineOp{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, lineOp{opInsert, b[j]})
+	}
+	return ops
+}
+
+// hunks groups an edit script into unified diff hunks with surrounding context
+func hunks(ops []lineOp) []string {
+	var result []string
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-contextLines, 0)
+		to := min(end+contextLines, len(ops))
+
+		// Line numbers of the hunk start in the old and new text
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != opInsert {
+				oldLine++
+			}
+			if op.kind != opDelete {
+				newLine++
+			}
+		}
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			switch op.kind {
+			case opEqual:
+				body.WriteString(" " + op.text + "\n")
+				oldCount++
+				newCount++
+			case opDelete:
+				body.WriteString("-" + op.text + "\n")
+				oldCount++
+			case opInsert:
+				body.WriteString("+" + op.text + "\n")
+				newCount++
+			}
+		}
+
+		// Empty ranges start at the line before, as in diff -u
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		result = append(result, fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", oldLine, oldCount, newLine, newCount, body.String()))
+		start = to
+	}
+
+	return result
+}
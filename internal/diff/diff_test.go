@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedChangedLines(t *testing.T) {
+	oldText := "---\ntitle: \"Note\"\nweight: 100\n---\n\nLine one\nLine two\nLine three\n"
+	newText := "---\ntitle: \"Note\"\nweight: 110\n---\n\nLine one\nLine 2\nLine three\nLine four\n"
+
+	result := Unified("a/note.md", "b/note.md", oldText, newText)
+
+	for _, expected := range []string{
+		"--- a/note.md\n+++ b/note.md\n",
+		"-weight: 100\n+weight: 110\n",
+		"-Line two\n+Line 2\n",
+		"+Line four\n",
+		" Line one\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", expected, result)
+		}
+	}
+
+	if strings.Contains(result, "-Line one") || strings.Contains(result, "+Line one") {
+		t.Errorf("Expected unchanged line to stay as context, got:\n%s", result)
+	}
+}
+
+func TestUnifiedCreationAndDeletion(t *testing.T) {
+	created := Unified("a/note.md", "b/note.md", "", "new\ncontent\n")
+	expected := "--- /dev/null\n+++ b/note.md\n@@ -0,0 +1,2 @@\n+new\n+content\n"
+	if created != expected {
+		t.Errorf("Expected creation diff:\n%s\ngot:\n%s", expected, created)
+	}
+
+	deleted := Unified("a/note.md", "b/note.md", "old\n", "")
+	expected = "--- a/note.md\n+++ /dev/null\n@@ -1,1 +0,0 @@\n-old\n"
+	if deleted != expected {
+		t.Errorf("Expected deletion diff:\n%s\ngot:\n%s", expected, deleted)
+	}
+
+	if result := Unified("a", "b", "same\n", "same\n"); result != "" {
+		t.Errorf("Expected empty diff for identical input, got %q", result)
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1)
+		oldLines = append(oldLines, line)
+		newLines = append(newLines, line)
+	}
+	newLines[1] = "changed early"
+	newLines[18] = "changed late"
+
+	result := Unified("a", "b", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	if count := strings.Count(result, "@@ -"); count != 2 {
+		t.Errorf("Expected 2 hunks, got %d:\n%s", count, result)
+	}
+	if !strings.Contains(result, "@@ -1,5 +1,5 @@\n") || !strings.Contains(result, "@@ -16,5 +16,5 @@\n") {
+		t.Errorf("Unexpected hunk headers:\n%s", result)
+	}
+}
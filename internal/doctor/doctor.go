@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"obsidian-hugo-sync/internal/config"
+	"obsidian-hugo-sync/internal/errors"
+	"obsidian-hugo-sync/internal/git"
+	"obsidian-hugo-sync/internal/process"
+	"obsidian-hugo-sync/internal/state"
+)
+
+// Status is the outcome of a single check
+type Status int
+
+const (
+	StatusPass Status = iota
+	StatusWarn        // Worth fixing, but syncing can proceed
+	StatusFail        // Syncing can't work until it is fixed
+)
+
+// Result is the outcome of one check, with the error explaining any problem
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Err    *errors.DaemonError // Nil when the check passed
+}
+
+// Run checks a sync configuration for common setup problems
+func Run(cfg *config.Config) []Result {
+	results := []Result{
+		checkVault(cfg),
+		checkRepoWritable(cfg),
+	}
+	results = append(results, checkContentDirs(cfg)...)
+	if cfg.GitAutoCommit {
+		results = append(results, checkGitRemote(cfg))
+	}
+	if result, ok := checkWatchLimit(cfg); ok {
+		results = append(results, result)
+	}
+	results = append(results,
+		checkLock(cfg),
+		checkState(cfg),
+	)
+	return results
+}
+
+// Failed reports whether any critical check failed
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the results with the suggestions for each problem found
+func Print(w io.Writer, results []Result) {
+	for _, result := range results {
+		icon := "✅"
+		switch result.Status {
+		case StatusWarn:
+			icon = "⚠️ "
+		case StatusFail:
+			icon = "❌"
+		}
+
+		fmt.Fprintf(w, "%s %s", icon, result.Name)
+		if result.Detail != "" {
+			fmt.Fprintf(w, ": %s", result.Detail)
+		}
+		fmt.Fprintln(w)
+
+		if result.Err != nil {
+			for _, suggestion := range result.Err.Suggestions {
+				fmt.Fprintf(w, "   💡 %s\n", suggestion)
+			}
+		}
+	}
+}
+
+// pass returns a passing result
+func pass(name, detail string) Result {
+	return Result{Name: name, Status: StatusPass, Detail: detail}
+}
+
+// problem returns a warning or failure explained by err
+func problem(name string, status Status, err *errors.DaemonError) Result {
+	detail := err.UserMessage
+	if err.Err != nil {
+		detail = err.Err.Error()
+	}
+	return Result{Name: name, Status: status, Detail: detail, Err: err}
+}
+
+// checkVault verifies the vault directory can be listed
+func checkVault(cfg *config.Config) Result {
+	const name = "Vault readable"
+
+	_, err := os.ReadDir(cfg.Vault)
+	if os.IsNotExist(err) && cfg.VaultURL != "" {
+		return pass(name, "remote vault is cloned on the first sync")
+	}
+	if err != nil {
+		return problem(name, StatusFail, errors.New(errors.ErrorTypeVault, "reading vault", err))
+	}
+	return pass(name, cfg.Vault)
+}
+
+// checkRepoWritable verifies a file can be created in the Hugo repository
+func checkRepoWritable(cfg *config.Config) Result {
+	const name = "Hugo repo writable"
+
+	file, err := os.CreateTemp(cfg.Repo, ".obsidian-hugo-sync-doctor-*")
+	if err != nil {
+		fsErr := errors.ClassifyWriteError("writing to hugo repo", err)
+		if fsErr == nil {
+			fsErr = errors.New(errors.ErrorTypeFileSystem, "writing to hugo repo", err)
+		}
+		return problem(name, StatusFail, fsErr)
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	return pass(name, cfg.Repo)
+}
+
+// checkContentDirs verifies the content directories exist in the Hugo repository
+func checkContentDirs(cfg *config.Config) []Result {
+	dirs := []string{cfg.ContentDir}
+	for _, dir := range cfg.SectionRoutes {
+		dirs = append(dirs, dir)
+	}
+
+	var results []Result
+	for _, dir := range dirs {
+		name := "Content dir present (" + filepath.ToSlash(dir) + ")"
+		path := filepath.Join(cfg.Repo, dir)
+
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			err = fmt.Errorf("%s is not a directory", path)
+		}
+		if err != nil {
+			results = append(results, problem(name, StatusWarn, errors.New(errors.ErrorTypeHugo, "checking content dir", err).
+				WithSuggestions(
+					"Check that --repo points at the Hugo site and --content-dir matches its content layout",
+					"The directory is created on the first sync if the path is right",
+				)))
+			continue
+		}
+		results = append(results, pass(name, path))
+	}
+	return results
+}
+
+// checkGitRemote verifies the Hugo repository is a git repository with an origin remote
+func checkGitRemote(cfg *config.Config) Result {
+	const name = "Git origin remote"
+
+	repo, err := git.NewRepository(cfg.Repo, "", "", true)
+	if err != nil {
+		return problem(name, StatusFail, errors.New(errors.ErrorTypeGit, "opening hugo git repository", err).
+			WithSuggestions("Run 'git init' in the Hugo site, or disable --git-auto-commit"))
+	}
+	if !repo.HasRemote("origin") {
+		return problem(name, StatusWarn, errors.New(errors.ErrorTypeGit, "checking git remote", fmt.Errorf("no 'origin' remote configured")).
+			WithSuggestions("Add one with: git remote add origin <url>"))
+	}
+	return pass(name, "origin")
+}
+
+// checkLock reports whether the vault's lock file is held or left behind
+func checkLock(cfg *config.Config) Result {
+	const name = "Lock file"
+
+	lockPath := process.GetLockPath(cfg.LockDir())
+	if _, err := os.Stat(lockPath); os.IsNotExist(err) {
+		return pass(name, "no other instance running")
+	}
+
+	pid, running, err := process.LockHolder(cfg.LockDir())
+	if err != nil {
+		return problem(name, StatusWarn, errors.New(errors.ErrorTypeProcess, "reading lock file", err))
+	}
+	if running {
+		return problem(name, StatusWarn, errors.New(errors.ErrorTypeProcess, "checking lock file", fmt.Errorf("held by running process %d", pid)).
+			WithSuggestions("Another instance is syncing this vault; stop it before starting a new one"))
+	}
+	return problem(name, StatusWarn, errors.New(errors.ErrorTypeProcess, "checking lock file", fmt.Errorf("stale lock left by process %d", pid)).
+		WithSuggestions(
+			"The stale lock is replaced on the next start",
+			fmt.Sprintf("To remove it now: rm %s", lockPath),
+		))
+}
+
+// checkState verifies the state cache loads, if there is one
+func checkState(cfg *config.Config) Result {
+	const name = "State cache"
+
+	if err := state.Verify(cfg.CacheDir, cfg.Vault); err != nil {
+		return problem(name, StatusWarn, errors.New(errors.ErrorTypeState, "loading state cache", err))
+	}
+	return pass(name, cfg.CacheDir)
+}
@@ -0,0 +1,101 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/config"
+	"obsidian-hugo-sync/internal/process"
+)
+
+// newTestConfig returns a configuration over temporary vault, repo and cache directories
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	
+	return &config.Config{
+		Vault:      t.TempDir(),
+		Repo:       t.TempDir(),
+		ContentDir: "content/docs",
+		CacheDir:   t.TempDir(),
+	}
+}
+
+// findResult returns the result of the check whose name starts with prefix
+func findResult(t *testing.T, results []Result, prefix string) Result {
+	t.Helper()
+	
+	for _, result := range results {
+		if strings.HasPrefix(result.Name, prefix) {
+			return result
+		}
+	}
+	t.Fatalf("Expected a %q check, got %+v", prefix, results)
+	return Result{}
+}
+
+func TestContentDirMissing(t *testing.T) {
+	cfg := newTestConfig(t)
+	
+	result := findResult(t, Run(cfg), "Content dir present")
+	if result.Status != StatusWarn {
+		t.Errorf("Expected missing content dir to warn, got status %d", result.Status)
+	}
+	if result.Err == nil || len(result.Err.Suggestions) == 0 {
+		t.Error("Expected suggestions for the missing content dir")
+	}
+	
+	if err := os.MkdirAll(filepath.Join(cfg.Repo, "content", "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create content dir: %v", err)
+	}
+	results := Run(cfg)
+	if result := findResult(t, results, "Content dir present"); result.Status != StatusPass {
+		t.Errorf("Expected existing content dir to pass, got %q", result.Detail)
+	}
+	if Failed(results) {
+		t.Errorf("Expected no critical failures, got %+v", results)
+	}
+}
+
+func TestStaleLockDetected(t *testing.T) {
+	cfg := newTestConfig(t)
+	
+	// Re-run the test binary with no tests selected to get the PID of an exited process
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to find test executable: %v", err)
+	}
+	cmd := exec.Command(executable, "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to run helper process: %v", err)
+	}
+	
+	stale := fmt.Sprintf("%d\n", cmd.Process.Pid)
+	if err := os.WriteFile(process.GetLockPath(cfg.Vault), []byte(stale), 0644); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+	
+	result := findResult(t, Run(cfg), "Lock file")
+	if result.Status != StatusWarn {
+		t.Errorf("Expected stale lock to warn, got status %d", result.Status)
+	}
+	if !strings.Contains(result.Detail, "stale lock") {
+		t.Errorf("Expected stale lock detail, got %q", result.Detail)
+	}
+}
+
+func TestUnreadableVaultFails(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Vault = filepath.Join(cfg.Vault, "missing")
+	
+	results := Run(cfg)
+	if result := findResult(t, results, "Vault readable"); result.Status != StatusFail {
+		t.Errorf("Expected missing vault to fail, got status %d", result.Status)
+	}
+	if !Failed(results) {
+		t.Error("Expected a critical failure")
+	}
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"obsidian-hugo-sync/internal/config"
+	"obsidian-hugo-sync/internal/errors"
+)
+
+// maxUserWatchesPath holds the per-user inotify watch limit
+const maxUserWatchesPath = "/proc/sys/fs/inotify/max_user_watches"
+
+// checkWatchLimit compares the inotify watches the vault needs, one per
+// directory, with the per-user limit they share with other programs
+func checkWatchLimit(cfg *config.Config) (Result, bool) {
+	const name = "inotify watch headroom"
+
+	data, err := os.ReadFile(maxUserWatchesPath)
+	if err != nil {
+		return Result{}, false
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || limit <= 0 {
+		return Result{}, false
+	}
+
+	needed := countWatchedDirs(cfg.Vault)
+	detail := fmt.Sprintf("%d of %d watches", needed, limit)
+
+	// Editors and other watchers draw from the same limit
+	if needed > limit/2 {
+		return problem(name, StatusWarn, errors.New(errors.ErrorTypeFileSystem, "checking inotify limit", fmt.Errorf("vault needs %s", detail)).
+			WithSuggestions(
+				"Raise the limit: sudo sysctl fs.inotify.max_user_watches=524288",
+				"Persist it in /etc/sysctl.d/ so it survives reboots",
+			)), true
+	}
+	return pass(name, detail), true
+}
+
+// countWatchedDirs counts the vault directories the watcher adds, skipping hidden ones
+func countWatchedDirs(vaultPath string) int {
+	count := 0
+	filepath.WalkDir(vaultPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != vaultPath && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		count++
+		return nil
+	})
+	return count
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package doctor
+
+import "obsidian-hugo-sync/internal/config"
+
+// checkWatchLimit is skipped where the file watcher has no per-user limit to exhaust
+func checkWatchLimit(cfg *config.Config) (Result, bool) {
+	return Result{}, false
+}
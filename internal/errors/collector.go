@@ -0,0 +1,101 @@
+package errors
+
+import (
+	stderrors "errors"
+	"log/slog"
+	"sort"
+)
+
+// Collector gathers the errors of a sync run so they can be reported grouped
+// by type once it finishes
+type Collector struct {
+	errors []*DaemonError
+	seen   map[string]bool
+}
+
+// ErrorGroup holds the collected errors of one type, in the order they occurred
+type ErrorGroup struct {
+	Type   ErrorType
+	Errors []*DaemonError
+}
+
+// NewCollector creates an empty error collector
+func NewCollector() *Collector {
+	return &Collector{
+		seen: make(map[string]bool),
+	}
+}
+
+// Add records an error and returns it as a DaemonError. A DaemonError anywhere
+// in the chain keeps its own type; other errors are wrapped as errType. An
+// error identical to one already recorded is only counted once.
+func (c *Collector) Add(errType ErrorType, operation string, err error) *DaemonError {
+	if err == nil {
+		return nil
+	}
+
+	var daemonErr *DaemonError
+	if !stderrors.As(err, &daemonErr) {
+		daemonErr = New(errType, operation, err)
+	}
+
+	key := daemonErr.Error()
+	if !c.seen[key] {
+		c.seen[key] = true
+		c.errors = append(c.errors, daemonErr)
+	}
+	return daemonErr
+}
+
+// Len returns the number of distinct errors collected
+func (c *Collector) Len() int {
+	return len(c.errors)
+}
+
+// Groups returns the collected errors grouped by type, in ErrorType order
+func (c *Collector) Groups() []ErrorGroup {
+	byType := make(map[ErrorType][]*DaemonError)
+	for _, err := range c.errors {
+		byType[err.Type] = append(byType[err.Type], err)
+	}
+
+	groups := make([]ErrorGroup, 0, len(byType))
+	for errType, errs := range byType {
+		groups = append(groups, ErrorGroup{Type: errType, Errors: errs})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Type < groups[j].Type
+	})
+	return groups
+}
+
+// Suggestion returns the first suggestion offered by the group's errors
+func (g ErrorGroup) Suggestion() string {
+	for _, err := range g.Errors {
+		if len(err.Suggestions) > 0 {
+			return err.Suggestions[0]
+		}
+	}
+	return ""
+}
+
+// LogSummary logs one line per error type with its count and first
+// suggestion, so failures don't get lost among a large sync's output
+func (c *Collector) LogSummary() {
+	if len(c.errors) == 0 {
+		return
+	}
+
+	slog.Warn("Sync finished with errors", "errors", len(c.errors))
+	for _, group := range c.Groups() {
+		attrs := []interface{}{
+			"category", group.Type.String(),
+			"count", len(group.Errors),
+			"first", group.Errors[0].Err,
+		}
+		if suggestion := group.Suggestion(); suggestion != "" {
+			attrs = append(attrs, "suggestion", suggestion)
+		}
+		slog.Warn("Sync errors", attrs...)
+	}
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCollectorGroupsByType(t *testing.T) {
+	collector := NewCollector()
+
+	// Five notes with broken front-matter, reported as vault errors
+	for i := 1; i <= 5; i++ {
+		err := New(ErrorTypeVault, "parsing note", fmt.Errorf("yaml: line %d: mapping values are not allowed", i)).
+			WithSuggestions("Indent YAML with spaces, not tabs")
+		collector.Add(ErrorTypeUnknown, "processing note", fmt.Errorf("note %d: %w", i, err))
+	}
+
+	// Two missing images, one of them reported twice
+	collector.Add(ErrorTypeImage, "copying image", fmt.Errorf("image not found: a.png"))
+	collector.Add(ErrorTypeImage, "copying image", fmt.Errorf("image not found: b.png"))
+	collector.Add(ErrorTypeImage, "copying image", fmt.Errorf("image not found: a.png"))
+	collector.Add(ErrorTypeImage, "copying image", nil)
+
+	if collector.Len() != 7 {
+		t.Errorf("Expected 7 distinct errors, got %d", collector.Len())
+	}
+
+	groups := collector.Groups()
+	expected := []struct {
+		errType    ErrorType
+		count      int
+		suggestion string
+	}{
+		{ErrorTypeVault, 5, "Indent YAML with spaces, not tabs"},
+		{ErrorTypeImage, 2, "Check that image files exist and are accessible"},
+	}
+
+	if len(groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %d", len(expected), len(groups))
+	}
+	for i, want := range expected {
+		if groups[i].Type != want.errType {
+			t.Errorf("Expected group %d to be %s, got %s", i, want.errType, groups[i].Type)
+		}
+		if len(groups[i].Errors) != want.count {
+			t.Errorf("Expected %d %s errors, got %d", want.count, want.errType, len(groups[i].Errors))
+		}
+		if suggestion := groups[i].Suggestion(); suggestion != want.suggestion {
+			t.Errorf("Expected %s suggestion %q, got %q", want.errType, want.suggestion, suggestion)
+		}
+	}
+}
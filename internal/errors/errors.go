@@ -1,8 +1,11 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"syscall"
 	"time"
 )
 
@@ -319,4 +322,37 @@ func GetErrorType(err error) ErrorType {
 		return daemonErr.Type
 	}
 	return ErrorTypeUnknown
-} 
\ No newline at end of file
+} 
+
+// ClassifyWriteError recognizes write failures that retrying right away won't
+// fix (a full disk, a read-only file system, missing permissions, or a path
+// that is too long or invalid) and returns them as a FileSystem error. It
+// returns nil for any other error.
+func ClassifyWriteError(operation string, err error) *DaemonError {
+	var message, suggestion string
+	switch {
+	case err == nil:
+		return nil
+	case stderrors.Is(err, syscall.ENOSPC):
+		message = "No space left on the device"
+		suggestion = "Free up disk space on the drive holding the files"
+	case stderrors.Is(err, syscall.EROFS):
+		message = "The file system is read-only"
+		suggestion = "Remount the file system read-write"
+	case stderrors.Is(err, fs.ErrPermission):
+		message = "Permission denied"
+		suggestion = "Check file and directory permissions"
+	case isPathTooLong(err):
+		message = "The path is too long"
+		suggestion = "Shorten vault folder names or move the Hugo repository closer to the drive root; on Windows, enable long paths (LongPathsEnabled) as well"
+	case isInvalidName(err):
+		message = "The file name is not valid on this file system"
+		suggestion = "Rename the vault folder or note; Windows rejects names like CON or NUL and names ending in a dot or space"
+	default:
+		return nil
+	}
+	
+	return New(ErrorTypeFileSystem, operation, err).
+		WithUserMessage(message).
+		WithSuggestions(suggestion, "Writes resume automatically once they succeed again")
+}
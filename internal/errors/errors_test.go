@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyWriteError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"read-only", &fs.PathError{Op: "open", Path: "note.md", Err: syscall.EROFS}, "The file system is read-only"},
+		{"disk full", fmt.Errorf("writing hugo file: %w", &fs.PathError{Op: "write", Path: "note.md", Err: syscall.ENOSPC}), "No space left on the device"},
+		{"permission", &fs.PathError{Op: "open", Path: "note.md", Err: fs.ErrPermission}, "Permission denied"},
+		{"name too long", &fs.PathError{Op: "open", Path: "note.md", Err: syscall.ENAMETOOLONG}, "The path is too long"},
+		{"not found", &fs.PathError{Op: "open", Path: "note.md", Err: fs.ErrNotExist}, ""},
+		{"nil", nil, ""},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classified := ClassifyWriteError("writing hugo file", tt.err)
+			if tt.message == "" {
+				if classified != nil {
+					t.Errorf("Expected no classification, got %v", classified)
+				}
+				return
+			}
+			
+			if classified == nil {
+				t.Fatalf("Expected %q to be classified", tt.err)
+			}
+			if classified.Type != ErrorTypeFileSystem {
+				t.Errorf("Expected FileSystem error, got %s", classified.Type)
+			}
+			if classified.UserMessage != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, classified.UserMessage)
+			}
+			if len(classified.Suggestions) == 0 {
+				t.Error("Expected suggestions for the error")
+			}
+		})
+	}
+}
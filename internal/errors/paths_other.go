@@ -0,0 +1,19 @@
+//go:build !windows
+
+package errors
+
+import (
+	stderrors "errors"
+	"syscall"
+)
+
+// isPathTooLong reports whether a path was rejected for its length
+func isPathTooLong(err error) bool {
+	return stderrors.Is(err, syscall.ENAMETOOLONG)
+}
+
+// isInvalidName reports whether the file system rejected a file name; only
+// Windows rejects names that are valid elsewhere
+func isInvalidName(err error) bool {
+	return false
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package errors
+
+import (
+	stderrors "errors"
+	"syscall"
+)
+
+const (
+	// errorInvalidName is ERROR_INVALID_NAME, returned for reserved device
+	// names and names ending in a dot or space
+	errorInvalidName syscall.Errno = 123
+
+	// errorFilenameExcedRange is ERROR_FILENAME_EXCED_RANGE, returned for
+	// paths past MAX_PATH when long paths aren't enabled
+	errorFilenameExcedRange syscall.Errno = 206
+)
+
+// isPathTooLong reports whether a path was rejected for its length
+func isPathTooLong(err error) bool {
+	return stderrors.Is(err, errorFilenameExcedRange) || stderrors.Is(err, syscall.ENAMETOOLONG)
+}
+
+// isInvalidName reports whether the file system rejected a file name
+func isInvalidName(err error) bool {
+	return stderrors.Is(err, errorInvalidName)
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// CommitBatch decides when accumulated changes are worth a commit: once at
+// least threshold files changed or maxDelay elapsed since the first pending
+// change, whichever comes first
+type CommitBatch struct {
+	threshold int
+	maxDelay  time.Duration
+	pending   int
+	since     time.Time
+}
+
+// NewCommitBatch creates a commit batch; a maxDelay of 0 disables the time trigger
+func NewCommitBatch(threshold int, maxDelay time.Duration) *CommitBatch {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CommitBatch{
+		threshold: threshold,
+		maxDelay:  maxDelay,
+	}
+}
+
+// Observe records the number of currently uncommitted files
+func (b *CommitBatch) Observe(pending int, now time.Time) {
+	if pending == 0 {
+		b.Reset()
+		return
+	}
+	if b.pending == 0 {
+		b.since = now
+	}
+	b.pending = pending
+}
+
+// Pending returns the number of uncommitted files last observed
+func (b *CommitBatch) Pending() int {
+	return b.pending
+}
+
+// Due reports whether the pending changes should be committed now
+func (b *CommitBatch) Due(now time.Time) bool {
+	if b.pending == 0 {
+		return false
+	}
+	if b.pending >= b.threshold {
+		return true
+	}
+	return b.maxDelay > 0 && now.Sub(b.since) >= b.maxDelay
+}
+
+// Reset clears the pending changes after a commit
+func (b *CommitBatch) Reset() {
+	b.pending = 0
+	b.since = time.Time{}
+}
+
+// CountChanges tallies a repository status into added, modified and deleted files
+func CountChanges(status map[string]git.StatusCode) (added, modified, deleted int) {
+	for _, code := range status {
+		switch code {
+		case git.Added, git.Untracked, git.Copied:
+			added++
+		case git.Deleted:
+			deleted++
+		case git.Unmodified:
+		default:
+			modified++
+		}
+	}
+	return added, modified, deleted
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCommitBatchThreshold(t *testing.T) {
+	batch := NewCommitBatch(3, time.Hour)
+	now := time.Now()
+
+	batch.Observe(2, now)
+	if batch.Due(now) {
+		t.Error("Expected batch below threshold not to be due")
+	}
+
+	batch.Observe(3, now.Add(time.Second))
+	if !batch.Due(now.Add(time.Second)) {
+		t.Error("Expected batch at threshold to be due")
+	}
+
+	batch.Reset()
+	if batch.Due(now.Add(time.Second)) {
+		t.Error("Expected reset batch not to be due")
+	}
+}
+
+func TestCommitBatchMaxDelay(t *testing.T) {
+	batch := NewCommitBatch(10, time.Minute)
+	start := time.Now()
+
+	batch.Observe(1, start)
+	batch.Observe(2, start.Add(30*time.Second))
+	if batch.Due(start.Add(59 * time.Second)) {
+		t.Error("Expected batch not to be due before max delay")
+	}
+	if !batch.Due(start.Add(time.Minute)) {
+		t.Error("Expected batch to be due once max delay elapsed since first change")
+	}
+
+	// Changes committed elsewhere clear the batch
+	batch.Observe(0, start.Add(time.Minute))
+	if batch.Due(start.Add(time.Hour)) {
+		t.Error("Expected empty batch never to be due")
+	}
+
+	// Without a max delay only the threshold triggers a commit
+	batch = NewCommitBatch(10, 0)
+	batch.Observe(1, start)
+	if batch.Due(start.Add(24 * time.Hour)) {
+		t.Error("Expected disabled max delay never to trigger")
+	}
+}
+
+func TestCountChanges(t *testing.T) {
+	status := map[string]git.StatusCode{
+		"content/a.md": git.Untracked,
+		"content/b.md": git.Added,
+		"content/c.md": git.Modified,
+		"content/d.md": git.Deleted,
+		"content/e.md": git.Renamed,
+	}
+
+	added, modified, deleted := CountChanges(status)
+	if added != 2 || modified != 2 || deleted != 1 {
+		t.Errorf("Expected 2 added, 2 modified, 1 deleted, got %d, %d, %d", added, modified, deleted)
+	}
+}
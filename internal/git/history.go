@@ -0,0 +1,96 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// History looks up when files of a git working tree were last committed.
+// Answers are cached until HEAD moves, so a full sync walks the log at most
+// once per file.
+type History struct {
+	repo *git.Repository
+	root string
+
+	mu    sync.Mutex
+	head  plumbing.Hash
+	cache map[string]time.Time // Repo-relative path -> author time of its last commit, zero if never committed
+}
+
+// OpenHistory opens the git repository holding path, which may be any
+// directory inside its working tree
+func OpenHistory(path string) (*History, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository for %s: %w", path, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("getting worktree: %w", err)
+	}
+
+	return &History{
+		repo:  repo,
+		root:  worktree.Filesystem.Root(),
+		cache: make(map[string]time.Time),
+	}, nil
+}
+
+// LastCommitTime returns the author time of the most recent commit touching
+// the file at path. ok is false when the file was never committed or lies
+// outside the repository.
+func (h *History) LastCommitTime(path string) (time.Time, bool) {
+	relPath, err := filepath.Rel(h.root, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return time.Time{}, false
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	head, err := h.repo.Head()
+	if err != nil {
+		return time.Time{}, false // No commits yet
+	}
+	if head.Hash() != h.head {
+		h.head = head.Hash()
+		h.cache = make(map[string]time.Time)
+	}
+
+	when, cached := h.cache[relPath]
+	if !cached {
+		when, err = h.lookup(relPath)
+		if err != nil {
+			return time.Time{}, false // Not cached, so the next call retries
+		}
+		h.cache[relPath] = when
+	}
+	return when, !when.IsZero()
+}
+
+// lookup walks the log from HEAD for the newest commit touching relPath
+func (h *History) lookup(relPath string) (time.Time, error) {
+	commits, err := h.repo.Log(&git.LogOptions{From: h.head, FileName: &relPath})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading log of %s: %w", relPath, err)
+	}
+	defer commits.Close()
+
+	commit, err := commits.Next()
+	if errors.Is(err, io.EOF) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading log of %s: %w", relPath, err)
+	}
+	return commit.Author.When, nil
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitAt writes files into a working repository and commits them with the given author time
+func commitAt(t *testing.T, repo *git.Repository, dir string, when time.Time, files map[string]string) {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(filepath.ToSlash(name)); err != nil {
+			t.Fatalf("Failed to stage file: %v", err)
+		}
+	}
+	_, err = worktree.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: when},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestHistoryLastCommitTime(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	first := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 2, 20, 15, 30, 0, 0, time.UTC)
+	third := time.Date(2024, 3, 5, 8, 45, 0, 0, time.UTC)
+	commitAt(t, repo, dir, first, map[string]string{"Notes/A.md": "a", "B.md": "b"})
+	commitAt(t, repo, dir, second, map[string]string{"Notes/A.md": "a2"})
+
+	// The history is opened from a folder inside the working tree, as for a vault in a repo subfolder
+	history, err := OpenHistory(filepath.Join(dir, "Notes"))
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Uncommitted.md"), []byte("c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected time.Time
+		ok       bool
+	}{
+		{filepath.Join(dir, "Notes", "A.md"), second, true},
+		{filepath.Join(dir, "B.md"), first, true},
+		{filepath.Join(dir, "Uncommitted.md"), time.Time{}, false},
+		{filepath.Join(t.TempDir(), "Outside.md"), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			when, ok := history.LastCommitTime(tt.path)
+			if ok != tt.ok || !when.Equal(tt.expected) {
+				t.Errorf("Expected %v (%v), got %v (%v)", tt.expected, tt.ok, when, ok)
+			}
+		})
+	}
+
+	// A new commit moves HEAD and invalidates the cached answers
+	commitAt(t, repo, dir, third, map[string]string{"B.md": "b2"})
+	if when, _ := history.LastCommitTime(filepath.Join(dir, "B.md")); !when.Equal(third) {
+		t.Errorf("Expected %v after a new commit, got %v", third, when)
+	}
+	if when, _ := history.LastCommitTime(filepath.Join(dir, "Notes", "A.md")); !when.Equal(second) {
+		t.Errorf("Expected %v for an untouched file, got %v", second, when)
+	}
+}
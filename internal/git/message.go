@@ -0,0 +1,62 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// CommitInfo is the data available to commit message templates
+type CommitInfo struct {
+	Added     int       // Files added since the last commit
+	Modified  int       // Files modified since the last commit
+	Deleted   int       // Files deleted since the last commit
+	Timestamp time.Time // When the commit is made
+}
+
+// CommitMessage renders commit messages from an optional text/template,
+// falling back to CreateCommitMessage when no template is configured
+type CommitMessage struct {
+	tmpl *template.Template
+}
+
+// NewCommitMessage parses a commit message template. The template is rendered
+// once with sample data so references to unknown fields fail at startup.
+func NewCommitMessage(text string) (*CommitMessage, error) {
+	if text == "" {
+		return &CommitMessage{}, nil
+	}
+
+	tmpl, err := template.New("commit").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing commit template: %w", err)
+	}
+
+	message := &CommitMessage{tmpl: tmpl}
+	if _, err := message.Render(CommitInfo{Added: 1, Modified: 1, Deleted: 1, Timestamp: time.Now()}); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// Render produces the commit message for a set of changes
+func (m *CommitMessage) Render(info CommitInfo) (string, error) {
+	if m == nil || m.tmpl == nil {
+		return CreateCommitMessage(info.Added, info.Modified, info.Deleted), nil
+	}
+
+	var buf bytes.Buffer
+	if err := m.tmpl.Execute(&buf, info); err != nil {
+		return "", fmt.Errorf("rendering commit template: %w", err)
+	}
+
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("commit template rendered an empty message")
+	}
+
+	return message, nil
+}
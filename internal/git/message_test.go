@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCommitMessageTemplate(t *testing.T) {
+	message, err := NewCommitMessage("docs: sync {{.Added}} new, {{.Modified}} changed, {{.Deleted}} removed\n\nSynced-at: {{.Timestamp.Format \"2006-01-02\"}}\nSigned-off-by: Docs Bot <docs@example.com>\n")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	result, err := message.Render(CommitInfo{Added: 2, Modified: 5, Deleted: 1, Timestamp: time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	expected := "docs: sync 2 new, 5 changed, 1 removed\n\nSynced-at: 2024-03-09\nSigned-off-by: Docs Bot <docs@example.com>"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestCommitMessageDefault(t *testing.T) {
+	message, err := NewCommitMessage("")
+	if err != nil {
+		t.Fatalf("Failed to create default message: %v", err)
+	}
+
+	result, err := message.Render(CommitInfo{Added: 1, Modified: 2})
+	if err != nil {
+		t.Fatalf("Failed to render default message: %v", err)
+	}
+	if expected := CreateCommitMessage(1, 2, 0); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestCommitMessageInvalidTemplate(t *testing.T) {
+	tests := []string{
+		"sync {{.Added",        // Syntax error
+		"sync {{.Renamed}}",    // Unknown field
+		"{{if false}}x{{end}}", // Empty message
+	}
+
+	for _, text := range tests {
+		if _, err := NewCommitMessage(text); err == nil {
+			t.Errorf("Expected error for template %q", text)
+		}
+	}
+}
+
+func TestCommitChangesUsesAuthor(t *testing.T) {
+	repoDir := t.TempDir()
+	if _, err := git.PlainInit(repoDir, false); err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "note.md"), []byte("# Note"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewRepository(repoDir, "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	repo.SetAuthor("Docs Bot", "docs@example.com")
+
+	if err := repo.CommitChanges("sync: added 1 notes"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	head, err := repo.repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to read HEAD: %v", err)
+	}
+	commit, err := repo.repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+
+	if commit.Author.Name != "Docs Bot" || commit.Author.Email != "docs@example.com" {
+		t.Errorf("Expected author Docs Bot <docs@example.com>, got %s <%s>", commit.Author.Name, commit.Author.Email)
+	}
+	if commit.Committer.Name != "Docs Bot" {
+		t.Errorf("Expected committer Docs Bot, got %s", commit.Committer.Name)
+	}
+}
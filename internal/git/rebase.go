@@ -0,0 +1,198 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// isPushAuthError reports whether a push failed on credentials, which a retry can't fix
+func isPushAuthError(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) ||
+		errors.Is(err, transport.ErrAuthorizationFailed)
+}
+
+// isPushConflict reports whether a push was rejected because origin has
+// commits the local branch lacks
+func isPushConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "non-fast-forward") || strings.Contains(message, "fetch first")
+}
+
+// rebaseOnOrigin fetches origin's copy of branch and replays the local
+// commits it lacks on top of it, like git pull --rebase. The sync output is
+// authoritative, so where both sides changed a file the local version wins.
+func (r *Repository) rebaseOnOrigin(branch string) error {
+	remoteName := plumbing.NewRemoteReferenceName("origin", branch)
+	err := r.repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", branch, remoteName)),
+		},
+		Auth: r.auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+
+	remoteRef, err := r.repo.Reference(remoteName, true)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", remoteName, err)
+	}
+	upstream, err := r.repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return fmt.Errorf("reading origin commit: %w", err)
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return fmt.Errorf("getting HEAD: %w", err)
+	}
+	local, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("reading HEAD commit: %w", err)
+	}
+
+	bases, err := local.MergeBase(upstream)
+	if err != nil {
+		return fmt.Errorf("finding merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		return fmt.Errorf("branch %s shares no history with origin", branch)
+	}
+
+	// Commits to replay, oldest first
+	var commits []*object.Commit
+	for commit := local; commit.Hash != bases[0].Hash; {
+		if commit.NumParents() != 1 {
+			return fmt.Errorf("cannot rebase commit %s with %d parents", commit.Hash, commit.NumParents())
+		}
+		commits = append(commits, commit)
+		if commit, err = commit.Parent(0); err != nil {
+			return fmt.Errorf("reading parent commit: %w", err)
+		}
+	}
+	slices.Reverse(commits)
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("getting worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("getting status: %w", err)
+	}
+	if !status.IsClean() {
+		return fmt.Errorf("worktree has uncommitted changes")
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: upstream.Hash, Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("resetting to origin: %w", err)
+	}
+
+	for _, commit := range commits {
+		if err := r.replayCommit(worktree, commit); err != nil {
+			return fmt.Errorf("replaying commit %s: %w", commit.Hash, err)
+		}
+	}
+
+	slog.Info("Rebased onto origin", "branch", branch, "origin", upstream.Hash.String(), "commits", len(commits))
+	return nil
+}
+
+// replayCommit applies the changes one commit made to its parent onto the
+// worktree and commits them with the original message and author
+func (r *Repository) replayCommit(worktree *git.Worktree, commit *object.Commit) error {
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return fmt.Errorf("reading parent commit: %w", err)
+	}
+	from, err := parent.Tree()
+	if err != nil {
+		return fmt.Errorf("reading parent tree: %w", err)
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("reading tree: %w", err)
+	}
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return fmt.Errorf("diffing trees: %w", err)
+	}
+
+	for _, change := range changes {
+		if change.From.Name != "" && change.From.Name != change.To.Name {
+			if err := r.removeReplayed(worktree, change.From.Name); err != nil {
+				return err
+			}
+		}
+		if change.To.Name != "" {
+			file, err := to.File(change.To.Name)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", change.To.Name, err)
+			}
+			if err := r.writeReplayed(worktree, file); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = worktree.Commit(commit.Message, &git.CommitOptions{
+		Author: &commit.Author,
+	})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return nil // Origin already has the same changes
+	}
+	return err
+}
+
+// writeReplayed writes a file from a replayed commit into the worktree and stages it
+func (r *Repository) writeReplayed(worktree *git.Worktree, file *object.File) error {
+	reader, err := file.Reader()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file.Name, err)
+	}
+
+	fullPath := filepath.Join(r.repoPath, filepath.FromSlash(file.Name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", file.Name, err)
+	}
+	if _, err := worktree.Add(file.Name); err != nil {
+		return fmt.Errorf("staging %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// removeReplayed deletes a file a replayed commit removed, if origin still has it
+func (r *Repository) removeReplayed(worktree *git.Worktree, name string) error {
+	fullPath := filepath.Join(r.repoPath, filepath.FromSlash(name))
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := worktree.Remove(name); err != nil {
+		return fmt.Errorf("removing %s: %w", name, err)
+	}
+	r.removeEmptyDirs(filepath.Dir(fullPath))
+	return nil
+}
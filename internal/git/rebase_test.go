@@ -0,0 +1,130 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+func TestPushErrorClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		auth     bool
+		conflict bool
+	}{
+		{"authentication", fmt.Errorf("%w: bad token", transport.ErrAuthenticationRequired), true, false},
+		{"authorization", fmt.Errorf("%w: forbidden", transport.ErrAuthorizationFailed), true, false},
+		{"client side", fmt.Errorf("non-fast-forward update: refs/heads/main"), false, true},
+		{"server side", fmt.Errorf("command error on refs/heads/main: fetch first"), false, true},
+		{"network", fmt.Errorf("dial tcp: connection refused"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isPushAuthError(tt.err); result != tt.auth {
+				t.Errorf("Expected auth error %v, got %v", tt.auth, result)
+			}
+			if result := isPushConflict(tt.err); result != tt.conflict {
+				t.Errorf("Expected conflict %v, got %v", tt.conflict, result)
+			}
+		})
+	}
+}
+
+func TestPushRebasesWhenRemoteAdvanced(t *testing.T) {
+	// A bare repository stands in for the remote
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatalf("Failed to create bare repository: %v", err)
+	}
+
+	authorDir := t.TempDir()
+	author, err := git.PlainInit(authorDir, false)
+	if err != nil {
+		t.Fatalf("Failed to create working repository: %v", err)
+	}
+	if _, err := author.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	commitNote(t, author, authorDir, "shared.md", "original\n")
+
+	hugoDir := t.TempDir()
+	if _, err := git.PlainClone(hugoDir, false, &git.CloneOptions{URL: remote}); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	// Someone else pushes between the clone and the sync's push
+	commitNote(t, author, authorDir, "theirs.md", "theirs\n")
+	commitNote(t, author, authorDir, "shared.md", "their edit\n")
+
+	repo, err := NewRepository(hugoDir, "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	repo.auth = nil
+	repo.pushRetries = []time.Duration{0, 0}
+
+	if err := os.WriteFile(filepath.Join(hugoDir, "ours.md"), []byte("ours\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hugoDir, "shared.md"), []byte("sync edit\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CommitChanges("sync: updated 2 notes"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	// Without rebasing, the rejected push fails at once
+	if err := repo.Push(); err == nil || !isPushConflict(err) {
+		t.Fatalf("Expected a push conflict, got %v", err)
+	}
+
+	repo.SetRebaseOnConflict(true)
+	if err := repo.Push(); err != nil {
+		t.Fatalf("Expected push after rebase to succeed, got %v", err)
+	}
+
+	// The remote now holds both sides, with the sync's version of the shared file
+	verifyDir := t.TempDir()
+	if _, err := git.PlainClone(verifyDir, false, &git.CloneOptions{URL: remote}); err != nil {
+		t.Fatalf("Failed to clone remote: %v", err)
+	}
+	expected := map[string]string{
+		"theirs.md": "theirs\n",
+		"ours.md":   "ours\n",
+		"shared.md": "sync edit\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(verifyDir, name))
+		if err != nil {
+			t.Errorf("Expected %s on the remote, got %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to contain %q, got %q", name, content, data)
+		}
+	}
+
+	// The rebased commit keeps its message and sits on top of the remote's
+	head, err := repo.repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to read HEAD: %v", err)
+	}
+	commit, err := repo.repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if commit.Message != "sync: updated 2 notes" {
+		t.Errorf("Expected the sync commit message, got %q", commit.Message)
+	}
+	if _, err := os.Stat(filepath.Join(hugoDir, "theirs.md")); err != nil {
+		t.Errorf("Expected the remote's file in the worktree, got %v", err)
+	}
+}
@@ -19,11 +19,17 @@ import (
 
 // Repository wraps git operations for the Hugo repository
 type Repository struct {
-	repo       *git.Repository
-	repoPath   string
-	branch     string
-	auth       transport.AuthMethod
-	dryRun     bool
+	repo     *git.Repository
+	repoPath string
+	branch   string
+	auth     transport.AuthMethod
+	dryRun   bool
+
+	authorName  string
+	authorEmail string
+
+	rebaseOnConflict bool            // Rebase onto origin when a push is rejected
+	pushRetries      []time.Duration // Delays before each push retry
 }
 
 // NewRepository creates a new Git repository wrapper
@@ -38,25 +44,28 @@ func NewRepository(repoPath, branch string, authToken string, dryRun bool) (*Rep
 		repoPath: repoPath,
 		branch:   branch,
 		dryRun:   dryRun,
+
+		authorName:  "obsidian-hugo-sync",
+		authorEmail: "obsidian-hugo-sync@automated",
+
+		pushRetries: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second},
 	}
 
 	// Set up authentication
-	if err := r.setupAuth(authToken); err != nil {
-		return nil, fmt.Errorf("setting up authentication: %w", err)
-	}
+	r.auth = setupAuth(authToken)
 
 	return r, nil
 }
 
-// setupAuth configures Git authentication
-func (r *Repository) setupAuth(token string) error {
+// setupAuth returns the Git authentication to use: a token when given, else
+// the user's SSH key, else nil to rely on the system configuration
+func setupAuth(token string) transport.AuthMethod {
 	// Try token-based auth first
 	if token != "" {
-		r.auth = &http.BasicAuth{
+		return &http.BasicAuth{
 			Username: "token",
 			Password: token,
 		}
-		return nil
 	}
 
 	// Try SSH key authentication
@@ -64,8 +73,7 @@ func (r *Repository) setupAuth(token string) error {
 	if _, err := os.Stat(sshPath); err == nil {
 		sshAuth, err := ssh.NewPublicKeysFromFile("git", sshPath, "")
 		if err == nil {
-			r.auth = sshAuth
-			return nil
+			return sshAuth
 		}
 	}
 
@@ -74,8 +82,7 @@ func (r *Repository) setupAuth(token string) error {
 	if _, err := os.Stat(sshPath); err == nil {
 		sshAuth, err := ssh.NewPublicKeysFromFile("git", sshPath, "")
 		if err == nil {
-			r.auth = sshAuth
-			return nil
+			return sshAuth
 		}
 	}
 
@@ -192,6 +199,12 @@ func (r *Repository) removeEmptyDirs(dir string) {
 	}
 }
 
+// SetAuthor sets the name and email used for commits
+func (r *Repository) SetAuthor(name, email string) {
+	r.authorName = name
+	r.authorEmail = email
+}
+
 // CommitChanges commits all changes in the repository
 func (r *Repository) CommitChanges(message string) error {
 	if r.dryRun {
@@ -223,8 +236,8 @@ func (r *Repository) CommitChanges(message string) error {
 	// Commit changes
 	commit, err := worktree.Commit(message, &git.CommitOptions{
 		Author: &object.Signature{
-			Name:  "obsidian-hugo-sync",
-			Email: "obsidian-hugo-sync@automated",
+			Name:  r.authorName,
+			Email: r.authorEmail,
 			When:  time.Now(),
 		},
 	})
@@ -236,48 +249,93 @@ func (r *Repository) CommitChanges(message string) error {
 	return nil
 }
 
-// Push pushes the current branch to origin
+// HasRemote reports whether the repository has a remote with the given name
+func (r *Repository) HasRemote(name string) bool {
+	_, err := r.repo.Remote(name)
+	return err == nil
+}
+
+// SetRebaseOnConflict makes Push fetch origin and rebase the sync branch onto
+// it when the push is rejected because someone else pushed first
+func (r *Repository) SetRebaseOnConflict(enabled bool) {
+	r.rebaseOnConflict = enabled
+}
+
+// Push pushes the sync branch (the current branch when none was given) to
+// origin. Transient failures are retried; authentication failures are not.
+// A rejected non-fast-forward push is only retried, after a rebase onto
+// origin, when rebasing on conflict is enabled.
 func (r *Repository) Push() error {
+	branch, err := r.pushBranch()
+	if err != nil {
+		return err
+	}
+
 	if r.dryRun {
-		slog.Info("DRY RUN: Would push to origin", "branch", r.branch)
+		slog.Info("DRY RUN: Would push to origin", "branch", branch)
 		return nil
 	}
 
 	// Push with retries
 	var lastErr error
-	retries := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
-	
-	for attempt := 0; attempt <= len(retries); attempt++ {
-		if attempt > 0 {
-			slog.Warn("Retrying push", "attempt", attempt, "delay", retries[attempt-1])
-			time.Sleep(retries[attempt-1])
+	for attempt := 0; attempt <= len(r.pushRetries); attempt++ {
+		if attempt > 0 && !isPushConflict(lastErr) {
+			slog.Warn("Retrying push", "attempt", attempt, "delay", r.pushRetries[attempt-1])
+			time.Sleep(r.pushRetries[attempt-1])
 		}
 
 		err := r.repo.Push(&git.PushOptions{
 			RemoteName: "origin",
 			RefSpecs: []config.RefSpec{
-				config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", r.branch, r.branch)),
+				config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch)),
 			},
 			Auth: r.auth,
 		})
 
 		if err == nil {
-			slog.Info("Successfully pushed to origin", "branch", r.branch)
+			slog.Info("Successfully pushed to origin", "branch", branch)
 			return nil
 		}
 
 		lastErr = err
-		
+
 		// Don't retry certain errors
 		if err == git.NoErrAlreadyUpToDate {
 			slog.Info("Repository already up to date")
 			return nil
 		}
+		if isPushAuthError(err) {
+			return fmt.Errorf("pushing to origin: %w", err)
+		}
+		if isPushConflict(err) {
+			if !r.rebaseOnConflict {
+				return fmt.Errorf("push rejected, origin has commits the %s branch lacks: %w", branch, err)
+			}
+			slog.Warn("Push rejected, rebasing onto origin", "branch", branch, "error", err)
+			if err := r.rebaseOnOrigin(branch); err != nil {
+				return fmt.Errorf("rebasing onto origin after rejected push: %w", err)
+			}
+		}
 	}
 
 	return fmt.Errorf("failed to push after retries: %w", lastErr)
 }
 
+// pushBranch returns the branch Push sends to origin
+func (r *Repository) pushBranch() (string, error) {
+	if r.branch != "" {
+		return r.branch, nil
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("getting HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is detached, no branch to push")
+	}
+	return head.Name().Short(), nil
+}
+
 // showDiff shows what changes would be made (for dry-run mode)
 func (r *Repository) showDiff() error {
 	worktree, err := r.repo.Worktree()
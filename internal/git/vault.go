@@ -0,0 +1,116 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// remoteVaultPrefix marks a vault location as a git remote rather than a path
+const remoteVaultPrefix = "git+"
+
+// remoteVaultSchemes are the transports a remote vault can be cloned over
+var remoteVaultSchemes = []string{"ssh://", "https://", "http://", "file://"}
+
+// IsRemoteVault reports whether a vault location is a git remote URL such as
+// git+ssh://git@host/user/vault.git or git+https://host/user/vault.git
+func IsRemoteVault(location string) bool {
+	if !strings.HasPrefix(location, remoteVaultPrefix) {
+		return false
+	}
+	for _, scheme := range remoteVaultSchemes {
+		if strings.HasPrefix(location, remoteVaultPrefix+scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL hides credentials in a vault URL for logs and errors: the
+// password of user:token@ and, for HTTP remotes, a lone token@ username
+func RedactURL(location string) string {
+	parsed, err := url.Parse(location)
+	if err != nil || parsed.User == nil {
+		return location
+	}
+	if _, hasPassword := parsed.User.Password(); !hasPassword && strings.Contains(parsed.Scheme, "http") {
+		parsed.User = url.User("xxxxx")
+	}
+	return parsed.Redacted()
+}
+
+// VaultCheckout is a local clone of a vault kept in a git remote
+type VaultCheckout struct {
+	repo     *git.Repository
+	url      string
+	redacted string // url without credentials, for logs and errors
+	path     string
+	auth     transport.AuthMethod
+}
+
+// OpenVault opens the checkout of a remote vault at path, cloning it first
+// when it isn't there yet. SSH remotes use the same keys as the Hugo
+// repository; HTTPS credentials can be given in the URL.
+func OpenVault(location, path string) (*VaultCheckout, error) {
+	url := strings.TrimPrefix(location, remoteVaultPrefix)
+
+	v := &VaultCheckout{url: url, redacted: RedactURL(url), path: path}
+	if strings.HasPrefix(url, "ssh://") {
+		v.auth = setupAuth("")
+	}
+
+	repo, err := git.PlainOpen(path)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		slog.Info("Cloning remote vault", "url", v.redacted, "path", path)
+		repo, err = git.PlainClone(path, false, &git.CloneOptions{
+			URL:  url,
+			Auth: v.auth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cloning vault %s: %w", v.redacted, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("opening vault checkout %s: %w", path, err)
+	}
+
+	v.repo = repo
+	return v, nil
+}
+
+// Pull fast-forwards the checkout to the remote's current state and reports
+// whether anything changed
+func (v *VaultCheckout) Pull() (bool, error) {
+	worktree, err := v.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("getting worktree: %w", err)
+	}
+
+	// The checkout only mirrors the remote; local edits, such as the noteUid
+	// write-backs of earlier versions, would make every pull fail
+	if err := worktree.Reset(&git.ResetOptions{Mode: git.HardReset}); err != nil {
+		return false, fmt.Errorf("resetting vault checkout: %w", err)
+	}
+
+	err = worktree.Pull(&git.PullOptions{
+		RemoteName: "origin",
+		Auth:       v.auth,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("pulling vault %s: %w", v.redacted, err)
+	}
+
+	head, err := v.repo.Head()
+	if err != nil {
+		return true, fmt.Errorf("getting HEAD: %w", err)
+	}
+	slog.Info("Pulled remote vault", "url", v.redacted, "commit", head.Hash().String())
+	return true, nil
+}
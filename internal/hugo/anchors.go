@@ -0,0 +1,150 @@
+package hugo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// noteHeading is an ATX heading of a note with the ID Hugo gives it
+type noteHeading struct {
+	level int
+	text  string
+	id    string
+}
+
+// Patterns for reducing heading markdown to the text Goldmark derives IDs from
+var (
+	atxHeadingRegex       = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	headingImageRegex     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	headingLinkRegex      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	headingWikiLinkRegex  = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	headingHTMLRegex      = regexp.MustCompile(`<[^>]+>`)
+	headingUnderscoreEmph = regexp.MustCompile(`(^|[^\pL\pN_])_+([^_]+?)_+([^\pL\pN_]|$)`)
+)
+
+// anchorID converts a heading into the ID Hugo's Goldmark renderer generates
+// for it with the default "github" heading IDs: markdown is reduced to its
+// text, letters are lowercased, spaces and hyphens become hyphens, letters,
+// digits and underscores are kept and everything else is dropped
+func anchorID(heading string) string {
+	var sb strings.Builder
+	for _, r := range headingText(heading) {
+		switch {
+		case r == ' ' || r == '-':
+			sb.WriteRune('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// headingText reduces heading markdown to the plain text Goldmark sees,
+// keeping link and image text. Other markup characters need no handling as
+// anchorID drops them anyway, except underscores around emphasized words.
+func headingText(heading string) string {
+	text := headingImageRegex.ReplaceAllString(heading, "$1")
+	text = headingWikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := headingWikiLinkRegex.FindStringSubmatch(match)
+		if parts[2] != "" {
+			return parts[2]
+		}
+		return parts[1]
+	})
+	text = headingLinkRegex.ReplaceAllString(text, "$1")
+	text = headingHTMLRegex.ReplaceAllString(text, "")
+	text = headingUnderscoreEmph.ReplaceAllString(text, "$1$2$3")
+	return strings.TrimSpace(text)
+}
+
+// anchorIDs hands out heading IDs the way Goldmark does within a page: an ID
+// already taken gets the first free -1, -2, ... suffix, and a heading without
+// any usable characters becomes "heading"
+type anchorIDs map[string]bool
+
+// next returns the ID for the next heading of the page
+func (ids anchorIDs) next(heading string) string {
+	id := anchorID(heading)
+	if id == "" {
+		id = "heading"
+	}
+	if ids[id] {
+		for i := 1; ; i++ {
+			candidate := id + "-" + strconv.Itoa(i)
+			if !ids[candidate] {
+				id = candidate
+				break
+			}
+		}
+	}
+	ids[id] = true
+	return id
+}
+
+// noteHeadings returns the ATX headings of a note body in order with their
+// Hugo IDs, skipping fenced code blocks
+func noteHeadings(content string) []noteHeading {
+	var headings []noteHeading
+	ids := anchorIDs{}
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		
+		matches := atxHeadingRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		headings = append(headings, noteHeading{
+			level: len(matches[1]),
+			text:  matches[2],
+			id:    ids.next(matches[2]),
+		})
+	}
+	return headings
+}
+
+// headingFragment converts an Obsidian heading reference such as "Setup" or
+// "Setup#Install" into a link fragment. With the target note's headings at
+// hand, each segment is matched in document order, so a repeated heading
+// gets its disambiguated ID; otherwise the last segment's ID is used, which
+// is the heading's first occurrence.
+func headingFragment(reference string, headings []noteHeading) string {
+	var segments []string
+	for _, segment := range strings.Split(reference, "#") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	
+	pos, id := 0, ""
+	for _, segment := range segments {
+		want := anchorID(segment)
+		id = ""
+		for ; pos < len(headings); pos++ {
+			if anchorID(headings[pos].text) == want {
+				id = headings[pos].id
+				pos++
+				break
+			}
+		}
+		if id == "" {
+			return anchorID(segments[len(segments)-1])
+		}
+	}
+	return id
+}
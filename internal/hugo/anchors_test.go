@@ -0,0 +1,84 @@
+package hugo
+
+import (
+	"testing"
+)
+
+func TestAnchorID(t *testing.T) {
+	tests := []struct {
+		heading  string
+		expected string
+	}{
+		{"Hello World", "hello-world"},
+		{"FAQ: Common Issues?", "faq-common-issues"},
+		{"Use `go test` now", "use-go-test-now"},
+		{"What's *new* in v1.2", "whats-new-in-v12"},
+		{"snake_case names", "snake_case-names"},
+		{"_Emphasis_ here", "emphasis-here"},
+		{"A -- B", "a----b"},
+		{"Über Café", "über-café"},
+		{"[Docs](https://example.com) and [[Setup|setup]]", "docs-and-setup"},
+		{"<span>Tagged</span> heading", "tagged-heading"},
+		{"日本語 タイトル", "日本語-タイトル"},
+		{"  Padded  ", "padded"},
+		{"!!!", ""},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			if result := anchorID(tt.heading); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNoteHeadingsDisambiguatesDuplicates(t *testing.T) {
+	content := "# Setup\n\nText\n\n## Setup\n\n```md\n# Setup\n```\n\n### Setup 1\n\n## Setup ##\n\n#notaheading\n\n## ???\n\n## ???\n"
+	expected := []string{"setup", "setup-1", "setup-1-1", "setup-2", "heading", "heading-1"}
+	
+	headings := noteHeadings(content)
+	if len(headings) != len(expected) {
+		t.Fatalf("Expected %d headings, got %+v", len(expected), headings)
+	}
+	for i, id := range expected {
+		if headings[i].id != id {
+			t.Errorf("Expected heading %d to get %q, got %q", i, id, headings[i].id)
+		}
+	}
+}
+
+func TestHeadingFragment(t *testing.T) {
+	headings := noteHeadings("# Install\n## Linux\n# Upgrade\n## Linux\n")
+	
+	tests := []struct {
+		name      string
+		reference string
+		headings  []noteHeading
+		expected  string
+	}{
+		{name: "single heading", reference: "Linux", headings: headings, expected: "linux"},
+		{name: "nested path to a repeated heading", reference: "Upgrade#Linux", headings: headings, expected: "linux-1"},
+		{name: "unknown path falls back", reference: "Remove#Linux", headings: headings, expected: "linux"},
+		{name: "other note", reference: "Upgrade#Linux", expected: "linux"},
+		{name: "empty", reference: "#", expected: ""},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := headingFragment(tt.reference, tt.headings); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSameNoteLinkToRepeatedHeading(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	content := "See [[#Upgrade#Linux|upgrading on Linux]].\n\n# Install\n## Linux\n# Upgrade\n## Linux\n"
+	expected := "See [upgrading on Linux](#linux-1).\n\n# Install\n## Linux\n# Upgrade\n## Linux\n"
+	if result := generator.convertContent(content, ""); result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
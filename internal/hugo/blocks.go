@@ -0,0 +1,156 @@
+package hugo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// literalFence is the info string of fenced blocks whose Hugo shortcodes are
+// shown as written instead of rendered, for notes documenting shortcode syntax
+const literalFence = "hugo-literal"
+
+// shortcodeRegex matches a Hugo shortcode call in either delimiter style
+var shortcodeRegex = regexp.MustCompile(`\{\{([<%])(.*?)([>%])\}\}`)
+
+// SetMermaidShortcode wraps ```mermaid fences in the named shortcode ("" leaves them as code)
+func (g *Generator) SetMermaidShortcode(name string) {
+	g.mermaidShortcode = name
+}
+
+// SetMathShortcode wraps $$ display math blocks in the named shortcode ("" leaves them as-is)
+func (g *Generator) SetMathShortcode(name string) {
+	g.mathShortcode = name
+}
+
+// transformBlocks rewrites mermaid fences and display math into the configured
+// theme shortcodes and turns hugo-literal fences into plain code blocks with
+// their shortcodes escaped. It walks the content line by line so other fenced
+// code is copied verbatim and $$ inside code is never treated as math.
+func (g *Generator) transformBlocks(content string) string {
+	if g.mermaidShortcode == "" && g.mathShortcode == "" && !strings.Contains(content, literalFence) {
+		return content
+	}
+	
+	lines := strings.Split(content, "\n")
+	result := make([]string, 0, len(lines))
+	
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		
+		// Fenced code block: transform mermaid, copy anything else untouched
+		if fence := fenceMarker(trimmed); fence != "" {
+			end := i + 1
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), fence) {
+				end++
+			}
+			if end == len(lines) {
+				// Unterminated fence runs to the end of the document
+				result = append(result, lines[i:]...)
+				break
+			}
+			
+			info := strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			if g.mermaidShortcode != "" && strings.EqualFold(info, "mermaid") {
+				result = append(result, shortcodeOpen(g.mermaidShortcode))
+				result = append(result, lines[i+1:end]...)
+				result = append(result, shortcodeClose(g.mermaidShortcode))
+			} else if language, ok := literalFenceLanguage(info); ok {
+				// Keep the fence's indentation and any language after the marker
+				indent := line[:strings.Index(line, fence)]
+				result = append(result, strings.TrimRight(indent+fence+language, " "))
+				for _, literal := range lines[i+1 : end] {
+					result = append(result, escapeShortcodes(literal))
+				}
+				result = append(result, lines[end])
+			} else {
+				result = append(result, lines[i:end+1]...)
+			}
+			i = end
+			continue
+		}
+		
+		// Display math block starting with $$
+		if g.mathShortcode != "" && strings.HasPrefix(trimmed, "$$") {
+			rest := strings.TrimPrefix(trimmed, "$$")
+			
+			// Single-line block: $$ x^2 $$
+			if idx := strings.Index(rest, "$$"); idx >= 0 {
+				result = append(result, shortcodeOpen(g.mathShortcode), strings.TrimSpace(rest[:idx]), shortcodeClose(g.mathShortcode))
+				continue
+			}
+			
+			end := i + 1
+			for end < len(lines) && !strings.Contains(lines[end], "$$") {
+				end++
+			}
+			if end == len(lines) {
+				result = append(result, line)
+				continue
+			}
+			
+			body := []string{}
+			if strings.TrimSpace(rest) != "" {
+				body = append(body, strings.TrimSpace(rest))
+			}
+			body = append(body, lines[i+1:end]...)
+			if last := strings.TrimSpace(lines[end][:strings.Index(lines[end], "$$")]); last != "" {
+				body = append(body, last)
+			}
+			
+			result = append(result, shortcodeOpen(g.mathShortcode))
+			result = append(result, body...)
+			result = append(result, shortcodeClose(g.mathShortcode))
+			i = end
+			continue
+		}
+		
+		result = append(result, line)
+	}
+	
+	return strings.Join(result, "\n")
+}
+
+// literalFenceLanguage reports whether a fence's info string marks a
+// hugo-literal block and returns the language given after the marker, if any
+// ("hugo-literal markdown" is highlighted as markdown)
+func literalFenceLanguage(info string) (string, bool) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 || fields[0] != literalFence {
+		return "", false
+	}
+	return strings.Join(fields[1:], " "), true
+}
+
+// escapeShortcodes rewrites Hugo shortcode calls into their comment form, so
+// {{< youtube id >}} is displayed as written instead of rendered. Calls that
+// are already escaped are left alone.
+func escapeShortcodes(text string) string {
+	return shortcodeRegex.ReplaceAllStringFunc(text, func(call string) string {
+		match := shortcodeRegex.FindStringSubmatch(call)
+		inner := match[2]
+		if strings.HasPrefix(strings.TrimSpace(inner), "/*") {
+			return call
+		}
+		return "{{" + match[1] + "/*" + inner + "*/" + match[3] + "}}"
+	})
+}
+
+// fenceMarker returns the fence (``` or ~~~, possibly longer) opening a code block line
+func fenceMarker(line string) string {
+	for _, char := range []string{"`", "~"} {
+		if strings.HasPrefix(line, char+char+char) {
+			return strings.Repeat(char, len(line)-len(strings.TrimLeft(line, char)))
+		}
+	}
+	return ""
+}
+
+func shortcodeOpen(name string) string {
+	return fmt.Sprintf("{{< %s >}}", name)
+}
+
+func shortcodeClose(name string) string {
+	return fmt.Sprintf("{{< /%s >}}", name)
+}
@@ -0,0 +1,100 @@
+package hugo
+
+import (
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestTransformMermaidBlock(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetMermaidShortcode("mermaid")
+	
+	note := &vault.Note{
+		Path:    "/vault/guides/flow.md",
+		UID:     "mermaid-uid-123",
+		Content: "Flow:\n\n```mermaid\ngraph TD\n  A[[Start]] --> B\n```\n\n```go\nfmt.Println(\"$$\")\n```\n",
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	// Mermaid's [[subroutine]] shape must not be treated as a wikilink
+	expected := "Flow:\n\n{{< mermaid >}}\ngraph TD\n  A[[Start]] --> B\n{{< /mermaid >}}\n\n```go\nfmt.Println(\"$$\")\n```\n"
+	if hugoContent.Content != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, hugoContent.Content)
+	}
+	
+	// Without a shortcode the fence is left alone
+	plain := NewGenerator("/vault", "content/docs", "relref", "text")
+	if result := plain.transformBlocks("```mermaid\ngraph TD\n```"); result != "```mermaid\ngraph TD\n```" {
+		t.Errorf("Expected mermaid fence unchanged, got %q", result)
+	}
+}
+
+func TestTransformMathBlock(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetMathShortcode("katex")
+	
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "multi-line block",
+			input:    "Euler:\n$$\ne^{i\\pi} + 1 = 0\n$$\nDone",
+			expected: "Euler:\n{{< katex >}}\ne^{i\\pi} + 1 = 0\n{{< /katex >}}\nDone",
+		},
+		{
+			name:     "single-line block",
+			input:    "$$ x^2 $$",
+			expected: "{{< katex >}}\nx^2\n{{< /katex >}}",
+		},
+		{
+			name:     "math inside code untouched",
+			input:    "```\n$$ x $$\n```",
+			expected: "```\n$$ x $$\n```",
+		},
+		{
+			name:     "unterminated block untouched",
+			input:    "$$\nx",
+			expected: "$$\nx",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := generator.transformBlocks(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLiteralShortcodeBlock(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	note := &vault.Note{
+		Path: "/vault/guides/shortcodes.md",
+		UID:  "literal-uid-123",
+		Content: "{{< youtube dQw4w9WgXcQ >}}\n\n" +
+			"```hugo-literal\n{{< figure src=\"a.png\" >}}\n{{% notice %}}[[Note]]{{% /notice %}}\n{{</* ref \"x\" */>}}\n```\n\n" +
+			"  ~~~~hugo-literal markdown\n  {{< youtube id >}}\n  ~~~~\n",
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	// Real shortcodes pass through; literal blocks show theirs as written
+	expected := "{{< youtube dQw4w9WgXcQ >}}\n\n" +
+		"```\n{{</* figure src=\"a.png\" */>}}\n{{%/* notice */%}}[[Note]]{{%/* /notice */%}}\n{{</* ref \"x\" */>}}\n```\n\n" +
+		"  ~~~~markdown\n  {{</* youtube id */>}}\n  ~~~~\n"
+	if hugoContent.Content != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, hugoContent.Content)
+	}
+}
@@ -0,0 +1,53 @@
+package hugo
+
+import (
+	"fmt"
+)
+
+// ConvertOptions configures a standalone Convert call
+type ConvertOptions struct {
+	// SlugMap maps wikilink targets (note filenames or titles) to Hugo paths
+	// such as "content/docs/guides/setup.md" or content-relative paths such as
+	// "docs/guides/setup". Unmapped targets are treated as unpublished.
+	// Vault-relative file paths such as "guides/Setup.md" resolve markdown links.
+	SlugMap map[string]string
+
+	ContentDir      string // Hugo content directory (default "content/docs")
+	LinkFormat      string // "relref" (default) or "md"
+	BaseURL         string // Site base URL or path prefixed to md links, e.g. "/repo/"
+	UnpublishedLink string // "text" (default) or "hash"
+	NoteUID         string // Used to namespace footnote labels; empty leaves them as-is
+	NoteDir         string // Vault-relative folder of the note, for relative .md links
+}
+
+// Convert runs the note body conversion pipeline used by the daemon (footnotes,
+// wikilinks and shortcode escaping) on input and returns the Hugo markdown.
+// It does not touch the filesystem and is safe to use from third-party tooling.
+func Convert(input string, opts ConvertOptions) (string, error) {
+	if opts.ContentDir == "" {
+		opts.ContentDir = "content/docs"
+	}
+	if opts.LinkFormat == "" {
+		opts.LinkFormat = "relref"
+	}
+	if opts.UnpublishedLink == "" {
+		opts.UnpublishedLink = "text"
+	}
+
+	if opts.LinkFormat != "relref" && opts.LinkFormat != "md" {
+		return "", fmt.Errorf("link format must be 'relref' or 'md', got %q", opts.LinkFormat)
+	}
+	if opts.UnpublishedLink != "text" && opts.UnpublishedLink != "hash" && opts.UnpublishedLink != "stub" {
+		return "", fmt.Errorf("unpublished link must be 'text', 'hash' or 'stub', got %q", opts.UnpublishedLink)
+	}
+
+	g := NewGenerator("", opts.ContentDir, opts.LinkFormat, opts.UnpublishedLink)
+	g.SetBaseURL(opts.BaseURL)
+	for target, hugoPath := range opts.SlugMap {
+		g.slugMap[target] = g.contentRelativePath(hugoPath)
+	}
+
+	g.linkBase = slashPath(opts.NoteDir)
+
+	return g.convertContent(input, opts.NoteUID), nil
+}
@@ -0,0 +1,65 @@
+package hugo
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	slugMap := map[string]string{
+		"Setup":       "content/docs/guides/setup.md",
+		"Setup Guide": "docs/guides/setup",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     ConvertOptions
+		expected string
+	}{
+		{
+			name:     "relref link by default",
+			input:    "See [[Setup]].",
+			opts:     ConvertOptions{SlugMap: slugMap},
+			expected: `See [Setup]({{< relref "docs/guides/setup" >}}).`,
+		},
+		{
+			name:     "md link with display text",
+			input:    "See [[Setup Guide|the guide]].",
+			opts:     ConvertOptions{SlugMap: slugMap, LinkFormat: "md"},
+			expected: "See [the guide](/docs/guides/setup/).",
+		},
+		{
+			name:     "unpublished link as hash",
+			input:    "See [[Missing]].",
+			opts:     ConvertOptions{UnpublishedLink: "hash"},
+			expected: "See [Missing](#).",
+		},
+		{
+			name:     "code is left untouched",
+			input:    "`[[Setup]]`",
+			opts:     ConvertOptions{SlugMap: slugMap},
+			expected: "`[[Setup]]`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertInvalidOptions(t *testing.T) {
+	if _, err := Convert("text", ConvertOptions{LinkFormat: "html"}); err == nil {
+		t.Error("Expected error for invalid link format")
+	}
+	if _, err := Convert("text", ConvertOptions{UnpublishedLink: "drop"}); err == nil {
+		t.Error("Expected error for invalid unpublished link handling")
+	}
+}
@@ -0,0 +1,116 @@
+package hugo
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SummaryMarker is Hugo's manual summary divider, kept in content as-is
+const SummaryMarker = "<!--more-->"
+
+// SetAutoDescription derives a description of up to maxLength characters from
+// the first paragraph of notes without one (0 disables)
+func (g *Generator) SetAutoDescription(maxLength int) {
+	g.descriptionLength = maxLength
+}
+
+// Patterns for reducing markdown to plain text
+var (
+	descImageRegex      = regexp.MustCompile(`!\[\[[^\]]*\]\]|!\[[^\]]*\]\([^)]*\)`)
+	descWikiLinkRegex   = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	descLinkRegex       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	descFootnoteRegex   = regexp.MustCompile(`\[\^[^\]]*\]|\^\[[^\]]*\]`)
+	descHTMLRegex       = regexp.MustCompile(`<[^>]+>`)
+	descEmphasisRegex   = regexp.MustCompile("[*_~=`]+")
+	descWhitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// noteDescription returns the author's description from front-matter, or one
+// derived from the body when auto-description is enabled
+func (g *Generator) noteDescription(frontMatter map[string]interface{}, body string) string {
+	if description, ok := frontMatter["description"].(string); ok && strings.TrimSpace(description) != "" {
+		return strings.TrimSpace(description)
+	}
+	if g.descriptionLength <= 0 {
+		return ""
+	}
+	return truncateDescription(plainText(firstParagraph(body)), g.descriptionLength)
+}
+
+// firstParagraph returns the first block of prose in a note body, skipping
+// headings, code, tables, quotes and callouts, rules and lines that hold
+// only an image. Text after a summary marker is never considered.
+func firstParagraph(body string) string {
+	if idx := strings.Index(body, SummaryMarker); idx >= 0 {
+		body = body[:idx]
+	}
+	
+	var paragraph []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+		
+		skip := trimmed == "" ||
+			strings.HasPrefix(trimmed, "#") && (len(trimmed) == 1 || strings.HasPrefix(strings.TrimLeft(trimmed, "#"), " ")) ||
+			strings.HasPrefix(trimmed, "|") ||
+			strings.HasPrefix(trimmed, ">") ||
+			strings.HasPrefix(trimmed, "$$") ||
+			strings.HasPrefix(trimmed, "<!--") ||
+			strings.Trim(trimmed, "-*_ ") == "" ||
+			strings.TrimSpace(descImageRegex.ReplaceAllString(trimmed, "")) == ""
+		if skip {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		
+		paragraph = append(paragraph, trimmed)
+	}
+	
+	return strings.Join(paragraph, " ")
+}
+
+// plainText strips markdown syntax, keeping link and wikilink text
+func plainText(markdown string) string {
+	text := descImageRegex.ReplaceAllString(markdown, "")
+	text = descWikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := descWikiLinkRegex.FindStringSubmatch(match)
+		if parts[2] != "" {
+			return parts[2]
+		}
+		return parts[1]
+	})
+	text = descLinkRegex.ReplaceAllString(text, "$1")
+	text = descFootnoteRegex.ReplaceAllString(text, "")
+	text = descHTMLRegex.ReplaceAllString(text, "")
+	text = descEmphasisRegex.ReplaceAllString(text, "")
+	return strings.TrimSpace(descWhitespaceRegex.ReplaceAllString(text, " "))
+}
+
+// truncateDescription shortens text to at most maxLength characters at a word
+// boundary, marking the cut with an ellipsis
+func truncateDescription(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	
+	runes := []rune(text)
+	cut := string(runes[:maxLength-1])
+	if idx := strings.LastIndex(cut, " "); idx > 0 {
+		cut = cut[:idx]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
@@ -0,0 +1,95 @@
+package hugo
+
+import (
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestNoteDescription(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetAutoDescription(60)
+	
+	tests := []struct {
+		name        string
+		frontMatter map[string]interface{}
+		body        string
+		expected    string
+	}{
+		{
+			name:     "first paragraph",
+			body:     "# Setup\n\n![[diagram.png]]\n\nInstall the **CLI** with [[Tools/Homebrew|brew]] and\nrun `init` once.\n\nSecond paragraph.\n",
+			expected: "Install the CLI with brew and run init once.",
+		},
+		{
+			name:     "skips code and callouts",
+			body:     "```bash\necho hi\n```\n\n> [!note] Draft\n> Not this\n\nSee the [guide](https://example.com)[^1].\n",
+			expected: "See the guide.",
+		},
+		{
+			name:     "truncated at a word boundary",
+			body:     "This paragraph is deliberately long so that it runs past the configured sixty character limit.\n",
+			expected: "This paragraph is deliberately long so that it runs past…",
+		},
+		{
+			name:        "explicit description wins",
+			frontMatter: map[string]interface{}{"description": "Written by the author"},
+			body:        "Derived text\n",
+			expected:    "Written by the author",
+		},
+		{
+			name:     "only text before the summary marker",
+			body:     "# Title\n\n<!--more-->\n\nAfter the fold\n",
+			expected: "",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := generator.noteDescription(tt.frontMatter, tt.body); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAutoDescriptionDisabled(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	if result := generator.noteDescription(nil, "Some body text\n"); result != "" {
+		t.Errorf("Expected no derived description when disabled, got %q", result)
+	}
+	
+	explicit := map[string]interface{}{"description": "Kept"}
+	if result := generator.noteDescription(explicit, "Some body text\n"); result != "Kept" {
+		t.Errorf("Expected explicit description when disabled, got %q", result)
+	}
+}
+
+func TestGenerateContentKeepsSummaryMarker(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetAutoDescription(160)
+	
+	note := &vault.Note{
+		Path:    "/vault/guides/Intro.md",
+		UID:     "uid-1",
+		Title:   "Intro",
+		Content: "Short summary of the page.\n\n<!--more-->\n\nThe rest of the page.\n",
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 10)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	if hugoContent.Description != "Short summary of the page." {
+		t.Errorf("Expected derived description, got %q", hugoContent.Description)
+	}
+	if !strings.Contains(hugoContent.Content, "\n<!--more-->\n") {
+		t.Errorf("Expected summary marker to be kept, got:\n%s", hugoContent.Content)
+	}
+	if !strings.Contains(hugoContent.Serialize(), "description: \"Short summary of the page.\"\n") {
+		t.Errorf("Expected description in front-matter, got:\n%s", hugoContent.Serialize())
+	}
+}
@@ -0,0 +1,89 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// SetDateFromFilename reads a leading date in the given Go time layout, such
+// as "2006-01-02", from note filenames: it becomes the page's date unless the
+// note sets one, and is dropped from the slug ("" disables)
+func (g *Generator) SetDateFromFilename(layout string) {
+	g.dateLayout = layout
+}
+
+// ParseDatePrefix splits a leading date in the given layout from a note name,
+// e.g. "2024-03-15-my-post" becomes 15 March 2024 and "my-post". The date must
+// be followed by a space, dot, underscore or hyphen and some remaining text.
+func ParseDatePrefix(name, layout string) (time.Time, string, bool) {
+	if layout == "" {
+		return time.Time{}, name, false
+	}
+	
+	// Layouts like "January" don't have a fixed width, so take the longest
+	// prefix that parses and is followed by a separator
+	for end := len(name) - 1; end > 0; end-- {
+		if !strings.ContainsRune(" ._-", rune(name[end])) {
+			continue
+		}
+		date, err := time.Parse(layout, name[:end])
+		if err != nil {
+			continue
+		}
+		rest := strings.TrimLeft(name[end:], " ._-")
+		if rest == "" {
+			return time.Time{}, name, false
+		}
+		return date, rest, true
+	}
+	return time.Time{}, name, false
+}
+
+// ValidDateLayout reports whether a Go time layout holds a full date: year, month and day
+func ValidDateLayout(layout string) bool {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, date.Format(layout))
+	return err == nil && parsed.Equal(date)
+}
+
+// filenameDate returns the date in a note's filename when the note doesn't set one
+func (g *Generator) filenameDate(name string, frontMatter map[string]interface{}) time.Time {
+	if _, ok := frontMatter["date"]; ok {
+		return time.Time{}
+	}
+	date, _, _ := ParseDatePrefix(name, g.dateLayout)
+	return date
+}
+
+// undatedFilename drops a leading date from a note's filename before it is
+// slugged. The date stays when another note in the folder has the same name
+// apart from its date, as with a weekly post, so their pages don't collide.
+func (g *Generator) undatedFilename(notePath, filename, noteUID string) string {
+	ext := vault.MarkdownExtension(filename, g.noteExtensions)
+	_, rest, ok := ParseDatePrefix(strings.TrimSuffix(filename, ext), g.dateLayout)
+	if !ok {
+		return filename
+	}
+	slug := g.createSlug(rest+ext, noteUID)
+
+	entries, _ := os.ReadDir(filepath.Dir(notePath))
+	for _, entry := range entries {
+		other := entry.Name()
+		otherExt := vault.MarkdownExtension(other, g.noteExtensions)
+		if other == filename || entry.IsDir() || otherExt == "" {
+			continue
+		}
+		otherName := strings.TrimSuffix(other, otherExt)
+		if _, otherRest, dated := ParseDatePrefix(otherName, g.dateLayout); dated {
+			otherName = otherRest
+		}
+		if g.createSlug(otherName+otherExt, noteUID) == slug {
+			return filename
+		}
+	}
+	return rest + ext
+}
@@ -0,0 +1,113 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestParseDatePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		date     time.Time
+		rest     string
+		expected bool
+	}{
+		{"2024-03-15-my-post", "2006-01-02", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "my-post", true},
+		{"2024-03-15 My Post", "2006-01-02", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "My Post", true},
+		{"20240315_my_post", "20060102", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "my_post", true},
+		{"15 March 2024 - Trip", "2 January 2006", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "Trip", true},
+		{"2024-03-15", "2006-01-02", time.Time{}, "2024-03-15", false},
+		{"2024-13-45-bad", "2006-01-02", time.Time{}, "2024-13-45-bad", false},
+		{"My Post", "2006-01-02", time.Time{}, "My Post", false},
+		{"2024-03-15-my-post", "", time.Time{}, "2024-03-15-my-post", false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, rest, ok := ParseDatePrefix(tt.name, tt.layout)
+			if ok != tt.expected || !date.Equal(tt.date) || rest != tt.rest {
+				t.Errorf("Expected (%v, %q, %v), got (%v, %q, %v)", tt.date, tt.rest, tt.expected, date, rest, ok)
+			}
+		})
+	}
+}
+
+func TestDateFromFilename(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetDateFromFilename("2006-01-02")
+	
+	dated := &vault.Note{Path: "/vault/blog/2024-03-15-my-post.md", UID: "dated-uid", Title: "2024-03-15-my-post"}
+	hugoContent, err := generator.GenerateContent(dated, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if hugoContent.Path != "content/docs/blog/my-post.md" {
+		t.Errorf("Expected the date to be dropped from the slug, got %s", hugoContent.Path)
+	}
+	if hugoContent.Title != "my-post" {
+		t.Errorf("Expected the date to be dropped from the title, got %q", hugoContent.Title)
+	}
+	if !strings.Contains(hugoContent.Serialize(), "date: 2024-03-15T00:00:00Z\n") {
+		t.Errorf("Expected the filename date in front-matter, got:\n%s", hugoContent.Serialize())
+	}
+	
+	// A date of the note's own is kept over the filename's
+	dated.FrontMatter = map[string]interface{}{"date": "2024-04-01"}
+	hugoContent, _ = generator.GenerateContent(dated, 100)
+	if !hugoContent.Date.IsZero() {
+		t.Errorf("Expected no filename date when the note sets one, got %v", hugoContent.Date)
+	}
+	
+	plain := &vault.Note{Path: "/vault/blog/My Post.md", UID: "plain-uid", Title: "My Post"}
+	hugoContent, _ = generator.GenerateContent(plain, 100)
+	if hugoContent.Path != "content/docs/blog/my-post.md" || hugoContent.Title != "My Post" {
+		t.Errorf("Expected an undated note to be unaffected, got path %s and title %q", hugoContent.Path, hugoContent.Title)
+	}
+	if strings.Contains(hugoContent.Serialize(), "\ndate:") {
+		t.Errorf("Expected no date without one in the filename, got:\n%s", hugoContent.Serialize())
+	}
+}
+
+func TestDateFromFilenameKeepsCollidingDates(t *testing.T) {
+	vaultDir := t.TempDir()
+	blog := filepath.Join(vaultDir, "blog")
+	if err := os.MkdirAll(blog, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2024-01-01-weekly.md", "2024-01-08-weekly.md", "2024-02-01-launch.md"} {
+		if err := os.WriteFile(filepath.Join(blog, name), []byte("Body\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	generator.SetDateFromFilename("2006-01-02")
+	
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"2024-01-01-weekly.md", "content/docs/blog/2024-01-01-weekly.md"},
+		{"2024-01-08-weekly.md", "content/docs/blog/2024-01-08-weekly.md"},
+		{"2024-02-01-launch.md", "content/docs/blog/launch.md"},
+	}
+	for _, tt := range tests {
+		note := &vault.Note{Path: filepath.Join(blog, tt.name), UID: "uid-" + tt.name, Title: vault.NoteName(tt.name)}
+		hugoContent, err := generator.GenerateContent(note, 100)
+		if err != nil {
+			t.Fatalf("Failed to generate content: %v", err)
+		}
+		if hugoContent.Path != tt.expected {
+			t.Errorf("Expected %s for %s, got %s", tt.expected, tt.name, hugoContent.Path)
+		}
+		if hugoContent.Date.IsZero() {
+			t.Errorf("Expected a date for %s", tt.name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package hugo
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// generatedKeys are the front-matter keys the generator derives itself. A
+// mapped key among them renames the generated field rather than copying the
+// note's property.
+var generatedKeys = map[string]bool{
+	"title":       true,
+	"description": true,
+	"weight":      true,
+	"draft":       true,
+	"publishDate": true,
+	"expiryDate":  true,
+	"lastmod":     true,
+	"wordCount":   true,
+	"readingTime": true,
+	"noteUid":     true,
+	"lastUpdated": true,
+	"tags":        true,
+	"aliases":     true,
+	"menu":        true,
+}
+
+// SetFrontMatterMap renames front-matter keys on output, e.g. "created" to
+// "date". Note properties named in the map are published under the new name;
+// all others are left out as before.
+func (g *Generator) SetFrontMatterMap(keys map[string]string) {
+	g.frontMatterMap = keys
+}
+
+// mappedProperties returns the note's properties named in the front-matter
+// map, sorted by key, under their Obsidian names. They are renamed when serialized.
+func (g *Generator) mappedProperties(frontMatter map[string]interface{}) []frontMatterField {
+	var fields []frontMatterField
+	for key := range g.frontMatterMap {
+		value, ok := frontMatter[key]
+		if !ok || value == nil || generatedKeys[key] {
+			continue
+		}
+		fields = append(fields, frontMatterField{key, value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// renameKeys applies the front-matter key map. A renamed key takes the place
+// of a generated field of the same name; when two renamed keys land on the
+// same name, the first is kept.
+func (hc *HugoContent) renameKeys(fields []frontMatterField) []frontMatterField {
+	if len(hc.KeyMap) == 0 {
+		return fields
+	}
+
+	sources := make(map[string]string) // New key -> key it was renamed from
+	values := make(map[string]interface{})
+	for _, field := range fields {
+		target, ok := hc.KeyMap[field.Key]
+		if !ok {
+			continue
+		}
+		if source, taken := sources[target]; taken {
+			slog.Warn("Front-matter keys map to the same key, keeping the first",
+				"path", hc.Path, "key", target, "kept", source, "dropped", field.Key)
+			continue
+		}
+		sources[target] = field.Key
+		values[target] = field.Value
+	}
+
+	renamed := make([]frontMatterField, 0, len(fields))
+	emitted := make(map[string]bool)
+	for _, field := range fields {
+		key := field.Key
+		if target, ok := hc.KeyMap[key]; ok {
+			if sources[target] != key {
+				continue
+			}
+			key = target
+		} else if _, replaced := sources[key]; !replaced {
+			renamed = append(renamed, field)
+			continue
+		}
+		if !emitted[key] {
+			renamed = append(renamed, frontMatterField{key, values[key]})
+			emitted[key] = true
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,56 @@
+package hugo
+
+import (
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestFrontMatterMap(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetFrontMatterMap(map[string]string{
+		"created": "date",
+		"updated": "date",
+		"summary": "description",
+		"tags":    "categories",
+	})
+
+	note := &vault.Note{
+		Path:  "/vault/guides/setup.md",
+		UID:   "setup-uid",
+		Title: "Setup",
+		Tags:  []string{"go"},
+		FrontMatter: map[string]interface{}{
+			"created":     "2024-01-02",
+			"updated":     "2024-03-04",
+			"summary":     "Short summary",
+			"description": "Long description",
+			"status":      "done",
+		},
+	}
+
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	serialized := hugoContent.Serialize()
+
+	for _, want := range []string{
+		"date: \"2024-01-02\"\n",
+		"description: \"Short summary\"\n",
+		"categories:\n  - \"go\"\n",
+	} {
+		if !strings.Contains(serialized, want) {
+			t.Errorf("Expected front-matter to contain %q, got:\n%s", want, serialized)
+		}
+	}
+	for _, unwanted := range []string{"created:", "updated:", "summary:", "tags:", "status:", "2024-03-04", "Long description"} {
+		if strings.Contains(serialized, unwanted) {
+			t.Errorf("Expected front-matter without %q, got:\n%s", unwanted, serialized)
+		}
+	}
+	if strings.Count(serialized, "description:") != 1 {
+		t.Errorf("Expected a single description, got:\n%s", serialized)
+	}
+}
@@ -1,85 +1,598 @@
 package hugo
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
 	"obsidian-hugo-sync/internal/vault"
 )
 
+// Supported front-matter formats for generated Hugo content
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// Handling of leading numeric filename prefixes such as "01 Introduction.md"
+const (
+	NumberPrefixKeep   = "keep"   // Prefix stays in slug and title, no effect on weight
+	NumberPrefixWeight = "weight" // Prefix drives the note weight within its folder
+	NumberPrefixStrip  = "strip"  // Prefix drives the weight and is removed from slug and title
+)
+
+// Case styles of generated slugs and URL folder segments
+const (
+	SlugStyleKebab    = "kebab"    // "My Note" becomes my-note
+	SlugStyleSnake    = "snake"    // "My Note" becomes my_note
+	SlugStylePreserve = "preserve" // "My Note" becomes My-Note
+)
+
+// Characters replaced when building slugs in each style
+var (
+	slugLowerRegex = regexp.MustCompile(`[^a-z0-9]+`)
+	slugMixedRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// StubPageName is the page, in the content directory, that unpublished links
+// point at with the "stub" unpublished-link mode
+const StubPageName = "unpublished.md"
+
 // Generator handles conversion from Obsidian notes to Hugo format
 type Generator struct {
-	vaultPath       string
-	contentDir      string
-	linkFormat      string
-	unpublishedLink string
-	slugMap         map[string]string // target -> hugo_path for link resolution
-	protectedContent map[string]string // placeholder -> original content for restoration
+	vaultPath         string
+	contentDir        string
+	routes            []sectionRoute // Vault folders with their own content directory, longest first
+	linkFormat        string
+	unpublishedLink   string
+	linkPrefix        string // Site base path for md links, e.g. "/repo"; empty for the domain root
+	frontMatterFormat string
+	timestampsUTC     bool
+	numberPrefix      string
+	slugStyle         string
+	maxSectionDepth   int                                        // Deepest section level below a content dir; 0 is unlimited
+	rootSection       string                                     // Section for vault-root notes; empty places them at the content root
+	mermaidShortcode  string                                     // Shortcode for mermaid fences; empty keeps them as code
+	mathShortcode     string                                     // Shortcode for $$ display math; empty keeps it as-is
+	stripPublishTag   bool                                       // Remove the inline publish tag from note bodies
+	descriptionLength int                                        // Max length of derived descriptions; 0 disables
+	emitDraft         bool                                       // Pass draft: true through to Hugo
+	readingStats      bool                                       // Emit wordCount and readingTime
+	readingWPM        int                                        // Reading speed for readingTime; 0 omits it
+	taskMetadata      string                                     // Tasks plugin metadata handling; empty keeps it
+	nestedTagMode     string                                     // Nested tag handling for emitted tags; empty keeps them
+	stripH1           string                                     // First H1 handling (keep, match or always); empty keeps it
+	imageURL          func(vaultImagePath string) string         // Site URL of a copied image; nil leaves image references as written
+	inlineSVG         func(vaultImagePath string) (string, bool) // Markup of an SVG to inline instead of referencing
+	lastmod           func(note *vault.Note) time.Time           // Hugo lastmod of a note; nil omits it
+	indexTemplate     *IndexTemplate                             // Template for section indexes; nil writes front-matter only
+	frontMatterMap    map[string]string                          // Obsidian key -> emitted front-matter key
+	noteExtensions    []string                                   // Extensions of vault notes; nil is .md only
+	indexNotes        bool                                       // Publish folder landing notes as section indexes
+	dateLayout        string                                     // Go layout of dates leading note filenames; "" disables
+	parseOptions      vault.ParseOptions                         // How notes the generator reads itself, like _folder.md, are parsed
+	slugMap           map[string]string                          // target -> hugo_path for link resolution
+	slugClaims        map[string]map[string]slugClaim            // target -> note UID -> claim on that target
+	protectedContent  map[string]string                          // placeholder -> original content for restoration
+	protectedOrder    []string                                   // placeholders in protection order, restored in reverse
+	linkBase          string                                     // Vault-relative folder of the note being converted
+	headings          []noteHeading                              // Headings of the note being converted, for same-note links
+	images            map[string]string                          // Image references of the note being converted -> vault path
+	unresolved        []string                                   // Link targets of the note being converted missing from the slug map
 }
 
 // NewGenerator creates a new Hugo content generator
 func NewGenerator(vaultPath, contentDir, linkFormat, unpublishedLink string) *Generator {
 	return &Generator{
-		vaultPath:        vaultPath,
-		contentDir:       contentDir,
-		linkFormat:       linkFormat,
-		unpublishedLink:  unpublishedLink,
-		slugMap:          make(map[string]string),
-		protectedContent: make(map[string]string),
+		vaultPath:         vaultPath,
+		contentDir:        contentDir,
+		linkFormat:        linkFormat,
+		unpublishedLink:   unpublishedLink,
+		frontMatterFormat: FormatYAML,
+		numberPrefix:      NumberPrefixKeep,
+		slugStyle:         SlugStyleKebab,
+		rootSection:       "posts",
+		slugMap:           make(map[string]string),
+		slugClaims:        make(map[string]map[string]slugClaim),
+		protectedContent:  make(map[string]string),
+	}
+}
+
+// SetFrontMatterFormat selects the front-matter format (yaml, toml or json) for generated content
+func (g *Generator) SetFrontMatterFormat(format string) {
+	g.frontMatterFormat = format
+}
+
+// SetTimestampsUTC normalizes all emitted front-matter dates to UTC when enabled
+func (g *Generator) SetTimestampsUTC(utc bool) {
+	g.timestampsUTC = utc
+}
+
+// SetNoteExtensions sets the file extensions of vault notes, such as ".md"
+// and ".markdown", stripped when building slugs and resolving links. Generated
+// Hugo files are .md whatever the note's extension.
+func (g *Generator) SetNoteExtensions(extensions []string) {
+	g.noteExtensions = extensions
+}
+
+// SetParseOptions reads the notes the generator loads itself, such as
+// _folder.md, the same way as the notes it is given (e.g. in their source encoding)
+func (g *Generator) SetParseOptions(opts vault.ParseOptions) {
+	g.parseOptions = opts
+}
+
+// SetNumberPrefix selects how leading numeric filename prefixes are handled (keep, weight or strip)
+func (g *Generator) SetNumberPrefix(mode string) {
+	g.numberPrefix = mode
+}
+
+// SetSlugStyle selects the case style (kebab, snake or preserve) of generated
+// file names and of the folder segments of links to them
+func (g *Generator) SetSlugStyle(style string) {
+	g.slugStyle = style
+}
+
+// SetMaxSectionDepth caps how many folder levels a note's section may nest
+// below its content directory; notes in deeper folders move up to the capped
+// section, with the folders they came from prefixed to their slug. 0 (the
+// default) leaves the hierarchy as-is.
+func (g *Generator) SetMaxSectionDepth(depth int) {
+	g.maxSectionDepth = depth
+}
+
+// SetLastmod sets how a note's Hugo lastmod is determined; nil (the default)
+// leaves lastmod out so Hugo falls back to its own defaults
+func (g *Generator) SetLastmod(lastmod func(note *vault.Note) time.Time) {
+	g.lastmod = lastmod
+}
+
+// SetEmitDraft passes a note's draft: true through to the generated front-matter
+func (g *Generator) SetEmitDraft(emit bool) {
+	g.emitDraft = emit
+}
+
+// SetBaseURL prefixes md links with the path of the site's base URL, e.g.
+// "https://user.github.io/repo/" or "/repo/" turns /guides/note/ into
+// /repo/guides/note/. relref links are resolved by Hugo and need no prefix.
+func (g *Generator) SetBaseURL(baseURL string) {
+	g.linkPrefix = basePath(baseURL)
+}
+
+// basePath returns the path of a base URL without its trailing slash, empty
+// for a site served from the domain root
+func basePath(baseURL string) string {
+	if parsed, err := url.Parse(baseURL); err == nil {
+		baseURL = parsed.Path
 	}
+	baseURL = strings.Trim(baseURL, "/")
+	if baseURL == "" {
+		return ""
+	}
+	return "/" + baseURL
+}
+
+// SetImageURL rewrites image references to the URL the given function returns
+// for the referenced vault image, for images copied outside the note's folder
+func (g *Generator) SetImageURL(imageURL func(vaultImagePath string) string) {
+	g.imageURL = imageURL
+}
+
+// SetInlineSVG replaces references to images for which the given function
+// returns markup, such as small SVG icons, with that markup
+func (g *Generator) SetInlineSVG(inlineSVG func(vaultImagePath string) (string, bool)) {
+	g.inlineSVG = inlineSVG
+}
+
+// SetRootSection selects the section vault-root notes are placed in ("" for the content root)
+func (g *Generator) SetRootSection(section string) {
+	g.rootSection = section
+}
+
+// ParseNumberPrefix splits a leading ordering number from a note name, e.g.
+// "01 Introduction" becomes 1 and "Introduction". The number must be followed
+// by a space, dot, underscore or hyphen and some remaining text.
+func ParseNumberPrefix(name string) (int, string, bool) {
+	matches := regexp.MustCompile(`^(\d+)[\s._-]+(\S.*)$`).FindStringSubmatch(name)
+	if matches == nil {
+		return 0, name, false
+	}
+	
+	number, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, name, false
+	}
+	return number, matches[2], true
 }
 
 // GenerateContent converts an Obsidian note to Hugo format
 func (g *Generator) GenerateContent(note *vault.Note, weight int) (*HugoContent, error) {
 	hugoPath := g.generateHugoPath(note.Path, note.UID)
 	
-	// Process wikilinks in content
-	processedContent := g.processWikiLinks(note.Content)
+	// Relative markdown links resolve against the note's own folder
+	g.linkBase = ""
+	if relNote, err := filepath.Rel(g.vaultPath, note.Path); err == nil {
+		g.linkBase = filepath.ToSlash(filepath.Dir(relNote))
+	}
+	
+	g.unresolved = nil
+	g.images = nil
+	if g.imageURL != nil || g.inlineSVG != nil {
+		g.images = make(map[string]string)
+		for _, ref := range note.ExtractImageReferences() {
+			g.images[ref.Target] = ref.Path
+		}
+	}
+	
+	// Titles derived from the filename lose their date and, when stripping,
+	// their ordering prefix
+	title := note.Title
+	if _, rest, ok := ParseDatePrefix(title, g.dateLayout); ok && title == vault.NoteName(note.Path) {
+		title = rest
+	}
+	if g.numberPrefix == NumberPrefixStrip && note.Title == vault.NoteName(note.Path) {
+		if _, rest, ok := ParseNumberPrefix(title); ok {
+			title = rest
+		}
+	}
 	
-	// Escape Hugo shortcodes with placeholder text
-	processedContent = g.escapeExampleShortcodes(processedContent)
+	// Index notes are titled and weighted like the section they stand for
+	if sectionDir, ok := g.indexNoteSection(note.Path); ok {
+		if _, hasTitle := note.FrontMatter["title"].(string); !hasTitle {
+			title = g.sectionTitle(sectionDir)
+		}
+		weight = CalculateFolderWeight(sectionDir)
+	}
+	
+	body := g.stripTitleHeading(note.Content, title, note.Title)
+	processedContent := g.convertContent(body, note.UID)
+	wordCount, readingTime := g.readingStatsFor(processedContent)
+	
+	var lastmod time.Time
+	if g.lastmod != nil {
+		lastmod = g.lastmod(note)
+	}
 	
+	weight = noteWeight(note.FrontMatter, weight)
 	content := &HugoContent{
-		Path:        hugoPath,
-		Title:       note.Title,
-		Content:     processedContent,
-		Weight:      weight,
-		NoteUID:     note.UID,
-		LastUpdated: time.Now(),
+		Path:          hugoPath,
+		Title:         title,
+		Description:   g.noteDescription(note.FrontMatter, note.Content),
+		Content:       processedContent,
+		Weight:        weight,
+		Draft:         g.emitDraft && note.Draft,
+		Date:          g.filenameDate(vault.NoteName(note.Path), note.FrontMatter),
+		PublishDate:   note.PublishDate,
+		ExpiryDate:    note.ExpiryDate,
+		Lastmod:       lastmod,
+		WordCount:     wordCount,
+		ReadingTime:   readingTime,
+		NoteUID:       note.UID,
+		Tags:          g.taxonomyTags(note.Tags),
+		Aliases:       dedupeStrings(note.Aliases),
+		Menu:          noteMenu(note.FrontMatter, note.Tags, weight),
+		Params:        g.mappedProperties(note.FrontMatter),
+		KeyMap:        g.frontMatterMap,
+		Unresolved:    dedupeStrings(g.unresolved),
+		LastUpdated:   time.Now(),
+		Format:        g.frontMatterFormat,
+		TimestampsUTC: g.timestampsUTC,
 	}
 	
 	return content, nil
 }
 
+// convertContent converts a note body to Hugo markdown
+func (g *Generator) convertContent(content, noteUID string) string {
+	g.headings = noteHeadings(content)
+	
+	// Drop the inline publish tag so it doesn't show on the page
+	processed := g.removePublishTag(content)
+	
+	// Strip or rewrite Tasks plugin metadata on checkbox items
+	processed = g.cleanTasks(processed)
+	
+	// Convert inline footnotes and namespace footnote labels per note
+	processed = g.processFootnotes(processed, noteUID)
+	
+	// Process wikilinks in content
+	processed = g.processWikiLinks(processed)
+	
+	// Rewrite mermaid and math blocks into theme shortcodes and escape the
+	// shortcodes in hugo-literal blocks (fences are intact again here)
+	return g.transformBlocks(processed)
+}
+
 // HugoContent represents processed content ready for Hugo
 type HugoContent struct {
 	Path        string
 	Title       string
+	Description string // Emitted only when non-empty
 	Content     string
 	Weight      int
+	Draft       bool      // Emitted only when true, so Hugo skips the page unless building drafts
+	Date        time.Time // Hugo date, emitted only when set
+	PublishDate time.Time // Hugo publishDate, emitted only when set
+	ExpiryDate  time.Time // Hugo expiryDate, emitted only when set
+	Lastmod     time.Time // Hugo lastmod, emitted only when set
+	WordCount   int       // Words of published prose, emitted only when positive
+	ReadingTime int       // Minutes to read, emitted only when positive
 	NoteUID     string
+	Tags        []string           // Hugo taxonomy terms, emitted only when non-empty
+	Aliases     []string           // Hugo redirect aliases, emitted only when non-empty
+	Menu        interface{}        // Hugo menu entries, emitted only when non-nil
+	Params      []frontMatterField // Further keys: mapped note properties or index template keys
+	KeyMap      map[string]string  // Keys renamed on output, from the front-matter map
+	Unresolved  []string           // Link targets that didn't resolve to a published note, not serialized
 	LastUpdated time.Time
+	Format      string // Front-matter format: yaml (default), toml or json
+
+	// TimestampsUTC emits every date field in UTC instead of its own location
+	TimestampsUTC bool
+}
+
+// frontMatterField is a single front-matter key/value pair
+type frontMatterField struct {
+	Key   string
+	Value interface{}
+}
+
+// frontMatter returns the front-matter fields in emission order
+func (hc *HugoContent) frontMatter() []frontMatterField {
+	fields := []frontMatterField{
+		{"title", hc.Title},
+	}
+	if hc.Description != "" {
+		fields = append(fields, frontMatterField{"description", hc.Description})
+	}
+	fields = append(fields, frontMatterField{"weight", hc.Weight})
+	if hc.Draft {
+		fields = append(fields, frontMatterField{"draft", true})
+	}
+	if !hc.Date.IsZero() {
+		fields = append(fields, frontMatterField{"date", hc.Date})
+	}
+	if !hc.PublishDate.IsZero() {
+		fields = append(fields, frontMatterField{"publishDate", hc.PublishDate})
+	}
+	if !hc.ExpiryDate.IsZero() {
+		fields = append(fields, frontMatterField{"expiryDate", hc.ExpiryDate})
+	}
+	if !hc.Lastmod.IsZero() {
+		fields = append(fields, frontMatterField{"lastmod", hc.Lastmod})
+	}
+	if hc.WordCount > 0 {
+		fields = append(fields, frontMatterField{"wordCount", hc.WordCount})
+	}
+	if hc.ReadingTime > 0 {
+		fields = append(fields, frontMatterField{"readingTime", hc.ReadingTime})
+	}
+	fields = append(fields,
+		frontMatterField{"noteUid", hc.NoteUID},
+		frontMatterField{"lastUpdated", hc.LastUpdated.Truncate(time.Second)},
+	)
+	
+	if len(hc.Tags) > 0 {
+		fields = append(fields, frontMatterField{"tags", hc.Tags})
+	}
+	if len(hc.Aliases) > 0 {
+		fields = append(fields, frontMatterField{"aliases", hc.Aliases})
+	}
+	
+	fields = append(fields, hc.Params...)
+	
+	// Last, as a TOML table would take in any keys after it
+	if hc.Menu != nil {
+		fields = append(fields, frontMatterField{"menu", hc.Menu})
+	}
+	
+	fields = hc.renameKeys(fields)
+	
+	// Normalize dates so contributors in different zones produce identical output
+	if hc.TimestampsUTC {
+		for i, field := range fields {
+			if t, ok := field.Value.(time.Time); ok {
+				fields[i].Value = t.UTC()
+			}
+		}
+	}
+	
+	return fields
 }
 
 // Serialize returns the complete Hugo content with front-matter
 func (hc *HugoContent) Serialize() string {
 	var sb strings.Builder
 	
-	sb.WriteString("---\n")
-	sb.WriteString(fmt.Sprintf("title: %q\n", hc.Title))
-	sb.WriteString(fmt.Sprintf("weight: %d\n", hc.Weight))
-	sb.WriteString(fmt.Sprintf("noteUid: %q\n", hc.NoteUID))
-	sb.WriteString(fmt.Sprintf("lastUpdated: %s\n", hc.LastUpdated.Format(time.RFC3339)))
-	sb.WriteString("---\n\n")
+	switch hc.Format {
+	case FormatTOML:
+		sb.WriteString("+++\n")
+		sb.WriteString(hc.serializeTOML())
+		sb.WriteString("+++\n\n")
+	case FormatJSON:
+		sb.WriteString(hc.serializeJSON())
+		sb.WriteString("\n\n")
+	default:
+		sb.WriteString("---\n")
+		sb.WriteString(hc.serializeYAML())
+		sb.WriteString("---\n\n")
+	}
 	sb.WriteString(hc.Content)
 	
 	return sb.String()
 }
 
-// generateHugoPath creates the Hugo content path for a note
+// lastUpdatedLine matches the lastUpdated front-matter line in YAML, TOML or JSON
+var lastUpdatedLine = regexp.MustCompile(`(?m)^\s*"?lastUpdated"?\s*[:=].*\n`)
+
+// ContentChanged reports whether generated Hugo content differs from an
+// existing file in anything other than the lastUpdated timestamp
+func ContentChanged(existing, generated string) bool {
+	return withoutLastUpdated(existing) != withoutLastUpdated(generated)
+}
+
+// withoutLastUpdated removes the first lastUpdated line from serialized content
+func withoutLastUpdated(content string) string {
+	if loc := lastUpdatedLine.FindStringIndex(content); loc != nil {
+		return content[:loc[0]] + content[loc[1]:]
+	}
+	return content
+}
+
+// serializeYAML renders the front-matter fields as YAML, building an ordered
+// mapping node so keys keep their emission order and nested maps are sorted
+func (hc *HugoContent) serializeYAML() string {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range hc.frontMatter() {
+		value := &yaml.Node{}
+		if err := value.Encode(field.Value); err != nil {
+			slog.Warn("Skipping front-matter field YAML cannot represent", "key", field.Key, "error", err)
+			continue
+		}
+		quoteStrings(value)
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field.Key}, value)
+	}
+	
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(mapping); err != nil {
+		slog.Warn("Failed to serialize YAML front-matter", "error", err)
+	}
+	_ = encoder.Close()
+	return buf.String()
+}
+
+// quoteStrings double-quotes every string value in a YAML node tree, so
+// titles like "yes" or "1.0" keep their type
+func quoteStrings(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	case yaml.MappingNode:
+		// Keys stay plain, only values are quoted
+		for i := 1; i < len(node.Content); i += 2 {
+			quoteStrings(node.Content[i])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			quoteStrings(item)
+		}
+	}
+}
+
+// serializeTOML renders the front-matter fields as TOML key/value lines
+func (hc *HugoContent) serializeTOML() string {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	for _, field := range hc.frontMatter() {
+		// Encode one key at a time to keep the emission order stable; values TOML
+		// cannot represent are skipped rather than failing the whole document
+		_ = encoder.Encode(map[string]interface{}{field.Key: field.Value})
+	}
+	return buf.String()
+}
+
+// serializeJSON renders the front-matter fields as an ordered JSON object
+func (hc *HugoContent) serializeJSON() string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	fields := hc.frontMatter()
+	for i, field := range fields {
+		value := field.Value
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339)
+		}
+		key, _ := json.Marshal(field.Key)
+		encoded, _ := json.Marshal(value)
+		sb.WriteString(fmt.Sprintf("  %s: %s", key, encoded))
+		if i < len(fields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// AddAliases appends Hugo aliases, skipping duplicates
+func (hc *HugoContent) AddAliases(aliases ...string) {
+	hc.Aliases = dedupeStrings(append(hc.Aliases, aliases...))
+}
+
+// dedupeStrings returns the non-empty values in order with duplicates removed
+func dedupeStrings(values []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+// noteWeight returns the author-set front-matter weight, falling back to the
+// computed one when it is absent or not a whole number
+func noteWeight(frontMatter map[string]interface{}, computed int) int {
+	switch value := frontMatter["weight"].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case uint64:
+		return int(value)
+	case float64:
+		if value == float64(int(value)) {
+			return int(value)
+		}
+	case string:
+		if weight, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return weight
+		}
+	}
+	return computed
+}
+
+// HugoPath returns the Hugo content path (relative to the repo root) for a note
+func (g *Generator) HugoPath(note *vault.Note) string {
+	return g.generateHugoPath(note.Path, note.UID)
+}
+
+// URLForPath converts a Hugo content path into the site URL it is served at,
+// e.g. content/docs/guides/note.md becomes /docs/guides/note/
+func (g *Generator) URLForPath(hugoPath string) string {
+	return "/" + g.contentRelativePath(hugoPath) + "/"
+}
+
+// contentRelativePath converts a Hugo content path to its slash-separated
+// form relative to content/ without the .md extension (as used by relref)
+func (g *Generator) contentRelativePath(hugoPath string) string {
+	// Strip content/ but keep subdirs like docs/
+	relPath := strings.TrimPrefix(slashPath(hugoPath), "content/")
+	relPath = g.convertToHugoURL(relPath)
+	return strings.TrimSuffix(strings.TrimSuffix(relPath, ".md"), "/_index")
+}
+
+// generateHugoPath creates the Hugo content path for a note, with forward
+// slashes on every platform
 func (g *Generator) generateHugoPath(notePath, noteUID string) string {
 	// Get relative path from vault root
 	relPath, err := filepath.Rel(g.vaultPath, notePath)
@@ -88,78 +601,207 @@ func (g *Generator) generateHugoPath(notePath, noteUID string) string {
 		relPath = filepath.Clean(notePath)
 	}
 	
+	// Index notes become their section's _index.md
+	if sectionDir, ok := g.indexNoteSection(notePath); ok {
+		return slashPath(filepath.Join(sectionDir, "_index.md"))
+	}
+	
 	// Convert to Hugo path structure
 	dir := filepath.Dir(relPath)
-	filename := filepath.Base(relPath)
+	filename := g.undatedFilename(notePath, filepath.Base(relPath), noteUID)
 	
 	// Create slug from filename
 	slug := g.createSlug(filename, noteUID)
 	
 	// Handle root level notes
 	if dir == "." || dir == "/" {
-		return filepath.Join(g.contentDir, "posts", slug)
+		return slashPath(filepath.Join(g.contentDir, g.rootSection, slug))
 	}
 	
-	// Convert folder structure to Hugo path
-	hugoDirs := strings.Split(dir, string(filepath.Separator))
-	hugoPath := append([]string{g.contentDir}, hugoDirs...)
+	// Convert folder structure to Hugo path, under the folder's routed content directory
+	contentDir, rest, _ := g.routeVaultDir(dir)
+	hugoDirs := strings.Split(windowsSafeDirs(rest), string(filepath.Separator))
+	if g.maxSectionDepth > 0 && len(hugoDirs) > g.maxSectionDepth {
+		// Flatten folders past the cap into the slug, keeping it unique
+		// within the capped section
+		flattened := append(append([]string{}, hugoDirs[g.maxSectionDepth:]...), vault.NoteName(filename))
+		slug = g.createSlug(strings.Join(flattened, " "), noteUID)
+		hugoDirs = hugoDirs[:g.maxSectionDepth]
+	}
+	hugoPath := append([]string{contentDir}, hugoDirs...)
 	hugoPath = append(hugoPath, slug)
 	
-	return filepath.Join(hugoPath...)
+	return slashPath(filepath.Join(hugoPath...))
+}
+
+// slashPath converts a Hugo content path to forward slashes. Backslashes are
+// replaced on every platform, as paths may come from a state file or a
+// config written on Windows.
+func slashPath(hugoPath string) string {
+	return strings.ReplaceAll(hugoPath, "\\", "/")
 }
 
 // createSlug creates a URL-friendly slug from a filename
 func (g *Generator) createSlug(filename, noteUID string) string {
-	// Remove .md extension
-	name := strings.TrimSuffix(filename, ".md")
+	// Remove the note's markdown extension
+	name := strings.TrimSuffix(filename, vault.MarkdownExtension(filename, g.noteExtensions))
 	
-	// Convert to lowercase and replace spaces/special chars with hyphens
-	slug := strings.ToLower(name)
-	slug = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(slug, "-")
-	slug = strings.Trim(slug, "-")
+	// Drop the ordering prefix when configured
+	if g.numberPrefix == NumberPrefixStrip {
+		if _, rest, ok := ParseNumberPrefix(name); ok {
+			name = rest
+		}
+	}
+	
+	// Replace spaces/special chars with the style's separator, lowercasing
+	// unless the case is preserved
+	var slug string
+	switch g.slugStyle {
+	case SlugStyleSnake:
+		slug = slugLowerRegex.ReplaceAllString(strings.ToLower(name), "_")
+		slug = strings.Trim(slug, "_")
+	case SlugStylePreserve:
+		slug = slugMixedRegex.ReplaceAllString(name, "-")
+		slug = strings.Trim(slug, "-")
+	default:
+		slug = slugLowerRegex.ReplaceAllString(strings.ToLower(name), "-")
+		slug = strings.Trim(slug, "-")
+	}
 	
 	// Handle edge cases
 	if slug == "" {
 		slug = "untitled"
 	}
 	
-	// Truncate if too long and append UID
+	// Truncate if too long and append UID (adopted UIDs may be shorter than 8)
 	if len(slug) > 50 {
-		slug = slug[:42] + "-" + noteUID[:8]
+		slug = slug[:42] + "-" + noteUID[:min(8, len(noteUID))]
 	}
 	
-	return slug + ".md"
+	return windowsSafeName(slug) + ".md"
 }
 
-// UpdateSlugMap updates the internal mapping of note targets to Hugo paths
+// UpdateSlugMap rebuilds the internal mapping of note targets to Hugo paths
+// from scratch. Use UpdateSlugMapEntry and RemoveSlugMapEntry to keep the map
+// current for individual notes after the initial build.
 func (g *Generator) UpdateSlugMap(publishedNotes map[string]*vault.Note) {
 	g.slugMap = make(map[string]string)
+	g.slugClaims = make(map[string]map[string]slugClaim)
 	
+	// Apply notes in path order so the result doesn't depend on map iteration
+	notes := make([]*vault.Note, 0, len(publishedNotes))
 	for _, note := range publishedNotes {
-		if note.Published {
-			// Map by filename (without path and extension)
-			filename := strings.TrimSuffix(filepath.Base(note.Path), ".md")
-			hugoPath := g.generateHugoPath(note.Path, note.UID)
-			
-			// Store relative path for Hugo relref (strip content/ but keep subdirs like docs/)
-			relPath := hugoPath
-			if strings.HasPrefix(relPath, "content/") {
-				relPath = strings.TrimPrefix(relPath, "content/")
-			} else if strings.HasPrefix(relPath, "content\\") {
-				relPath = strings.TrimPrefix(relPath, "content\\")
-			}
-			relPath = strings.ReplaceAll(relPath, "\\", "/")
-			relPath = g.convertToHugoURL(relPath)
-			relPath = strings.TrimSuffix(relPath, ".md")
-			
-			g.slugMap[filename] = relPath
-			
-			// Also map by full title if different
-			if note.Title != filename {
-				g.slugMap[note.Title] = relPath
-			}
+		notes = append(notes, note)
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Path < notes[j].Path
+	})
+	
+	for _, note := range notes {
+		g.UpdateSlugMapEntry(note)
+	}
+}
+
+// UpdateSlugMapEntry adds, updates or (for unpublished notes) removes the
+// slug-map entries for a single note, replacing any it contributed before
+func (g *Generator) UpdateSlugMapEntry(note *vault.Note) {
+	g.RemoveSlugMapEntry(note.UID)
+	
+	if !note.Published {
+		return
+	}
+	
+	// Map by filename (without path and extension)
+	filename := vault.NoteName(note.Path)
+	hugoPath := g.generateHugoPath(note.Path, note.UID)
+	
+	// Store relative path for Hugo relref (strip content/ but keep subdirs like docs/)
+	relPath := g.contentRelativePath(hugoPath)
+	claim := slugClaim{relPath: relPath, notePath: note.Path}
+	
+	g.setSlug(filename, note.UID, claim)
+	
+	// Also map by full title if different
+	if note.Title != filename {
+		g.setSlug(note.Title, note.UID, claim)
+	}
+	
+	// Map by parent folder and filename so links like [[Guides/Setup]] can
+	// pick one of several notes sharing a filename, and by the vault-relative
+	// path with and without extension for path-qualified links like
+	// [[Guides/Install/Setup]] and markdown links like [Setup](guides/Setup.md)
+	if relNote, err := filepath.Rel(g.vaultPath, note.Path); err == nil {
+		relNote = filepath.ToSlash(relNote)
+		parentKey := filename
+		if parent := path.Base(path.Dir(relNote)); parent != "." {
+			parentKey = parent + "/" + filename
+			g.setSlug(parentKey, note.UID, claim)
+		}
+		if pathKey := strings.TrimSuffix(relNote, path.Ext(relNote)); pathKey != parentKey {
+			g.setSlug(pathKey, note.UID, claim)
+		}
+		g.setSlug(relNote, note.UID, claim)
+	}
+}
+
+// RemoveSlugMapEntry removes all slug-map entries contributed by a note
+func (g *Generator) RemoveSlugMapEntry(noteUID string) {
+	for target, claims := range g.slugClaims {
+		if _, exists := claims[noteUID]; exists {
+			delete(claims, noteUID)
+			g.resolveSlug(target)
+		}
+	}
+}
+
+// slugClaim is a published note's claim on a link target
+type slugClaim struct {
+	relPath  string // Content-relative Hugo path the target resolves to
+	notePath string // Vault path of the claiming note
+}
+
+// setSlug records a link target for a note, warning when another note
+// already claims the same target
+func (g *Generator) setSlug(target, noteUID string, claim slugClaim) {
+	claims := g.slugClaims[target]
+	if claims == nil {
+		claims = make(map[string]slugClaim)
+		g.slugClaims[target] = claims
+	}
+	claims[noteUID] = claim
+	
+	if len(claims) > 1 {
+		paths := make([]string, 0, len(claims))
+		for _, other := range claims {
+			paths = append(paths, other.notePath)
+		}
+		sort.Strings(paths)
+		slog.Warn("Ambiguous wikilink target, qualify links with the folder name",
+			"target", target,
+			"notes", paths,
+			"resolves_to", paths[0])
+	}
+	
+	g.resolveSlug(target)
+}
+
+// resolveSlug points a target at the claiming note with the lowest vault
+// path, so ambiguous links resolve the same way regardless of sync order
+func (g *Generator) resolveSlug(target string) {
+	claims := g.slugClaims[target]
+	if len(claims) == 0 {
+		delete(g.slugMap, target)
+		delete(g.slugClaims, target)
+		return
+	}
+	
+	var winner *slugClaim
+	for _, claim := range claims {
+		if winner == nil || claim.notePath < winner.notePath {
+			winner = &claim
 		}
 	}
+	g.slugMap[target] = winner.relPath
 }
 
 // processWikiLinks converts wikilinks to Hugo links
@@ -170,11 +812,31 @@ func (g *Generator) processWikiLinks(content string) string {
 	// First, protect code blocks and inline code
 	protectedContent := g.protectCodeSections(content)
 	
+	// Point image embeds at their copies before they're taken for wikilinks
+	protectedContent = imageEmbedRegex.ReplaceAllStringFunc(protectedContent, g.convertImageEmbed)
+	
 	// Process wikilinks
 	result := wikiLinkRegex.ReplaceAllStringFunc(protectedContent, func(match string) string {
 		return g.convertWikiLink(match)
 	})
 	
+	// Rewrite protected markdown links that point at other notes or images
+	for placeholder, link := range g.protectedContent {
+		if !strings.HasPrefix(placeholder, markdownLinkPlaceholder) {
+			continue
+		}
+		if strings.Contains(result, "!"+placeholder) {
+			if markup, ok := g.inlineImageLink(link); ok {
+				result = strings.ReplaceAll(result, "!"+placeholder, placeholder)
+				g.protectedContent[placeholder] = markup
+				continue
+			}
+			g.protectedContent[placeholder] = g.convertImageLink(link)
+		} else {
+			g.protectedContent[placeholder] = g.convertMarkdownLink(link)
+		}
+	}
+	
 	// Restore code sections
 	return g.restoreCodeSections(result)
 }
@@ -196,60 +858,216 @@ func (g *Generator) convertWikiLink(wikilink string) string {
 		displayText = strings.TrimSpace(matches[2])
 	}
 	
-	// Remove section reference for target lookup
+	// Split off the section reference, kept as the link's anchor; headings
+	// of the note being converted are known, so those resolve exactly
 	targetForLookup := target
+	var anchor string
 	if idx := strings.Index(target, "#"); idx >= 0 {
-		targetForLookup = target[:idx]
+		targetForLookup = strings.TrimSpace(target[:idx])
+		if targetForLookup == "" {
+			anchor = headingFragment(target[idx+1:], g.headings)
+		} else {
+			anchor = headingFragment(target[idx+1:], nil)
+		}
 	}
 	
-	// Look up target in slug map
-	if hugoPath, exists := g.slugMap[targetForLookup]; exists {
+	// Links to a heading in the same note stay on the page
+	if targetForLookup == "" {
+		if len(matches) < 3 || matches[2] == "" {
+			displayText = strings.TrimSpace(strings.TrimPrefix(target, "#"))
+		}
+		return fmt.Sprintf("[%s](#%s)", displayText, anchor)
+	}
+	
+	// Look up target in slug map; Obsidian also accepts targets written with
+	// the file extension, as in [[Setup.md]] or [[Guides/Setup.md]]
+	hugoPath, exists := g.slugMap[targetForLookup]
+	if ext := vault.MarkdownExtension(targetForLookup, g.noteExtensions); !exists && ext != "" {
+		hugoPath, exists = g.slugMap[strings.TrimSuffix(targetForLookup, ext)]
+	}
+	if exists {
 		// Target is published, create proper link
-		return g.createHugoLink(hugoPath, displayText)
+		return g.createHugoLink(hugoPath, displayText, anchor)
 	}
 	
-	// Target not published, handle based on configuration
+	g.unresolved = append(g.unresolved, targetForLookup)
+	return g.unpublishedLinkText(displayText)
+}
+
+// unpublishedLinkText renders a link to an unpublished note based on configuration
+func (g *Generator) unpublishedLinkText(displayText string) string {
 	switch g.unpublishedLink {
 	case "hash":
 		return fmt.Sprintf("[%s](#)", displayText)
+	case "stub":
+		return g.createHugoLink(g.contentRelativePath(g.StubPagePath()), displayText, "")
 	default: // "text"
 		return displayText
 	}
 }
 
-// createHugoLink creates a Hugo link based on the configured format
-func (g *Generator) createHugoLink(hugoPath, displayText string) string {
+// markdownLinkRegex matches inline markdown links, capturing text and destination
+var markdownLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+
+// convertMarkdownLink converts a markdown link to another note's file into
+// a Hugo link through the slug map. External URLs, site-absolute paths,
+// anchor-only links and links to non-markdown files are returned unchanged.
+func (g *Generator) convertMarkdownLink(link string) string {
+	matches := markdownLinkRegex.FindStringSubmatch(link)
+	if len(matches) < 3 {
+		return link
+	}
+	displayText := matches[1]
+	
+	// Obsidian writes destinations with spaces as <My Note.md> or My%20Note.md
+	destination := strings.TrimSpace(matches[2])
+	if strings.HasPrefix(destination, "<") && strings.HasSuffix(destination, ">") {
+		destination = destination[1 : len(destination)-1]
+	} else if strings.ContainsAny(destination, " \t") {
+		return link // Destination with a link title
+	}
+	
+	parsed, err := url.Parse(destination)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" || strings.HasPrefix(parsed.Path, "/") {
+		return link
+	}
+	if !g.isNoteLink(parsed.Path) {
+		return link
+	}
+	
+	var anchor string
+	if parsed.Fragment != "" {
+		anchor = headingFragment(parsed.Fragment, nil)
+	}
+	
+	// Resolve against the linking note's folder, falling back to the bare
+	// filename for Obsidian's shortest-path links
+	target := path.Join(g.linkBase, parsed.Path)
+	hugoPath, exists := g.slugMap[target]
+	if !exists {
+		hugoPath, exists = g.slugMap[strings.TrimSuffix(path.Base(parsed.Path), path.Ext(parsed.Path))]
+	}
+	if exists {
+		return g.createHugoLink(hugoPath, displayText, anchor)
+	}
+	
+	g.unresolved = append(g.unresolved, target)
+	return g.unpublishedLinkText(displayText)
+}
+
+// isNoteLink reports whether a link path points at a note file, comparing
+// extensions without regard to case as Obsidian does
+func (g *Generator) isNoteLink(linkPath string) bool {
+	extensions := g.noteExtensions
+	if len(extensions) == 0 {
+		extensions = vault.DefaultMarkdownExtensions
+	}
+	for _, ext := range extensions {
+		if strings.EqualFold(path.Ext(linkPath), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageEmbedRegex matches Obsidian image embeds like ![[diagram.png]]
+var imageEmbedRegex = regexp.MustCompile(`!\[\[([^\]]+)\]\]`)
+
+// convertImageEmbed rewrites an image embed into a markdown image pointing at
+// the copied image; embeds of other files are returned unchanged
+func (g *Generator) convertImageEmbed(embed string) string {
+	target := embed[3 : len(embed)-2]
+	if markup, ok := g.inlineImage(target); ok {
+		// Protected so the markup isn't taken for links
+		placeholder := fmt.Sprintf("__INLINE_SVG_%d__", len(g.protectedOrder))
+		g.protectedContent[placeholder] = markup
+		g.protectedOrder = append(g.protectedOrder, placeholder)
+		return placeholder
+	}
+	
+	imageURL, ok := g.imageLinkURL(target)
+	if !ok {
+		return embed
+	}
+	
+	alt := strings.TrimSpace(strings.SplitN(target, "|", 2)[0])
+	return fmt.Sprintf("![%s](%s)", path.Base(filepath.ToSlash(alt)), imageURL)
+}
+
+// convertImageLink rewrites the [alt](path) part of a markdown image to point
+// at the copied image; external and unknown images are returned unchanged
+func (g *Generator) convertImageLink(link string) string {
+	matches := markdownLinkRegex.FindStringSubmatch(link)
+	if len(matches) < 3 {
+		return link
+	}
+	
+	imageURL, ok := g.imageLinkURL(matches[2])
+	if !ok {
+		return link
+	}
+	return fmt.Sprintf("[%s](%s)", matches[1], imageURL)
+}
+
+// inlineImageLink returns the markup replacing a markdown image link to an
+// inlined image
+func (g *Generator) inlineImageLink(link string) (string, bool) {
+	matches := markdownLinkRegex.FindStringSubmatch(link)
+	if len(matches) < 3 {
+		return "", false
+	}
+	return g.inlineImage(matches[2])
+}
+
+// inlineImage returns the markup to inline for an image referenced in the
+// note being converted, if it is inlined rather than linked
+func (g *Generator) inlineImage(target string) (string, bool) {
+	vaultPath, ok := g.images[target]
+	if !ok || g.inlineSVG == nil {
+		return "", false
+	}
+	return g.inlineSVG(vaultPath)
+}
+
+// imageLinkURL returns the escaped site URL of an image referenced in the note
+// being converted, if image references are rewritten
+func (g *Generator) imageLinkURL(target string) (string, bool) {
+	vaultPath, ok := g.images[target]
+	if !ok || g.imageURL == nil || strings.Contains(target, "://") {
+		return "", false
+	}
+	
+	imageURL := &url.URL{Path: g.linkPrefix + g.imageURL(vaultPath)}
+	return imageURL.EscapedPath(), true
+}
+
+// createHugoLink creates a Hugo link based on the configured format, pointing
+// at the given heading anchor when it is non-empty
+func (g *Generator) createHugoLink(hugoPath, displayText, anchor string) string {
+	fragment := ""
+	if anchor != "" {
+		fragment = "#" + anchor
+	}
+	
 	switch g.linkFormat {
 	case "md":
 		// Generate static markdown link
-		url := "/" + strings.ReplaceAll(hugoPath, "\\", "/")
+		url := g.linkPrefix + "/" + strings.TrimPrefix(slashPath(hugoPath), "/")
 		if !strings.HasSuffix(url, "/") {
 			url += "/"
 		}
-		return fmt.Sprintf("[%s](%s)", displayText, url)
+		return fmt.Sprintf("[%s](%s%s)", displayText, url, fragment)
 	default: // "relref"
 		// Hugo relref expects path relative to content root (content/), not contentDir (content/docs)
 		// So we need to strip only "content/" prefix, keeping the docs/ part
-		relrefPath := hugoPath
-		
-		// Strip "content/" prefix if present (but keep subdirectories like "docs/")
-		if strings.HasPrefix(relrefPath, "content/") {
-			relrefPath = strings.TrimPrefix(relrefPath, "content/")
-		} else if strings.HasPrefix(relrefPath, "content\\") {
-			relrefPath = strings.TrimPrefix(relrefPath, "content\\")
-		}
-		
-		// Convert backslashes to forward slashes for Hugo
-		relrefPath = strings.ReplaceAll(relrefPath, "\\", "/")
-		
-		// Convert to Hugo URL format (lowercase, spaces to hyphens)
-		relrefPath = g.convertToHugoURL(relrefPath)
+		relrefPath := g.contentRelativePath(hugoPath)
 		
-		return fmt.Sprintf("[%s]({{< relref \"%s\" >}})", displayText, relrefPath)
+		return fmt.Sprintf("[%s]({{< relref \"%s%s\" >}})", displayText, relrefPath, fragment)
 	}
 }
 
-// convertToHugoURL converts a file path to Hugo's URL format (lowercase, spaces to hyphens)
+// convertToHugoURL converts a file path to Hugo's URL format in the slug style
+// (kebab lowercases and turns spaces and underscores into hyphens)
 func (g *Generator) convertToHugoURL(path string) string {
 	// Split path into components
 	parts := strings.Split(path, "/")
@@ -262,40 +1080,42 @@ func (g *Generator) convertToHugoURL(path string) string {
 		}
 		
 		// Convert folder names to Hugo format: lowercase, spaces to hyphens
-		converted := strings.ToLower(part)
-		converted = strings.ReplaceAll(converted, " ", "-")
-		// Also handle other common characters
-		converted = strings.ReplaceAll(converted, "_", "-")
+		var converted string
+		switch g.slugStyle {
+		case SlugStyleSnake:
+			converted = strings.ToLower(part)
+			converted = strings.ReplaceAll(converted, " ", "_")
+			converted = strings.ReplaceAll(converted, "-", "_")
+		case SlugStylePreserve:
+			converted = strings.ReplaceAll(part, " ", "-")
+		default:
+			converted = strings.ToLower(part)
+			converted = strings.ReplaceAll(converted, " ", "-")
+			// Also handle other common characters
+			converted = strings.ReplaceAll(converted, "_", "-")
+		}
 		parts[i] = converted
 	}
 	
 	return strings.Join(parts, "/")
 }
 
+// markdownLinkPlaceholder prefixes the placeholders of protected markdown links
+const markdownLinkPlaceholder = "__MARKDOWN_LINK_"
+
 // protectCodeSections replaces code blocks, inline code, and markdown links with placeholders
 func (g *Generator) protectCodeSections(content string) string {
 	// Clear previous protected content
 	g.protectedContent = make(map[string]string)
+	g.protectedOrder = nil
 	protected := content
 	
-	// Protect markdown links first (to avoid processing wikilinks inside them)
-	markdownLinkRegex := regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
-	markdownLinks := markdownLinkRegex.FindAllString(protected, -1)
-	
-	for i, link := range markdownLinks {
-		placeholder := fmt.Sprintf("__MARKDOWN_LINK_%d__", i)
-		g.protectedContent[placeholder] = link
-		protected = strings.Replace(protected, link, placeholder, 1)
-	}
-	
-	// Protect code blocks
+	// Protect code blocks first, so links shown inside code are never rewritten
 	codeBlockRegex := regexp.MustCompile("(?s)```[^`]*```")
 	codeBlocks := codeBlockRegex.FindAllString(protected, -1)
 	
 	for i, block := range codeBlocks {
-		placeholder := fmt.Sprintf("__CODE_BLOCK_%d__", i)
-		g.protectedContent[placeholder] = block
-		protected = strings.Replace(protected, block, placeholder, 1)
+		protected = g.protect(protected, block, fmt.Sprintf("__CODE_BLOCK_%d__", i))
 	}
 	
 	// Protect inline code
@@ -303,80 +1123,217 @@ func (g *Generator) protectCodeSections(content string) string {
 	inlineCodes := inlineCodeRegex.FindAllString(protected, -1)
 	
 	for i, code := range inlineCodes {
-		placeholder := fmt.Sprintf("__INLINE_CODE_%d__", i)
-		g.protectedContent[placeholder] = code
-		protected = strings.Replace(protected, code, placeholder, 1)
+		protected = g.protect(protected, code, fmt.Sprintf("__INLINE_CODE_%d__", i))
+	}
+	
+	// Protect markdown links (to avoid processing wikilinks inside them)
+	markdownLinks := markdownLinkRegex.FindAllString(protected, -1)
+	
+	for i, link := range markdownLinks {
+		protected = g.protect(protected, link, fmt.Sprintf("%s%d__", markdownLinkPlaceholder, i))
 	}
 	
 	return protected
 }
 
+// protect replaces the first occurrence of original in content with placeholder
+func (g *Generator) protect(content, original, placeholder string) string {
+	g.protectedContent[placeholder] = original
+	g.protectedOrder = append(g.protectedOrder, placeholder)
+	return strings.Replace(content, original, placeholder, 1)
+}
+
 // restoreCodeSections restores code blocks, inline code, and markdown links from placeholders
 func (g *Generator) restoreCodeSections(content string) string {
 	restored := content
 	
-	// Restore all protected content
-	for placeholder, original := range g.protectedContent {
-		restored = strings.Replace(restored, placeholder, original, -1)
+	// Restore in reverse order, since later sections may contain earlier placeholders
+	for i := len(g.protectedOrder) - 1; i >= 0; i-- {
+		placeholder := g.protectedOrder[i]
+		restored = strings.Replace(restored, placeholder, g.protectedContent[placeholder], -1)
 	}
 	
 	return restored
 }
 
-// escapeExampleShortcodes escapes Hugo shortcodes that contain placeholder/example text
-func (g *Generator) escapeExampleShortcodes(content string) string {
-	// Pattern to match Hugo shortcodes like {{< relref "path" >}}
-	shortcodeRegex := regexp.MustCompile(`\{\{<\s*(\w+)\s+"([^"]+)"\s*>\}\}`)
+// processFootnotes converts inline footnotes (^[text]) into numbered footnotes
+// with appended definitions and prefixes every footnote label with the note UID,
+// so footnotes from embedded notes do not collide with the embedding note's
+func (g *Generator) processFootnotes(content, noteUID string) string {
+	if !strings.Contains(content, "^") {
+		return content
+	}
+	
+	footnoteRegex := regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	protected := g.protectCodeSections(content)
 	
-	// Common placeholder patterns that should be escaped
-	placeholderPatterns := []string{
-		"folder/slug",
-		"folder/note", 
-		"path",
-		"folder/path",
-		"docs/path",
+	// Only code is off limits; links may be part of inline footnote text
+	for placeholder, original := range g.protectedContent {
+		if strings.HasPrefix(placeholder, "__MARKDOWN_LINK_") {
+			protected = strings.Replace(protected, placeholder, original, -1)
+			delete(g.protectedContent, placeholder)
+		}
 	}
 	
-	return shortcodeRegex.ReplaceAllStringFunc(content, func(match string) string {
-		// Extract the path from the shortcode
-		matches := shortcodeRegex.FindStringSubmatch(match)
-		if len(matches) < 3 {
-			return match
+	// Number inline footnotes after the highest numeric label already in use
+	next := 1
+	for _, match := range footnoteRegex.FindAllStringSubmatch(protected, -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	
+	var definitions []string
+	protected = replaceInlineFootnotes(protected, func(text string) string {
+		label := strconv.Itoa(next)
+		next++
+		definitions = append(definitions, fmt.Sprintf("[^%s]: %s", label, text))
+		return "[^" + label + "]"
+	})
+	if len(definitions) > 0 {
+		protected = strings.TrimRight(protected, "\n") + "\n\n" + strings.Join(definitions, "\n") + "\n"
+	}
+	
+	if prefix := footnotePrefix(noteUID); prefix != "" {
+		protected = footnoteRegex.ReplaceAllString(protected, "[^"+prefix+"-$1]")
+	}
+	
+	return g.restoreCodeSections(protected)
+}
+
+// replaceInlineFootnotes replaces each ^[text] with the result of replace,
+// matching nested brackets inside the footnote text
+func replaceInlineFootnotes(content string, replace func(text string) string) string {
+	var result strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] != '^' || i+1 >= len(content) || content[i+1] != '[' || (i > 0 && content[i-1] == '[') {
+			result.WriteByte(content[i])
+			continue
 		}
 		
-		shortcodeType := matches[1]
-		path := matches[2]
-		
-		// Check if this looks like a placeholder/example
-		for _, placeholder := range placeholderPatterns {
-			if path == placeholder {
-				// Escape the shortcode so Hugo displays it as literal text
-				return fmt.Sprintf("{{</* %s \"%s\" */>}}", shortcodeType, path)
+		depth := 0
+		end := -1
+		for j := i + 1; j < len(content) && end < 0; j++ {
+			switch content[j] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			case '\n':
+				if j+1 < len(content) && content[j+1] == '\n' {
+					j = len(content) // Footnotes do not span paragraphs
+				}
 			}
 		}
+		if end < 0 {
+			result.WriteByte(content[i])
+			continue
+		}
 		
-		// Not a placeholder, leave unchanged
-		return match
-	})
+		result.WriteString(replace(strings.TrimSpace(content[i+2 : end])))
+		i = end
+	}
+	return result.String()
+}
+
+// footnotePrefix derives a short footnote label prefix from a note UID
+func footnotePrefix(noteUID string) string {
+	var prefix strings.Builder
+	for _, r := range strings.ToLower(noteUID) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			prefix.WriteRune(r)
+			if prefix.Len() == 8 {
+				break
+			}
+		}
+	}
+	return prefix.String()
+}
+
+// StubPagePath returns the Hugo path of the page unpublished links point at
+// in "stub" mode
+func (g *Generator) StubPagePath() string {
+	return slashPath(filepath.Join(g.contentDir, StubPageName))
+}
+
+// GenerateStubPage creates the page unpublished links point at in "stub" mode.
+// Like section indexes it carries no noteUid, so it is never treated as a note.
+func (g *Generator) GenerateStubPage() *HugoContent {
+	return &HugoContent{
+		Path:          g.StubPagePath(),
+		Title:         "Not Published Yet",
+		Content:       "The page you followed a link to hasn't been published yet.\n",
+		LastUpdated:   time.Now(),
+		Format:        g.frontMatterFormat,
+		TimestampsUTC: g.timestampsUTC,
+	}
 }
 
 // GenerateIndexFile creates an _index.md file for a directory
 func (g *Generator) GenerateIndexFile(dirPath string, weight int) *HugoContent {
-	// Extract directory name for title
-	dirName := filepath.Base(dirPath)
-	title := strings.ReplaceAll(dirName, "-", " ")
-	title = strings.Title(title)
+	title := g.sectionTitle(dirPath)
 	
-	indexPath := filepath.Join(dirPath, "_index.md")
+	// A _folder.md note in the matching vault folder overrides title, weight and description
+	var description string
+	var menu interface{}
+	if folderNote := g.folderNote(dirPath); folderNote != nil {
+		weight = noteWeight(folderNote.FrontMatter, weight)
+		if value, ok := folderNote.FrontMatter["description"].(string); ok {
+			description = value
+		}
+		menu = noteMenu(folderNote.FrontMatter, folderNote.Tags, weight)
+	}
 	
-	return &HugoContent{
-		Path:        indexPath,
-		Title:       title,
-		Content:     "", // No content, just front-matter
-		Weight:      weight,
-		NoteUID:     "", // Index files don't have UIDs
-		LastUpdated: time.Now(),
+	indexPath := slashPath(filepath.Join(dirPath, "_index.md"))
+	
+	content := &HugoContent{
+		Path:          indexPath,
+		Title:         title,
+		Description:   description,
+		Content:       "", // No content, just front-matter
+		Weight:        weight,
+		NoteUID:       "", // Index files don't have UIDs
+		Menu:          menu,
+		KeyMap:        g.frontMatterMap,
+		LastUpdated:   time.Now(),
+		Format:        g.frontMatterFormat,
+		TimestampsUTC: g.timestampsUTC,
+	}
+	
+	if g.indexTemplate != nil {
+		if err := g.applyIndexTemplate(content, dirPath); err != nil {
+			slog.Warn("Falling back to a minimal section index", "path", indexPath, "error", err)
+		}
 	}
+	return content
+}
+
+// sectionTitle returns the title of a section: the title in its folder's
+// _folder.md, or else the directory name
+func (g *Generator) sectionTitle(dirPath string) string {
+	if folderNote := g.folderNote(dirPath); folderNote != nil {
+		if value, ok := folderNote.FrontMatter["title"].(string); ok && value != "" {
+			return value
+		}
+	}
+	return strings.Title(strings.ReplaceAll(filepath.Base(dirPath), "-", " "))
+}
+
+// folderNote loads the _folder.md note of the vault folder matching a Hugo section directory
+func (g *Generator) folderNote(dirPath string) *vault.Note {
+	relDir, ok := g.vaultDirForSection(dirPath)
+	if !ok || relDir == "." {
+		return nil
+	}
+	
+	note, err := vault.ParseNoteWithOptions(filepath.Join(g.vaultPath, relDir, vault.FolderNoteName), g.parseOptions)
+	if err != nil {
+		return nil
+	}
+	return note
 }
 
 // CalculateFolderWeight calculates weight for a folder based on depth
@@ -387,5 +1344,11 @@ func CalculateFolderWeight(folderPath string) int {
 
 // CalculateNoteWeight calculates weight for a note within its folder
 func CalculateNoteWeight(folderWeight int, alphabeticalIndex int) int {
-	return folderWeight + (10 * alphabeticalIndex)
+	return CalculateNoteWeightStep(folderWeight, alphabeticalIndex, 10)
+}
+
+// CalculateNoteWeightStep calculates weight for a note within its folder using
+// a custom gap between siblings
+func CalculateNoteWeightStep(folderWeight, alphabeticalIndex, step int) int {
+	return folderWeight + (step * alphabeticalIndex)
 } 
\ No newline at end of file
@@ -1,20 +1,28 @@
 package hugo
 
 import (
+	"encoding/json"
+	"fmt"
 	"obsidian-hugo-sync/internal/vault"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
 func TestGenerateContent(t *testing.T) {
 	generator := NewGenerator("/vault", "content/docs", "relref", "text")
 	
 	note := &vault.Note{
-		Path:    "/vault/guides/test.md",
-		UID:     "test-uid-123",
-		Title:   "Test Note",
-		Content: "This is test content with [[Another Note]] link.",
+		Path:      "/vault/guides/test.md",
+		UID:       "test-uid-123",
+		Title:     "Test Note",
+		Content:   "This is test content with [[Another Note]] link.",
 		Published: true,
 	}
 	
@@ -48,6 +56,7 @@ func TestCreateSlug(t *testing.T) {
 		{"File with Spaces & Special!.md", "uid123", "file-with-spaces-special.md"},
 		{"VeryLongFileNameThatExceedsFiftyCharactersAndShouldBeTruncated.md", "uid12345", "verylongfilenamethatexceedsfiftycharacters-uid12345.md"},
 		{".md", "uid123", "untitled.md"},
+		{"VeryLongFileNameThatExceedsFiftyCharactersAndShouldBeTruncated.md", "7", "verylongfilenamethatexceedsfiftycharacters-7.md"},
 	}
 	
 	for _, tt := range tests {
@@ -60,6 +69,28 @@ func TestCreateSlug(t *testing.T) {
 	}
 }
 
+func TestLongNoteWithShortAdoptedUID(t *testing.T) {
+	vaultDir := t.TempDir()
+	notePath := filepath.Join(vaultDir, "A Very Long Note Title That Goes Well Past Fifty Characters.md")
+	if err := os.WriteFile(notePath, []byte("---\nid: 7\npublish: true\n---\n\nBody\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	note, err := vault.ParseNoteWithOptions(notePath, vault.ParseOptions{UIDKeys: []string{"id"}})
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+	if note.UID != "7" {
+		t.Fatalf("Expected adopted UID '7', got %q", note.UID)
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	expected := filepath.Join("content/docs", "posts", "a-very-long-note-title-that-goes-well-past-7.md")
+	if hugoPath := generator.HugoPath(note); hugoPath != expected {
+		t.Errorf("Expected %q, got %q", expected, hugoPath)
+	}
+}
+
 func TestProcessWikiLinks(t *testing.T) {
 	generator := NewGenerator("/vault", "content/docs", "relref", "text")
 	
@@ -92,7 +123,7 @@ func TestProcessWikiLinks(t *testing.T) {
 		{
 			name:     "code blocks preserved",
 			content:  "Normal [[Published Note]] and `[[Not A Link]]` and ```\n[[Also Not A Link]]\n```",
-			expected: "Normal [Published Note]({{< relref \"guides/published-note\" >}}) and __INLINE_CODE_0__ and __CODE_BLOCK_0__",
+			expected: "Normal [Published Note]({{< relref \"guides/published-note\" >}}) and `[[Not A Link]]` and ```\n[[Also Not A Link]]\n```",
 		},
 	}
 	
@@ -108,10 +139,10 @@ func TestProcessWikiLinks(t *testing.T) {
 
 func TestCreateHugoLink(t *testing.T) {
 	tests := []struct {
-		linkFormat   string
-		hugoPath     string
-		displayText  string
-		expected     string
+		linkFormat  string
+		hugoPath    string
+		displayText string
+		expected    string
 	}{
 		{
 			linkFormat:  "relref",
@@ -130,7 +161,7 @@ func TestCreateHugoLink(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.linkFormat, func(t *testing.T) {
 			generator := NewGenerator("/vault", "content/docs", tt.linkFormat, "text")
-			result := generator.createHugoLink(tt.hugoPath, tt.displayText)
+			result := generator.createHugoLink(tt.hugoPath, tt.displayText, "")
 			if result != tt.expected {
 				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
 			}
@@ -138,6 +169,149 @@ func TestCreateHugoLink(t *testing.T) {
 	}
 }
 
+func TestCreateHugoLinkBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		anchor   string
+		expected string
+	}{
+		{name: "empty prefix keeps root links", baseURL: "", expected: "[Note](/guides/note/)"},
+		{name: "root base URL", baseURL: "https://example.com/", expected: "[Note](/guides/note/)"},
+		{name: "path prefix", baseURL: "/repo/", expected: "[Note](/repo/guides/note/)"},
+		{name: "path prefix without slashes", baseURL: "repo", expected: "[Note](/repo/guides/note/)"},
+		{name: "full base URL", baseURL: "https://user.github.io/repo", expected: "[Note](/repo/guides/note/)"},
+		{name: "nested prefix with anchor", baseURL: "/sites/repo/", anchor: "setup", expected: "[Note](/sites/repo/guides/note/#setup)"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "md", "text")
+			generator.SetBaseURL(tt.baseURL)
+			if result := generator.createHugoLink("guides/note", "Note", tt.anchor); result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+	
+	// relref links are left for Hugo to resolve against its own baseURL
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetBaseURL("/repo/")
+	if result := generator.createHugoLink("guides/note", "Note", ""); result != `[Note]({{< relref "guides/note" >}})` {
+		t.Errorf("Expected unprefixed relref, got '%s'", result)
+	}
+}
+
+func TestImageReferencesPointAtOutputDir(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "md", "text")
+	generator.SetBaseURL("/repo/")
+	generator.SetImageURL(func(vaultImagePath string) string {
+		rel, _ := filepath.Rel("/vault", vaultImagePath)
+		return "/images/" + strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+	})
+	
+	note := &vault.Note{
+		Path:      "/vault/guides/test.md",
+		UID:       "test-uid",
+		Title:     "Test",
+		Content:   "![[x.png]]\n\n![Photo](../Attachments/my photo.jpg)\n\n![Logo](https://example.com/logo.png)\n\n```\n![[x.png]]\n```\n",
+		Published: true,
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	expected := []string{
+		"![x.png](/repo/images/guides-x.png)",
+		"![Photo](/repo/images/Attachments-my%20photo.jpg)",
+		"![Logo](https://example.com/logo.png)",
+		"```\n![[x.png]]\n```",
+	}
+	for _, want := range expected {
+		if !strings.Contains(hugoContent.Content, want) {
+			t.Errorf("Expected content to contain '%s', got:\n%s", want, hugoContent.Content)
+		}
+	}
+}
+
+func TestGenerateContentPassesScheduleThrough(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	note := &vault.Note{
+		Path:        "/vault/guides/launch.md",
+		UID:         "launch-uid",
+		Title:       "Launch",
+		Published:   true,
+		PublishDate: time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC),
+		ExpiryDate:  time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	serialized := hugoContent.Serialize()
+	for _, want := range []string{"publishDate: 2030-01-02T09:00:00Z\n", "expiryDate: 2030-02-01T00:00:00Z\n"} {
+		if !strings.Contains(serialized, want) {
+			t.Errorf("Expected front-matter to contain '%s', got:\n%s", want, serialized)
+		}
+	}
+	
+	// Notes without a schedule don't get the keys
+	note.PublishDate, note.ExpiryDate = time.Time{}, time.Time{}
+	hugoContent, _ = generator.GenerateContent(note, 100)
+	if serialized := hugoContent.Serialize(); strings.Contains(serialized, "publishDate") || strings.Contains(serialized, "expiryDate") {
+		t.Errorf("Expected no schedule keys, got:\n%s", serialized)
+	}
+}
+
+func TestGenerateContentLastmod(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	note := &vault.Note{Path: "/vault/guides/setup.md", UID: "setup-uid", Title: "Setup", Published: true}
+	
+	hugoContent, _ := generator.GenerateContent(note, 100)
+	if serialized := hugoContent.Serialize(); strings.Contains(serialized, "lastmod") {
+		t.Errorf("Expected no lastmod without a source, got:\n%s", serialized)
+	}
+	
+	committed := time.Date(2024, 3, 5, 8, 45, 0, 0, time.UTC)
+	generator.SetLastmod(func(*vault.Note) time.Time { return committed })
+	hugoContent, _ = generator.GenerateContent(note, 100)
+	if serialized := hugoContent.Serialize(); !strings.Contains(serialized, "lastmod: 2024-03-05T08:45:00Z\n") {
+		t.Errorf("Expected lastmod from the source, got:\n%s", serialized)
+	}
+}
+
+func TestUnpublishedLinkStub(t *testing.T) {
+	tests := []struct {
+		linkFormat string
+		expected   string
+	}{
+		{linkFormat: "relref", expected: `See [Roadmap]({{< relref "docs/unpublished" >}})`},
+		{linkFormat: "md", expected: "See [Roadmap](/docs/unpublished/)"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.linkFormat, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", tt.linkFormat, "stub")
+			if result := generator.processWikiLinks("See [[Roadmap]]"); result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+	
+	stub := NewGenerator("/vault", "content/docs", "relref", "stub").GenerateStubPage()
+	if stub.Path != "content/docs/unpublished.md" {
+		t.Errorf("Expected stub page at content/docs/unpublished.md, got '%s'", stub.Path)
+	}
+	if stub.NoteUID != "" {
+		t.Errorf("Expected stub page without noteUid, got '%s'", stub.NoteUID)
+	}
+}
+
 func TestGenerateIndexFile(t *testing.T) {
 	generator := NewGenerator("/vault", "content/docs", "relref", "text")
 	
@@ -224,4 +398,1068 @@ func TestHugoContentSerialization(t *testing.T) {
 	if !strings.Contains(serialized, "\n---\n\n") {
 		t.Error("Expected front-matter to end with --- followed by content")
 	}
-} 
\ No newline at end of file
+}
+
+func TestHugoContentSerializationFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		prefix string
+		parse  func(t *testing.T, serialized string) map[string]interface{}
+	}{
+		{
+			format: FormatYAML,
+			prefix: "---\n",
+			parse: func(t *testing.T, serialized string) map[string]interface{} {
+				parts := strings.SplitN(serialized, "---\n", 3)
+				var fm map[string]interface{}
+				if err := yaml.Unmarshal([]byte(parts[1]), &fm); err != nil {
+					t.Fatalf("Failed to parse YAML front-matter: %v", err)
+				}
+				return fm
+			},
+		},
+		{
+			format: FormatTOML,
+			prefix: "+++\n",
+			parse: func(t *testing.T, serialized string) map[string]interface{} {
+				parts := strings.SplitN(serialized, "+++\n", 3)
+				var fm map[string]interface{}
+				if _, err := toml.Decode(parts[1], &fm); err != nil {
+					t.Fatalf("Failed to parse TOML front-matter: %v", err)
+				}
+				return fm
+			},
+		},
+		{
+			format: FormatJSON,
+			prefix: "{\n",
+			parse: func(t *testing.T, serialized string) map[string]interface{} {
+				var fm map[string]interface{}
+				if err := json.NewDecoder(strings.NewReader(serialized)).Decode(&fm); err != nil {
+					t.Fatalf("Failed to parse JSON front-matter: %v", err)
+				}
+				return fm
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			content := &HugoContent{
+				Path:        "content/docs/test.md",
+				Title:       "Test \"Quoted\" Note",
+				Content:     "This is test content.",
+				Weight:      120,
+				NoteUID:     "test-uid-123",
+				LastUpdated: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+				Format:      tt.format,
+			}
+			
+			serialized := content.Serialize()
+			
+			if !strings.HasPrefix(serialized, tt.prefix) {
+				t.Errorf("Expected serialized content to start with %q, got %q", tt.prefix, serialized[:10])
+			}
+			
+			if !strings.HasSuffix(serialized, "\n\nThis is test content.") {
+				t.Error("Expected content after front-matter")
+			}
+			
+			fm := tt.parse(t, serialized)
+			
+			if fm["title"] != content.Title {
+				t.Errorf("Expected title %q, got %v", content.Title, fm["title"])
+			}
+			
+			if fmt.Sprint(fm["weight"]) != "120" {
+				t.Errorf("Expected weight 120, got %v", fm["weight"])
+			}
+			
+			if fm["noteUid"] != "test-uid-123" {
+				t.Errorf("Expected noteUid 'test-uid-123', got %v", fm["noteUid"])
+			}
+		})
+	}
+}
+
+func TestGeneratorFrontMatterFormat(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetFrontMatterFormat(FormatTOML)
+	
+	note := &vault.Note{
+		Path:      "/vault/guides/test.md",
+		UID:       "test-uid-123",
+		Title:     "Test Note",
+		Published: true,
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	if hugoContent.Format != FormatTOML {
+		t.Errorf("Expected format %q, got %q", FormatTOML, hugoContent.Format)
+	}
+	
+	indexContent := generator.GenerateIndexFile("content/docs/guides", 200)
+	if !strings.HasPrefix(indexContent.Serialize(), "+++\n") {
+		t.Error("Expected section index to use TOML front-matter")
+	}
+}
+
+func TestHugoContentTimestampsUTC(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	lastUpdated := time.Date(2024, 1, 15, 11, 30, 0, 0, cet)
+	
+	content := &HugoContent{
+		Title:       "Test Note",
+		NoteUID:     "test-uid-123",
+		LastUpdated: lastUpdated,
+	}
+	
+	if !strings.Contains(content.Serialize(), "lastUpdated: 2024-01-15T11:30:00+01:00\n") {
+		t.Error("Expected lastUpdated in its own location when UTC is disabled")
+	}
+	
+	content.TimestampsUTC = true
+	if !strings.Contains(content.Serialize(), "lastUpdated: 2024-01-15T10:30:00Z\n") {
+		t.Errorf("Expected lastUpdated normalized to UTC, got:\n%s", content.Serialize())
+	}
+	
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetTimestampsUTC(true)
+	hugoContent, err := generator.GenerateContent(&vault.Note{Path: "/vault/test.md", UID: "uid"}, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if !strings.Contains(hugoContent.Serialize(), "Z\n---\n") {
+		t.Errorf("Expected generated lastUpdated in UTC, got:\n%s", hugoContent.Serialize())
+	}
+}
+
+func TestGenerateContentAliases(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	note := &vault.Note{
+		Path:    "/vault/guides/test.md",
+		UID:     "test-uid-123",
+		Title:   "Test Note",
+		Aliases: []string{"/old/url/", "/legacy/", "/old/url/"},
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	hugoContent.AddAliases(generator.URLForPath("content/docs/guides/previous-name.md"), "/legacy/")
+	
+	expected := []string{"/old/url/", "/legacy/", "/docs/guides/previous-name/"}
+	if strings.Join(hugoContent.Aliases, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected aliases %v, got %v", expected, hugoContent.Aliases)
+	}
+	
+	serialized := hugoContent.Serialize()
+	if !strings.Contains(serialized, "aliases:\n  - \"/old/url/\"\n  - \"/legacy/\"\n  - \"/docs/guides/previous-name/\"\n") {
+		t.Errorf("Expected aliases list in front-matter, got:\n%s", serialized)
+	}
+	
+	// Notes without aliases don't emit the key
+	hugoContent.Aliases = nil
+	if strings.Contains(hugoContent.Serialize(), "aliases:") {
+		t.Error("Expected no aliases key when there are no aliases")
+	}
+}
+
+func TestIncrementalSlugMapMatchesFullRebuild(t *testing.T) {
+	incremental := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	noteA := &vault.Note{Path: "/vault/guides/Setup.md", UID: "uid-a", Title: "Setup", Published: true}
+	noteB := &vault.Note{Path: "/vault/api/Endpoints.md", UID: "uid-b", Title: "API Endpoints", Published: true}
+	noteC := &vault.Note{Path: "/vault/Drafts.md", UID: "uid-c", Title: "Drafts", Published: true}
+	
+	incremental.UpdateSlugMap(map[string]*vault.Note{"uid-a": noteA, "uid-b": noteB, "uid-c": noteC})
+	
+	// Rename B, unpublish C, retitle A and add D, one event at a time
+	renamedB := &vault.Note{Path: "/vault/api/Routes.md", UID: "uid-b", Title: "API Routes", Published: true}
+	unpublishedC := &vault.Note{Path: "/vault/Drafts.md", UID: "uid-c", Title: "Drafts", Published: false}
+	retitledA := &vault.Note{Path: "/vault/guides/Setup.md", UID: "uid-a", Title: "Getting Set Up", Published: true}
+	noteD := &vault.Note{Path: "/vault/guides/Install.md", UID: "uid-d", Title: "Install", Published: true}
+	
+	incremental.UpdateSlugMapEntry(renamedB)
+	incremental.UpdateSlugMapEntry(unpublishedC)
+	incremental.UpdateSlugMapEntry(retitledA)
+	incremental.UpdateSlugMapEntry(noteD)
+	
+	full := NewGenerator("/vault", "content/docs", "relref", "text")
+	full.UpdateSlugMap(map[string]*vault.Note{"uid-a": retitledA, "uid-b": renamedB, "uid-d": noteD})
+	
+	if len(incremental.slugMap) != len(full.slugMap) {
+		t.Errorf("Expected %d slug-map entries, got %d: %v", len(full.slugMap), len(incremental.slugMap), incremental.slugMap)
+	}
+	for target, path := range full.slugMap {
+		if incremental.slugMap[target] != path {
+			t.Errorf("Expected %q -> %q, got %q", target, path, incremental.slugMap[target])
+		}
+	}
+	
+	for _, stale := range []string{"Endpoints", "API Endpoints", "Drafts"} {
+		if _, exists := incremental.slugMap[stale]; exists {
+			t.Errorf("Expected stale target %q to be removed", stale)
+		}
+	}
+	
+	// Removing a note entirely (deleted from the vault) drops all its targets
+	incremental.RemoveSlugMapEntry("uid-d")
+	if _, exists := incremental.slugMap["Install"]; exists {
+		t.Error("Expected removed note's target to be gone")
+	}
+}
+
+func TestProcessFootnotesInline(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	content := "Claim[^1] and aside^[see [docs](https://example.com)].\n\n`code ^[not a footnote]`\n\n[^1]: Source.\n"
+	result := generator.processFootnotes(content, "ABCD-1234-ef")
+	
+	expected := "Claim[^abcd1234-1] and aside[^abcd1234-2].\n\n`code ^[not a footnote]`\n\n[^abcd1234-1]: Source.\n\n[^abcd1234-2]: see [docs](https://example.com)\n"
+	if result != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, result)
+	}
+}
+
+func TestProcessFootnotesDistinctAcrossEmbeds(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	host := generator.processFootnotes("Host text[^1]\n\n[^1]: Host note.\n", "11111111-aaaa")
+	embedded := generator.processFootnotes("Embedded text[^1]\n\n[^1]: Embedded note.\n", "22222222-bbbb")
+	
+	// Embedding one note in another must not produce duplicate labels
+	combined := host + "\n" + embedded
+	footnoteRegex := regexp.MustCompile(`(?m)^\[\^([^\]]+)\]:`)
+	seen := make(map[string]bool)
+	for _, match := range footnoteRegex.FindAllStringSubmatch(combined, -1) {
+		if seen[match[1]] {
+			t.Errorf("Duplicate footnote definition %q in:\n%s", match[1], combined)
+		}
+		seen[match[1]] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("Expected 2 distinct footnote definitions, got %v", seen)
+	}
+	
+	// Code blocks are left untouched
+	code := "```\nx[^1]\n```\n"
+	if result := generator.processFootnotes(code, "11111111"); result != code {
+		t.Errorf("Expected code block unchanged, got %q", result)
+	}
+}
+
+func TestParseNumberPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   int
+		rest     string
+		expected bool
+	}{
+		{"01 Introduction", 1, "Introduction", true},
+		{"10-Advanced Topics", 10, "Advanced Topics", true},
+		{"3. Setup", 3, "Setup", true},
+		{"2024", 0, "2024", false},
+		{"42nd Street", 0, "42nd Street", false},
+		{"Introduction", 0, "Introduction", false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, rest, ok := ParseNumberPrefix(tt.name)
+			if ok != tt.expected || number != tt.number || rest != tt.rest {
+				t.Errorf("Expected (%d, %q, %v), got (%d, %q, %v)", tt.number, tt.rest, tt.expected, number, rest, ok)
+			}
+		})
+	}
+}
+
+func TestNumberPrefixStripping(t *testing.T) {
+	note := &vault.Note{
+		Path:  "/vault/guides/01 Introduction.md",
+		UID:   "prefix-uid-123",
+		Title: "01 Introduction",
+	}
+	
+	tests := []struct {
+		mode          string
+		expectedPath  string
+		expectedTitle string
+	}{
+		{NumberPrefixKeep, "content/docs/guides/01-introduction.md", "01 Introduction"},
+		{NumberPrefixWeight, "content/docs/guides/01-introduction.md", "01 Introduction"},
+		{NumberPrefixStrip, "content/docs/guides/introduction.md", "Introduction"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetNumberPrefix(tt.mode)
+			
+			hugoContent, err := generator.GenerateContent(note, 100)
+			if err != nil {
+				t.Fatalf("Failed to generate content: %v", err)
+			}
+			if hugoContent.Path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, hugoContent.Path)
+			}
+			if hugoContent.Title != tt.expectedTitle {
+				t.Errorf("Expected title %q, got %q", tt.expectedTitle, hugoContent.Title)
+			}
+		})
+	}
+	
+	// Explicit front-matter titles are left alone
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetNumberPrefix(NumberPrefixStrip)
+	titled := &vault.Note{Path: "/vault/01 Intro.md", UID: "titled-uid-123", Title: "1 Way To Do It"}
+	hugoContent, _ := generator.GenerateContent(titled, 100)
+	if hugoContent.Title != "1 Way To Do It" {
+		t.Errorf("Expected explicit title to be kept, got %q", hugoContent.Title)
+	}
+}
+
+func TestRootSection(t *testing.T) {
+	tests := []struct {
+		name         string
+		section      string
+		expectedPath string
+		expectedLink string
+	}{
+		{"default", "posts", "content/docs/posts/overview.md", `[Overview]({{< relref "docs/posts/overview" >}})`},
+		{"content root", "", "content/docs/overview.md", `[Overview]({{< relref "docs/overview" >}})`},
+		{"custom section", "Top Level", "content/docs/Top Level/overview.md", `[Overview]({{< relref "docs/top-level/overview" >}})`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetRootSection(tt.section)
+			
+			note := &vault.Note{Path: "/vault/Overview.md", UID: "root-uid-123", Title: "Overview", Published: true}
+			if path := generator.HugoPath(note); path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+			
+			generator.UpdateSlugMap(map[string]*vault.Note{note.UID: note})
+			if link := generator.processWikiLinks("[[Overview]]"); link != tt.expectedLink {
+				t.Errorf("Expected link %s, got %s", tt.expectedLink, link)
+			}
+		})
+	}
+}
+
+func TestSlugStyles(t *testing.T) {
+	tests := []struct {
+		style        string
+		expectedPath string
+		expectedLink string
+		expectedURL  string
+	}{
+		{SlugStyleKebab, "content/docs/Team Docs/my-mixed-case-note.md", `[My Mixed_Case Note]({{< relref "docs/team-docs/my-mixed-case-note" >}})`, "/docs/team-docs/my-mixed-case-note/"},
+		{SlugStyleSnake, "content/docs/Team Docs/my_mixed_case_note.md", `[My Mixed_Case Note]({{< relref "docs/team_docs/my_mixed_case_note" >}})`, "/docs/team_docs/my_mixed_case_note/"},
+		{SlugStylePreserve, "content/docs/Team Docs/My-Mixed-Case-Note.md", `[My Mixed_Case Note]({{< relref "docs/Team-Docs/My-Mixed-Case-Note" >}})`, "/docs/Team-Docs/My-Mixed-Case-Note/"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetSlugStyle(tt.style)
+			
+			note := &vault.Note{Path: "/vault/Team Docs/My Mixed_Case Note.md", UID: "style-uid-123", Title: "My Mixed_Case Note", Published: true}
+			path := generator.HugoPath(note)
+			if path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+			if url := generator.URLForPath(path); url != tt.expectedURL {
+				t.Errorf("Expected URL %s, got %s", tt.expectedURL, url)
+			}
+			
+			generator.UpdateSlugMap(map[string]*vault.Note{note.UID: note})
+			if link := generator.processWikiLinks("[[My Mixed_Case Note]]"); link != tt.expectedLink {
+				t.Errorf("Expected link %s, got %s", tt.expectedLink, link)
+			}
+		})
+	}
+}
+
+func TestMaxSectionDepth(t *testing.T) {
+	tests := []struct {
+		name         string
+		notePath     string
+		expectedPath string
+	}{
+		{"five levels deep", "/vault/a/b/c/d/e/Deep Note.md", "content/docs/a/b/c-d-e-deep-note.md"},
+		{"at the cap", "/vault/a/b/Shallow.md", "content/docs/a/b/shallow.md"},
+		{"above the cap", "/vault/a/Top.md", "content/docs/a/top.md"},
+		{"same name in another deep folder", "/vault/a/b/x/d/e/Deep Note.md", "content/docs/a/b/x-d-e-deep-note.md"},
+	}
+	
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetMaxSectionDepth(2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := &vault.Note{Path: tt.notePath, UID: "depth-uid-123", Title: "Note", Published: true}
+			if path := generator.HugoPath(note); path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+		})
+	}
+	
+	if dir := generator.SectionDir(filepath.Join("a", "b", "c", "d", "e")); dir != filepath.Join("content", "docs", "a", "b") {
+		t.Errorf("Expected section dir capped at content/docs/a/b, got %s", dir)
+	}
+	
+	generator.SetSlugStyle(SlugStyleSnake)
+	note := &vault.Note{Path: "/vault/a/b/c/d/e/Deep Note.md", UID: "depth-uid-123", Title: "Deep Note", Published: true}
+	if path := generator.HugoPath(note); path != "content/docs/a/b/c_d_e_deep_note.md" {
+		t.Errorf("Expected snake-style flattened slug, got %s", path)
+	}
+}
+
+func TestWindowsSafePaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		notePath     string
+		expectedPath string
+	}{
+		{"reserved name", "/vault/con.md", "content/docs/posts/con_.md"},
+		{"reserved name in any case", "/vault/Tools/LPT1.md", "content/docs/Tools/lpt1_.md"},
+		{"reserved folder", "/vault/NUL/Note.md", "content/docs/NUL_/note.md"},
+		{"trailing dot", "/vault/Drafts./Almost done..md", "content/docs/Drafts/almost-done.md"},
+		{"trailing space", "/vault/Ideas /Note.md", "content/docs/Ideas/note.md"},
+		{"reserved prefix only", "/vault/Aux Files/Console.md", "content/docs/Aux Files/console.md"},
+	}
+	
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := &vault.Note{Path: tt.notePath, UID: "windows-uid-123", Title: "Note", Published: true}
+			if path := generator.HugoPath(note); path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+		})
+	}
+	
+	if dir := generator.SectionDir("Drafts."); dir != filepath.Join("content", "docs", "Drafts") {
+		t.Errorf("Expected section dir without the trailing dot, got %s", dir)
+	}
+	if url := generator.URLForPath("content/docs/con_.md"); url != "/docs/con_/" {
+		t.Errorf("Expected URL /docs/con_/, got %s", url)
+	}
+}
+
+func TestGenerateIndexFileFolderNote(t *testing.T) {
+	vaultDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vaultDir, "api-reference"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	folderNote := "---\ntitle: API Reference\nweight: 5\ndescription: Endpoints and payloads\n---\n"
+	if err := os.WriteFile(filepath.Join(vaultDir, "api-reference", vault.FolderNoteName), []byte(folderNote), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	
+	// Folder with a _folder.md override
+	indexContent := generator.GenerateIndexFile(filepath.Join("content/docs", "api-reference"), 200)
+	if indexContent.Title != "API Reference" {
+		t.Errorf("Expected title 'API Reference', got '%s'", indexContent.Title)
+	}
+	if indexContent.Weight != 5 {
+		t.Errorf("Expected weight 5, got %d", indexContent.Weight)
+	}
+	if !strings.Contains(indexContent.Serialize(), "description: \"Endpoints and payloads\"\n") {
+		t.Errorf("Expected description in front-matter, got:\n%s", indexContent.Serialize())
+	}
+	
+	// Folder without one falls back to the folder name
+	indexContent = generator.GenerateIndexFile(filepath.Join("content/docs", "getting-started"), 200)
+	if indexContent.Title != "Getting Started" || indexContent.Weight != 200 || indexContent.Description != "" {
+		t.Errorf("Expected default index, got title=%q weight=%d description=%q", indexContent.Title, indexContent.Weight, indexContent.Description)
+	}
+	if strings.Contains(indexContent.Serialize(), "description:") {
+		t.Error("Expected no description key without a folder note")
+	}
+}
+
+func TestFolderNoteUsesSourceEncoding(t *testing.T) {
+	vaultDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vaultDir, "cafe"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// "Café Société" encoded as Latin-1 (not valid UTF-8)
+	folderNote := []byte("---\ntitle: \"Caf\xe9 Soci\xe9t\xe9\"\n---\n")
+	if err := os.WriteFile(filepath.Join(vaultDir, "cafe", vault.FolderNoteName), folderNote, 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	generator.SetParseOptions(vault.ParseOptions{SourceEncoding: vault.EncodingLatin1})
+	
+	indexContent := generator.GenerateIndexFile(filepath.Join("content/docs", "cafe"), 200)
+	if indexContent.Title != "Café Société" {
+		t.Errorf("Expected title 'Café Société', got %q", indexContent.Title)
+	}
+}
+
+func TestSlugMapSameFilenameCollision(t *testing.T) {
+	guides := &vault.Note{Path: "/vault/Guides/Setup.md", UID: "uid-guides", Title: "Setup", Published: true}
+	api := &vault.Note{Path: "/vault/API/Setup.md", UID: "uid-api", Title: "Setup", Published: true}
+	
+	// Bare targets resolve to the same note whichever order notes were synced in
+	forward := NewGenerator("/vault", "content/docs", "relref", "text")
+	forward.UpdateSlugMapEntry(guides)
+	forward.UpdateSlugMapEntry(api)
+	
+	backward := NewGenerator("/vault", "content/docs", "relref", "text")
+	backward.UpdateSlugMapEntry(api)
+	backward.UpdateSlugMapEntry(guides)
+	
+	if forward.slugMap["Setup"] != "docs/api/setup" || backward.slugMap["Setup"] != "docs/api/setup" {
+		t.Errorf("Expected [[Setup]] to resolve to docs/api/setup in both orders, got %q and %q",
+			forward.slugMap["Setup"], backward.slugMap["Setup"])
+	}
+	
+	// Removing the winner hands the target to the remaining note
+	forward.RemoveSlugMapEntry("uid-api")
+	if forward.slugMap["Setup"] != "docs/guides/setup" {
+		t.Errorf("Expected [[Setup]] to fall back to docs/guides/setup, got %q", forward.slugMap["Setup"])
+	}
+}
+
+func TestFolderQualifiedWikiLink(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.UpdateSlugMap(map[string]*vault.Note{
+		"uid-guides": {Path: "/vault/Guides/Setup.md", UID: "uid-guides", Title: "Setup", Published: true},
+		"uid-api":    {Path: "/vault/API/Setup.md", UID: "uid-api", Title: "Setup", Published: true},
+	})
+	
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[[Guides/Setup]]", `[Guides/Setup]({{< relref "docs/guides/setup" >}})`},
+		{"[[API/Setup|API setup]]", `[API setup]({{< relref "docs/api/setup" >}})`},
+		{"[[Setup]]", `[Setup]({{< relref "docs/api/setup" >}})`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := generator.processWikiLinks(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPathQualifiedWikiLink(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.UpdateSlugMap(map[string]*vault.Note{
+		"uid-install": {Path: "/vault/Guides/Install/Setup.md", UID: "uid-install", Title: "Setup", Published: true},
+		"uid-deploy":  {Path: "/vault/Guides/Deploy/Setup.md", UID: "uid-deploy", Title: "Setup", Published: true},
+		"uid-note":    {Path: "/vault/Notes/Note.md", UID: "uid-note", Title: "Note", Published: true},
+	})
+	
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[[Guides/Install/Setup]]", `[Guides/Install/Setup]({{< relref "docs/guides/install/setup" >}})`},
+		{"[[Guides/Deploy/Setup.md|Deploying]]", `[Deploying]({{< relref "docs/guides/deploy/setup" >}})`},
+		{"[[Notes/Note]]", `[Notes/Note]({{< relref "docs/notes/note" >}})`},
+		{"[[Note.md]]", `[Note.md]({{< relref "docs/notes/note" >}})`},
+		{"[[Note]]", `[Note]({{< relref "docs/notes/note" >}})`},
+		{"[[Guides/Missing]]", "Guides/Missing"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := generator.processWikiLinks(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestContentChanged(t *testing.T) {
+	base := &HugoContent{Title: "Note", Weight: 10, NoteUID: "uid-1", LastUpdated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Content: "Body\n"}
+	
+	for _, format := range []string{FormatYAML, FormatTOML, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			existing := *base
+			existing.Format = format
+			
+			restamped := existing
+			restamped.LastUpdated = existing.LastUpdated.Add(time.Hour)
+			if ContentChanged(existing.Serialize(), restamped.Serialize()) {
+				t.Error("Expected a timestamp-only difference not to count as a change")
+			}
+			
+			edited := restamped
+			edited.Content = "Edited body\n"
+			if !ContentChanged(existing.Serialize(), edited.Serialize()) {
+				t.Error("Expected a body edit to count as a change")
+			}
+			
+			reweighted := restamped
+			reweighted.Weight = 20
+			if !ContentChanged(existing.Serialize(), reweighted.Serialize()) {
+				t.Error("Expected a front-matter edit to count as a change")
+			}
+		})
+	}
+}
+
+func TestWikiLinkHeadingAnchors(t *testing.T) {
+	tests := []struct {
+		name       string
+		linkFormat string
+		input      string
+		expected   string
+	}{
+		{
+			name:       "same-note heading",
+			linkFormat: "relref",
+			input:      "Jump to [[#Getting Started]]",
+			expected:   "Jump to [Getting Started](#getting-started)",
+		},
+		{
+			name:       "same-note heading with display text",
+			linkFormat: "md",
+			input:      "[[#FAQ: Common Issues?|the FAQ]]",
+			expected:   "[the FAQ](#faq-common-issues)",
+		},
+		{
+			name:       "cross-note heading relref",
+			linkFormat: "relref",
+			input:      "[[Setup#Install Steps]]",
+			expected:   `[Setup#Install Steps]({{< relref "docs/guides/setup#install-steps" >}})`,
+		},
+		{
+			name:       "cross-note heading md",
+			linkFormat: "md",
+			input:      "[[Setup#Install Steps|install]]",
+			expected:   "[install](/docs/guides/setup/#install-steps)",
+		},
+		{
+			name:       "display text and heading relref",
+			linkFormat: "relref",
+			input:      "[[Setup#Install Steps|Read the section]]",
+			expected:   `[Read the section]({{< relref "docs/guides/setup#install-steps" >}})`,
+		},
+		{
+			name:       "display text and heading md",
+			linkFormat: "md",
+			input:      "[[Setup#Install Steps|Read the section]]",
+			expected:   "[Read the section](/docs/guides/setup/#install-steps)",
+		},
+		{
+			name:       "display text containing a hash",
+			linkFormat: "relref",
+			input:      "[[Setup#Step 2|Step #2]]",
+			expected:   `[Step #2]({{< relref "docs/guides/setup#step-2" >}})`,
+		},
+		{
+			name:       "display text and heading escaped in a table",
+			linkFormat: "md",
+			input:      "| [[Setup#Install Steps\\|install]] |",
+			expected:   "| [install](/docs/guides/setup/#install-steps) |",
+		},
+		{
+			name:       "unpublished note with heading",
+			linkFormat: "relref",
+			input:      "[[Draft#Ideas]]",
+			expected:   "Draft#Ideas",
+		},
+		{
+			name:       "unpublished note with heading and display text",
+			linkFormat: "relref",
+			input:      "[[Draft#Ideas|my ideas]]",
+			expected:   "my ideas",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", tt.linkFormat, "text")
+			generator.slugMap = map[string]string{"Setup": "docs/guides/setup"}
+			
+			if result := generator.processWikiLinks(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateContentEmitDraft(t *testing.T) {
+	note := &vault.Note{
+		Path:  "/vault/guides/test.md",
+		UID:   "test-uid-123",
+		Title: "Test Note",
+		Draft: true,
+	}
+	
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	hugoContent, err := generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if strings.Contains(hugoContent.Serialize(), "draft:") {
+		t.Error("Expected no draft key unless emit-draft is enabled")
+	}
+	
+	generator.SetEmitDraft(true)
+	hugoContent, err = generator.GenerateContent(note, 100)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if !strings.Contains(hugoContent.Serialize(), "weight: 100\ndraft: true\n") {
+		t.Errorf("Expected draft: true in front-matter, got:\n%s", hugoContent.Serialize())
+	}
+}
+
+func TestMarkdownLinksToNotes(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.UpdateSlugMap(map[string]*vault.Note{
+		"uid-setup": {Path: "/vault/guides/Setup.md", UID: "uid-setup", Title: "Setup", Published: true},
+		"uid-intro": {Path: "/vault/Intro.md", UID: "uid-intro", Title: "Intro", Published: true},
+	})
+	generator.linkBase = "guides"
+	
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "sibling note",
+			content:  "See [the setup](Setup.md).",
+			expected: "See [the setup]({{< relref \"docs/guides/setup\" >}}).",
+		},
+		{
+			name:     "parent folder with anchor",
+			content:  "See [intro](../Intro.md#Getting%20Started).",
+			expected: "See [intro]({{< relref \"docs/posts/intro#getting-started\" >}}).",
+		},
+		{
+			name:     "angle-bracket destination resolved by filename",
+			content:  "See [intro](<elsewhere/Intro.md>).",
+			expected: "See [intro]({{< relref \"docs/posts/intro\" >}}).",
+		},
+		{
+			name:     "unpublished note",
+			content:  "See [draft](Draft.md).",
+			expected: "See draft.",
+		},
+		{
+			name:     "external URL untouched",
+			content:  "See [docs](https://example.com/README.md) and [home](https://example.com).",
+			expected: "See [docs](https://example.com/README.md) and [home](https://example.com).",
+		},
+		{
+			name:     "anchor and non-markdown links untouched",
+			content:  "See [below](#usage) and [file](files/report.pdf).",
+			expected: "See [below](#usage) and [file](files/report.pdf).",
+		},
+		{
+			name:     "links in code untouched",
+			content:  "Use `[x](Setup.md)` or\n```\n[x](Setup.md)\n```",
+			expected: "Use `[x](Setup.md)` or\n```\n[x](Setup.md)\n```",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := generator.processWikiLinks(tt.content)
+			if result != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSectionRoutes(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetSectionRoutes(map[string]string{
+		"Blog":         "content/posts",
+		"Blog/Archive": "content/archive",
+	})
+	
+	tests := []struct {
+		name         string
+		note         *vault.Note
+		link         string
+		expectedPath string
+		expectedLink string
+	}{
+		{
+			name:         "routed folder",
+			note:         &vault.Note{Path: "/vault/Blog/Hello World.md", UID: "uid-hello", Title: "Hello World", Published: true},
+			link:         "[[Hello World]]",
+			expectedPath: "content/posts/hello-world.md",
+			expectedLink: `[Hello World]({{< relref "posts/hello-world" >}})`,
+		},
+		{
+			name:         "subfolder of routed folder",
+			note:         &vault.Note{Path: "/vault/Blog/2024/Recap.md", UID: "uid-recap", Title: "Recap", Published: true},
+			link:         "[[Recap]]",
+			expectedPath: "content/posts/2024/recap.md",
+			expectedLink: `[Recap]({{< relref "posts/2024/recap" >}})`,
+		},
+		{
+			name:         "longest route wins",
+			note:         &vault.Note{Path: "/vault/Blog/Archive/Old.md", UID: "uid-old", Title: "Old", Published: true},
+			link:         "[[Old]]",
+			expectedPath: "content/archive/old.md",
+			expectedLink: `[Old]({{< relref "archive/old" >}})`,
+		},
+		{
+			name:         "unrouted folder falls back to content dir",
+			note:         &vault.Note{Path: "/vault/Guides/Setup.md", UID: "uid-setup", Title: "Setup", Published: true},
+			link:         "[[Setup]]",
+			expectedPath: "content/docs/Guides/setup.md",
+			expectedLink: `[Setup]({{< relref "docs/guides/setup" >}})`,
+		},
+		{
+			name:         "folder sharing a route's name prefix is not routed",
+			note:         &vault.Note{Path: "/vault/Blogroll/Links.md", UID: "uid-links", Title: "Links", Published: true},
+			link:         "[[Links]]",
+			expectedPath: "content/docs/Blogroll/links.md",
+			expectedLink: `[Links]({{< relref "docs/blogroll/links" >}})`,
+		},
+	}
+	
+	notes := make(map[string]*vault.Note)
+	for _, tt := range tests {
+		notes[tt.note.UID] = tt.note
+	}
+	generator.UpdateSlugMap(notes)
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path := generator.HugoPath(tt.note); path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+			if link := generator.processWikiLinks(tt.link); link != tt.expectedLink {
+				t.Errorf("Expected link %s, got %s", tt.expectedLink, link)
+			}
+		})
+	}
+	
+	if !generator.IsContentRoot("content/posts") || generator.IsContentRoot("content/posts/2024") {
+		t.Errorf("Expected only routed directories to be content roots")
+	}
+	if dirs := generator.ContentDirs(); len(dirs) != 3 || dirs[0] != "content/docs" {
+		t.Errorf("Expected default content dir first of 3, got %v", dirs)
+	}
+}
+
+func TestGenerateContentWeight(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontMatter map[string]interface{}
+		expected    int
+	}{
+		{"author weight wins", map[string]interface{}{"weight": 5}, 5},
+		{"quoted author weight", map[string]interface{}{"weight": "7"}, 7},
+		{"no weight uses computed", map[string]interface{}{}, 120},
+		{"invalid weight uses computed", map[string]interface{}{"weight": "first"}, 120},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			note := &vault.Note{
+				Path:        "/vault/Guides/Setup.md",
+				UID:         "uid-setup",
+				Title:       "Setup",
+				FrontMatter: tt.frontMatter,
+				Published:   true,
+			}
+			
+			content, err := generator.GenerateContent(note, 120)
+			if err != nil {
+				t.Fatalf("GenerateContent failed: %v", err)
+			}
+			if content.Weight != tt.expected {
+				t.Errorf("Expected weight %d, got %d", tt.expected, content.Weight)
+			}
+			if !strings.Contains(content.Serialize(), fmt.Sprintf("weight: %d\n", tt.expected)) {
+				t.Errorf("Expected serialized weight %d, got:\n%s", tt.expected, content.Serialize())
+			}
+		})
+	}
+}
+
+func TestSerializeIsDeterministic(t *testing.T) {
+	lastUpdated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	
+	for _, format := range []string{FormatYAML, FormatTOML, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var outputs []string
+			for i := 0; i < 5; i++ {
+				generator := NewGenerator("/vault", "content/docs", "relref", "text")
+				generator.SetFrontMatterFormat(format)
+				note := &vault.Note{
+					Path:  "/vault/Guides/Setup.md",
+					UID:   "uid-setup",
+					Title: "Setup",
+					FrontMatter: map[string]interface{}{
+						"menu": map[string]interface{}{
+							"main":   map[string]interface{}{"weight": 2, "name": "Setup", "parent": "Guides", "identifier": "setup"},
+							"footer": map[string]interface{}{"weight": 9, "pre": "icon"},
+						},
+					},
+					Tags:      []string{"#publish", "#guides", "#setup"},
+					Aliases:   []string{"/old/setup/"},
+					Content:   "Install it.\n",
+					Published: true,
+				}
+				
+				content, err := generator.GenerateContent(note, 10)
+				if err != nil {
+					t.Fatalf("GenerateContent failed: %v", err)
+				}
+				content.LastUpdated = lastUpdated
+				outputs = append(outputs, content.Serialize())
+			}
+			
+			for i, output := range outputs[1:] {
+				if output != outputs[0] {
+					t.Errorf("Expected identical output on regeneration %d, got:\n%s\nvs:\n%s", i+1, outputs[0], output)
+				}
+			}
+		})
+	}
+}
+
+func TestBackslashPathsBecomeSlashLinks(t *testing.T) {
+	for _, format := range []string{"relref", "md"} {
+		generator := NewGenerator("/vault", `content\docs`, format, "text")
+		expected := map[string]string{
+			"relref": `[Setup]({{< relref "docs/guides/setup#install" >}})`,
+			"md":     `[Setup](/docs/guides/setup/#install)`,
+		}[format]
+		
+		link := generator.createHugoLink(generator.contentRelativePath(`content\docs\Guides\setup.md`), "Setup", "install")
+		if link != expected {
+			t.Errorf("Expected %s link %q, got %q", format, expected, link)
+		}
+	}
+	
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	if url := generator.URLForPath(`content\docs\guides\setup.md`); url != "/docs/guides/setup/" {
+		t.Errorf("Expected URL '/docs/guides/setup/', got %q", url)
+	}
+	
+	// Slug-map entries injected with Windows paths resolve to slash targets
+	output, err := Convert("See [[Setup]] and [guide](Setup.md).\n", ConvertOptions{
+		SlugMap: map[string]string{"Setup": `content\docs\guides\setup.md`, `guides/Setup.md`: `content\docs\guides\setup.md`},
+		NoteDir: `guides`,
+	})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if strings.Contains(output, `\`) || !strings.Contains(output, `relref "docs/guides/setup"`) {
+		t.Errorf("Expected slash-separated relrefs, got %q", output)
+	}
+}
+
+func TestSlugMapUsesForwardSlashes(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetSectionRoutes(map[string]string{"Blog": `content\posts`})
+	generator.UpdateSlugMap(map[string]*vault.Note{
+		"uid-1": {Path: "/vault/Guides/Install/Setup.md", UID: "uid-1", Title: "Setup", Published: true},
+		"uid-2": {Path: "/vault/Blog/2024/Hello.md", UID: "uid-2", Title: "Hello", Published: true},
+	})
+	
+	for target, hugoPath := range generator.slugMap {
+		if strings.Contains(target, `\`) || strings.Contains(hugoPath, `\`) {
+			t.Errorf("Expected forward slashes in slug map, got %q -> %q", target, hugoPath)
+		}
+	}
+	if hugoPath := generator.slugMap["Guides/Install/Setup"]; hugoPath != "docs/guides/install/setup" {
+		t.Errorf("Expected 'docs/guides/install/setup', got %q", hugoPath)
+	}
+	
+	note := &vault.Note{Path: "/vault/Blog/2024/Hello.md", UID: "uid-2", Title: "Hello"}
+	if hugoPath := generator.HugoPath(note); hugoPath != "content/posts/2024/hello.md" {
+		t.Errorf("Expected 'content/posts/2024/hello.md', got %q", hugoPath)
+	}
+}
+
+func TestTitleRoundTrip(t *testing.T) {
+	titles := []string{
+		`He said: "hi"`,
+		`C:\path`,
+		`literal \n backslash-n`,
+		"# Heading-like",
+		"Colon: at the end:",
+		"- dash first",
+		"yes",
+		"1.0",
+		"null",
+		"'single' quotes",
+		"Tab\there",
+		"Line\nbreak",
+		"Über café — 日本語 🎉",
+		"Line\u2028separator",
+		"{{< shortcode >}}",
+		"---",
+		"  padded  ",
+	}
+	
+	for _, title := range titles {
+		t.Run(title, func(t *testing.T) {
+			hc := &HugoContent{
+				Path:        "content/docs/note.md",
+				Title:       title,
+				Description: title,
+				NoteUID:     "uid-1",
+				Aliases:     []string{"/" + title + "/"},
+				LastUpdated: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			}
+			first := hc.Serialize()
+			
+			parsed := parseYAMLFrontMatter(t, first)
+			if parsed["title"] != title || parsed["description"] != title {
+				t.Fatalf("Expected title %q to reparse identically, got %q in:\n%s", title, parsed["title"], first)
+			}
+			if parsed["noteUid"] != "uid-1" {
+				t.Errorf("Expected noteUid to survive, got %v", parsed["noteUid"])
+			}
+			
+			// Serializing the reparsed title again yields the same bytes
+			hc.Title = parsed["title"].(string)
+			if second := hc.Serialize(); second != first {
+				t.Errorf("Expected stable serialization, got:\n%s\nvs:\n%s", first, second)
+			}
+		})
+	}
+}
+
+// parseYAMLFrontMatter decodes the YAML front-matter of serialized content
+func parseYAMLFrontMatter(t *testing.T, content string) map[string]interface{} {
+	t.Helper()
+	if !strings.HasPrefix(content, "---\n") {
+		t.Fatalf("Expected YAML front-matter, got:\n%s", content)
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		t.Fatalf("Expected closing delimiter, got:\n%s", content)
+	}
+	
+	var frontMatter map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content[4:4+end]), &frontMatter); err != nil {
+		t.Fatalf("Front-matter is not valid YAML: %v\n%s", err, content)
+	}
+	return frontMatter
+}
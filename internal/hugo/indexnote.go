@@ -0,0 +1,71 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// SetIndexNotes publishes a folder's landing note as its section's _index.md
+// instead of a page of its own: a note named _index, index or after the
+// folder itself, in that order of precedence
+func (g *Generator) SetIndexNotes(enabled bool) {
+	g.indexNotes = enabled
+}
+
+// indexNoteSection returns the Hugo section directory a note is the index
+// note of. Folders that aren't sections of their own, such as the vault
+// root, routed folders and folders flattened by the section depth cap, have
+// no index note.
+func (g *Generator) indexNoteSection(notePath string) (string, bool) {
+	if !g.indexNotes {
+		return "", false
+	}
+	relNote, err := filepath.Rel(g.vaultPath, notePath)
+	if err != nil {
+		return "", false
+	}
+	relDir := filepath.Dir(relNote)
+	if relDir == "." || strings.HasPrefix(relDir, "..") {
+		return "", false
+	}
+
+	name := vault.NoteName(notePath)
+	candidates := []string{"_index", "index", filepath.Base(relDir)}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			break
+		}
+		if g.noteExists(filepath.Join(g.vaultPath, relDir, candidate)) {
+			return "", false // A note earlier in the order takes the section
+		}
+		if candidate == candidates[len(candidates)-1] {
+			return "", false
+		}
+	}
+
+	if g.maxSectionDepth > 0 && len(strings.Split(relDir, string(filepath.Separator))) > g.maxSectionDepth {
+		return "", false
+	}
+	dir := g.SectionDir(relDir)
+	if g.IsContentRoot(dir) {
+		return "", false
+	}
+	return dir, true
+}
+
+// noteExists reports whether a note exists at a path given without its extension
+func (g *Generator) noteExists(pathWithoutExt string) bool {
+	extensions := g.noteExtensions
+	if len(extensions) == 0 {
+		extensions = vault.DefaultMarkdownExtensions
+	}
+	for _, ext := range extensions {
+		if info, err := os.Stat(pathWithoutExt + ext); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexNoteSection(t *testing.T) {
+	vaultDir := t.TempDir()
+	for _, name := range []string{"guides/Guides.md", "guides/Setup.md", "recipes/index.md", "recipes/Recipes.md", "Root.md"} {
+		path := filepath.Join(vaultDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("Body\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	generator.SetIndexNotes(true)
+	
+	tests := []struct {
+		note     string
+		expected string
+	}{
+		{"guides/Guides.md", "content/docs/guides/_index.md"},
+		{"guides/Setup.md", "content/docs/guides/setup.md"},
+		{"recipes/index.md", "content/docs/recipes/_index.md"},
+		{"recipes/Recipes.md", "content/docs/recipes/recipes.md"}, // index.md takes precedence
+		{"Root.md", "content/docs/posts/root.md"},
+	}
+	for _, tt := range tests {
+		hugoPath := generator.generateHugoPath(filepath.Join(vaultDir, tt.note), "")
+		if hugoPath != tt.expected {
+			t.Errorf("Expected %s for %s, got %s", tt.expected, tt.note, hugoPath)
+		}
+	}
+	
+	if url := generator.URLForPath("content/docs/guides/_index.md"); url != "/docs/guides/" {
+		t.Errorf("Expected the section URL, got %s", url)
+	}
+	
+	generator.SetIndexNotes(false)
+	if hugoPath := generator.generateHugoPath(filepath.Join(vaultDir, "guides/Guides.md"), ""); hugoPath != "content/docs/guides/guides.md" {
+		t.Errorf("Expected a regular page with index notes off, got %s", hugoPath)
+	}
+}
@@ -0,0 +1,141 @@
+package hugo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IndexInfo is the data available to section index templates
+type IndexInfo struct {
+	Title       string // Section title, from _folder.md or the folder name
+	Description string // Description from _folder.md, empty without one
+	Section     string // Folder name of the section
+	Path        string // Section URL path, e.g. "/docs/guides/"
+	Weight      int    // Section weight
+}
+
+// IndexTemplate renders section _index.md files from a text/template, like a
+// Hugo archetype. The output may open with a YAML front-matter block between
+// --- lines: its title, description and weight replace the generated ones and
+// any other keys are added. Whatever follows becomes the index's body.
+type IndexTemplate struct {
+	tmpl *template.Template
+}
+
+// LoadIndexTemplate reads and parses a section index template file
+func LoadIndexTemplate(path string) (*IndexTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading index template: %w", err)
+	}
+	return ParseIndexTemplate(string(data))
+}
+
+// ParseIndexTemplate parses a section index template. The template is rendered
+// once with sample data so references to unknown fields fail at startup.
+func ParseIndexTemplate(text string) (*IndexTemplate, error) {
+	tmpl, err := template.New("index").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing index template: %w", err)
+	}
+
+	t := &IndexTemplate{tmpl: tmpl}
+	if _, _, err := t.Render(IndexInfo{Title: "Guides", Section: "guides", Path: "/guides/", Weight: 100}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render produces the front-matter fields and body of a section index
+func (t *IndexTemplate) Render(info IndexInfo) ([]frontMatterField, string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, info); err != nil {
+		return nil, "", fmt.Errorf("rendering index template: %w", err)
+	}
+	output := buf.String()
+
+	if !strings.HasPrefix(output, "---\n") {
+		return nil, strings.TrimLeft(output, "\n"), nil
+	}
+	end := strings.Index(output[4:], "\n---")
+	if end < 0 {
+		return nil, "", fmt.Errorf("index template front-matter has no closing ---")
+	}
+	frontMatter := output[4 : 4+end+1]
+	body := strings.TrimPrefix(output[4+end+len("\n---"):], "\n")
+
+	var mapping yaml.Node
+	if err := yaml.Unmarshal([]byte(frontMatter), &mapping); err != nil {
+		return nil, "", fmt.Errorf("parsing index template front-matter: %w", err)
+	}
+	if len(mapping.Content) == 0 {
+		return nil, strings.TrimLeft(body, "\n"), nil
+	}
+	root := mapping.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("index template front-matter must be a mapping")
+	}
+
+	// Keys keep the template's order
+	fields := make([]frontMatterField, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		var value interface{}
+		if err := root.Content[i+1].Decode(&value); err != nil {
+			return nil, "", fmt.Errorf("parsing index template key %q: %w", root.Content[i].Value, err)
+		}
+		fields = append(fields, frontMatterField{root.Content[i].Value, value})
+	}
+	return fields, strings.TrimLeft(body, "\n"), nil
+}
+
+// SetIndexTemplate renders generated section indexes from a template; nil
+// (the default) writes front-matter only
+func (g *Generator) SetIndexTemplate(tmpl *IndexTemplate) {
+	g.indexTemplate = tmpl
+}
+
+// applyIndexTemplate renders the index template into a generated section index
+func (g *Generator) applyIndexTemplate(content *HugoContent, dirPath string) error {
+	fields, body, err := g.indexTemplate.Render(IndexInfo{
+		Title:       content.Title,
+		Description: content.Description,
+		Section:     filepath.Base(dirPath),
+		Path:        g.URLForPath(slashPath(dirPath)),
+		Weight:      content.Weight,
+	})
+	if err != nil {
+		return err
+	}
+
+	var tables []frontMatterField
+	for _, field := range fields {
+		switch field.Key {
+		case "title":
+			content.Title = fmt.Sprint(field.Value)
+		case "description":
+			content.Description = fmt.Sprint(field.Value)
+		case "weight":
+			if weight, ok := field.Value.(int); ok {
+				content.Weight = weight
+			}
+		case "noteUid", "lastUpdated":
+			// Kept by the generator
+		default:
+			if _, ok := field.Value.(map[string]interface{}); ok {
+				tables = append(tables, field)
+			} else {
+				content.Params = append(content.Params, field)
+			}
+		}
+	}
+	// After plain keys, as a TOML table would take in any keys after it
+	content.Params = append(content.Params, tables...)
+	content.Content = body
+	return nil
+}
@@ -0,0 +1,69 @@
+package hugo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndexFileFromTemplate(t *testing.T) {
+	tmpl, err := ParseIndexTemplate(`---
+description: "All about {{.Title}}"
+layout: section
+cascade:
+  type: docs
+---
+
+Pages in {{.Section}} ({{.Path}}), weight {{.Weight}}.
+`)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetIndexTemplate(tmpl)
+	indexContent := generator.GenerateIndexFile("content/docs/guides", 200)
+
+	if indexContent.Description != "All about Guides" {
+		t.Errorf("Expected the template's description, got %q", indexContent.Description)
+	}
+	if indexContent.Content != "Pages in guides (/docs/guides/), weight 200.\n" {
+		t.Errorf("Expected the template's body, got %q", indexContent.Content)
+	}
+
+	expected := "---\n" +
+		"title: \"Guides\"\n" +
+		"description: \"All about Guides\"\n" +
+		"weight: 200\n" +
+		"noteUid: \"\"\n"
+	serialized := indexContent.Serialize()
+	if !strings.HasPrefix(serialized, expected) {
+		t.Errorf("Expected generated keys first, got:\n%s", serialized)
+	}
+	if !strings.Contains(serialized, "layout: \"section\"\ncascade:\n  type: \"docs\"\n---\n\nPages in guides") {
+		t.Errorf("Expected the template's keys after the generated ones, got:\n%s", serialized)
+	}
+}
+
+func TestGenerateIndexFileTemplateBodyOnly(t *testing.T) {
+	tmpl, err := ParseIndexTemplate("{{.Title}} overview\n")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetIndexTemplate(tmpl)
+	indexContent := generator.GenerateIndexFile("content/docs/guides", 200)
+
+	if indexContent.Title != "Guides" || indexContent.Content != "Guides overview\n" {
+		t.Errorf("Expected title Guides and the rendered body, got %q and %q", indexContent.Title, indexContent.Content)
+	}
+}
+
+func TestParseIndexTemplateRejectsUnknownFields(t *testing.T) {
+	if _, err := ParseIndexTemplate("{{.Author}}"); err == nil {
+		t.Error("Expected an unknown field to fail at parse time")
+	}
+	if _, err := ParseIndexTemplate("---\nlayout: [unclosed\n---\n"); err == nil {
+		t.Error("Expected invalid front-matter to fail at parse time")
+	}
+}
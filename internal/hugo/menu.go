@@ -0,0 +1,46 @@
+package hugo
+
+import "strings"
+
+// menuKey is the front-matter key naming the Hugo menus a note or section joins
+const menuKey = "hugoMenu"
+
+// menuTagPrefix marks a note for a Hugo menu through a tag such as menu/main
+const menuTagPrefix = "menu/"
+
+// noteMenu returns the menu front-matter for a note or section. An author's
+// own menu block is kept as written; otherwise each menu named by hugoMenu or
+// a menu/ tag gets an entry with the given weight. It returns nil for neither.
+func noteMenu(frontMatter map[string]interface{}, tags []string, weight int) interface{} {
+	if menu, ok := frontMatter["menu"]; ok && menu != nil {
+		return menu
+	}
+
+	var names []string
+	switch v := frontMatter[menuKey].(type) {
+	case string:
+		names = append(names, v)
+	case []interface{}:
+		for _, name := range v {
+			if str, ok := name.(string); ok {
+				names = append(names, str)
+			}
+		}
+	}
+	for _, tag := range tags {
+		if name, ok := strings.CutPrefix(strings.TrimPrefix(tag, "#"), menuTagPrefix); ok {
+			names = append(names, name)
+		}
+	}
+
+	menus := make(map[string]interface{})
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			menus[name] = map[string]interface{}{"weight": weight}
+		}
+	}
+	if len(menus) == 0 {
+		return nil
+	}
+	return menus
+}
@@ -0,0 +1,98 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestGenerateContentMenu(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	tests := []struct {
+		name        string
+		frontMatter map[string]interface{}
+		tags        []string
+		format      string
+		expected    string
+	}{
+		{
+			name:        "hugoMenu key",
+			frontMatter: map[string]interface{}{"hugoMenu": "main"},
+			format:      FormatYAML,
+			expected:    "menu:\n  main:\n    weight: 30\n",
+		},
+		{
+			name:        "menu tag",
+			frontMatter: map[string]interface{}{},
+			tags:        []string{"#menu/footer"},
+			format:      FormatYAML,
+			expected:    "menu:\n  footer:\n    weight: 30\n",
+		},
+		{
+			name: "author menu kept",
+			frontMatter: map[string]interface{}{
+				"hugoMenu": "footer",
+				"menu":     map[string]interface{}{"main": map[string]interface{}{"parent": "Docs", "weight": 5}},
+			},
+			format:   FormatYAML,
+			expected: "menu:\n  main:\n    parent: \"Docs\"\n    weight: 5\n",
+		},
+		{
+			name:        "toml table last",
+			frontMatter: map[string]interface{}{"hugoMenu": []interface{}{"main"}},
+			format:      FormatTOML,
+			expected:    "[menu]\n  [menu.main]\n    weight = 30\n",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator.SetFrontMatterFormat(tt.format)
+			note := &vault.Note{Path: "/vault/Guides/Note.md", Title: "Note", UID: "uid-1", FrontMatter: tt.frontMatter, Tags: tt.tags}
+			
+			content, err := generator.GenerateContent(note, 30)
+			if err != nil {
+				t.Fatalf("Failed to generate content: %v", err)
+			}
+			
+			serialized := content.Serialize()
+			if !strings.Contains(serialized, tt.expected) {
+				t.Errorf("Expected menu %q in front-matter, got:\n%s", tt.expected, serialized)
+			}
+			if strings.Contains(serialized, "menu/") {
+				t.Errorf("Expected menu tags to be left out of tags, got:\n%s", serialized)
+			}
+		})
+	}
+	
+	generator.SetFrontMatterFormat(FormatYAML)
+	content, err := generator.GenerateContent(&vault.Note{Path: "/vault/Guides/Plain.md", Title: "Plain", UID: "uid-2"}, 30)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if strings.Contains(content.Serialize(), "menu:") {
+		t.Errorf("Expected no menu for a note that didn't opt in, got:\n%s", content.Serialize())
+	}
+}
+
+func TestGenerateIndexFileMenu(t *testing.T) {
+	vaultDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vaultDir, "guides"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	folderNote := "---\ntitle: Guides\nweight: 5\nhugoMenu: main\n---\n"
+	if err := os.WriteFile(filepath.Join(vaultDir, "guides", vault.FolderNoteName), []byte(folderNote), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	generator := NewGenerator(vaultDir, "content/docs", "relref", "text")
+	indexContent := generator.GenerateIndexFile(filepath.Join("content/docs", "guides"), 200)
+	
+	if !strings.Contains(indexContent.Serialize(), "menu:\n  main:\n    weight: 5\n") {
+		t.Errorf("Expected section menu entry with the folder weight, got:\n%s", indexContent.Serialize())
+	}
+}
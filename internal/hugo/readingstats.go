@@ -0,0 +1,90 @@
+package hugo
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SetReadingStats emits wordCount and, when wordsPerMinute is positive, a
+// readingTime in minutes for every note
+func (g *Generator) SetReadingStats(wordsPerMinute int) {
+	g.readingStats = true
+	g.readingWPM = wordsPerMinute
+}
+
+// Patterns for content that isn't read as prose
+var (
+	statsShortcodeRegex = regexp.MustCompile(`\{\{[<%].*?[>%]\}\}`)
+	statsMathRegex      = regexp.MustCompile(`(?s)\$\$.*?\$\$`)
+	statsInlineCode     = regexp.MustCompile("`[^`\n]*`")
+)
+
+// readingStatsFor returns the word count of a converted note body and its
+// reading time in minutes (0 when disabled)
+func (g *Generator) readingStatsFor(body string) (int, int) {
+	if !g.readingStats {
+		return 0, 0
+	}
+	words := countWords(body, g.mermaidShortcode, g.mathShortcode)
+	return words, readingMinutes(words, g.readingWPM)
+}
+
+// countWords counts the words of prose in a converted note body, leaving out
+// code, math, shortcodes and markup. The bodies of the given block
+// shortcodes, such as wrapped diagrams, are skipped along with their tags.
+func countWords(body string, blockShortcodes ...string) int {
+	var prose []string
+	inFence, fence := false, ""
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inFence {
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence, fence = true, trimmed[:3]
+			continue
+		}
+		prose = append(prose, line)
+	}
+	
+	text := strings.Join(prose, "\n")
+	text = stripShortcodes(text, blockShortcodes)
+	text = statsMathRegex.ReplaceAllString(text, "")
+	text = statsInlineCode.ReplaceAllString(text, "")
+	text = plainText(text)
+	
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// stripShortcodes removes shortcode tags, dropping the inner content of the
+// named block shortcodes whose body is not prose
+func stripShortcodes(text string, blockShortcodes []string) string {
+	for _, name := range blockShortcodes {
+		if name == "" {
+			continue
+		}
+		quoted := regexp.QuoteMeta(name)
+		block := regexp.MustCompile(`(?s)\{\{[<%]\s*` + quoted + `\b.*?[>%]\}\}.*?\{\{[<%]\s*/` + quoted + `\s*[>%]\}\}`)
+		text = block.ReplaceAllString(text, "")
+	}
+	return statsShortcodeRegex.ReplaceAllString(text, "")
+}
+
+// readingMinutes converts a word count to whole minutes at the given speed,
+// rounding up so short notes read in at least a minute (0 when disabled)
+func readingMinutes(words, wordsPerMinute int) int {
+	if words <= 0 || wordsPerMinute <= 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,114 @@
+package hugo
+
+import (
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{
+			name:     "plain prose",
+			body:     "# Getting Started\n\nInstall the tool, then run it once.\n",
+			expected: 9,
+		},
+		{
+			name:     "links count their text",
+			body:     "Read [the setup guide](/docs/setup/) and ![diagram](/images/a.png) first.\n",
+			expected: 6,
+		},
+		{
+			name:     "code is skipped",
+			body:     "Run `make build` now.\n\n```bash\necho one two three\n```\n\n~~~\nmore code\n~~~\nDone.\n",
+			expected: 3,
+		},
+		{
+			name:     "shortcodes and math are skipped",
+			body:     "Before {{< ref \"setup\" >}} after.\n\n{{< mermaid >}}\ngraph TD; A-->B\n{{< /mermaid >}}\n\n$$\nE = mc^2\n$$\n",
+			expected: 2,
+		},
+		{
+			name:     "punctuation alone is not a word",
+			body:     "One - two — three *\n\n---\n",
+			expected: 3,
+		},
+		{
+			name:     "empty body",
+			body:     "\n",
+			expected: 0,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := countWords(tt.body, "mermaid"); result != tt.expected {
+				t.Errorf("Expected %d words, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReadingMinutes(t *testing.T) {
+	tests := []struct {
+		words    int
+		wpm      int
+		expected int
+	}{
+		{0, 200, 0},
+		{1, 200, 1},
+		{200, 200, 1},
+		{201, 200, 2},
+		{450, 200, 3},
+		{450, 0, 0},
+	}
+	
+	for _, tt := range tests {
+		if result := readingMinutes(tt.words, tt.wpm); result != tt.expected {
+			t.Errorf("Expected %d minutes for %d words at %d wpm, got %d", tt.expected, tt.words, tt.wpm, result)
+		}
+	}
+}
+
+func TestGenerateContentEmitsReadingStats(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetReadingStats(4)
+	
+	// Eight words once the wikilink is resolved to its display text
+	note := &vault.Note{
+		Path:    "/vault/guides/Intro.md",
+		UID:     "uid-1",
+		Title:   "Intro",
+		Content: "Start with [[Setup Guide|the setup]] and then\nread on.\n\n```go\nfmt.Println(\"skipped\")\n```\n",
+	}
+	
+	hugoContent, err := generator.GenerateContent(note, 10)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	if hugoContent.WordCount != 8 || hugoContent.ReadingTime != 2 {
+		t.Errorf("Expected 8 words and 2 minutes, got %d words and %d minutes", hugoContent.WordCount, hugoContent.ReadingTime)
+	}
+	serialized := hugoContent.Serialize()
+	if !strings.Contains(serialized, "wordCount: 8\nreadingTime: 2\n") {
+		t.Errorf("Expected reading stats in front-matter, got:\n%s", serialized)
+	}
+}
+
+func TestReadingStatsDisabled(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	hugoContent, err := generator.GenerateContent(&vault.Note{Path: "/vault/a.md", UID: "uid-1", Title: "A", Content: "Some words\n"}, 0)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	if strings.Contains(hugoContent.Serialize(), "wordCount") {
+		t.Errorf("Expected no reading stats by default, got:\n%s", hugoContent.Serialize())
+	}
+}
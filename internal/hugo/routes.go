@@ -0,0 +1,130 @@
+package hugo
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sectionRoute sends the notes under a vault folder to their own content directory
+type sectionRoute struct {
+	vaultPrefix string // Vault-relative folder, e.g. "Blog"
+	contentDir  string // Hugo content path, e.g. "content/posts"
+}
+
+// SetSectionRoutes maps vault folders to Hugo content directories, e.g.
+// "Blog" to "content/posts". Notes under a routed folder land in its content
+// directory with the folder itself dropped from the path; all other notes use
+// the generator's content directory. The longest matching folder wins.
+func (g *Generator) SetSectionRoutes(routes map[string]string) {
+	g.routes = nil
+	for prefix, contentDir := range routes {
+		g.routes = append(g.routes, sectionRoute{
+			vaultPrefix: filepath.Clean(filepath.FromSlash(strings.Trim(prefix, "/"))),
+			contentDir:  filepath.Clean(filepath.FromSlash(contentDir)),
+		})
+	}
+	sort.Slice(g.routes, func(i, j int) bool {
+		if len(g.routes[i].vaultPrefix) != len(g.routes[j].vaultPrefix) {
+			return len(g.routes[i].vaultPrefix) > len(g.routes[j].vaultPrefix)
+		}
+		return g.routes[i].vaultPrefix < g.routes[j].vaultPrefix
+	})
+}
+
+// routeVaultDir returns the content directory for a vault-relative folder
+// and the part of the folder below the routed prefix ("." when none)
+func (g *Generator) routeVaultDir(relDir string) (string, string, bool) {
+	for _, route := range g.routes {
+		if rest, ok := trimPathPrefix(relDir, route.vaultPrefix); ok {
+			return route.contentDir, rest, true
+		}
+	}
+	return g.contentDir, relDir, false
+}
+
+// SectionDir returns the Hugo section directory (relative to the repo root)
+// for a vault-relative folder
+func (g *Generator) SectionDir(relDir string) string {
+	contentDir, rest, _ := g.routeVaultDir(relDir)
+	rest = windowsSafeDirs(rest)
+	if g.maxSectionDepth > 0 {
+		if dirs := strings.Split(rest, string(filepath.Separator)); len(dirs) > g.maxSectionDepth {
+			rest = filepath.Join(dirs[:g.maxSectionDepth]...)
+		}
+	}
+	return filepath.Join(contentDir, rest)
+}
+
+// ContentDirs returns every content directory notes are written to: the
+// default one first, then the routed ones, skipping any nested in another
+func (g *Generator) ContentDirs() []string {
+	dirs := []string{filepath.Clean(g.contentDir)}
+	for _, route := range g.routes {
+		dirs = append(dirs, route.contentDir)
+	}
+
+	var result []string
+	for i, dir := range dirs {
+		nested := false
+		for j, other := range dirs {
+			if _, ok := trimPathPrefix(dir, other); ok && (dir != other || j < i) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// IsContentRoot reports whether a directory (relative to the repo root) is
+// the default or a routed content directory, which never get a generated index
+func (g *Generator) IsContentRoot(dir string) bool {
+	dir = filepath.Clean(dir)
+	if dir == filepath.Clean(g.contentDir) {
+		return true
+	}
+	for _, route := range g.routes {
+		if dir == route.contentDir {
+			return true
+		}
+	}
+	return false
+}
+
+// vaultDirForSection maps a Hugo section directory back to its vault folder
+func (g *Generator) vaultDirForSection(dirPath string) (string, bool) {
+	dirPath = filepath.Clean(dirPath)
+
+	// Routed directories first, longest (most specific) first
+	routes := append([]sectionRoute{}, g.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].contentDir) > len(routes[j].contentDir)
+	})
+	for _, route := range routes {
+		if rest, ok := trimPathPrefix(dirPath, route.contentDir); ok {
+			return filepath.Join(route.vaultPrefix, rest), true
+		}
+	}
+
+	rest, ok := trimPathPrefix(dirPath, filepath.Clean(g.contentDir))
+	return rest, ok
+}
+
+// trimPathPrefix removes a leading directory prefix from path, matching whole
+// path components; it returns "." when path equals prefix
+func trimPathPrefix(path, prefix string) (string, bool) {
+	if path == prefix {
+		return ".", true
+	}
+	if prefix == "." {
+		return path, true
+	}
+	if strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+		return path[len(prefix)+1:], true
+	}
+	return "", false
+}
@@ -0,0 +1,131 @@
+package hugo
+
+import (
+	"strings"
+	"unicode"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+// Handling of nested Obsidian tags such as "project/alpha/frontend" in the
+// emitted Hugo tags
+const (
+	NestedTagKeep   = "keep"   // Emit the nested tag as written
+	NestedTagExpand = "expand" // Emit the tag and each of its ancestors
+	NestedTagSplit  = "split"  // Emit only the final segment
+)
+
+// SetNestedTagMode sets how nested tags become Hugo taxonomy terms
+// (NestedTagKeep, NestedTagExpand or NestedTagSplit)
+func (g *Generator) SetNestedTagMode(mode string) {
+	g.nestedTagMode = mode
+}
+
+// taxonomyTags converts a note's tags into the Hugo tags to emit. Leading
+// hashes are dropped, the publish and menu/ tags are left out as they only
+// control syncing, and nested tags are expanded or split per the nested tag mode.
+func (g *Generator) taxonomyTags(tags []string) []string {
+	var terms []string
+	for _, tag := range tags {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "#"), "/")
+		if tag == "" || tag == strings.TrimPrefix(vault.PublishTag, "#") || strings.HasPrefix(tag, menuTagPrefix) {
+			continue
+		}
+		
+		switch g.nestedTagMode {
+		case NestedTagExpand:
+			for i := range len(tag) {
+				if tag[i] == '/' {
+					terms = append(terms, tag[:i])
+				}
+			}
+			terms = append(terms, tag)
+		case NestedTagSplit:
+			terms = append(terms, tag[strings.LastIndex(tag, "/")+1:])
+		default:
+			terms = append(terms, tag)
+		}
+	}
+	return dedupeStrings(terms)
+}
+
+// SetStripPublishTag removes the inline publish tag from generated content when enabled
+func (g *Generator) SetStripPublishTag(strip bool) {
+	g.stripPublishTag = strip
+}
+
+// removePublishTag strips inline occurrences of the publish tag from the note
+// body. Code and markdown links are left untouched, and longer tags such as
+// #publishing or #publish/later are kept. Lines that held nothing but the tag
+// are dropped.
+func (g *Generator) removePublishTag(content string) string {
+	if !g.stripPublishTag || !strings.Contains(content, vault.PublishTag) {
+		return content
+	}
+	
+	protected := g.protectCodeSections(content)
+	
+	lines := strings.Split(protected, "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		stripped := removeTag(line, vault.PublishTag)
+		if stripped != line && strings.TrimSpace(stripped) == "" {
+			continue
+		}
+		result = append(result, stripped)
+	}
+	
+	return g.restoreCodeSections(strings.Join(result, "\n"))
+}
+
+// removeTag removes whole-tag occurrences of tag from a single line along with
+// one adjacent space, so the surrounding words stay evenly spaced
+func removeTag(line, tag string) string {
+	var sb strings.Builder
+	
+	for {
+		idx := indexTag(line, tag)
+		if idx < 0 {
+			sb.WriteString(line)
+			return sb.String()
+		}
+		
+		before, after := line[:idx], line[idx+len(tag):]
+		if strings.HasPrefix(after, " ") {
+			after = after[1:]
+		} else if strings.HasSuffix(before, " ") {
+			before = before[:len(before)-1]
+		}
+		
+		sb.WriteString(before)
+		line = after
+	}
+}
+
+// indexTag returns the position of the first occurrence of tag in line that
+// starts a tag (at line start or after whitespace or an opening bracket) and
+// is not the prefix of a longer tag, or -1
+func indexTag(line, tag string) int {
+	offset := 0
+	for {
+		idx := strings.Index(line[offset:], tag)
+		if idx < 0 {
+			return -1
+		}
+		idx += offset
+		
+		startOK := idx == 0 || strings.ContainsRune(" \t([", rune(line[idx-1]))
+		end := idx + len(tag)
+		endOK := end == len(line) || !isTagRune(rune(line[end]))
+		if startOK && endOK {
+			return idx
+		}
+		
+		offset = idx + 1
+	}
+}
+
+// isTagRune reports whether r can continue an Obsidian tag
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
@@ -0,0 +1,112 @@
+package hugo
+
+import (
+	"strings"
+	"testing"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestRemovePublishTag(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetStripPublishTag(true)
+	
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "tag on its own line",
+			input:    "# Title\n\n#publish\n\nBody text\n",
+			expected: "# Title\n\n\nBody text\n",
+		},
+		{
+			name:     "tag inline with other tags",
+			input:    "Tags: #guide #publish #howto\n",
+			expected: "Tags: #guide #howto\n",
+		},
+		{
+			name:     "tag at line end",
+			input:    "Ready to ship #publish\n",
+			expected: "Ready to ship\n",
+		},
+		{
+			name:     "longer tags are kept",
+			input:    "About #publishing and #publish/later and #publish-ready\n",
+			expected: "About #publishing and #publish/later and #publish-ready\n",
+		},
+		{
+			name:     "code is kept",
+			input:    "Use `#publish` to mark notes\n\n```\n#publish\n```\n",
+			expected: "Use `#publish` to mark notes\n\n```\n#publish\n```\n",
+		},
+		{
+			name:     "URL fragments are kept",
+			input:    "See https://example.com/docs#publish and [docs](https://example.com/#publish)\n",
+			expected: "See https://example.com/docs#publish and [docs](https://example.com/#publish)\n",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := generator.removePublishTag(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRemovePublishTagDisabled(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	
+	input := "Ready to ship #publish\n"
+	if result := generator.removePublishTag(input); result != input {
+		t.Errorf("Expected content unchanged when disabled, got %q", result)
+	}
+}
+
+func TestTaxonomyTags(t *testing.T) {
+	tags := []string{"#project/alpha/frontend", "project/beta", "guide", "#publish"}
+	
+	tests := []struct {
+		mode     string
+		expected []string
+	}{
+		{NestedTagKeep, []string{"project/alpha/frontend", "project/beta", "guide"}},
+		{NestedTagExpand, []string{"project", "project/alpha", "project/alpha/frontend", "project/beta", "guide"}},
+		{NestedTagSplit, []string{"frontend", "beta", "guide"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetNestedTagMode(tt.mode)
+			
+			result := generator.taxonomyTags(tags)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected tags %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateContentEmitsExpandedTags(t *testing.T) {
+	generator := NewGenerator("/vault", "content/docs", "relref", "text")
+	generator.SetNestedTagMode(NestedTagExpand)
+	
+	note := &vault.Note{
+		Path:  "/vault/Guides/Note.md",
+		Title: "Note",
+		UID:   "uid-1",
+		Tags:  []string{"project/alpha", "#publish"},
+	}
+	content, err := generator.GenerateContent(note, 10)
+	if err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	
+	if !strings.Contains(content.Serialize(), "tags:\n  - \"project\"\n  - \"project/alpha\"\n") {
+		t.Errorf("Expected expanded tags in front-matter, got:\n%s", content.Serialize())
+	}
+}
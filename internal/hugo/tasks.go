@@ -0,0 +1,170 @@
+package hugo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Handling of Tasks plugin metadata such as "📅 2024-01-01 ⏫" on task lines
+const (
+	TaskMetadataKeep   = "keep"   // Leave task lines as written
+	TaskMetadataStrip  = "strip"  // Remove the metadata, keeping checkbox and text
+	TaskMetadataSuffix = "suffix" // Replace the metadata with "(due 2024-01-01, high priority)"
+)
+
+// taskField is a Tasks plugin metadata marker and how it reads in a suffix
+type taskField struct {
+	marker string
+	label  string // Empty for fields left out of the readable suffix
+	kind   int
+}
+
+// Values a task field takes
+const (
+	taskFieldDate     = iota // A YYYY-MM-DD date follows the marker
+	taskFieldPriority        // The marker stands alone
+	taskFieldText            // Free text follows the marker
+)
+
+// taskFields lists the Tasks plugin markers in the order they are searched
+var taskFields = []taskField{
+	{"📅", "due", taskFieldDate},
+	{"⏳", "scheduled", taskFieldDate},
+	{"🛫", "starts", taskFieldDate},
+	{"➕", "created", taskFieldDate},
+	{"✅", "done", taskFieldDate},
+	{"❌", "cancelled", taskFieldDate},
+	{"🔺", "highest priority", taskFieldPriority},
+	{"⏫", "high priority", taskFieldPriority},
+	{"🔼", "medium priority", taskFieldPriority},
+	{"🔽", "low priority", taskFieldPriority},
+	{"⏬", "lowest priority", taskFieldPriority},
+	{"🔁", "repeats", taskFieldText},
+	{"🆔", "", taskFieldText},
+	{"⛔", "", taskFieldText},
+}
+
+var (
+	// taskLineRegex matches a list item with a checkbox, capturing the prefix and task text
+	taskLineRegex = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[.\]\s+)(.*)$`)
+
+	// taskDateRegex matches the value of a date field
+	taskDateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// SetTaskMetadata sets how Tasks plugin metadata on task lines is rendered
+// (TaskMetadataKeep, TaskMetadataStrip or TaskMetadataSuffix)
+func (g *Generator) SetTaskMetadata(mode string) {
+	g.taskMetadata = mode
+}
+
+// cleanTasks strips or rewrites the metadata decorations the Tasks plugin adds
+// to checkbox items. Code is left untouched, as are lines whose trailing
+// markers don't parse as task metadata.
+func (g *Generator) cleanTasks(content string) string {
+	if g.taskMetadata == "" || g.taskMetadata == TaskMetadataKeep {
+		return content
+	}
+
+	protected := g.protectCodeSections(content)
+
+	lines := strings.Split(protected, "\n")
+	for i, line := range lines {
+		matches := taskLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		if text, ok := g.cleanTaskText(matches[2]); ok {
+			lines[i] = matches[1] + text
+		}
+	}
+
+	return g.restoreCodeSections(strings.Join(lines, "\n"))
+}
+
+// cleanTaskText removes the metadata from the end of a task's text, adding a
+// readable suffix in suffix mode. It reports false when there is none.
+func (g *Generator) cleanTaskText(text string) (string, bool) {
+	start := -1
+	for _, field := range taskFields {
+		if idx := strings.Index(text, field.marker); idx >= 0 && (start < 0 || idx < start) {
+			start = idx
+		}
+	}
+	if start < 0 {
+		return text, false
+	}
+
+	parts, ok := parseTaskMetadata(text[start:])
+	if !ok {
+		return text, false
+	}
+
+	cleaned := strings.TrimRight(text[:start], " \t")
+	if g.taskMetadata == TaskMetadataSuffix && len(parts) > 0 {
+		cleaned += fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+	}
+	return cleaned, true
+}
+
+// parseTaskMetadata splits a run of metadata into readable parts such as
+// "due 2024-01-01". It reports false if anything in it isn't valid metadata.
+func parseTaskMetadata(metadata string) ([]string, bool) {
+	var parts []string
+
+	for metadata != "" {
+		field, ok := taskFieldAt(metadata)
+		if !ok {
+			return nil, false
+		}
+		rest := metadata[len(field.marker):]
+		rest = strings.TrimPrefix(rest, "\uFE0F") // Emoji presentation selector
+
+		// The value runs up to the next marker
+		end := len(rest)
+		for _, other := range taskFields {
+			if idx := strings.Index(rest, other.marker); idx >= 0 && idx < end {
+				end = idx
+			}
+		}
+		value := strings.TrimSpace(rest[:end])
+		metadata = rest[end:]
+
+		switch field.kind {
+		case taskFieldDate:
+			if !taskDateRegex.MatchString(value) {
+				return nil, false
+			}
+		case taskFieldPriority:
+			if value != "" {
+				return nil, false
+			}
+		case taskFieldText:
+			if value == "" {
+				return nil, false
+			}
+		}
+
+		if field.label == "" {
+			continue
+		}
+		if value != "" {
+			parts = append(parts, field.label+" "+value)
+		} else {
+			parts = append(parts, field.label)
+		}
+	}
+
+	return parts, true
+}
+
+// taskFieldAt returns the metadata field whose marker starts s
+func taskFieldAt(s string) (taskField, bool) {
+	for _, field := range taskFields {
+		if strings.HasPrefix(s, field.marker) {
+			return field, true
+		}
+	}
+	return taskField{}, false
+}
@@ -0,0 +1,72 @@
+package hugo
+
+import "testing"
+
+func TestCleanTasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "due date and priority stripped",
+			mode:     TaskMetadataStrip,
+			input:    "- [ ] do thing 📅 2024-01-01 ⏫\n",
+			expected: "- [ ] do thing\n",
+		},
+		{
+			name:     "completion date stripped",
+			mode:     TaskMetadataStrip,
+			input:    "- [x] done ✅ 2024-01-02\n",
+			expected: "- [x] done\n",
+		},
+		{
+			name:     "readable suffix",
+			mode:     TaskMetadataSuffix,
+			input:    "  * [ ] water plants 🔁 every week ⏳ 2024-01-01 🔼 🆔 abc123\n",
+			expected: "  * [ ] water plants (repeats every week, scheduled 2024-01-01, medium priority)\n",
+		},
+		{
+			name:     "plain task untouched",
+			mode:     TaskMetadataStrip,
+			input:    "- [ ] plain task\n- [x] finished task\n",
+			expected: "- [ ] plain task\n- [x] finished task\n",
+		},
+		{
+			name:     "emoji in prose untouched",
+			mode:     TaskMetadataStrip,
+			input:    "- [ ] plan the 📅 calendar review\n",
+			expected: "- [ ] plan the 📅 calendar review\n",
+		},
+		{
+			name:     "non-task list item untouched",
+			mode:     TaskMetadataStrip,
+			input:    "- release 📅 2024-01-01\n",
+			expected: "- release 📅 2024-01-01\n",
+		},
+		{
+			name:     "code untouched",
+			mode:     TaskMetadataStrip,
+			input:    "```\n- [ ] in code 📅 2024-01-01\n```\n",
+			expected: "```\n- [ ] in code 📅 2024-01-01\n```\n",
+		},
+		{
+			name:     "keep mode",
+			mode:     TaskMetadataKeep,
+			input:    "- [ ] do thing 📅 2024-01-01 ⏫\n",
+			expected: "- [ ] do thing 📅 2024-01-01 ⏫\n",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetTaskMetadata(tt.mode)
+			
+			if result := generator.cleanTasks(tt.input); result != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
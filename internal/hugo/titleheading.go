@@ -0,0 +1,69 @@
+package hugo
+
+import (
+	"strings"
+)
+
+// Handling of an H1 that repeats the note title at the top of the body
+const (
+	StripH1Keep   = "keep"   // Body is left as written
+	StripH1Match  = "match"  // First H1 is removed when its text matches the title
+	StripH1Always = "always" // First H1 is removed whatever its text
+)
+
+// SetStripH1 selects how the first H1 of a note body is handled ("" keeps it)
+func (g *Generator) SetStripH1(mode string) {
+	g.stripH1 = mode
+}
+
+// stripTitleHeading removes the first level-1 ATX heading outside fenced code
+// from a note body, along with the blank lines after it. In match mode it is
+// only removed when it reads the same as one of the titles, compared by the
+// heading ID both would get so case, markup and punctuation don't matter.
+func (g *Generator) stripTitleHeading(content string, titles ...string) string {
+	if g.stripH1 != StripH1Match && g.stripH1 != StripH1Always {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+			continue
+		}
+
+		matches := atxHeadingRegex.FindStringSubmatch(line)
+		if matches == nil || len(matches[1]) != 1 {
+			continue
+		}
+		if g.stripH1 == StripH1Match && !matchesTitle(matches[2], titles) {
+			return content
+		}
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+			end++
+		}
+		return strings.Join(append(lines[:i:i], lines[end:]...), "\n")
+	}
+	return content
+}
+
+// matchesTitle reports whether heading markdown reads the same as one of the titles
+func matchesTitle(heading string, titles []string) bool {
+	id := anchorID(strings.TrimSpace(heading))
+	for _, title := range titles {
+		if id != "" && id == anchorID(strings.TrimSpace(title)) {
+			return true
+		}
+	}
+	return false
+}
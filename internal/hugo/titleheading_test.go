@@ -0,0 +1,79 @@
+package hugo
+
+import (
+	"testing"
+)
+
+func TestStripTitleHeading(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "matching H1 stripped",
+			mode:     StripH1Match,
+			content:  "# Setup Guide\n\nInstall it.\n",
+			expected: "Install it.\n",
+		},
+		{
+			name:     "match ignores case and markup",
+			mode:     StripH1Match,
+			content:  "# *setup* [[Other|guide]]\nInstall it.\n",
+			expected: "Install it.\n",
+		},
+		{
+			name:     "non-matching H1 kept in match mode",
+			mode:     StripH1Match,
+			content:  "# Overview\n\nInstall it.\n",
+			expected: "# Overview\n\nInstall it.\n",
+		},
+		{
+			name:     "non-matching H1 stripped in always mode",
+			mode:     StripH1Always,
+			content:  "# Overview\n\nInstall it.\n",
+			expected: "Install it.\n",
+		},
+		{
+			name:     "only the first H1 stripped",
+			mode:     StripH1Always,
+			content:  "# Setup Guide\n\nIntro\n\n# Second\n",
+			expected: "Intro\n\n# Second\n",
+		},
+		{
+			name:     "H2 kept",
+			mode:     StripH1Always,
+			content:  "## Setup Guide\n\nIntro\n",
+			expected: "## Setup Guide\n\nIntro\n",
+		},
+		{
+			name:     "comment in code kept",
+			mode:     StripH1Always,
+			content:  "```bash\n# Setup Guide\necho hi\n```\n\n# Setup Guide\n\nIntro\n",
+			expected: "```bash\n# Setup Guide\necho hi\n```\n\nIntro\n",
+		},
+		{
+			name:     "tilde fence respected",
+			mode:     StripH1Match,
+			content:  "~~~\n# Setup Guide\n~~~\n",
+			expected: "~~~\n# Setup Guide\n~~~\n",
+		},
+		{
+			name:     "keep mode leaves H1",
+			mode:     StripH1Keep,
+			content:  "# Setup Guide\n\nIntro\n",
+			expected: "# Setup Guide\n\nIntro\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator("/vault", "content/docs", "relref", "text")
+			generator.SetStripH1(tt.mode)
+			if result := generator.stripTitleHeading(tt.content, "Setup Guide"); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package hugo
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// windowsReservedNames are device names Windows won't create files or
+// folders under, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsSafeName makes a file or folder name valid on Windows on every
+// platform, so a Hugo repository synced on Linux still checks out there:
+// trailing dots and spaces are dropped and reserved device names get a
+// trailing underscore ("con" becomes "con_")
+func windowsSafeName(name string) string {
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return "_"
+	}
+	base, _, _ := strings.Cut(name, ".")
+	if windowsReservedNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		return base + "_" + strings.TrimPrefix(name, base)
+	}
+	return name
+}
+
+// windowsSafeDirs applies windowsSafeName to each folder of a relative path
+func windowsSafeDirs(relDir string) string {
+	if relDir == "." || relDir == "" {
+		return relDir
+	}
+	dirs := strings.Split(relDir, string(filepath.Separator))
+	for i, dir := range dirs {
+		dirs[i] = windowsSafeName(dir)
+	}
+	return filepath.Join(dirs...)
+}
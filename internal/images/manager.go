@@ -1,13 +1,18 @@
 package images
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"obsidian-hugo-sync/internal/state"
 )
 
 // Manager handles image copying and cleanup
@@ -15,8 +20,25 @@ type Manager struct {
 	vaultPath   string
 	hugoPath    string
 	contentDir  string
+	outputDir   string                     // Flat directory for all images; empty mirrors the vault layout under contentDir
+	preserve    func(fullPath string) bool // Reports images cleanup must never delete
+	keepUnused  func(imagePath string)     // Called instead of deleting unused images; nil deletes them
 	dryRun      bool
 	gracePeriod time.Duration
+	stored      map[string]string // content hash -> Hugo path of a copied image
+
+	// Optimization is disabled while maxDimension is 0
+	maxDimension int
+	quality      int
+	optimized    map[string]optimizedImage // Hugo path -> optimized copy
+
+	// SVGs below this size in bytes are inlined instead of copied; 0 copies all
+	inlineSVGUnder int64
+
+	// Concurrent copies are bounded by workers and deduplicated by destination
+	mu       sync.Mutex           // Guards stored, optimized and inflight
+	workers  chan struct{}        // One slot per copy allowed to run at once
+	inflight map[string]*copyCall // Hugo path -> copy in progress
 }
 
 // NewManager creates a new image manager
@@ -27,19 +49,58 @@ func NewManager(vaultPath, hugoPath, contentDir string, dryRun bool) *Manager {
 		contentDir:  contentDir,
 		dryRun:      dryRun,
 		gracePeriod: 24 * time.Hour, // 24h grace period before cleanup
+		stored:      make(map[string]string),
+		optimized:   make(map[string]optimizedImage),
+		workers:     make(chan struct{}, DefaultWorkers),
+		inflight:    make(map[string]*copyCall),
+	}
+}
+
+// RegisterImageHash records an already copied image so identical content can be deduplicated
+func (m *Manager) RegisterImageHash(vaultImagePath, hash string) {
+	if hash != "" {
+		m.mu.Lock()
+		m.stored[hash] = m.calculateHugoImagePath(vaultImagePath)
+		m.mu.Unlock()
 	}
 }
 
+// SetOutputDir routes every image into a single directory (relative to the
+// Hugo repository, e.g. "static/images") instead of mirroring its vault folder
+// under the content directory. Empty keeps the mirrored layout.
+func (m *Manager) SetOutputDir(dir string) {
+	m.outputDir = dir
+}
+
+// SetKeepUnused leaves unused images in place during cleanup, passing each
+// one's Hugo path to keep instead of deleting it
+func (m *Manager) SetKeepUnused(keep func(imagePath string)) {
+	m.keepUnused = keep
+}
+
+// SetPreserve sets a check for images that cleanup must leave in place even
+// when nothing references them
+func (m *Manager) SetPreserve(preserve func(fullPath string) bool) {
+	m.preserve = preserve
+}
+
 // ImageInfo represents information about an image
 type ImageInfo struct {
 	VaultPath string    // Original path in vault
 	HugoPath  string    // Target path in Hugo repo
 	Size      int64     // File size in bytes
 	ModTime   time.Time // Last modification time
+	Hash      string    // SHA256 of the image content
+
+	// Optimized is set when a downscaled copy was written instead of the
+	// original, with OutputHash holding the SHA256 of that copy
+	Optimized  bool
+	OutputHash string
 }
 
-// CopyImage copies an image from vault to Hugo repository
-func (m *Manager) CopyImage(vaultImagePath, noteUID string) (*ImageInfo, error) {
+// copyImage copies an image from vault to Hugo repository; callers go through
+// CopyImage, which bounds and deduplicates concurrent copies
+func (m *Manager) copyImage(vaultImagePath, noteUID string) (*ImageInfo, error) {
 	// Validate image format
 	if !m.isSupportedFormat(vaultImagePath) {
 		return nil, fmt.Errorf("unsupported image format: %s", filepath.Ext(vaultImagePath))
@@ -64,30 +125,68 @@ func (m *Manager) CopyImage(vaultImagePath, noteUID string) (*ImageInfo, error)
 	}
 
 	// Check if source exists
-	srcPath := vaultImagePath
-	if !filepath.IsAbs(srcPath) {
-		srcPath = filepath.Join(m.vaultPath, vaultImagePath)
-	}
+	srcPath := m.sourcePath(vaultImagePath)
 
 	srcInfo, err := os.Stat(srcPath)
 	if err != nil {
 		return nil, fmt.Errorf("source image not found: %w", err)
 	}
 
+	srcHash, err := hashFile(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("hashing source image: %w", err)
+	}
+
+	info := &ImageInfo{
+		VaultPath: vaultImagePath,
+		HugoPath:  hugoImagePath,
+		Size:      srcInfo.Size(),
+		ModTime:   srcInfo.ModTime(),
+		Hash:      srcHash,
+	}
+
 	// Calculate full destination path
 	dstPath := filepath.Join(m.hugoPath, hugoImagePath)
 
-	// Check if destination already exists and is up to date
-	if dstInfo, err := os.Stat(dstPath); err == nil {
-		if dstInfo.ModTime().Equal(srcInfo.ModTime()) && dstInfo.Size() == srcInfo.Size() {
-			slog.Debug("Image already up to date", "path", hugoImagePath)
-			return &ImageInfo{
-				VaultPath: vaultImagePath,
-				HugoPath:  hugoImagePath,
-				Size:      srcInfo.Size(),
-				ModTime:   srcInfo.ModTime(),
-			}, nil
+	dstHash, _ := hashFile(dstPath)
+	
+	// Large raster images are downscaled; an optimized copy made from the
+	// same source is kept without re-encoding it
+	var optimized []byte
+	if m.maxDimension > 0 && canOptimize(srcPath) {
+		m.mu.Lock()
+		prev, ok := m.optimized[hugoImagePath]
+		m.mu.Unlock()
+		if ok && prev.sourceHash == srcHash && dstHash == prev.outputHash {
+			slog.Debug("Optimized image already up to date", "path", hugoImagePath)
+			info.Optimized = true
+			info.OutputHash = prev.outputHash
+			return info, nil
 		}
+		
+		optimized, err = m.optimizeImage(srcPath)
+		if err != nil {
+			slog.Warn("Failed to optimize image, copying original", "path", vaultImagePath, "error", err)
+			optimized = nil
+		}
+	}
+	
+	if optimized != nil {
+		info.Optimized = true
+		info.OutputHash = hashBytes(optimized)
+		m.mu.Lock()
+		m.optimized[hugoImagePath] = optimizedImage{sourceHash: srcHash, outputHash: info.OutputHash}
+		m.mu.Unlock()
+		
+		if dstHash == info.OutputHash {
+			slog.Debug("Optimized image already up to date", "path", hugoImagePath)
+			return info, nil
+		}
+	} else if dstHash == srcHash {
+		// Destination already exists with identical content
+		slog.Debug("Image already up to date", "path", hugoImagePath)
+		m.storeHash(srcHash, hugoImagePath)
+		return info, nil
 	}
 
 	// Ensure destination directory exists
@@ -95,10 +194,49 @@ func (m *Manager) CopyImage(vaultImagePath, noteUID string) (*ImageInfo, error)
 		return nil, fmt.Errorf("creating destination directory: %w", err)
 	}
 
+	// Never write through an existing file, it may be a hard link shared with another image
+	if err := os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("replacing image: %w", err)
+	}
+
+	if optimized != nil {
+		if err := os.WriteFile(dstPath, optimized, 0644); err != nil {
+			return nil, fmt.Errorf("writing optimized image: %w", err)
+		}
+		if err := os.Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+			slog.Warn("Failed to preserve image modification time", "path", dstPath, "error", err)
+		}
+		
+		slog.Info("Copied optimized image",
+			"from", vaultImagePath,
+			"to", hugoImagePath,
+			"size", srcInfo.Size(),
+			"optimized_size", len(optimized))
+		return info, nil
+	}
+	
+	// Identical content already stored elsewhere is linked instead of copied
+	m.mu.Lock()
+	existing, ok := m.stored[srcHash]
+	m.mu.Unlock()
+	if ok && existing != hugoImagePath {
+		existingPath := filepath.Join(m.hugoPath, existing)
+		if existingHash, err := hashFile(existingPath); err == nil && existingHash == srcHash {
+			if err := os.Link(existingPath, dstPath); err == nil {
+				slog.Info("Linked identical image",
+					"from", vaultImagePath,
+					"to", hugoImagePath,
+					"existing", existing)
+				return info, nil
+			}
+		}
+	}
+
 	// Copy the file
 	if err := m.copyFile(srcPath, dstPath); err != nil {
 		return nil, fmt.Errorf("copying image: %w", err)
 	}
+	m.storeHash(srcHash, hugoImagePath)
 
 	// Preserve modification time
 	if err := os.Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
@@ -110,17 +248,22 @@ func (m *Manager) CopyImage(vaultImagePath, noteUID string) (*ImageInfo, error)
 		"to", hugoImagePath,
 		"size", srcInfo.Size())
 
-	return &ImageInfo{
-		VaultPath: vaultImagePath,
-		HugoPath:  hugoImagePath,
-		Size:      srcInfo.Size(),
-		ModTime:   srcInfo.ModTime(),
-	}, nil
+	return info, nil
 }
 
-// CleanupUnusedImages removes images that are no longer referenced
-func (m *Manager) CleanupUnusedImages(referencedImages map[string][]string) error {
+// CleanupUnusedImages removes images that are no longer referenced. The grace
+// period is measured from the image's last reference in the state, falling back
+// to the file modification time for images the state does not track. It returns
+// the vault paths of tracked images that were deleted or no longer exist, so the
+// caller can drop them from the state.
+func (m *Manager) CleanupUnusedImages(trackedImages map[string]*state.Image) ([]string, error) {
 	// Find all images in the Hugo repository
+	// State tracks images by vault path; index them by Hugo path for lookup
+	vaultPaths := make(map[string]string, len(trackedImages))
+	for vaultPath := range trackedImages {
+		vaultPaths[m.calculateHugoImagePath(vaultPath)] = vaultPath
+	}
+	
 	var existingImages []string
 	
 	contentPath := filepath.Join(m.hugoPath, m.contentDir)
@@ -142,32 +285,70 @@ func (m *Manager) CleanupUnusedImages(referencedImages map[string][]string) erro
 	})
 
 	if err != nil {
-		return fmt.Errorf("scanning existing images: %w", err)
+		return nil, fmt.Errorf("scanning existing images: %w", err)
+	}
+	
+	// The output directory may hold hand-placed images too, so only images
+	// the state tracks are candidates there
+	if m.outputDir != "" && !m.inContentDir(m.outputDir) {
+		for imagePath := range vaultPaths {
+			if _, err := os.Stat(filepath.Join(m.hugoPath, imagePath)); err == nil {
+				existingImages = append(existingImages, imagePath)
+			}
+		}
 	}
 
 	// Check each existing image for references
 	var deletedCount int
+	var forgotten []string
+	seen := make(map[string]bool, len(existingImages))
 	for _, imagePath := range existingImages {
-		if refs := referencedImages[imagePath]; len(refs) == 0 {
-			// No references found, check if grace period has passed
-			fullPath := filepath.Join(m.hugoPath, imagePath)
-			info, err := os.Stat(fullPath)
-			if err != nil {
-				continue // File might have been deleted already
-			}
+		seen[imagePath] = true
 
-			// Check if file is old enough to delete (grace period)
-			if time.Since(info.ModTime()) > m.gracePeriod {
-				if err := m.deleteImage(imagePath); err != nil {
-					slog.Warn("Failed to delete unused image", "path", imagePath, "error", err)
-				} else {
-					deletedCount++
-				}
+		vaultPath, tracked := vaultPaths[imagePath]
+		image := trackedImages[vaultPath]
+		if tracked && image != nil && len(image.Notes) > 0 {
+			continue
+		}
+
+		// No references found, check if grace period has passed
+		fullPath := filepath.Join(m.hugoPath, imagePath)
+		if m.preserve != nil && m.preserve(fullPath) {
+			continue
+		}
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			continue // File might have been deleted already
+		}
+
+		lastUsed := info.ModTime()
+		if tracked && image != nil && !image.LastReferenced.IsZero() {
+			lastUsed = image.LastReferenced
+		}
+
+		// Check if image has been unused long enough to delete (grace period)
+		if time.Since(lastUsed) > m.gracePeriod && m.keepUnused != nil && !m.dryRun {
+			m.keepUnused(imagePath)
+		} else if time.Since(lastUsed) > m.gracePeriod {
+			if err := m.deleteImage(imagePath); err != nil {
+				slog.Warn("Failed to delete unused image", "path", imagePath, "error", err)
 			} else {
-				slog.Debug("Image in grace period, keeping", 
-					"path", imagePath,
-					"remaining", m.gracePeriod-time.Since(info.ModTime()))
+				deletedCount++
+				if tracked && !m.dryRun {
+					forgotten = append(forgotten, vaultPath)
+				}
 			}
+		} else {
+			slog.Debug("Image in grace period, keeping", 
+				"path", imagePath,
+				"remaining", m.gracePeriod-time.Since(lastUsed))
+		}
+	}
+
+	// Unreferenced entries whose Hugo file is already gone can be dropped too
+	for imagePath, vaultPath := range vaultPaths {
+		if image := trackedImages[vaultPath]; !seen[imagePath] && (image == nil || len(image.Notes) == 0) {
+			forgotten = append(forgotten, vaultPath)
 		}
 	}
 
@@ -175,7 +356,7 @@ func (m *Manager) CleanupUnusedImages(referencedImages map[string][]string) erro
 		slog.Info("Cleaned up unused images", "count", deletedCount)
 	}
 
-	return nil
+	return forgotten, nil
 }
 
 // deleteImage removes an image file and cleans up empty directories
@@ -199,6 +380,32 @@ func (m *Manager) deleteImage(imagePath string) error {
 	return nil
 }
 
+// HugoPath returns the path (relative to the Hugo repo root) an image is copied to
+func (m *Manager) HugoPath(vaultImagePath string) string {
+	return m.calculateHugoImagePath(vaultImagePath)
+}
+
+// SiteURL returns the site-absolute URL of an image in the output directory.
+// Hugo serves static/ and content/ at the site root, so that prefix is dropped.
+func (m *Manager) SiteURL(vaultImagePath string) string {
+	urlPath := filepath.ToSlash(m.calculateHugoImagePath(vaultImagePath))
+	for _, root := range []string{"static/", "content/"} {
+		if strings.HasPrefix(urlPath, root) {
+			urlPath = strings.TrimPrefix(urlPath, root)
+			break
+		}
+	}
+	return "/" + urlPath
+}
+
+// sourcePath returns the full path of an image given absolute or relative to the vault
+func (m *Manager) sourcePath(vaultImagePath string) string {
+	if filepath.IsAbs(vaultImagePath) {
+		return vaultImagePath
+	}
+	return filepath.Join(m.vaultPath, vaultImagePath)
+}
+
 // calculateHugoImagePath converts a vault image path to Hugo path
 func (m *Manager) calculateHugoImagePath(vaultImagePath string) string {
 	// Remove vault root prefix if present
@@ -209,10 +416,33 @@ func (m *Manager) calculateHugoImagePath(vaultImagePath string) string {
 		}
 	}
 
+	if m.outputDir != "" {
+		return filepath.Join(m.outputDir, flatImageName(relPath))
+	}
+
 	// Build Hugo path
 	return filepath.Join(m.contentDir, relPath)
 }
 
+// flatImageName names an image in the flat output directory. Images outside
+// the vault root are prefixed with their folders, so same-named images from
+// different folders (Attachments/a/x.png, Attachments/b/x.png) don't collide.
+func flatImageName(relPath string) string {
+	dir, name := path.Split(filepath.ToSlash(filepath.Clean(relPath)))
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return name
+	}
+	prefix := strings.NewReplacer("/", "-", " ", "-").Replace(dir)
+	return prefix + "-" + name
+}
+
+// inContentDir reports whether a Hugo repository path lies in the content directory
+func (m *Manager) inContentDir(path string) bool {
+	rel, err := filepath.Rel(m.contentDir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // isSupportedFormat checks if the file extension is a supported image format
 func (m *Manager) isSupportedFormat(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -226,6 +456,26 @@ func (m *Manager) isSupportedFormat(path string) bool {
 	return false
 }
 
+// hashFile returns the SHA256 of a file's content
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256-%x", hash.Sum(nil)), nil
+}
+
+// hashBytes returns the SHA256 of content in the same form as hashFile
+func hashBytes(content []byte) string {
+	return fmt.Sprintf("sha256-%x", sha256.Sum256(content))
+}
+
 // copyFile copies a file from src to dst
 func (m *Manager) copyFile(src, dst string) error {
 	srcFile, err := os.Open(src)
@@ -249,11 +499,14 @@ func (m *Manager) copyFile(src, dst string) error {
 
 // removeEmptyDirs recursively removes empty directories
 func (m *Manager) removeEmptyDirs(dir string) {
-	// Don't remove the Hugo repository root or content directory
+	// Don't remove the Hugo repository root, content directory or output directory
 	hugoContentDir := filepath.Join(m.hugoPath, m.contentDir)
 	if dir == m.hugoPath || dir == hugoContentDir {
 		return
 	}
+	if m.outputDir != "" && dir == filepath.Join(m.hugoPath, m.outputDir) {
+		return
+	}
 
 	entries, err := os.ReadDir(dir)
 	if err != nil || len(entries) > 0 {
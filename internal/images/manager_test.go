@@ -0,0 +1,252 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"obsidian-hugo-sync/internal/state"
+)
+
+func writeImage(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanupUnusedImagesUsesLastReferenced(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+
+	old := time.Now().Add(-72 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	// Old file that was referenced until an hour ago: still in grace period
+	writeImage(t, filepath.Join(hugoDir, "content/docs/old-file.png"), old)
+	// Fresh file that has been unreferenced for days: past grace period
+	writeImage(t, filepath.Join(hugoDir, "content/docs/new-file.png"), recent)
+	// Referenced image is never removed
+	writeImage(t, filepath.Join(hugoDir, "content/docs/used.png"), old)
+	// Untracked image falls back to file modification time
+	writeImage(t, filepath.Join(hugoDir, "content/docs/untracked.png"), old)
+
+	tracked := map[string]*state.Image{
+		filepath.Join(vaultDir, "old-file.png"): {LastReferenced: recent},
+		filepath.Join(vaultDir, "new-file.png"): {LastReferenced: old},
+		filepath.Join(vaultDir, "used.png"):     {Notes: []string{"note-1"}, LastReferenced: old},
+		filepath.Join(vaultDir, "gone.png"):     {LastReferenced: old},
+	}
+
+	forgotten, err := manager.CleanupUnusedImages(tracked)
+	if err != nil {
+		t.Fatalf("CleanupUnusedImages failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		exists bool
+	}{
+		{"old-file.png", true},
+		{"new-file.png", false},
+		{"used.png", true},
+		{"untracked.png", false},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(hugoDir, "content/docs", tt.name))
+		if exists := err == nil; exists != tt.exists {
+			t.Errorf("Expected %s exists=%v, got %v", tt.name, tt.exists, exists)
+		}
+	}
+
+	want := map[string]bool{
+		filepath.Join(vaultDir, "new-file.png"): true,
+		filepath.Join(vaultDir, "gone.png"):     true,
+	}
+	if len(forgotten) != len(want) {
+		t.Fatalf("Expected %d forgotten images, got %v", len(want), forgotten)
+	}
+	for _, path := range forgotten {
+		if !want[path] {
+			t.Errorf("Unexpected forgotten image %s", path)
+		}
+	}
+}
+
+func TestCopyImageDetectsSameSizeEdit(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+
+	srcPath := filepath.Join(vaultDir, "diagram.png")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeImage(t, srcPath, modTime)
+
+	info, err := manager.CopyImage(srcPath, "note-1")
+	if err != nil {
+		t.Fatalf("CopyImage failed: %v", err)
+	}
+
+	// Same size, same mtime, different bytes
+	if err := os.WriteFile(srcPath, []byte("gif"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(srcPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := manager.CopyImage(srcPath, "note-1")
+	if err != nil {
+		t.Fatalf("CopyImage failed: %v", err)
+	}
+	if updated.Hash == info.Hash {
+		t.Error("Expected hash to change after content edit")
+	}
+
+	content, err := os.ReadFile(filepath.Join(hugoDir, "content/docs/diagram.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "gif" {
+		t.Errorf("Expected same-size edit to be re-copied, got %q", content)
+	}
+}
+
+func TestCopyImageDedupesIdenticalContent(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+
+	first := filepath.Join(vaultDir, "a", "logo.png")
+	second := filepath.Join(vaultDir, "b", "logo copy.png")
+	writeImage(t, first, time.Now())
+	writeImage(t, second, time.Now())
+
+	firstInfo, err := manager.CopyImage(first, "note-a")
+	if err != nil {
+		t.Fatalf("CopyImage failed: %v", err)
+	}
+	secondInfo, err := manager.CopyImage(second, "note-b")
+	if err != nil {
+		t.Fatalf("CopyImage failed: %v", err)
+	}
+	if firstInfo.Hash != secondInfo.Hash {
+		t.Fatalf("Expected identical hashes, got %s and %s", firstInfo.Hash, secondInfo.Hash)
+	}
+
+	firstStat, err := os.Stat(filepath.Join(hugoDir, firstInfo.HugoPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondStat, err := os.Stat(filepath.Join(hugoDir, secondInfo.HugoPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(firstStat, secondStat) {
+		t.Error("Expected identical images to share a single stored file")
+	}
+
+	// Editing one source must not leak into the other through the shared file
+	if err := os.WriteFile(second, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CopyImage(second, "note-b"); err != nil {
+		t.Fatalf("CopyImage failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(hugoDir, firstInfo.HugoPath))
+	if string(content) != "png" {
+		t.Errorf("Expected first image to be unaffected, got %q", content)
+	}
+}
+
+func TestCopyImageFlattensIntoOutputDir(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+	manager.SetOutputDir("static/images")
+
+	first := filepath.Join(vaultDir, "Attachments", "diagrams", "x.png")
+	second := filepath.Join(vaultDir, "Attachments", "photos", "x.png")
+	root := filepath.Join(vaultDir, "logo.png")
+	writeImage(t, first, time.Now())
+	writeImage(t, root, time.Now())
+	if err := os.MkdirAll(filepath.Dir(second), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("gif"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		vaultPath string
+		hugoPath  string
+		url       string
+	}{
+		{vaultPath: first, hugoPath: "static/images/Attachments-diagrams-x.png", url: "/images/Attachments-diagrams-x.png"},
+		{vaultPath: second, hugoPath: "static/images/Attachments-photos-x.png", url: "/images/Attachments-photos-x.png"},
+		{vaultPath: root, hugoPath: "static/images/logo.png", url: "/images/logo.png"},
+	}
+
+	for _, tt := range tests {
+		info, err := manager.CopyImage(tt.vaultPath, "note-1")
+		if err != nil {
+			t.Fatalf("CopyImage failed: %v", err)
+		}
+		if filepath.ToSlash(info.HugoPath) != tt.hugoPath {
+			t.Errorf("Expected image at %s, got %s", tt.hugoPath, info.HugoPath)
+		}
+		if _, err := os.Stat(filepath.Join(hugoDir, tt.hugoPath)); err != nil {
+			t.Errorf("Expected image to be copied to %s: %v", tt.hugoPath, err)
+		}
+		if url := manager.SiteURL(tt.vaultPath); url != tt.url {
+			t.Errorf("Expected URL %s, got %s", tt.url, url)
+		}
+	}
+
+	// Same-named images from different folders keep their own content
+	content, err := os.ReadFile(filepath.Join(hugoDir, "static/images/Attachments-photos-x.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "gif" {
+		t.Errorf("Expected colliding image to keep its own content, got %q", content)
+	}
+}
+
+func TestCleanupKeepUnused(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+	var kept []string
+	manager.SetKeepUnused(func(imagePath string) {
+		kept = append(kept, filepath.ToSlash(imagePath))
+	})
+
+	old := time.Now().Add(-72 * time.Hour)
+	writeImage(t, filepath.Join(hugoDir, "content/docs/unused.png"), old)
+	tracked := map[string]*state.Image{
+		filepath.Join(vaultDir, "unused.png"): {LastReferenced: old},
+	}
+
+	forgotten, err := manager.CleanupUnusedImages(tracked)
+	if err != nil {
+		t.Fatalf("CleanupUnusedImages failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hugoDir, "content/docs/unused.png")); err != nil {
+		t.Errorf("Expected the unused image to be kept: %v", err)
+	}
+	if len(kept) != 1 || kept[0] != "content/docs/unused.png" {
+		t.Errorf("Expected the unused image to be passed on, got %v", kept)
+	}
+	if len(forgotten) != 0 {
+		t.Errorf("Expected a kept image to stay tracked, got %v", forgotten)
+	}
+}
@@ -0,0 +1,140 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// optimizedImage records the optimized copy written for a vault image
+type optimizedImage struct {
+	sourceHash string // Hash of the vault image the copy was made from
+	outputHash string // Hash of the optimized copy in the Hugo repo
+}
+
+// SetOptimize enables downscaling of raster images larger than maxDimension
+// pixels on either side, re-encoding JPEGs at the given quality (1-100). PNGs
+// are re-encoded losslessly; GIF, WebP and SVG images are always copied as-is.
+func (m *Manager) SetOptimize(maxDimension, quality int) {
+	m.maxDimension = maxDimension
+	m.quality = quality
+}
+
+// RegisterOptimizedImage records a previously optimized image so unchanged
+// sources are not re-encoded on later runs
+func (m *Manager) RegisterOptimizedImage(vaultImagePath, sourceHash, outputHash string) {
+	if sourceHash != "" && outputHash != "" {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.optimized[m.calculateHugoImagePath(vaultImagePath)] = optimizedImage{
+			sourceHash: sourceHash,
+			outputHash: outputHash,
+		}
+	}
+}
+
+// canOptimize reports whether an image format can be decoded and re-encoded
+// with the standard library
+func canOptimize(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	default:
+		return false
+	}
+}
+
+// optimizeImage downscales an image exceeding the max dimension and returns the
+// re-encoded content. It returns nil when the image is small enough to copy as-is.
+func (m *Manager) optimizeImage(srcPath string) ([]byte, error) {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening image: %w", err)
+	}
+	defer file.Close()
+
+	config, format, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading image header: %w", err)
+	}
+	if config.Width <= m.maxDimension && config.Height <= m.maxDimension {
+		return nil, nil
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("rewinding image: %w", err)
+	}
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	resized := downscale(src, m.maxDimension)
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: m.quality})
+	case "png":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, resized)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s image: %w", format, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// downscale shrinks an image so its longer side is maxDimension pixels,
+// averaging the source pixels covered by each destination pixel
+func downscale(src image.Image, maxDimension int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := maxDimension, maxDimension
+	if srcW >= srcH {
+		dstH = max(1, srcH*maxDimension/srcW)
+	} else {
+		dstW = max(1, srcW*maxDimension/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
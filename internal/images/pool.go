@@ -0,0 +1,89 @@
+package images
+
+import "sync"
+
+// DefaultWorkers is the number of image copies run at once unless set otherwise
+const DefaultWorkers = 4
+
+// copyCall is a copy in progress that later callers for the same
+// destination wait on instead of writing it again
+type copyCall struct {
+	done chan struct{}
+	info *ImageInfo
+	err  error
+}
+
+// CopyResult is the outcome of copying one image with CopyImages
+type CopyResult struct {
+	VaultPath string
+	Info      *ImageInfo
+	Err       error
+	Inlined   bool // A small SVG inlined into the page, so nothing was copied
+}
+
+// SetWorkers sets how many image copies may run at once (at least 1)
+func (m *Manager) SetWorkers(workers int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = make(chan struct{}, max(workers, 1))
+}
+
+// CopyImage copies an image from vault to Hugo repository. It is safe for
+// concurrent use: copies beyond the worker limit wait for a free slot, and a
+// copy to a destination already being written waits for and shares that result.
+func (m *Manager) CopyImage(vaultImagePath, noteUID string) (*ImageInfo, error) {
+	hugoImagePath := m.calculateHugoImagePath(vaultImagePath)
+
+	m.mu.Lock()
+	if call, ok := m.inflight[hugoImagePath]; ok {
+		m.mu.Unlock()
+		<-call.done
+		return call.info, call.err
+	}
+	call := &copyCall{done: make(chan struct{})}
+	m.inflight[hugoImagePath] = call
+	workers := m.workers
+	m.mu.Unlock()
+
+	workers <- struct{}{}
+	call.info, call.err = m.copyImage(vaultImagePath, noteUID)
+	<-workers
+
+	m.mu.Lock()
+	delete(m.inflight, hugoImagePath)
+	m.mu.Unlock()
+	close(call.done)
+
+	return call.info, call.err
+}
+
+// CopyImages copies a note's images on the worker pool, returning a result
+// for each path in the order given. SVGs below the inline threshold are
+// skipped and marked Inlined.
+func (m *Manager) CopyImages(vaultImagePaths []string, noteUID string) []CopyResult {
+	results := make([]CopyResult, len(vaultImagePaths))
+
+	var wg sync.WaitGroup
+	for i, vaultImagePath := range vaultImagePaths {
+		if m.inlines(vaultImagePath) {
+			results[i] = CopyResult{VaultPath: vaultImagePath, Inlined: true}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := m.CopyImage(vaultImagePath, noteUID)
+			results[i] = CopyResult{VaultPath: vaultImagePath, Info: info, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// storeHash records the Hugo path holding an image's content for deduplication
+func (m *Manager) storeHash(hash, hugoImagePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stored[hash] = hugoImagePath
+}
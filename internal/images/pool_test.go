@@ -0,0 +1,81 @@
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCopyImageConcurrentSameSource(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+	manager.SetWorkers(2)
+	
+	source := filepath.Join(vaultDir, "shared", "diagram.png")
+	writeImage(t, source, time.Now())
+	
+	var wg sync.WaitGroup
+	infos := make([]*ImageInfo, 16)
+	errs := make([]error, 16)
+	for i := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i], errs[i] = manager.CopyImage(source, fmt.Sprintf("note-%d", i))
+		}()
+	}
+	wg.Wait()
+	
+	for i := range infos {
+		if errs[i] != nil {
+			t.Fatalf("CopyImage %d failed: %v", i, errs[i])
+		}
+		if infos[i].HugoPath != infos[0].HugoPath || infos[i].Hash != infos[0].Hash {
+			t.Errorf("Expected every copy to share one result, got %+v and %+v", infos[0], infos[i])
+		}
+	}
+	
+	data, err := os.ReadFile(filepath.Join(hugoDir, infos[0].HugoPath))
+	if err != nil {
+		t.Fatalf("Expected copied image: %v", err)
+	}
+	if string(data) != "png" {
+		t.Errorf("Expected intact image content, got %q", data)
+	}
+	if len(manager.inflight) != 0 {
+		t.Errorf("Expected no copies left in flight, got %d", len(manager.inflight))
+	}
+}
+
+func TestCopyImagesKeepsOrder(t *testing.T) {
+	vaultDir := t.TempDir()
+	hugoDir := t.TempDir()
+	manager := NewManager(vaultDir, hugoDir, "content/docs", false)
+	
+	var paths []string
+	for i := 0; i < 6; i++ {
+		path := filepath.Join(vaultDir, fmt.Sprintf("img-%d.png", i))
+		writeImage(t, path, time.Now())
+		paths = append(paths, path)
+	}
+	paths = append(paths, filepath.Join(vaultDir, "missing.png"), filepath.Join(vaultDir, "notes.txt"))
+	
+	results := manager.CopyImages(paths, "note-a")
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+	for i, result := range results[:6] {
+		if result.VaultPath != paths[i] || result.Err != nil {
+			t.Errorf("Expected copy of %s, got %+v", paths[i], result)
+		}
+	}
+	for _, result := range results[6:] {
+		if result.Err == nil {
+			t.Errorf("Expected an error for %s", result.VaultPath)
+		}
+	}
+}
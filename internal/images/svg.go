@@ -0,0 +1,217 @@
+package images
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// svgAllowedElements are the elements kept in inlined SVGs; any other element
+// is removed together with its content. Scripts, styles, foreign objects and
+// animations, which can set attributes like href to script, are never kept.
+var svgAllowedElements = setOf(
+	"svg", "g", "defs", "symbol", "use", "title", "desc", "switch", "view",
+	"path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+	"text", "tspan", "textpath", "a", "image", "marker", "pattern", "clippath", "mask",
+	"lineargradient", "radialgradient", "stop",
+	"filter", "feblend", "fecolormatrix", "fecomponenttransfer", "fecomposite",
+	"feconvolvematrix", "fediffuselighting", "fedisplacementmap", "fedistantlight",
+	"fedropshadow", "feflood", "fefunca", "fefuncb", "fefuncg", "fefuncr",
+	"fegaussianblur", "feimage", "femerge", "femergenode", "femorphology", "feoffset",
+	"fepointlight", "fespecularlighting", "fespotlight", "fetile", "feturbulence",
+)
+
+// svgAllowedAttrs are the attributes kept on inlined SVG elements, besides
+// namespace declarations and links checked by safeSVGLink
+var svgAllowedAttrs = setOf(
+	"id", "class", "style", "lang", "role", "tabindex", "focusable",
+	"aria-label", "aria-labelledby", "aria-describedby", "aria-hidden",
+	"version", "baseprofile", "viewbox", "preserveaspectratio", "width", "height",
+	"x", "y", "z", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry", "fx", "fy", "fr",
+	"d", "points", "pathlength", "transform", "offset", "target",
+	"fill", "fill-opacity", "fill-rule", "stroke", "stroke-width", "stroke-opacity",
+	"stroke-linecap", "stroke-linejoin", "stroke-miterlimit", "stroke-dasharray",
+	"stroke-dashoffset", "opacity", "color", "display", "visibility", "overflow",
+	"clip", "clip-path", "clip-rule", "clippathunits", "mask", "maskunits", "maskcontentunits",
+	"marker-start", "marker-mid", "marker-end", "markerwidth", "markerheight",
+	"markerunits", "refx", "refy", "orient",
+	"gradientunits", "gradienttransform", "spreadmethod", "stop-color", "stop-opacity",
+	"patternunits", "patterncontentunits", "patterntransform",
+	"font-family", "font-size", "font-size-adjust", "font-stretch", "font-style",
+	"font-variant", "font-weight", "text-anchor", "text-decoration", "text-rendering",
+	"dominant-baseline", "alignment-baseline", "baseline-shift", "letter-spacing",
+	"word-spacing", "writing-mode", "direction", "unicode-bidi",
+	"dx", "dy", "rotate", "textlength", "lengthadjust", "startoffset", "method", "spacing", "side",
+	"shape-rendering", "image-rendering", "color-interpolation", "color-interpolation-filters",
+	"vector-effect", "paint-order", "mix-blend-mode", "isolation", "cursor", "pointer-events",
+	"filter", "filterunits", "primitiveunits", "in", "in2", "result", "stddeviation",
+	"mode", "operator", "k1", "k2", "k3", "k4", "values", "type", "tablevalues", "slope",
+	"intercept", "amplitude", "exponent", "flood-color", "flood-opacity", "lighting-color",
+	"radius", "scale", "xchannelselector", "ychannelselector", "basefrequency", "numoctaves",
+	"seed", "stitchtiles", "edgemode", "kernelmatrix", "kernelunitlength", "order", "divisor",
+	"bias", "targetx", "targety", "preservealpha", "surfacescale", "diffuseconstant",
+	"specularconstant", "specularexponent", "azimuth", "elevation", "pointsatx",
+	"pointsaty", "pointsatz", "limitingconeangle",
+)
+
+// setOf builds a lookup set of names
+func setOf(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// SetInlineSVGUnder inlines SVG images smaller than size bytes into the page
+// instead of copying them; 0 (the default) copies every SVG
+func (m *Manager) SetInlineSVGUnder(size int64) {
+	m.inlineSVGUnder = size
+}
+
+// inlines reports whether an image is an SVG small enough to be inlined
+func (m *Manager) inlines(vaultImagePath string) bool {
+	if m.inlineSVGUnder <= 0 || strings.ToLower(filepath.Ext(vaultImagePath)) != ".svg" {
+		return false
+	}
+	info, err := os.Stat(m.sourcePath(vaultImagePath))
+	return err == nil && info.Size() < m.inlineSVGUnder
+}
+
+// InlineSVG returns the sanitized markup of an SVG image below the inline
+// size threshold. It reports false for other images, which are copied and
+// referenced by URL as usual, and for SVGs that can't be parsed.
+func (m *Manager) InlineSVG(vaultImagePath string) (string, bool) {
+	if !m.inlines(vaultImagePath) {
+		return "", false
+	}
+	data, err := os.ReadFile(m.sourcePath(vaultImagePath))
+	if err != nil {
+		return "", false
+	}
+	markup, err := SanitizeSVG(data)
+	if err != nil {
+		return "", false
+	}
+	return markup, true
+}
+
+// SanitizeSVG returns SVG markup fit for inlining into HTML on a single line:
+// the XML declaration, doctype and comments are removed, and only known
+// drawing elements and attributes are kept, with links limited to safe schemes
+func SanitizeSVG(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var out strings.Builder
+	depth, skipDepth := 0, 0
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parsing svg: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 && !strings.EqualFold(t.Name.Local, "svg") {
+				return "", fmt.Errorf("root element is <%s>, not <svg>", t.Name.Local)
+			}
+			if skipDepth == 0 && (t.Name.Space != "" || !svgAllowedElements[strings.ToLower(t.Name.Local)]) {
+				skipDepth = depth
+			}
+			if skipDepth != 0 {
+				continue
+			}
+			out.WriteString("<" + xmlName(t.Name))
+			for _, attr := range t.Attr {
+				if !safeSVGAttr(attr) {
+					continue
+				}
+				out.WriteString(" " + xmlName(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			if skipDepth == 0 {
+				out.WriteString("</" + xmlName(t.Name) + ">")
+			} else if depth == skipDepth {
+				skipDepth = 0
+			}
+			depth--
+		case xml.CharData:
+			if skipDepth != 0 || depth == 0 || strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			// A blank line would end the HTML block in markdown
+			text := strings.Join(strings.Fields(string(t)), " ")
+			xml.EscapeText(&out, []byte(text))
+		}
+	}
+
+	if out.Len() == 0 {
+		return "", fmt.Errorf("no svg element found")
+	}
+	return out.String(), nil
+}
+
+// xmlName formats an element or attribute name with its namespace prefix
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// safeSVGAttr reports whether an attribute can be kept when inlined: a
+// namespace declaration, an allowed attribute or a safe link
+func safeSVGAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	switch space := strings.ToLower(attr.Name.Space); {
+	case space == "xmlns" || (space == "" && name == "xmlns"):
+		return true
+	case space == "xml":
+		return name == "space" || name == "lang"
+	case name == "href" && (space == "" || space == "xlink"):
+		return safeSVGLink(attr.Value)
+	case space != "":
+		return false // Editor metadata such as inkscape:label
+	case name == "style":
+		value := strings.ToLower(attr.Value)
+		return !strings.Contains(value, "javascript:") && !strings.Contains(value, "expression(") && !strings.Contains(value, "@import")
+	}
+	return svgAllowedAttrs[name]
+}
+
+// safeSVGLink reports whether a link target can't run script: a fragment, a
+// relative URL, an http(s) or mailto URL or a data: image
+func safeSVGLink(value string) bool {
+	// Browsers ignore whitespace and control characters inside the scheme
+	link := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(value))
+
+	scheme, _, found := strings.Cut(link, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true // Relative, with no scheme of its own
+	}
+	switch scheme {
+	case "http", "https", "mailto":
+		return true
+	case "data":
+		return strings.HasPrefix(link, "data:image/") && !strings.HasPrefix(link, "data:image/svg")
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "declaration and comments",
+			input:    "<?xml version=\"1.0\"?>\n<!DOCTYPE svg>\n<!-- icon -->\n<svg viewBox=\"0 0 1 1\">\n\n  <circle r=\"1\"/>\n</svg>\n",
+			expected: `<svg viewBox="0 0 1 1"><circle r="1"></circle></svg>`,
+		},
+		{
+			name:     "scripts and handlers",
+			input:    `<svg onload="x()"><script>x()</script><foreignObject><div>hi</div></foreignObject><g onclick="x()"><rect/></g></svg>`,
+			expected: `<svg><g><rect></rect></g></svg>`,
+		},
+		{
+			name:     "javascript links",
+			input:    `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><a xlink:href=" javascript:x()"><text>A &amp; B</text></a><use href="#icon"/></svg>`,
+			expected: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><a><text>A &amp; B</text></a><use href="#icon"></use></svg>`,
+		},
+		{
+			name:     "animations setting links",
+			input:    `<svg><a><set attributeName="href" to="javascript:alert(1)"/><animate attributeName="href" values="javascript:alert(1)"/><text>Go</text></a></svg>`,
+			expected: `<svg><a><text>Go</text></a></svg>`,
+		},
+		{
+			name:     "styles and unknown markup",
+			input:    `<svg xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"><style>@import url(x.css)</style><handler>x()</handler><g inkscape:label="Layer" fill="red" formaction="x"><rect style="fill:blue"/></g></svg>`,
+			expected: `<svg xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"><g fill="red"><rect style="fill:blue"></rect></g></svg>`,
+		},
+		{
+			name:     "link schemes",
+			input:    `<svg><a href="java&#x09;script:x()"/><a href="vbscript:x()"/><a href="data:text/html,x"/><image href="data:image/svg+xml;base64,x"/><a href="https://example.com/"/><image href="data:image/png;base64,x"/><use href="sprite.svg#icon"/></svg>`,
+			expected: `<svg><a></a><a></a><a></a><image></image><a href="https://example.com/"></a><image href="data:image/png;base64,x"></image><use href="sprite.svg#icon"></use></svg>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SanitizeSVG([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := SanitizeSVG([]byte(`<html><svg></svg></html>`)); err == nil {
+		t.Error("Expected an error for a document that isn't an SVG")
+	}
+}
+
+func TestInlineSVGThreshold(t *testing.T) {
+	vault := t.TempDir()
+	m := NewManager(vault, t.TempDir(), "content", false)
+
+	small := `<svg><rect/></svg>`
+	if err := os.WriteFile(filepath.Join(vault, "icon.svg"), []byte(small), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vault, "photo.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Disabled by default
+	if _, ok := m.InlineSVG("icon.svg"); ok {
+		t.Error("Expected no inlining without a threshold")
+	}
+
+	m.SetInlineSVGUnder(int64(len(small)) + 1)
+	if markup, ok := m.InlineSVG("icon.svg"); !ok || markup != "<svg><rect></rect></svg>" {
+		t.Errorf("Expected icon.svg inlined, got %q (%v)", markup, ok)
+	}
+	if _, ok := m.InlineSVG("photo.png"); ok {
+		t.Error("Expected only SVGs to be inlined")
+	}
+
+	m.SetInlineSVGUnder(int64(len(small)))
+	if _, ok := m.InlineSVG("icon.svg"); ok {
+		t.Error("Expected an SVG at the threshold to be copied")
+	}
+}
@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -8,6 +9,11 @@ import (
 
 // NewLogger creates a new structured logger with the specified level
 func NewLogger(level string) *slog.Logger {
+	return NewLoggerTo(level, os.Stdout)
+}
+
+// NewLoggerTo creates a structured logger writing to w, e.g. a RotatingFile
+func NewLoggerTo(level string, w io.Writer) *slog.Logger {
 	logLevel := parseLogLevel(level)
 	
 	// Create a text handler for human-readable output
@@ -22,7 +28,7 @@ func NewLogger(level string) *slog.Logger {
 		},
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, opts)
+	handler := slog.NewTextHandler(w, opts)
 	return slog.New(handler)
 }
 
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotatesPastLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "sync.log")
+	
+	r, err := NewRotatingFile(path, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	defer r.Close()
+	
+	line := strings.Repeat("x", 39) + "\n" // 40 bytes
+	for i := 0; i < 2; i++ {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("Expected no backup below the limit, got %v", err)
+	}
+	
+	// The third line would pass 100 bytes, so the file rotates first
+	third := strings.Repeat("z", 39) + "\n"
+	if _, err := r.Write([]byte(third)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	
+	backup, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("Expected backup file, got %v", err)
+	}
+	if string(backup) != line+line {
+		t.Errorf("Expected backup to hold the rotated lines, got %q", backup)
+	}
+	active, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read active file: %v", err)
+	}
+	if string(active) != third {
+		t.Errorf("Expected truncated active file, got %q", active)
+	}
+	
+	// Older backups shift up and the oldest falls off
+	for i := 0; i < 3; i++ {
+		if _, err := r.Write([]byte(strings.Repeat("y", 99) + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("Expected second backup, got %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept, got %v", err)
+	}
+}
+
+func TestNewRotatingFileInvalidLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync.log")
+	if _, err := NewRotatingFile(path, 0, 1); err == nil {
+		t.Error("Expected error for zero size limit")
+	}
+	if _, err := NewRotatingFile(path, 100, -1); err == nil {
+		t.Error("Expected error for negative backups")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
 )
 
 const lockFileName = ".obsidian-hugo-sync.lock"
@@ -87,24 +86,30 @@ func isProcessRunning(lockPath string) bool {
 
 	pidStr := strings.TrimSpace(string(data))
 	pid, err := strconv.Atoi(pidStr)
-	if err != nil {
+	if err != nil || pid <= 0 {
 		return false
 	}
 
-	// Check if process exists by sending signal 0
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
+	// Liveness checks are platform specific (see lock_unix.go and lock_windows.go)
+	return processExists(pid)
+}
 
-	// On Unix systems, we can check if the process is still running
-	err = process.Signal(syscall.Signal(0))
-	if err != nil {
-		// Process doesn't exist or we don't have permission to signal it
-		return false
+// LockHolder returns the PID recorded in a vault's lock file and whether that
+// process is still running. The PID is 0 when there is no lock file or it
+// doesn't hold a valid PID.
+func LockHolder(vaultPath string) (pid int, running bool, err error) {
+	data, err := os.ReadFile(GetLockPath(vaultPath))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("reading lock file: %w", err)
 	}
 
-	return true
+	pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false, nil
+	}
+	return pid, processExists(pid), nil
 }
 
 // GetLockPath returns the lock file path for a given vault
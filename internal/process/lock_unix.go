@@ -0,0 +1,25 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// processExists reports whether a process with the given PID is running
+func processExists(pid int) bool {
+	// On Unix FindProcess always succeeds, so probe the process with signal 0
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = process.Signal(syscall.Signal(0))
+	if err != nil {
+		// Process doesn't exist or we don't have permission to signal it
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package process
+
+import (
+	"syscall"
+)
+
+const (
+	// processQueryLimitedInformation is the minimal access right needed for GetExitCodeProcess
+	processQueryLimitedInformation = 0x1000
+
+	// stillActive is the exit code reported for a process that hasn't exited yet
+	stillActive = 259
+)
+
+// processExists reports whether a process with the given PID is running
+func processExists(pid int) bool {
+	// Signals aren't supported on Windows, so open the process and check
+	// whether it has an exit code yet
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// No such process (or it has exited and been reaped)
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+
+	return exitCode == stillActive
+}
@@ -0,0 +1,86 @@
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestStartServesPprof(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := Start(ctx, "127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("Failed to start pprof server: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr.String() + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("Failed to reach pprof endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+		wantErr  bool
+	}{
+		{":6060", "localhost:6060", false},
+		{"127.0.0.1:6060", "127.0.0.1:6060", false},
+		{"6060", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			result, err := LocalAddr(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestResyncEndpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requested := make(chan struct{}, 1)
+	addr, err := Start(ctx, "127.0.0.1:0", func() { requested <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Failed to start pprof server: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr.String() + "/resync")
+	if err != nil {
+		t.Fatalf("Failed to reach resync endpoint: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post("http://"+addr.String()+"/resync", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to reach resync endpoint: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", resp.StatusCode)
+	}
+
+	select {
+	case <-requested:
+	default:
+		t.Error("Expected POST /resync to request a resync")
+	}
+}
@@ -0,0 +1,57 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// stateV10 is the 1.0 state layout, which kept only the UIDs of the notes
+// referencing each image
+type stateV10 struct {
+	Version   string              `json:"version"`
+	VaultHash string              `json:"vault_hash"`
+	Notes     map[string]*Note    `json:"notes"`
+	Images    map[string][]string `json:"images"` // image_path -> []note_uid
+}
+
+// decodeState unmarshals a state file, migrating older versions to the
+// current layout
+func decodeState(data []byte) (*State, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("unmarshaling state: %w", err)
+	}
+	if header.Version == "1.0" {
+		return migrateV10(data)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshaling state: %w", err)
+	}
+	return &state, nil
+}
+
+// migrateV10 converts a 1.0 state. Images have no last-referenced time yet,
+// so the grace period of unreferenced ones starts at the migration.
+func migrateV10(data []byte) (*State, error) {
+	var old stateV10
+	if err := json.Unmarshal(data, &old); err != nil {
+		return nil, fmt.Errorf("unmarshaling 1.0 state: %w", err)
+	}
+
+	now := time.Now()
+	images := make(map[string]*Image, len(old.Images))
+	for imagePath, notes := range old.Images {
+		images[imagePath] = &Image{Notes: notes, LastReferenced: now}
+	}
+	return &State{
+		Version:   stateVersion,
+		VaultHash: old.VaultHash,
+		Notes:     old.Notes,
+		Images:    images,
+	}, nil
+}
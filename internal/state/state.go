@@ -4,22 +4,37 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
 const (
-	stateVersion = "1.0"
-	stateFileName = "state.json"
+	stateVersion    = "1.1"
+	stateFileName   = "state.json"
+	backupExtension = ".bak" // The last good state, kept next to the state file
 )
 
 // State represents the daemon's persistent state
 type State struct {
-	Version   string             `json:"version"`
-	VaultHash string             `json:"vault_hash"`
-	Notes     map[string]*Note   `json:"notes"`
-	Images    map[string][]string `json:"images"` // image_path -> []note_uid
+	Version   string            `json:"version"`
+	VaultHash string            `json:"vault_hash"`
+	Notes     map[string]*Note  `json:"notes"`
+	Images    map[string]*Image `json:"images"` // image_path -> image references
+}
+
+// Image represents the cached reference state of an image
+type Image struct {
+	Notes          []string  `json:"notes"`           // UIDs of notes referencing the image
+	LastReferenced time.Time `json:"last_referenced"` // When a note last referenced the image
+	Hash           string    `json:"hash,omitempty"`  // SHA256 of the image content when last copied
+
+	// Optimized is set when a downscaled copy was written, with OutputHash
+	// holding the SHA256 of that copy so unchanged images aren't re-encoded
+	Optimized  bool   `json:"optimized,omitempty"`
+	OutputHash string `json:"output_hash,omitempty"`
 }
 
 // Note represents the cached state of a note
@@ -30,12 +45,24 @@ type Note struct {
 	LastSync     time.Time `json:"last_sync"`
 	Published    bool      `json:"published"`
 	ContentHash  string    `json:"content_hash"`
+
+	// PreviousHugoPaths lists Hugo paths the note was published under before
+	// a rename, emitted as Hugo aliases so old URLs keep redirecting
+	PreviousHugoPaths []string `json:"previous_hugo_paths,omitempty"`
 }
 
 // Manager handles state persistence and change detection
 type Manager struct {
 	statePath string
 	state     *State
+
+	// mu guards the image references, which concurrent image copies update,
+	// and the state while it is saved
+	mu sync.Mutex
+
+	// primaryGood is set once the state file is known to hold valid state, so
+	// a corrupt one is never rotated into the backup
+	primaryGood bool
 }
 
 // NewManager creates a new state manager
@@ -55,20 +82,34 @@ func NewManager(cacheDir, vaultPath string) (*Manager, error) {
 			Version:   stateVersion,
 			VaultHash: vaultHash,
 			Notes:     make(map[string]*Note),
-			Images:    make(map[string][]string),
+			Images:    make(map[string]*Image),
 		},
 	}
 
-	// Load existing state if available
+	// Load existing state if available; if neither the state file nor its
+	// backup loads, start fresh rather than failing initialization
 	if err := manager.load(); err != nil {
-		// If loading fails, we'll start with a fresh state
-		// Log the error but don't fail initialization
-		fmt.Printf("Warning: Could not load existing state: %v\n", err)
+		slog.Warn("Could not load state or its backup, starting fresh", "path", statePath, "error", err)
 	}
 
 	return manager, nil
 }
 
+// Verify checks that a vault's state cache can be loaded. A missing cache is
+// fine, as it is created on the first sync.
+func Verify(cacheDir, vaultPath string) error {
+	vaultAbs, err := filepath.Abs(vaultPath)
+	if err != nil {
+		return fmt.Errorf("getting absolute vault path: %w", err)
+	}
+
+	manager := &Manager{
+		statePath: filepath.Join(cacheDir, stateFileName),
+		state:     &State{Version: stateVersion, VaultHash: hashString(vaultAbs)},
+	}
+	return manager.load()
+}
+
 // GetNote returns the cached state for a note by UID
 func (m *Manager) GetNote(uid string) *Note {
 	return m.state.Notes[uid]
@@ -94,47 +135,104 @@ func (m *Manager) GetAllNotes() map[string]*Note {
 
 // AddImageReference adds a note UID to an image's reference list
 func (m *Manager) AddImageReference(imagePath, noteUID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.state.Images == nil {
-		m.state.Images = make(map[string][]string)
+		m.state.Images = make(map[string]*Image)
 	}
 	
-	refs := m.state.Images[imagePath]
+	image := m.state.Images[imagePath]
+	if image == nil {
+		image = &Image{}
+		m.state.Images[imagePath] = image
+	}
+	image.LastReferenced = time.Now()
 	
 	// Check if reference already exists
-	for _, ref := range refs {
+	for _, ref := range image.Notes {
 		if ref == noteUID {
 			return // Already exists
 		}
 	}
 	
-	m.state.Images[imagePath] = append(refs, noteUID)
+	image.Notes = append(image.Notes, noteUID)
 }
 
-// RemoveImageReference removes a note UID from an image's reference list
+// RemoveImageReference removes a note UID from an image's reference list.
+// The image entry is kept so cleanup can measure its grace period from the
+// moment the last reference went away.
 func (m *Manager) RemoveImageReference(imagePath, noteUID string) {
-	refs := m.state.Images[imagePath]
-	for i, ref := range refs {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	image := m.state.Images[imagePath]
+	if image == nil {
+		return
+	}
+	
+	for i, ref := range image.Notes {
 		if ref == noteUID {
 			// Remove this reference
-			m.state.Images[imagePath] = append(refs[:i], refs[i+1:]...)
+			image.Notes = append(image.Notes[:i], image.Notes[i+1:]...)
+			image.LastReferenced = time.Now()
 			break
 		}
 	}
-	
-	// If no more references, remove the image entry
-	if len(m.state.Images[imagePath]) == 0 {
-		delete(m.state.Images, imagePath)
+}
+
+// SetImageHash records the content hash of a tracked image
+func (m *Manager) SetImageHash(imagePath, hash string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if image := m.state.Images[imagePath]; image != nil {
+		image.Hash = hash
+	}
+}
+
+// SetImageOutput records whether an optimized copy was written for a tracked image
+func (m *Manager) SetImageOutput(imagePath string, optimized bool, outputHash string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if image := m.state.Images[imagePath]; image != nil {
+		image.Optimized = optimized
+		image.OutputHash = outputHash
 	}
 }
 
+// ForgetImage removes an image entry from the cached state
+func (m *Manager) ForgetImage(imagePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.state.Images, imagePath)
+}
+
 // GetImageReferences returns all note UIDs referencing an image
 func (m *Manager) GetImageReferences(imagePath string) []string {
-	return m.state.Images[imagePath]
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if image := m.state.Images[imagePath]; image != nil {
+		return append([]string(nil), image.Notes...)
+	}
+	return nil
 }
 
-// GetAllImages returns all tracked images and their references
-func (m *Manager) GetAllImages() map[string][]string {
-	return m.state.Images
+// GetAllImages returns a snapshot of all tracked images and their references
+func (m *Manager) GetAllImages() map[string]*Image {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	images := make(map[string]*Image, len(m.state.Images))
+	for imagePath, image := range m.state.Images {
+		snapshot := *image
+		snapshot.Notes = append([]string(nil), image.Notes...)
+		images[imagePath] = &snapshot
+	}
+	return images
 }
 
 // NeedsSync determines if a note needs to be synced based on file modification time and content hash
@@ -171,7 +269,9 @@ func (m *Manager) Save() error {
 
 	// Write to temporary file first for atomic operation
 	tempPath := m.statePath + ".tmp"
+	m.mu.Lock()
 	data, err := json.MarshalIndent(m.state, "", "  ")
+	m.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("marshaling state: %w", err)
 	}
@@ -180,38 +280,80 @@ func (m *Manager) Save() error {
 		return fmt.Errorf("writing state file: %w", err)
 	}
 
+	// Keep the previous good state as the backup
+	if m.primaryGood {
+		if err := os.Rename(m.statePath, m.backupPath()); err != nil && !os.IsNotExist(err) {
+			os.Remove(tempPath)
+			return fmt.Errorf("backing up state file: %w", err)
+		}
+	}
+
 	// Atomic rename
 	if err := os.Rename(tempPath, m.statePath); err != nil {
 		os.Remove(tempPath) // Clean up on error
 		return fmt.Errorf("renaming state file: %w", err)
 	}
+	m.primaryGood = true
 
 	return nil
 }
 
-// load reads state from disk
+// backupPath returns the path of the state file's backup
+func (m *Manager) backupPath() string {
+	return m.statePath + backupExtension
+}
+
+// load reads state from disk, falling back to the backup when the state file
+// is missing or unreadable
 func (m *Manager) load() error {
-	data, err := os.ReadFile(m.statePath)
+	state, primaryErr := m.readState(m.statePath)
+	if primaryErr == nil && state != nil {
+		slog.Debug("Loaded state", "path", m.statePath)
+		m.state = state
+		m.primaryGood = true
+		return nil
+	}
+
+	backup, backupErr := m.readState(m.backupPath())
+	if backupErr == nil && backup != nil {
+		slog.Warn("Restored state from backup", "path", m.backupPath(), "error", primaryErr)
+		m.state = backup
+		return nil
+	}
+
+	switch {
+	case primaryErr != nil && backupErr != nil:
+		return fmt.Errorf("%w (backup: %v)", primaryErr, backupErr)
+	case primaryErr != nil:
+		return primaryErr
+	default:
+		return backupErr // Nil when there is no state yet
+	}
+}
+
+// readState reads and validates a state file, returning nil if it doesn't exist
+func (m *Manager) readState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil // No existing state, start fresh
+			return nil, nil
 		}
-		return fmt.Errorf("reading state file: %w", err)
+		return nil, fmt.Errorf("reading state file: %w", err)
 	}
 
-	var state State
-	if err := json.Unmarshal(data, &state); err != nil {
-		return fmt.Errorf("unmarshaling state: %w", err)
+	state, err := decodeState(data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate state version
 	if state.Version != stateVersion {
-		return fmt.Errorf("state version mismatch: got %s, expected %s", state.Version, stateVersion)
+		return nil, fmt.Errorf("state version mismatch: got %s, expected %s", state.Version, stateVersion)
 	}
 
 	// Validate vault hash
 	if state.VaultHash != m.state.VaultHash {
-		return fmt.Errorf("vault hash mismatch: state is for a different vault")
+		return nil, fmt.Errorf("vault hash mismatch: state is for a different vault")
 	}
 
 	// Initialize maps if nil
@@ -219,17 +361,27 @@ func (m *Manager) load() error {
 		state.Notes = make(map[string]*Note)
 	}
 	if state.Images == nil {
-		state.Images = make(map[string][]string)
+		state.Images = make(map[string]*Image)
 	}
 
-	m.state = &state
-	return nil
+	// Hugo paths are compared with generated ones, which use forward slashes
+	for _, note := range state.Notes {
+		note.HugoPath = filepath.ToSlash(note.HugoPath)
+		for i, previous := range note.PreviousHugoPaths {
+			note.PreviousHugoPaths[i] = filepath.ToSlash(previous)
+		}
+	}
+
+	return state, nil
 }
 
 // Reset clears all cached state (useful for full rescan)
 func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.state.Notes = make(map[string]*Note)
-	m.state.Images = make(map[string][]string)
+	m.state.Images = make(map[string]*Image)
 }
 
 // CalculateContentHash computes SHA256 hash of file content
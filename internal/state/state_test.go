@@ -0,0 +1,244 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentImageReferences(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uid := fmt.Sprintf("note-%d", i%10)
+			manager.AddImageReference("/vault/img.png", uid)
+			manager.SetImageHash("/vault/img.png", "hash")
+			_ = manager.GetAllImages()
+		}()
+	}
+	wg.Wait()
+
+	if refs := manager.GetImageReferences("/vault/img.png"); len(refs) != 10 {
+		t.Errorf("Expected 10 references, got %v", refs)
+	}
+}
+
+func TestImageReferenceTracking(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	before := time.Now()
+	manager.AddImageReference("/vault/img.png", "note-1")
+	manager.AddImageReference("/vault/img.png", "note-2")
+	manager.AddImageReference("/vault/img.png", "note-1")
+
+	refs := manager.GetImageReferences("/vault/img.png")
+	if len(refs) != 2 {
+		t.Fatalf("Expected 2 references, got %v", refs)
+	}
+
+	image := manager.GetAllImages()["/vault/img.png"]
+	if image.LastReferenced.Before(before) {
+		t.Errorf("Expected LastReferenced to be updated, got %v", image.LastReferenced)
+	}
+
+	manager.RemoveImageReference("/vault/img.png", "note-1")
+	manager.RemoveImageReference("/vault/img.png", "note-2")
+
+	image = manager.GetAllImages()["/vault/img.png"]
+	if image == nil {
+		t.Fatal("Expected unreferenced image to stay tracked")
+	}
+	if len(image.Notes) != 0 {
+		t.Errorf("Expected no references, got %v", image.Notes)
+	}
+	if image.LastReferenced.Before(before) {
+		t.Errorf("Expected LastReferenced to record when the last reference was removed, got %v", image.LastReferenced)
+	}
+
+	manager.ForgetImage("/vault/img.png")
+	if _, ok := manager.GetAllImages()["/vault/img.png"]; ok {
+		t.Error("Expected image to be forgotten")
+	}
+}
+
+func TestImageStatePersistence(t *testing.T) {
+	cacheDir := t.TempDir()
+	vaultDir := t.TempDir()
+
+	manager, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.AddImageReference("/vault/img.png", "note-1")
+	manager.SetImageHash("/vault/img.png", "sha256-abc")
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	reloaded, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to reload manager: %v", err)
+	}
+	image := reloaded.GetAllImages()["/vault/img.png"]
+	if image == nil || len(image.Notes) != 1 || image.Notes[0] != "note-1" {
+		t.Fatalf("Expected image reference to persist, got %+v", image)
+	}
+	if image.LastReferenced.IsZero() {
+		t.Error("Expected LastReferenced to persist")
+	}
+	if image.Hash != "sha256-abc" {
+		t.Errorf("Expected hash to persist, got %q", image.Hash)
+	}
+}
+
+// saveNoteState saves a state holding a single note and returns its manager
+func saveNoteState(t *testing.T, cacheDir, vaultDir, uid string) *Manager {
+	t.Helper()
+	
+	manager, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.Reset()
+	manager.SetNote(uid, &Note{SourcePath: "/vault/" + uid + ".md", Published: true})
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	return manager
+}
+
+func TestCorruptStateRestoredFromBackup(t *testing.T) {
+	cacheDir := t.TempDir()
+	vaultDir := t.TempDir()
+	
+	// The second save rotates the first state into the backup
+	saveNoteState(t, cacheDir, vaultDir, "note-1")
+	manager := saveNoteState(t, cacheDir, vaultDir, "note-2")
+	
+	backup, err := os.ReadFile(manager.backupPath())
+	if err != nil {
+		t.Fatalf("Expected a backup after the second save: %v", err)
+	}
+	if !strings.Contains(string(backup), "note-1") {
+		t.Errorf("Expected the backup to hold the previous state, got:\n%s", backup)
+	}
+	
+	// Truncate the state file as an interrupted copy would
+	if err := os.WriteFile(manager.statePath, []byte(`{"version": "1.1", "no`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt state: %v", err)
+	}
+	
+	restored, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if restored.GetNote("note-1") == nil {
+		t.Error("Expected state to be restored from the backup")
+	}
+	
+	// Saving replaces the corrupt file but keeps the good backup
+	if err := restored.Save(); err != nil {
+		t.Fatalf("Failed to save restored state: %v", err)
+	}
+	if backup, _ := os.ReadFile(restored.backupPath()); !strings.Contains(string(backup), "note-1") {
+		t.Errorf("Expected the good backup to survive, got:\n%s", backup)
+	}
+}
+
+func TestCorruptStateAndBackupStartFresh(t *testing.T) {
+	cacheDir := t.TempDir()
+	vaultDir := t.TempDir()
+	
+	manager := saveNoteState(t, cacheDir, vaultDir, "note-1")
+	for _, path := range []string{manager.statePath, manager.backupPath()} {
+		if err := os.WriteFile(path, []byte("{truncated"), 0644); err != nil {
+			t.Fatalf("Failed to corrupt %s: %v", path, err)
+		}
+	}
+	
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+	
+	fresh, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if len(fresh.GetAllNotes()) != 0 {
+		t.Errorf("Expected a fresh state, got %d notes", len(fresh.GetAllNotes()))
+	}
+	if !strings.Contains(logs.String(), "starting fresh") {
+		t.Errorf("Expected a warning about starting fresh, got:\n%s", logs.String())
+	}
+}
+
+func TestLoadVersion10State(t *testing.T) {
+	cacheDir := t.TempDir()
+	vaultDir := t.TempDir()
+	
+	fixture := fmt.Sprintf(`{
+  "version": "1.0",
+  "vault_hash": %q,
+  "notes": {
+    "note-1": {
+      "source_path": "/vault/Note.md",
+      "hugo_path": "content/docs/posts/note.md",
+      "last_modified": "2024-03-15T10:00:00Z",
+      "last_sync": "2024-03-15T10:00:05Z",
+      "published": true,
+      "content_hash": "abc123"
+    }
+  },
+  "images": {
+    "/vault/img.png": ["note-1", "note-2"],
+    "/vault/unused.png": []
+  }
+}`, hashString(vaultDir))
+	if err := os.WriteFile(filepath.Join(cacheDir, stateFileName), []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	before := time.Now()
+	manager, err := NewManager(cacheDir, vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	
+	note := manager.GetNote("note-1")
+	if note == nil || note.HugoPath != "content/docs/posts/note.md" || note.ContentHash != "abc123" {
+		t.Fatalf("Expected the 1.0 note state to be kept, got %+v", note)
+	}
+	if refs := manager.GetImageReferences("/vault/img.png"); len(refs) != 2 || refs[0] != "note-1" || refs[1] != "note-2" {
+		t.Errorf("Expected the 1.0 image references to be kept, got %v", refs)
+	}
+	unused := manager.GetAllImages()["/vault/unused.png"]
+	if unused == nil || unused.LastReferenced.Before(before) {
+		t.Errorf("Expected unreferenced images to start their grace period at the migration, got %+v", unused)
+	}
+	
+	// Saving writes the current version
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	data, _ := os.ReadFile(manager.statePath)
+	if !strings.Contains(string(data), `"version": "`+stateVersion+`"`) {
+		t.Errorf("Expected the saved state to be version %s, got:\n%s", stateVersion, data)
+	}
+}
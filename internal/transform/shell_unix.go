@@ -0,0 +1,16 @@
+//go:build !windows
+
+package transform
+
+import (
+	"context"
+	"os/exec"
+)
+
+// notFoundExitCode is the status sh exits with when it can't find a command
+const notFoundExitCode = 127
+
+// shellCommand runs a command line through sh
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package transform
+
+import (
+	"context"
+	"os/exec"
+)
+
+// notFoundExitCode is the status cmd exits with when it can't find a command
+const notFoundExitCode = 9009
+
+// shellCommand runs a command line through cmd
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
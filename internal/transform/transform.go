@@ -0,0 +1,92 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"obsidian-hugo-sync/internal/errors"
+)
+
+// Note describes the note being transformed. It is passed to the command in
+// the OBSIDIAN_HUGO_SYNC_NOTE_PATH, OBSIDIAN_HUGO_SYNC_HUGO_PATH and
+// OBSIDIAN_HUGO_SYNC_NOTE_UID environment variables.
+type Note struct {
+	Path     string // Vault-relative path of the note
+	HugoPath string // Hugo content path the body is written to
+	UID      string
+}
+
+// Command pipes converted note bodies through an external program, such as
+// a LaTeX-to-MathML converter or a custom shortcode expander. The body is
+// written to the program's stdin and its stdout replaces it.
+type Command struct {
+	command string
+	timeout time.Duration
+}
+
+// New returns a transform running command through the system shell (sh -c,
+// or cmd /C on Windows), killing it after timeout (0 never does)
+func New(command string, timeout time.Duration) *Command {
+	return &Command{command: command, timeout: timeout}
+}
+
+// Run transforms a note body. Failures are returned as Process errors that
+// say whether the command is missing, timed out or exited with an error.
+func (c *Command) Run(body string, note Note) (string, error) {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	cmd := shellCommand(ctx, c.command)
+	cmd.Env = append(os.Environ(),
+		"OBSIDIAN_HUGO_SYNC_NOTE_PATH="+note.Path,
+		"OBSIDIAN_HUGO_SYNC_HUGO_PATH="+note.HugoPath,
+		"OBSIDIAN_HUGO_SYNC_NOTE_UID="+note.UID,
+	)
+	cmd.Stdin = strings.NewReader(body)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on pipes held open by processes the command started
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.String(), nil
+	}
+
+	failure := errors.New(errors.ErrorTypeProcess, "running transform command", err).
+		WithContext("command", c.command).
+		WithContext("note", note.Path)
+	var exitErr *exec.ExitError
+	switch {
+	case stderrors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", failure.
+			WithUserMessage(fmt.Sprintf("Transform command timed out after %v", c.timeout)).
+			WithSuggestions("Check that the command reads all of stdin and exits", "Raise --transform-timeout for slow commands")
+	case stderrors.As(err, &exitErr) && exitErr.ExitCode() == notFoundExitCode:
+		return "", failure.
+			WithUserMessage("Transform command not found").
+			WithSuggestions("Check the --transform-cmd program name and that it is on the PATH")
+	case stderrors.As(err, &exitErr):
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			failure = failure.WithContext("stderr", message)
+		}
+		return "", failure.
+			WithUserMessage(fmt.Sprintf("Transform command exited with status %d", exitErr.ExitCode())).
+			WithSuggestions("Run the command by hand with a note body on stdin to see its error")
+	default:
+		return "", failure.
+			WithUserMessage("Transform command could not be started").
+			WithSuggestions("Check that the shell and the --transform-cmd program can be run")
+	}
+}
@@ -0,0 +1,64 @@
+package transform
+
+import (
+	stderrors "errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"obsidian-hugo-sync/internal/errors"
+)
+
+func TestCommandTransformsBody(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	cmd := New(`tr a-z A-Z; printf '%s' "$OBSIDIAN_HUGO_SYNC_NOTE_UID"`, 5*time.Second)
+	result, err := cmd.Run("hello [[world]]\n", Note{Path: "guides/Note.md", HugoPath: "content/docs/guides/note.md", UID: "uid-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != "HELLO [[WORLD]]\nuid-1" {
+		t.Errorf("Expected the transformed body, got %q", result)
+	}
+}
+
+func TestCommandFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		message string
+	}{
+		{"exit status", "echo broken >&2; exit 3", 5 * time.Second, "Transform command exited with status 3"},
+		{"not found", "no-such-transform-command-xyz", 5 * time.Second, "Transform command not found"},
+		{"timeout", "sleep 5", 100 * time.Millisecond, "Transform command timed out after 100ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.command, tt.timeout).Run("body\n", Note{Path: "Note.md"})
+			var daemonErr *errors.DaemonError
+			if !stderrors.As(err, &daemonErr) {
+				t.Fatalf("Expected a DaemonError, got %v", err)
+			}
+			if daemonErr.Type != errors.ErrorTypeProcess {
+				t.Errorf("Expected a Process error, got %s", daemonErr.Type)
+			}
+			if daemonErr.UserMessage != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, daemonErr.UserMessage)
+			}
+		})
+	}
+
+	_, err := New("echo broken >&2; exit 1", 5*time.Second).Run("", Note{})
+	if daemonErr := err.(*errors.DaemonError); !strings.Contains(daemonErr.Context["stderr"].(string), "broken") {
+		t.Errorf("Expected stderr in the error context, got %v", daemonErr.Context)
+	}
+}
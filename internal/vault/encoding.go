@@ -0,0 +1,136 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported source encodings for vault notes
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "latin-1"
+	EncodingAuto        = "auto"
+)
+
+// utf8BOM is the byte order mark some editors prepend to UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// windows1252 maps the 0x80-0x9F range, where Windows-1252 differs from Latin-1
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// DecodeToUTF8 transcodes note bytes from the given source encoding to UTF-8.
+// In auto mode valid UTF-8 is kept as-is and anything else is treated as Windows-1252.
+func DecodeToUTF8(data []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingUTF8, "utf8":
+		return data, nil
+	case EncodingWindows1252, "cp1252":
+		return decodeSingleByte(data, true), nil
+	case EncodingLatin1, "iso-8859-1", "latin1":
+		return decodeSingleByte(data, false), nil
+	case EncodingAuto:
+		if bytes.HasPrefix(data, utf8BOM) {
+			return data[len(utf8BOM):], nil
+		}
+		if utf8.Valid(data) {
+			return data, nil
+		}
+		return decodeSingleByte(data, true), nil
+	default:
+		return nil, fmt.Errorf("unsupported source encoding %q", encoding)
+	}
+}
+
+// EncodeFromUTF8 converts UTF-8 text back to the given source encoding, the
+// reverse of DecodeToUTF8. Auto mode must be resolved first (see
+// resolveEncoding); characters the encoding can't represent are an error.
+func EncodeFromUTF8(data []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingUTF8, "utf8":
+		return data, nil
+	case EncodingWindows1252, "cp1252":
+		return encodeSingleByte(data, true)
+	case EncodingLatin1, "iso-8859-1", "latin1":
+		return encodeSingleByte(data, false)
+	default:
+		return nil, fmt.Errorf("unsupported source encoding %q", encoding)
+	}
+}
+
+// resolveEncoding returns the encoding DecodeToUTF8 reads data in, deciding
+// auto mode from the data, and whether it strips a UTF-8 byte order mark
+func resolveEncoding(data []byte, encoding string) (string, bool) {
+	if strings.ToLower(encoding) != EncodingAuto {
+		return encoding, false
+	}
+	if bytes.HasPrefix(data, utf8BOM) {
+		return EncodingUTF8, true
+	}
+	if utf8.Valid(data) {
+		return EncodingUTF8, false
+	}
+	return EncodingWindows1252, false
+}
+
+// IsSupportedEncoding reports whether DecodeToUTF8 understands the encoding name
+func IsSupportedEncoding(encoding string) bool {
+	_, err := DecodeToUTF8(nil, encoding)
+	return err == nil
+}
+
+// decodeSingleByte converts Latin-1 or Windows-1252 bytes to UTF-8
+func decodeSingleByte(data []byte, cp1252 bool) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			buf.WriteByte(b)
+		case cp1252 && b < 0xA0:
+			buf.WriteRune(windows1252[b-0x80])
+		default:
+			buf.WriteRune(rune(b))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// encodeSingleByte converts UTF-8 to Latin-1 or Windows-1252 bytes
+func encodeSingleByte(data []byte, cp1252 bool) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+
+	for _, r := range string(data) {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			buf.WriteByte(byte(r))
+		case !cp1252 && r < 0xA0:
+			buf.WriteByte(byte(r))
+		case cp1252 && windows1252Byte(r) != 0:
+			buf.WriteByte(windows1252Byte(r))
+		default:
+			return nil, fmt.Errorf("character %q has no single-byte encoding", r)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// windows1252Byte returns the 0x80-0x9F byte a rune decodes from in
+// Windows-1252, or 0 if there is none
+func windows1252Byte(r rune) byte {
+	for i, mapped := range windows1252 {
+		if mapped == r {
+			return byte(0x80 + i)
+		}
+	}
+	return 0
+}
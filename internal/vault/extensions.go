@@ -0,0 +1,37 @@
+package vault
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMarkdownExtensions are the file extensions read as notes unless
+// configured otherwise
+var DefaultMarkdownExtensions = []string{".md"}
+
+// MarkdownExtension returns the extension of a file name if it is one of the
+// markdown extensions (DefaultMarkdownExtensions when none are given), and ""
+// for any other file
+func MarkdownExtension(name string, extensions []string) string {
+	if len(extensions) == 0 {
+		extensions = DefaultMarkdownExtensions
+	}
+	ext := filepath.Ext(name)
+	for _, candidate := range extensions {
+		if ext == candidate {
+			return ext
+		}
+	}
+	return ""
+}
+
+// IsNoteFile reports whether a file has one of the scanned markdown extensions
+func (o ScanOptions) IsNoteFile(path string) bool {
+	return MarkdownExtension(path, o.Extensions) != ""
+}
+
+// NoteName returns the file name of a note without its extension
+func NoteName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SerializeFrontMatter returns the updated front-matter as YAML, touching only
+// the keys that were added or changed since the note was parsed. Added keys
+// are appended to the front-matter as written, so properties Obsidian
+// formatted (dates, lists, quoting) stay byte-for-byte the same. When an
+// existing key changed, the parsed yaml.Node is re-encoded instead, which
+// still keeps key order, comments and value styles.
+func (n *Note) SerializeFrontMatter() ([]byte, error) {
+	if len(n.FrontMatter) == 0 {
+		return nil, nil
+	}
+
+	mapping := n.frontMatterMapping()
+	values := make(map[string]int, len(mapping.Content)/2) // Key -> index of its value node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		values[mapping.Content[i].Value] = i + 1
+	}
+
+	keys := make([]string, 0, len(n.FrontMatter))
+	for key := range n.FrontMatter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Sort keys into added ones and existing ones whose value changed or
+	// that were removed
+	var added []string
+	changed := false
+	for key := range values {
+		if _, ok := n.FrontMatter[key]; !ok {
+			changed = true
+		}
+	}
+	for _, key := range keys {
+		index, ok := values[key]
+		if !ok {
+			added = append(added, key)
+			continue
+		}
+		var current interface{}
+		if err := mapping.Content[index].Decode(&current); err != nil || !reflect.DeepEqual(current, n.FrontMatter[key]) {
+			changed = true
+		}
+	}
+
+	// Keys can't be appended as text to front-matter written as {key: value}
+	if len(added) > 0 && mapping.Style&yaml.FlowStyle != 0 {
+		changed = true
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(FrontMatterDelimiter + "\n")
+
+	if changed {
+		// Re-encode the parsed front-matter with every change applied
+		updated := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+			value, ok := n.FrontMatter[keyNode.Value]
+			if !ok {
+				continue // Removed key
+			}
+			var current interface{}
+			if err := valueNode.Decode(&current); err != nil || !reflect.DeepEqual(current, value) {
+				encoded, err := encodeValueNode(value)
+				if err != nil {
+					return nil, err
+				}
+				encoded.LineComment = valueNode.LineComment
+				valueNode = encoded
+			}
+			updated.Content = append(updated.Content, keyNode, valueNode)
+		}
+		if err := appendKeys(updated, added, n.FrontMatter); err != nil {
+			return nil, err
+		}
+		if err := encodeFrontMatterNode(&buf, updated); err != nil {
+			return nil, err
+		}
+	} else {
+		// Keep the front-matter as written and append the new keys
+		if n.frontMatterRaw != "" {
+			buf.WriteString(n.frontMatterRaw)
+			if n.frontMatterRaw[len(n.frontMatterRaw)-1] != '\n' {
+				buf.WriteString("\n")
+			}
+		}
+		if len(added) > 0 {
+			addition := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if err := appendKeys(addition, added, n.FrontMatter); err != nil {
+				return nil, err
+			}
+			if err := encodeFrontMatterNode(&buf, addition); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf.WriteString(FrontMatterDelimiter + "\n")
+	return buf.Bytes(), nil
+}
+
+// frontMatterMapping returns the parsed front-matter mapping node, or an empty
+// one for notes without front-matter
+func (n *Note) frontMatterMapping() *yaml.Node {
+	if n.frontMatterNode != nil && n.frontMatterNode.Kind == yaml.DocumentNode && len(n.frontMatterNode.Content) == 1 {
+		if mapping := n.frontMatterNode.Content[0]; mapping.Kind == yaml.MappingNode {
+			return mapping
+		}
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// appendKeys appends the given front-matter keys with their values to a mapping node
+func appendKeys(mapping *yaml.Node, keys []string, values map[string]interface{}) error {
+	for _, key := range keys {
+		valueNode, err := encodeValueNode(values[key])
+		if err != nil {
+			return err
+		}
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			valueNode)
+	}
+	return nil
+}
+
+// encodeValueNode converts a front-matter value into a YAML node
+func encodeValueNode(value interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("encoding front-matter: %w", err)
+	}
+	return &node, nil
+}
+
+// encodeFrontMatterNode writes a mapping node as YAML with two-space indents
+func encodeFrontMatterNode(buf *bytes.Buffer, mapping *yaml.Node) error {
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(mapping); err != nil {
+		return fmt.Errorf("encoding front-matter: %w", err)
+	}
+	return encoder.Close()
+}
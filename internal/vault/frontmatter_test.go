@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSerializeFrontMatterKeepsFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		update   func(note *Note)
+		expected string
+	}{
+		{
+			name:    "uid appended to Obsidian properties",
+			content: "---\ncreated: 2024-01-02\nrating: 4.5\ndone: true\ntags:\n- project\n- \"#publish\"\naliases: [Setup, Install]\n# Kept comment\n---\nBody\n",
+			update:  func(note *Note) { note.EnsureUID() },
+			expected: "---\ncreated: 2024-01-02\nrating: 4.5\ndone: true\ntags:\n- project\n- \"#publish\"\naliases: [Setup, Install]\n# Kept comment\n" +
+				"noteUid: uid-1\n---\nBody\n",
+		},
+		{
+			name:     "uid and weight appended in key order",
+			content:  "---\ntitle: 'Setup'\n---\nBody\n",
+			update:   func(note *Note) { note.EnsureUID(); note.EnsureWeight(20, true) },
+			expected: "---\ntitle: 'Setup'\nnoteUid: uid-1\nweight: 20\n---\nBody\n",
+		},
+		{
+			name:     "note without front-matter",
+			content:  "Body\n",
+			update:   func(note *Note) { note.EnsureUID() },
+			expected: "---\nnoteUid: uid-1\n---\nBody\n",
+		},
+		{
+			name:     "changed key replaced in place",
+			content:  "---\ncreated: 2024-01-02\nweight: 5 # manual\ntags: [a, b]\n---\nBody\n",
+			update:   func(note *Note) { note.FrontMatter["weight"] = 10 },
+			expected: "---\ncreated: 2024-01-02\nweight: 10 # manual\ntags: [a, b]\n---\nBody\n",
+		},
+		{
+			name:     "flow-style front-matter re-encoded",
+			content:  "---\n{title: Setup}\n---\nBody\n",
+			update:   func(note *Note) { note.EnsureUID() },
+			expected: "---\ntitle: Setup\nnoteUid: uid-1\n---\nBody\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "note.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			note, err := ParseNote(path)
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			note.UID = "uid-1"
+			delete(note.FrontMatter, "noteUid")
+			tt.update(note)
+
+			content, err := note.SerializeContent()
+			if err != nil {
+				t.Fatalf("Failed to serialize note: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestSerializeFrontMatterUnchangedIsIdentical(t *testing.T) {
+	content := "---\ncreated: 2024-01-02T10:30\ntags:\n    - deeply\n    - indented\nnoteUid: uid-1\n---\n\nBody\n"
+	path := filepath.Join(t.TempDir(), "note.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	note, err := ParseNote(path)
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+
+	if note.EnsureUID() {
+		t.Error("Expected existing noteUid to be kept")
+	}
+	serialized, err := note.SerializeContent()
+	if err != nil {
+		t.Fatalf("Failed to serialize note: %v", err)
+	}
+	if string(serialized) != content {
+		t.Errorf("Expected note to be unchanged, got:\n%s", serialized)
+	}
+}
@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the vault-root file listing gitignore-style exclusion patterns
+const IgnoreFileName = ".obsidian-hugo-syncignore"
+
+// IgnoreMatcher matches vault-relative paths against gitignore-style patterns
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is a single compiled ignore pattern
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool // Pattern started with "!" and re-includes matches
+	dirOnly bool // Pattern ended with "/" and only matches directories
+}
+
+// LoadIgnoreFile reads the vault's ignore file; a missing file ignores nothing
+func LoadIgnoreFile(vaultPath string) (*IgnoreMatcher, error) {
+	file, err := os.Open(filepath.Join(vaultPath, IgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IgnoreMatcher{}, nil
+		}
+		return nil, fmt.Errorf("opening ignore file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ignore file: %w", err)
+	}
+
+	return ParseIgnorePatterns(lines), nil
+}
+
+// ParseIgnorePatterns compiles gitignore-style pattern lines. Blank lines and
+// "#" comments are skipped, "!" negates, a trailing "/" matches directories
+// only and patterns containing "/" are anchored at the vault root.
+func ParseIgnorePatterns(lines []string) *IgnoreMatcher {
+	matcher := &IgnoreMatcher{}
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // Escaped leading "!" or "#"
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+		rule.pattern = regexp.MustCompile("^" + expr + "$")
+
+		matcher.rules = append(matcher.rules, rule)
+	}
+
+	return matcher
+}
+
+// Match reports whether a vault-relative path is ignored. Paths inside an
+// ignored directory are ignored too, as with git.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	if relPath == "." || strings.HasPrefix(relPath, "../") {
+		return false
+	}
+
+	// An excluded parent directory can't be re-included by a later pattern
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchRules(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return m.matchRules(relPath, isDir)
+}
+
+// matchRules applies all rules to a single path; the last matching rule wins
+func (m *IgnoreMatcher) matchRules(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(relPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp converts a gitignore glob into a regular expression body
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					expr.WriteString("(?:.*/)?") // "**/" matches zero or more directories
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return expr.String()
+}
@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher := ParseIgnorePatterns([]string{
+		"# Drafts and scratch notes",
+		"*.draft.md",
+		"",
+		"templates/",
+		"private/*",
+		"!private/shared.md",
+		"/Inbox.md",
+		"archive/**/old.md",
+	})
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		// Simple pattern matches at any depth
+		{"idea.draft.md", false, true},
+		{"guides/setup.draft.md", false, true},
+		{"guides/setup.md", false, false},
+
+		// Directory pattern matches the directory and everything inside
+		{"templates", true, true},
+		{"templates/daily.md", false, true},
+		{"guides/templates/note.md", false, true},
+		{"templates.md", false, false},
+
+		// Negation re-includes a file
+		{"private/secret.md", false, true},
+		{"private/shared.md", false, false},
+
+		// Anchored pattern only matches at the root
+		{"Inbox.md", false, true},
+		{"guides/Inbox.md", false, false},
+
+		// Double-star spans directories
+		{"archive/old.md", false, true},
+		{"archive/2023/q1/old.md", false, true},
+		{"archive/new.md", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := matcher.Match(tt.path, tt.isDir); result != tt.expected {
+				t.Errorf("Expected Match(%q) = %v, got %v", tt.path, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestScanVaultRespectsIgnoreFile(t *testing.T) {
+	vaultDir := t.TempDir()
+	files := map[string]string{
+		"note.md":            "# Note",
+		"scratch.draft.md":   "# Draft",
+		"templates/daily.md": "# Template",
+		"private/secret.md":  "# Secret",
+		"private/shared.md":  "# Shared",
+		IgnoreFileName:       "*.draft.md\ntemplates/\nprivate/*\n!private/shared.md\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(vaultDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notePaths, err := ScanVault(vaultDir)
+	if err != nil {
+		t.Fatalf("ScanVault failed: %v", err)
+	}
+
+	var relPaths []string
+	for _, path := range notePaths {
+		rel, _ := filepath.Rel(vaultDir, path)
+		relPaths = append(relPaths, filepath.ToSlash(rel))
+	}
+	sort.Strings(relPaths)
+
+	expected := []string{"note.md", "private/shared.md"}
+	if strings.Join(relPaths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, relPaths)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,9 +22,29 @@ type Note struct {
 	Content     string
 	FrontMatter map[string]interface{}
 	Tags        []string
+	Aliases     []string
 	Published   bool
+	Draft       bool      // Front-matter draft: true
+	PublishDate time.Time // Front-matter publishDate; zero when unset
+	ExpiryDate  time.Time // Front-matter expiryDate; zero when unset
 	ModTime     time.Time
 	Raw         []byte
+
+	// AttachmentsDir is searched for ![[file]] embeds not found beside the note
+	AttachmentsDir string
+
+	// AttachmentsSubdir names a folder beside the note searched for ![[file]]
+	// embeds before AttachmentsDir
+	AttachmentsSubdir string
+
+	// Front-matter as written, kept so write-backs only touch changed keys
+	frontMatterRaw  string
+	frontMatterNode *yaml.Node
+
+	// Encoding the file was read in and whether a UTF-8 byte order mark was
+	// dropped, so write-backs restore both (see EncodeForFile)
+	sourceEncoding string
+	sourceBOM      bool
 }
 
 // FrontMatterDelimiter is the YAML front-matter delimiter
@@ -32,6 +53,9 @@ const FrontMatterDelimiter = "---"
 // PublishTag is the tag that marks a note for publishing
 const PublishTag = "#publish"
 
+// FolderNoteName is the file whose front-matter configures its folder's Hugo section
+const FolderNoteName = "_folder.md"
+
 var (
 	// wikiLinkRegex matches [[Note]] and [[Note|Display Text]] patterns
 	wikiLinkRegex = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
@@ -46,31 +70,115 @@ var (
 	inlineCodeRegex = regexp.MustCompile("`[^`]*`")
 )
 
+// ParseOptions controls how note files are read and interpreted
+type ParseOptions struct {
+	// SourceEncoding is the character encoding of note files (see DecodeToUTF8).
+	// Notes are transcoded to UTF-8 on read; empty means UTF-8.
+	SourceEncoding string
+
+	// PublishDrafts lets notes with draft: true publish; by default a draft
+	// is never published, even with publish: true or the publish tag
+	PublishDrafts bool
+
+	// UIDKeys are front-matter keys consulted in order for an existing UID
+	// (e.g. "uid" from another publishing tool) when a note has no noteUid.
+	// EnsureUID promotes an adopted value into noteUid.
+	UIDKeys []string
+
+	// AttachmentsDir is the folder Obsidian stores attachments in when it
+	// keeps them in one place; ![[file]] embeds fall back to it
+	AttachmentsDir string
+
+	// AttachmentsSubdir is the folder name Obsidian stores attachments under
+	// when it keeps them in a subfolder of each note's folder (e.g.
+	// "_attachments"); ![[file]] embeds not found beside the note look there
+	AttachmentsSubdir string
+
+	// PublishFolders are folders whose notes are published without needing
+	// the publish key or tag; a note moved out of them is unpublished again
+	PublishFolders []string
+
+	// ExcludeTags keep a note unpublished whatever its publish key, tags or
+	// folder say. Tags match without the # and case-insensitively, and cover
+	// nested tags below them (wip also excludes wip/design).
+	ExcludeTags []string
+
+	// EnforceSchedule keeps a note unpublished before its publishDate and
+	// from its expiryDate on, instead of leaving the schedule to Hugo
+	EnforceSchedule bool
+}
+
 // ParseNote reads and parses an Obsidian note file
 func ParseNote(filePath string) (*Note, error) {
+	return ParseNoteWithOptions(filePath, ParseOptions{})
+}
+
+// ParseNoteWithOptions reads and parses an Obsidian note file using the given options
+func ParseNoteWithOptions(filePath string, opts ParseOptions) (*Note, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
 
+	sourceEncoding, sourceBOM := resolveEncoding(data, opts.SourceEncoding)
+	data, err = DecodeToUTF8(data, opts.SourceEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("decoding file: %w", err)
+	}
+
 	info, err := os.Stat(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("getting file info: %w", err)
 	}
 
 	note := &Note{
-		Path:    filePath,
-		ModTime: info.ModTime(),
-		Raw:     data,
+		Path:              filePath,
+		ModTime:           info.ModTime(),
+		Raw:               data,
+		AttachmentsDir:    opts.AttachmentsDir,
+		AttachmentsSubdir: opts.AttachmentsSubdir,
+		sourceEncoding:    sourceEncoding,
+		sourceBOM:         sourceBOM,
 	}
 
 	if err := note.parse(); err != nil {
 		return nil, fmt.Errorf("parsing note: %w", err)
 	}
 
+	if !note.Published && !note.optedOut() && inFolders(filePath, opts.PublishFolders) {
+		note.Published = true
+	}
+
+	if note.Draft && !opts.PublishDrafts {
+		note.Published = false
+	}
+
+	if note.Published && note.hasAnyTag(opts.ExcludeTags) {
+		note.Published = false
+	}
+
+	if note.Published && opts.EnforceSchedule && !note.InSchedule(time.Now()) {
+		note.Published = false
+	}
+
+	if note.UID == "" {
+		note.adoptUID(opts.UIDKeys)
+	}
+
 	return note, nil
 }
 
+// inFolders reports whether a file lies anywhere under one of the folders
+func inFolders(filePath string, folders []string) bool {
+	for _, folder := range folders {
+		rel, err := filepath.Rel(folder, filePath)
+		if err == nil && rel != "." && !strings.HasPrefix(filepath.ToSlash(rel), "../") {
+			return true
+		}
+	}
+	return false
+}
+
 // parse extracts front-matter and content from the note
 func (n *Note) parse() error {
 	content := string(n.Raw)
@@ -90,19 +198,26 @@ func (n *Note) parse() error {
 			}
 		}
 
-		if endIndex > 0 {
-			// Extract and parse front-matter
-			frontMatterContent := strings.Join(lines[1:endIndex], "\n")
-			if err := yaml.Unmarshal([]byte(frontMatterContent), &n.FrontMatter); err != nil {
-				return fmt.Errorf("parsing front-matter YAML: %w", err)
+		if endIndex < 0 {
+			return &FrontMatterError{
+				Line: 1,
+				Err:  fmt.Errorf("front-matter is never closed with %s", FrontMatterDelimiter),
 			}
+		}
 
-			// Extract content after front-matter
-			n.Content = strings.Join(lines[endIndex+1:], "\n")
-		} else {
-			// Malformed front-matter, treat as content
-			n.Content = content
+		// Extract and parse front-matter
+		frontMatterContent := strings.Join(lines[1:endIndex], "\n")
+		if err := yaml.Unmarshal([]byte(frontMatterContent), &n.FrontMatter); err != nil {
+			return newYAMLError(err)
+		}
+		n.frontMatterRaw = frontMatterContent
+		n.frontMatterNode = &yaml.Node{}
+		if err := yaml.Unmarshal([]byte(frontMatterContent), n.frontMatterNode); err != nil {
+			return newYAMLError(err)
 		}
+
+		// Extract content after front-matter
+		n.Content = strings.Join(lines[endIndex+1:], "\n")
 	} else {
 		// No front-matter, entire content is body
 		n.Content = content
@@ -113,7 +228,7 @@ func (n *Note) parse() error {
 		n.Title = title
 	} else {
 		// Use filename as title if not specified
-		n.Title = strings.TrimSuffix(filepath.Base(n.Path), ".md")
+		n.Title = NoteName(n.Path)
 	}
 
 	// Extract UID from front-matter
@@ -126,6 +241,24 @@ func (n *Note) parse() error {
 		n.Tags = extractTags(tags)
 	}
 
+	// Extract aliases (Obsidian accepts a list or a single string)
+	if aliases, ok := n.FrontMatter["aliases"]; ok {
+		n.Aliases = extractTags(aliases)
+	}
+
+	// Drafts are held back from publishing unless the caller opts in
+	if draft, ok := frontMatterBool(n.FrontMatter["draft"]); ok {
+		n.Draft = draft
+	}
+
+	// Scheduling dates are passed on to Hugo
+	if date, ok := frontMatterTime(n.FrontMatter["publishDate"]); ok {
+		n.PublishDate = date
+	}
+	if date, ok := frontMatterTime(n.FrontMatter["expiryDate"]); ok {
+		n.ExpiryDate = date
+	}
+
 	// Determine if note should be published
 	n.Published = n.isPublished()
 
@@ -134,8 +267,13 @@ func (n *Note) parse() error {
 
 // isPublished determines if the note should be published based on front-matter and tags
 func (n *Note) isPublished() bool {
+	// An explicit publish: false wins over the publish tag
+	if n.optedOut() {
+		return false
+	}
+
 	// Check for publish: true in front-matter
-	if publish, ok := n.FrontMatter["publish"].(bool); ok && publish {
+	if publish, ok := frontMatterBool(n.FrontMatter["publish"]); ok && publish {
 		return true
 	}
 
@@ -149,17 +287,92 @@ func (n *Note) isPublished() bool {
 	return false
 }
 
-// EnsureUID ensures the note has a UID, generating one if necessary
+// optedOut reports whether the note sets publish: false, keeping it private
+// regardless of its tags or folder
+func (n *Note) optedOut() bool {
+	publish, ok := frontMatterBool(n.FrontMatter["publish"])
+	return ok && !publish
+}
+
+// frontMatterBool reads a yes/no front-matter value. Besides YAML booleans it
+// accepts the forms authors commonly write, which YAML parses as strings or
+// numbers: "true"/"false", yes/no, on/off and 1/0 (case-insensitive). It
+// reports false for anything else, such as a missing key.
+func frontMatterBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case int:
+		if v == 0 || v == 1 {
+			return v == 1, true
+		}
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, true
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "on", "1":
+			return true, true
+		case "false", "no", "off", "0":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// hasAnyTag reports whether the note carries one of the given tags or a
+// nested tag below one
+func (n *Note) hasAnyTag(tags []string) bool {
+	for _, tag := range n.Tags {
+		tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+		for _, want := range tags {
+			want = strings.ToLower(strings.TrimPrefix(want, "#"))
+			if tag == want || strings.HasPrefix(tag, want+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adoptUID takes the note's UID from the first of the given front-matter keys
+// holding a non-empty value
+func (n *Note) adoptUID(keys []string) {
+	for _, key := range keys {
+		switch value := n.FrontMatter[key].(type) {
+		case string:
+			if uid := strings.TrimSpace(value); uid != "" {
+				n.UID = uid
+				return
+			}
+		case int:
+			n.UID = strconv.Itoa(value)
+			return
+		}
+	}
+}
+
+// EnsureUID ensures the note's UID is stored under noteUid, generating one if
+// necessary or promoting one adopted from another key
 func (n *Note) EnsureUID() bool {
-	if n.UID != "" {
+	if n.UID == "" {
+		n.UID = uuid.New().String()
+	} else if uid, ok := n.FrontMatter["noteUid"].(string); ok && uid == n.UID {
 		return false // No change needed
 	}
 
-	n.UID = uuid.New().String()
 	n.FrontMatter["noteUid"] = n.UID
 	return true // Changed
 }
 
+// RegenerateUID gives the note a fresh UID under noteUid, for a note that
+// turned out to share its UID with another
+func (n *Note) RegenerateUID() {
+	n.UID = uuid.New().String()
+	n.FrontMatter["noteUid"] = n.UID
+}
+
 // EnsureWeight ensures the note has a weight if auto-weight is enabled and user hasn't set one
 func (n *Note) EnsureWeight(weight int, autoWeight bool) bool {
 	if !autoWeight {
@@ -175,28 +388,6 @@ func (n *Note) EnsureWeight(weight int, autoWeight bool) bool {
 	return true // Changed
 }
 
-// SerializeFrontMatter returns the updated front-matter as YAML
-func (n *Note) SerializeFrontMatter() ([]byte, error) {
-	if len(n.FrontMatter) == 0 {
-		return nil, nil
-	}
-
-	var buf bytes.Buffer
-	buf.WriteString(FrontMatterDelimiter + "\n")
-	
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	
-	if err := encoder.Encode(n.FrontMatter); err != nil {
-		return nil, fmt.Errorf("encoding front-matter: %w", err)
-	}
-	
-	encoder.Close()
-	buf.WriteString(FrontMatterDelimiter + "\n")
-	
-	return buf.Bytes(), nil
-}
-
 // SerializeContent returns the complete note content with updated front-matter
 func (n *Note) SerializeContent() ([]byte, error) {
 	frontMatter, err := n.SerializeFrontMatter()
@@ -213,6 +404,19 @@ func (n *Note) SerializeContent() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// EncodeForFile converts serialized note content (see SerializeContent) back
+// to the encoding the note file was read in, for writing it to the vault
+func (n *Note) EncodeForFile(content []byte) ([]byte, error) {
+	data, err := EncodeFromUTF8(content, n.sourceEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("encoding note as %s: %w", n.sourceEncoding, err)
+	}
+	if n.sourceBOM {
+		data = append(append([]byte{}, utf8BOM...), data...)
+	}
+	return data, nil
+}
+
 // ExtractWikiLinks finds all wikilinks in the note content
 func (n *Note) ExtractWikiLinks() []WikiLink {
 	// Remove code blocks and inline code to avoid processing wikilinks within them
@@ -265,17 +469,22 @@ func (n *Note) ExtractImageReferences() []ImageRef {
 			// ![alt](path) format
 			ref.AltText = match[1]
 			ref.Path = match[2]
+			ref.Target = match[2]
 		} else if match[3] != "" {
 			// ![[filename]] format
 			ref.Path = match[3]
 			ref.AltText = match[3]
+			ref.Target = match[3]
 		}
 
 		if ref.Path != "" {
 			// Resolve relative to note's directory
 			if !filepath.IsAbs(ref.Path) {
-				noteDir := filepath.Dir(n.Path)
-				ref.Path = filepath.Join(noteDir, ref.Path)
+				if match[3] != "" {
+					ref.Path = n.resolveEmbed(ref.Path)
+				} else {
+					ref.Path = filepath.Join(filepath.Dir(n.Path), ref.Path)
+				}
 			}
 			refs = append(refs, ref)
 		}
@@ -284,6 +493,33 @@ func (n *Note) ExtractImageReferences() []ImageRef {
 	return refs
 }
 
+// resolveEmbed resolves an ![[file]] embed beside the note, falling back to
+// the note's attachments subfolder and then the attachments folder when it
+// isn't there
+func (n *Note) resolveEmbed(name string) string {
+	beside := filepath.Join(filepath.Dir(n.Path), name)
+	if n.AttachmentsDir == "" && n.AttachmentsSubdir == "" {
+		return beside
+	}
+	if _, err := os.Stat(beside); err == nil {
+		return beside
+	}
+	
+	var candidates []string
+	if n.AttachmentsSubdir != "" {
+		candidates = append(candidates, filepath.Join(filepath.Dir(n.Path), n.AttachmentsSubdir, name))
+	}
+	if n.AttachmentsDir != "" {
+		candidates = append(candidates, filepath.Join(n.AttachmentsDir, name))
+	}
+	for _, attachment := range candidates {
+		if _, err := os.Stat(attachment); err == nil {
+			return attachment
+		}
+	}
+	return beside
+}
+
 // resolveRelativePath resolves a relative path from the note's directory
 func (n *Note) resolveRelativePath(relativePath string) string {
 	noteDir := filepath.Dir(n.Path)
@@ -303,9 +539,10 @@ type WikiLink struct {
 type ImageRef struct {
 	Path    string // Image file path
 	AltText string // Alt text for the image
+	Target  string // Reference as written in the note, before resolving
 }
 
-// extractTags converts various tag formats to a string slice
+// extractTags converts various tag (or alias) formats to a string slice
 func extractTags(tags interface{}) []string {
 	switch v := tags.(type) {
 	case []interface{}:
@@ -326,13 +563,81 @@ func extractTags(tags interface{}) []string {
 	}
 }
 
+// IsFolderNote reports whether a path is a folder's section settings note
+func IsFolderNote(path string) bool {
+	return filepath.Base(path) == FolderNoteName
+}
+
+// HugoConfigFiles are the site configuration files that mark a Hugo site root
+var HugoConfigFiles = []string{"hugo.toml", "hugo.yaml", "hugo.json", "config.toml"}
+
+// IsHugoSite reports whether a directory is the root of a Hugo site
+func IsHugoSite(dir string) bool {
+	for _, name := range HugoConfigFiles {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanOptions controls which parts of a vault are scanned for notes
+type ScanOptions struct {
+	// SkipDirs are never descended into, such as the Hugo repository when
+	// it is kept inside the vault. Symlinked paths to them are skipped too.
+	SkipDirs []string
+	
+	// FollowSymlinks descends into symlinked directories, so notes shared
+	// into the vault through a link are scanned too
+	FollowSymlinks bool
+	
+	// Extensions of the files read as notes, such as ".md" and ".markdown";
+	// empty means DefaultMarkdownExtensions
+	Extensions []string
+}
+
+// SkipsDir reports whether a directory below the vault is left out of scans:
+// one of SkipDirs, or any Hugo site, whose generated content would otherwise
+// be read back as notes
+func (o ScanOptions) SkipsDir(vaultPath, dir string) bool {
+	if filepath.Clean(dir) == filepath.Clean(vaultPath) {
+		return false
+	}
+	
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	for _, skip := range o.SkipDirs {
+		if skipInfo, err := os.Stat(skip); err == nil && os.SameFile(info, skipInfo) {
+			return true
+		}
+	}
+	return IsHugoSite(dir)
+}
+
 // ScanVault recursively scans a vault directory for markdown files
 func ScanVault(vaultPath string) ([]string, error) {
+	return ScanVaultWithOptions(vaultPath, ScanOptions{})
+}
+
+// ScanVaultWithOptions recursively scans a vault directory for markdown files
+// using the given options
+func ScanVaultWithOptions(vaultPath string, opts ScanOptions) ([]string, error) {
 	var notePaths []string
 	
-	err := filepath.Walk(vaultPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	ignore, err := LoadIgnoreFile(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	
+	err = WalkVault(vaultPath, opts.FollowSymlinks, func(path string, info os.FileInfo) error {
+		// Skip anything excluded by the vault's ignore file
+		if relPath, err := filepath.Rel(vaultPath, path); err == nil && ignore.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
 		// Skip hidden directories and files (except our lock file)
@@ -344,8 +649,13 @@ func ScanVault(vaultPath string) ([]string, error) {
 			return nil
 		}
 
+		// Never read a Hugo site's generated content back as notes
+		if info.IsDir() && opts.SkipsDir(vaultPath, path) {
+			return filepath.SkipDir
+		}
+
 		// Only process markdown files
-		if !info.IsDir() && filepath.Ext(path) == ".md" {
+		if !info.IsDir() && opts.IsNoteFile(path) {
 			notePaths = append(notePaths, path)
 		}
 
@@ -353,4 +663,44 @@ func ScanVault(vaultPath string) ([]string, error) {
 	})
 
 	return notePaths, err
-} 
\ No newline at end of file
+} 
+
+// FrontMatterError reports malformed front-matter, with the position of the
+// problem in the note file when it is known
+type FrontMatterError struct {
+	Line   int // 1-based line in the note file, 0 when unknown
+	Column int // 1-based column, 0 when unknown
+	Err    error
+}
+
+func (e *FrontMatterError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("invalid front-matter at line %d, column %d: %v", e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("invalid front-matter at line %d: %v", e.Line, e.Err)
+	default:
+		return fmt.Sprintf("invalid front-matter: %v", e.Err)
+	}
+}
+
+func (e *FrontMatterError) Unwrap() error {
+	return e.Err
+}
+
+// yamlPositionRegex finds the position yaml.v3 embeds in its error messages
+var yamlPositionRegex = regexp.MustCompile(`line (\d+)(?:, column (\d+))?`)
+
+// newYAMLError converts a YAML error into a FrontMatterError, translating the
+// position within the front-matter into a position within the note file
+func newYAMLError(err error) *FrontMatterError {
+	fmErr := &FrontMatterError{Err: err}
+	if matches := yamlPositionRegex.FindStringSubmatch(err.Error()); matches != nil {
+		line, _ := strconv.Atoi(matches[1])
+		fmErr.Line = line + 1 // The opening delimiter is line 1
+		if matches[2] != "" {
+			fmErr.Column, _ = strconv.Atoi(matches[2])
+		}
+	}
+	return fmErr
+}
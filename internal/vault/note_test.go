@@ -1,10 +1,15 @@
 package vault
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseNote(t *testing.T) {
@@ -189,4 +194,640 @@ func TestIsPublished(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestParseNoteWithSourceEncoding(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "latin1.md")
+	
+	// "Café Société" and "Ça marche déjà" encoded as Latin-1 (not valid UTF-8)
+	content := []byte("---\ntitle: \"Caf\xe9 Soci\xe9t\xe9\"\npublish: true\n---\n\n\xc7a marche d\xe9j\xe0.\n")
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	
+	for _, encoding := range []string{EncodingLatin1, EncodingWindows1252, EncodingAuto} {
+		t.Run(encoding, func(t *testing.T) {
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{SourceEncoding: encoding})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			
+			if note.Title != "Café Société" {
+				t.Errorf("Expected title 'Café Société', got '%s'", note.Title)
+			}
+			
+			if !strings.Contains(note.Content, "Ça marche déjà.") {
+				t.Errorf("Expected transcoded content, got %q", note.Content)
+			}
+		})
+	}
+}
+
+func TestWriteBackKeepsSourceEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		content  []byte
+	}{
+		// "Café" and "déjà vu" in each encoding
+		{"latin-1", EncodingLatin1, []byte("---\ntitle: \"Caf\xe9\"\npublish: true\n---\n\nd\xe9j\xe0 vu\n")},
+		{"windows-1252", EncodingWindows1252, []byte("---\ntitle: \"Caf\xe9\"\npublish: true\n---\n\nd\xe9j\xe0 vu \x96 \x80\n")},
+		{"auto with bom", EncodingAuto, []byte("\xef\xbb\xbf---\ntitle: \"Café\"\npublish: true\n---\n\ndéjà vu\n")},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "note.md")
+			if err := os.WriteFile(testFile, tt.content, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			opts := ParseOptions{SourceEncoding: tt.encoding}
+			
+			note, err := ParseNoteWithOptions(testFile, opts)
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			note.EnsureUID()
+			content, err := note.SerializeContent()
+			if err != nil {
+				t.Fatalf("Failed to serialize note: %v", err)
+			}
+			data, err := note.EncodeForFile(content)
+			if err != nil {
+				t.Fatalf("Failed to encode note: %v", err)
+			}
+			if err := os.WriteFile(testFile, data, 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+			
+			// The second read decodes the written note the same way
+			reread, err := ParseNoteWithOptions(testFile, opts)
+			if err != nil {
+				t.Fatalf("Failed to parse written note: %v", err)
+			}
+			if reread.UID != note.UID {
+				t.Errorf("Expected UID %q after the write-back, got %q", note.UID, reread.UID)
+			}
+			if reread.Title != "Café" {
+				t.Errorf("Expected title 'Café', got %q", reread.Title)
+			}
+			if reread.Content != note.Content || !strings.Contains(reread.Content, "déjà vu") {
+				t.Errorf("Expected content %q, got %q", note.Content, reread.Content)
+			}
+			if tt.encoding == EncodingAuto && !bytes.HasPrefix(data, utf8BOM) {
+				t.Error("Expected the byte order mark to be kept")
+			}
+		})
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		encoding string
+		expected string
+	}{
+		{"utf-8 passthrough", []byte("Café"), EncodingUTF8, "Café"},
+		{"auto keeps utf-8", []byte("Café"), EncodingAuto, "Café"},
+		{"auto strips bom", []byte("\xef\xbb\xbfCafé"), EncodingAuto, "Café"},
+		{"windows-1252 quotes", []byte("\x93quoted\x94 \x80"), EncodingWindows1252, "“quoted” €"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeToUTF8(tt.input, tt.encoding)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, string(result))
+			}
+		})
+	}
+	
+	if _, err := DecodeToUTF8([]byte("x"), "ebcdic"); err == nil {
+		t.Error("Expected error for unsupported encoding")
+	}
+}
+
+func TestParseNoteDraft(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "draft.md")
+	
+	content := "---\npublish: true\ndraft: true\n---\n\nWork in progress\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	
+	tests := []struct {
+		name          string
+		publishDrafts bool
+		expected      bool
+	}{
+		{name: "drafts held back", publishDrafts: false, expected: false},
+		{name: "drafts published", publishDrafts: true, expected: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{PublishDrafts: tt.publishDrafts})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			
+			if !note.Draft {
+				t.Error("Expected note to be marked as a draft")
+			}
+			if note.Published != tt.expected {
+				t.Errorf("Expected published %v, got %v", tt.expected, note.Published)
+			}
+		})
+	}
+}
+
+func TestPublishAndDraftValueTypes(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+		valid    bool
+	}{
+		{"true", true, true},
+		{`"true"`, true, true},
+		{"yes", true, true},
+		{"Yes", true, true},
+		{"on", true, true},
+		{"1", true, true},
+		{"false", false, true},
+		{`"false"`, false, true},
+		{"no", false, true},
+		{"NO", false, true},
+		{"off", false, true},
+		{"0", false, true},
+		{"maybe", false, false},
+		{"2", false, false},
+	}
+	
+	tmpDir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			publishFile := filepath.Join(tmpDir, "publish.md")
+			if err := os.WriteFile(publishFile, []byte("---\npublish: "+tt.value+"\n---\n\nBody\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			note, err := ParseNote(publishFile)
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.Published != tt.expected {
+				t.Errorf("Expected publish: %s to give published %v, got %v", tt.value, tt.expected, note.Published)
+			}
+			
+			// Only recognized false values keep a tagged note private
+			tagged := &Note{FrontMatter: note.FrontMatter, Tags: []string{"publish"}}
+			if optedOut := tagged.optedOut(); optedOut != (tt.valid && !tt.expected) {
+				t.Errorf("Expected publish: %s to opt out %v, got %v", tt.value, tt.valid && !tt.expected, optedOut)
+			}
+			
+			draftFile := filepath.Join(tmpDir, "draft.md")
+			if err := os.WriteFile(draftFile, []byte("---\npublish: true\ndraft: "+tt.value+"\n---\n\nBody\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			note, err = ParseNote(draftFile)
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.Draft != tt.expected {
+				t.Errorf("Expected draft: %s to give draft %v, got %v", tt.value, tt.expected, note.Draft)
+			}
+			if note.Published == tt.expected {
+				t.Errorf("Expected draft: %s to give published %v, got %v", tt.value, !tt.expected, note.Published)
+			}
+		})
+	}
+}
+
+func TestParseNotePublishFolders(t *testing.T) {
+	vaultDir := t.TempDir()
+	publishDir := filepath.Join(vaultDir, "Published")
+	
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected bool
+	}{
+		{name: "inside publish folder", path: "Published/Note.md", content: "# Note\n", expected: true},
+		{name: "nested inside publish folder", path: "Published/Guides/Note.md", content: "# Note\n", expected: true},
+		{name: "outside publish folder", path: "Drafts/Note.md", content: "# Note\n", expected: false},
+		{name: "folder with a longer name", path: "Published Later/Note.md", content: "# Note\n", expected: false},
+		{name: "tagged outside publish folder", path: "Drafts/Tagged.md", content: "---\ntags: [publish]\n---\n", expected: true},
+		{name: "draft inside publish folder", path: "Published/Draft.md", content: "---\ndraft: true\n---\n", expected: false},
+		{name: "publish false inside publish folder", path: "Published/Private.md", content: "---\npublish: false\n---\n", expected: false},
+		{name: "publish false beats the tag", path: "Drafts/Private.md", content: "---\npublish: false\ntags: [publish]\n---\n", expected: false},
+		{name: "no publish key inside publish folder", path: "Published/Plain.md", content: "---\ntitle: Plain\n---\n", expected: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(vaultDir, tt.path)
+			if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+				t.Fatalf("Failed to create folder: %v", err)
+			}
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{PublishFolders: []string{publishDir}})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.Published != tt.expected {
+				t.Errorf("Expected published %v, got %v", tt.expected, note.Published)
+			}
+		})
+	}
+}
+
+func TestParseNoteExcludeTags(t *testing.T) {
+	vaultDir := t.TempDir()
+	publishDir := filepath.Join(vaultDir, "Published")
+	
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected bool
+	}{
+		{name: "publish tag only", path: "Notes/Ready.md", content: "---\ntags: [\"#publish\"]\n---\n", expected: true},
+		{name: "publish and exclude tags", path: "Notes/WIP.md", content: "---\ntags: [\"#publish\", \"#wip\"]\n---\n", expected: false},
+		{name: "publish key and exclude tag", path: "Notes/Key.md", content: "---\npublish: true\ntags: [noindex]\n---\n", expected: false},
+		{name: "exclude tag in publish folder", path: "Published/Draft.md", content: "---\ntags: [WIP]\n---\n", expected: false},
+		{name: "nested exclude tag", path: "Notes/Nested.md", content: "---\ntags: [publish, wip/design]\n---\n", expected: false},
+		{name: "tag with exclude prefix", path: "Notes/Wipe.md", content: "---\ntags: [publish, wipe]\n---\n", expected: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(vaultDir, tt.path)
+			if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+				t.Fatalf("Failed to create folder: %v", err)
+			}
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{
+				PublishFolders: []string{publishDir},
+				ExcludeTags:    []string{"wip", "#noindex"},
+			})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.Published != tt.expected {
+				t.Errorf("Expected published %v, got %v", tt.expected, note.Published)
+			}
+		})
+	}
+}
+
+func TestParseNoteSchedule(t *testing.T) {
+	vaultDir := t.TempDir()
+	
+	tests := []struct {
+		name        string
+		content     string
+		enforce     bool
+		published   bool
+		publishDate string
+		expiryDate  string
+	}{
+		{name: "future publish date passed through", content: "---\npublish: true\npublishDate: 2999-01-01\n---\n", published: true, publishDate: "2999-01-01"},
+		{name: "future publish date enforced", content: "---\npublish: true\npublishDate: 2999-01-01\n---\n", enforce: true, published: false, publishDate: "2999-01-01"},
+		{name: "past publish date enforced", content: "---\npublish: true\npublishDate: \"2020-01-01 09:30\"\n---\n", enforce: true, published: true, publishDate: "2020-01-01"},
+		{name: "past expiry enforced", content: "---\npublish: true\nexpiryDate: 2020-01-01T00:00:00Z\n---\n", enforce: true, published: false, expiryDate: "2020-01-01"},
+		{name: "future expiry enforced", content: "---\npublish: true\nexpiryDate: 2999-01-01\n---\n", enforce: true, published: true, expiryDate: "2999-01-01"},
+		{name: "unparseable date ignored", content: "---\npublish: true\npublishDate: someday\n---\n", enforce: true, published: true},
+	}
+	
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(vaultDir, fmt.Sprintf("note-%d.md", i))
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{EnforceSchedule: tt.enforce})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.Published != tt.published {
+				t.Errorf("Expected published %v, got %v", tt.published, note.Published)
+			}
+			if got := scheduleDay(note.PublishDate); got != tt.publishDate {
+				t.Errorf("Expected publishDate %q, got %q", tt.publishDate, got)
+			}
+			if got := scheduleDay(note.ExpiryDate); got != tt.expiryDate {
+				t.Errorf("Expected expiryDate %q, got %q", tt.expiryDate, got)
+			}
+		})
+	}
+}
+
+// scheduleDay formats a schedule date as its day, empty when unset
+func scheduleDay(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
+
+func TestParseNoteAdoptsUIDKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		keys     []string
+		expected string
+	}{
+		{
+			name:     "uid promoted",
+			content:  "---\nuid: legacy-123\n---\n\nBody\n",
+			keys:     []string{"noteUid", "uid", "id"},
+			expected: "legacy-123",
+		},
+		{
+			name:     "first matching key wins",
+			content:  "---\nid: 42\nguid: legacy-guid\n---\n\nBody\n",
+			keys:     []string{"uid", "id", "guid"},
+			expected: "42",
+		},
+		{
+			name:     "noteUid takes precedence",
+			content:  "---\nnoteUid: current\nuid: legacy-123\n---\n\nBody\n",
+			keys:     []string{"uid"},
+			expected: "current",
+		},
+		{
+			name:     "unlisted key ignored",
+			content:  "---\nuid: legacy-123\n---\n\nBody\n",
+			keys:     []string{"noteUid"},
+			expected: "",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "note.md")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			
+			note, err := ParseNoteWithOptions(testFile, ParseOptions{UIDKeys: tt.keys})
+			if err != nil {
+				t.Fatalf("Failed to parse note: %v", err)
+			}
+			if note.UID != tt.expected {
+				t.Errorf("Expected UID '%s', got '%s'", tt.expected, note.UID)
+			}
+			
+			// EnsureUID promotes an adopted UID into noteUid instead of minting one
+			if tt.expected != "" {
+				note.EnsureUID()
+				if note.UID != tt.expected || note.FrontMatter["noteUid"] != tt.expected {
+					t.Errorf("Expected noteUid '%s', got '%v'", tt.expected, note.FrontMatter["noteUid"])
+				}
+			}
+		})
+	}
+}
+
+func TestParseNoteFrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		expectedLine int
+	}{
+		{
+			name:         "unterminated front-matter",
+			content:      "---\ntitle: Test\npublish: true\n\nBody without a closing delimiter\n",
+			expectedLine: 1,
+		},
+		{
+			name:         "tab-indented YAML",
+			content:      "---\ntitle: Test\n\tpublish: true\n---\n\nBody\n",
+			expectedLine: 3,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "broken.md")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			
+			_, err := ParseNote(testFile)
+			var fmErr *FrontMatterError
+			if !errors.As(err, &fmErr) {
+				t.Fatalf("Expected FrontMatterError, got %v", err)
+			}
+			if fmErr.Line != tt.expectedLine {
+				t.Errorf("Expected error on line %d, got %d (%v)", tt.expectedLine, fmErr.Line, fmErr)
+			}
+		})
+	}
+}
+
+func TestExtractImageReferencesAttachmentsDir(t *testing.T) {
+	vaultDir := t.TempDir()
+	attachments := filepath.Join(vaultDir, "Attachments")
+	noteDir := filepath.Join(vaultDir, "Notes")
+	for _, path := range []string{
+		filepath.Join(attachments, "shared.png"),
+		filepath.Join(attachments, "local.png"),
+		filepath.Join(noteDir, "local.png"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+			t.Fatalf("Failed to write image: %v", err)
+		}
+	}
+	
+	note := &Note{
+		Path:           filepath.Join(noteDir, "Note.md"),
+		Content:        "![[shared.png]] ![[local.png]] ![[missing.png]] ![link](shared.png)",
+		AttachmentsDir: attachments,
+	}
+	
+	expected := []string{
+		filepath.Join(attachments, "shared.png"), // Only in the attachments folder
+		filepath.Join(noteDir, "local.png"),      // Beside the note wins
+		filepath.Join(noteDir, "missing.png"),    // Nowhere, so left beside the note
+		filepath.Join(noteDir, "shared.png"),     // Markdown links stay relative to the note
+	}
+	
+	refs := note.ExtractImageReferences()
+	if len(refs) != len(expected) {
+		t.Fatalf("Expected %d image references, got %d", len(expected), len(refs))
+	}
+	for i, want := range expected {
+		if refs[i].Path != want {
+			t.Errorf("Expected reference %d to resolve to %s, got %s", i, want, refs[i].Path)
+		}
+	}
+}
+
+func TestExtractImageReferencesAttachmentsSubdir(t *testing.T) {
+	vaultDir := t.TempDir()
+	attachments := filepath.Join(vaultDir, "Attachments")
+	noteDir := filepath.Join(vaultDir, "Notes")
+	subdir := filepath.Join(noteDir, "_attachments")
+	for _, path := range []string{
+		filepath.Join(subdir, "pic.png"),
+		filepath.Join(subdir, "both.png"),
+		filepath.Join(attachments, "both.png"),
+		filepath.Join(attachments, "shared.png"),
+		filepath.Join(noteDir, "local.png"),
+		filepath.Join(subdir, "local.png"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+			t.Fatalf("Failed to write image: %v", err)
+		}
+	}
+	
+	note := &Note{
+		Path:              filepath.Join(noteDir, "Note.md"),
+		Content:           "![[pic.png]] ![[both.png]] ![[shared.png]] ![[local.png]] ![[missing.png]]",
+		AttachmentsDir:    attachments,
+		AttachmentsSubdir: "_attachments",
+	}
+	
+	expected := []string{
+		filepath.Join(subdir, "pic.png"),         // Only in the note's attachments subfolder
+		filepath.Join(subdir, "both.png"),        // The subfolder wins over the attachments folder
+		filepath.Join(attachments, "shared.png"), // Falls back to the attachments folder
+		filepath.Join(noteDir, "local.png"),      // Beside the note wins
+		filepath.Join(noteDir, "missing.png"),    // Nowhere, so left beside the note
+	}
+	
+	refs := note.ExtractImageReferences()
+	if len(refs) != len(expected) {
+		t.Fatalf("Expected %d image references, got %d", len(expected), len(refs))
+	}
+	for i, want := range expected {
+		if refs[i].Path != want {
+			t.Errorf("Expected reference %d to resolve to %s, got %s", i, want, refs[i].Path)
+		}
+	}
+	
+	// Without an attachments folder, a missing subfolder file stays beside the note
+	note.AttachmentsDir = ""
+	if refs := note.ExtractImageReferences(); refs[2].Path != filepath.Join(noteDir, "shared.png") {
+		t.Errorf("Expected shared.png to resolve beside the note, got %s", refs[2].Path)
+	}
+}
+
+func TestScanVaultSkipsHugoSites(t *testing.T) {
+	vaultDir := t.TempDir()
+	repoDir := filepath.Join(vaultDir, "site")
+	files := map[string]string{
+		"note.md":                      "# Note",
+		"guides/setup.md":              "# Setup",
+		"site/content/docs/note.md":    "---\nnoteUid: generated\n---\n",
+		"blog/hugo.toml":               "baseURL = '/'\n",
+		"blog/content/posts/post.md":   "# Post",
+		"archive/config.toml/notes.md": "# Not a Hugo config file",
+		"projects/config.yaml":         "name: project\n",
+		"projects/plan.md":             "# Plan",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(vaultDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notePaths, err := ScanVaultWithOptions(vaultDir, ScanOptions{SkipDirs: []string{repoDir}})
+	if err != nil {
+		t.Fatalf("ScanVaultWithOptions failed: %v", err)
+	}
+
+	var relPaths []string
+	for _, path := range notePaths {
+		rel, _ := filepath.Rel(vaultDir, path)
+		relPaths = append(relPaths, filepath.ToSlash(rel))
+	}
+	sort.Strings(relPaths)
+
+	expected := []string{"archive/config.toml/notes.md", "guides/setup.md", "note.md", "projects/plan.md"}
+	if strings.Join(relPaths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, relPaths)
+	}
+}
+
+func TestScanVaultMarkdownExtensions(t *testing.T) {
+	vaultDir := t.TempDir()
+	files := map[string]string{
+		"note.md":               "# Note",
+		"guides/setup.markdown": "# Setup",
+		"guides/component.mdx":  "# Component",
+		"guides/todo.txt":       "Not a note",
+		"guides/archive.md.bak": "# Backup",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(vaultDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		extensions []string
+		expected   []string
+	}{
+		{nil, []string{"note.md"}},
+		{[]string{".md", ".markdown"}, []string{"guides/setup.markdown", "note.md"}},
+		{[]string{".markdown", ".mdx"}, []string{"guides/component.mdx", "guides/setup.markdown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.extensions, ","), func(t *testing.T) {
+			notePaths, err := ScanVaultWithOptions(vaultDir, ScanOptions{Extensions: tt.extensions})
+			if err != nil {
+				t.Fatalf("ScanVaultWithOptions failed: %v", err)
+			}
+
+			var relPaths []string
+			for _, path := range notePaths {
+				rel, _ := filepath.Rel(vaultDir, path)
+				relPaths = append(relPaths, filepath.ToSlash(rel))
+			}
+			sort.Strings(relPaths)
+
+			if strings.Join(relPaths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, relPaths)
+			}
+		})
+	}
+
+	// Titles drop whichever extension the note has
+	note, err := ParseNote(filepath.Join(vaultDir, "guides/setup.markdown"))
+	if err != nil {
+		t.Fatalf("Failed to parse note: %v", err)
+	}
+	if note.Title != "setup" {
+		t.Errorf("Expected title setup, got %q", note.Title)
+	}
+}
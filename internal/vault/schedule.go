@@ -0,0 +1,55 @@
+package vault
+
+import (
+	"time"
+)
+
+// scheduleLayouts are the date formats accepted for publishDate and expiryDate
+// when the YAML parser leaves them as strings
+var scheduleLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// frontMatterTime reads a front-matter date, which YAML may have decoded into
+// a time.Time or left as a string
+func frontMatterTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range scheduleLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// InSchedule reports whether now lies between the note's publishDate and
+// expiryDate; notes without either are always in schedule
+func (n *Note) InSchedule(now time.Time) bool {
+	if !n.PublishDate.IsZero() && now.Before(n.PublishDate) {
+		return false
+	}
+	if !n.ExpiryDate.IsZero() && !now.Before(n.ExpiryDate) {
+		return false
+	}
+	return true
+}
+
+// NextScheduleChange returns when the note next enters or leaves its schedule
+// after now, or the zero time when its schedule won't change again
+func (n *Note) NextScheduleChange(now time.Time) time.Time {
+	if !n.PublishDate.IsZero() && now.Before(n.PublishDate) {
+		return n.PublishDate
+	}
+	if !n.ExpiryDate.IsZero() && now.Before(n.ExpiryDate) {
+		return n.ExpiryDate
+	}
+	return time.Time{}
+}
@@ -0,0 +1,76 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// WalkVault walks the file tree at root like filepath.Walk, calling fn for
+// every file and directory. With followSymlinks, symlinks to directories are
+// descended into and fn sees the target's info under the link's path. Each
+// real directory is walked once, so symlink cycles end and a directory
+// reachable through several links isn't reported twice.
+func WalkVault(root string, followSymlinks bool, fn func(path string, info os.FileInfo) error) error {
+	if !followSymlinks {
+		return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return fn(path, info)
+		})
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	err = walkFollowing(root, info, make(map[string]bool), fn)
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkFollowing walks path, resolving symlinks, and skips real directories
+// already in visited
+func walkFollowing(path string, info os.FileInfo, visited map[string]bool, fn func(path string, info os.FileInfo) error) error {
+	if !info.IsDir() {
+		return fn(path, info)
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil // Vanished since it was listed
+	}
+	if visited[realPath] {
+		return nil
+	}
+	visited[realPath] = true
+
+	if err := fn(path, info); err != nil {
+		if errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := os.Stat(child)
+		if err != nil {
+			continue // Dangling symlink
+		}
+		if err := walkFollowing(child, childInfo, visited, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) && !childInfo.IsDir() {
+				return nil // A file skipping its directory, as with filepath.Walk
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanVaultFollowsSymlinks(t *testing.T) {
+	vaultDir := t.TempDir()
+	shared := t.TempDir()
+	files := map[string]string{
+		filepath.Join(vaultDir, "note.md"):           "# Note",
+		filepath.Join(shared, "Shared Note.md"):      "# Shared",
+		filepath.Join(shared, "team", "Handbook.md"): "# Handbook",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := []struct{ name, target string }{
+		{"Linked Note.md", filepath.Join(shared, "Shared Note.md")}, // Symlinked note file
+		{"Team", filepath.Join(shared, "team")},                     // Symlinked folder
+		{"Team/Up", vaultDir},                                       // Cycle back to the vault root, reached through the link
+		{"Self", vaultDir},                                          // Cycle from the vault itself
+	}
+	for _, link := range links {
+		if err := os.Symlink(link.target, filepath.Join(vaultDir, link.name)); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+	}
+
+	scan := func(follow bool) []string {
+		var relPaths []string
+		done := make(chan error, 1)
+		go func() {
+			notePaths, err := ScanVaultWithOptions(vaultDir, ScanOptions{FollowSymlinks: follow})
+			for _, path := range notePaths {
+				rel, _ := filepath.Rel(vaultDir, path)
+				relPaths = append(relPaths, filepath.ToSlash(rel))
+			}
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("ScanVaultWithOptions failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected scan to finish despite symlink cycles")
+		}
+		sort.Strings(relPaths)
+		return relPaths
+	}
+
+	// Symlinked note files are always scanned, symlinked folders only when following
+	expected := []string{"Linked Note.md", "note.md"}
+	if relPaths := scan(false); strings.Join(relPaths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v without following symlinks, got %v", expected, relPaths)
+	}
+
+	expected = []string{"Linked Note.md", "Team/Handbook.md", "note.md"}
+	if relPaths := scan(true); strings.Join(relPaths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v when following symlinks, got %v", expected, relPaths)
+	}
+}
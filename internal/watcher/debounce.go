@@ -0,0 +1,117 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer coalesces bursts of events for the same path into a single event.
+// Editors typically save with a create/write/chmod sequence within a few
+// milliseconds; the debouncer waits until a path has been quiet for the
+// configured window before emitting one event for it.
+type Debouncer struct {
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[string]Operation
+	timers  map[string]*time.Timer
+	out     chan Event
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewDebouncer creates a debouncer with the given quiet window.
+// A zero window passes events through unchanged.
+func NewDebouncer(window time.Duration) *Debouncer {
+	return &Debouncer{
+		window:  window,
+		pending: make(map[string]Operation),
+		timers:  make(map[string]*time.Timer),
+		out:     make(chan Event, 100),
+		done:    make(chan struct{}),
+	}
+}
+
+// Add records an event, restarting the quiet window for its path
+func (d *Debouncer) Add(event Event) {
+	if d.window <= 0 {
+		d.emit(event)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+
+	d.pending[event.Path] = coalesce(event.Operation)
+	if timer, exists := d.timers[event.Path]; exists {
+		timer.Reset(d.window)
+		return
+	}
+
+	path := event.Path
+	d.timers[path] = time.AfterFunc(d.window, func() { d.fire(path) })
+}
+
+// Enabled reports whether the debouncer has a quiet window, rather than
+// passing events through unchanged
+func (d *Debouncer) Enabled() bool {
+	return d.window > 0
+}
+
+// Events returns the channel of coalesced events
+func (d *Debouncer) Events() <-chan Event {
+	return d.out
+}
+
+// Stop discards pending events and stops all timers
+func (d *Debouncer) Stop() {
+	d.once.Do(func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		close(d.done)
+		for path, timer := range d.timers {
+			timer.Stop()
+			delete(d.timers, path)
+			delete(d.pending, path)
+		}
+	})
+}
+
+// fire emits the coalesced event for a path once its window has elapsed
+func (d *Debouncer) fire(path string) {
+	d.mu.Lock()
+	op, exists := d.pending[path]
+	delete(d.pending, path)
+	delete(d.timers, path)
+	d.mu.Unlock()
+
+	if exists {
+		d.emit(Event{Path: path, Operation: op})
+	}
+}
+
+// emit delivers an event unless the debouncer has been stopped
+func (d *Debouncer) emit(event Event) {
+	select {
+	case d.out <- event:
+	case <-d.done:
+	}
+}
+
+// coalesce maps the latest operation in a burst to the one to emit.
+// Removals and renames are kept since the file is gone; anything else
+// (create, write, chmod) collapses into a single write.
+func coalesce(op Operation) Operation {
+	switch op {
+	case Remove, Rename:
+		return op
+	default:
+		return Write
+	}
+}
@@ -0,0 +1,78 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebouncerCoalescesBurst(t *testing.T) {
+	debouncer := NewDebouncer(50 * time.Millisecond)
+	defer debouncer.Stop()
+	
+	// Simulate an editor save: create, chmod and several writes in quick succession
+	path := "/vault/note.md"
+	for _, op := range []Operation{Create, Chmod, Write, Write, Chmod} {
+		debouncer.Add(Event{Path: path, Operation: op})
+		time.Sleep(5 * time.Millisecond)
+	}
+	
+	select {
+	case event := <-debouncer.Events():
+		if event.Path != path {
+			t.Errorf("Expected path %s, got %s", path, event.Path)
+		}
+		if event.Operation != Write {
+			t.Errorf("Expected coalesced WRITE, got %s", event.Operation)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a coalesced event")
+	}
+	
+	select {
+	case event := <-debouncer.Events():
+		t.Errorf("Expected a single event, got extra %s for %s", event.Operation, event.Path)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestDebouncerSeparatesPaths(t *testing.T) {
+	debouncer := NewDebouncer(30 * time.Millisecond)
+	defer debouncer.Stop()
+	
+	debouncer.Add(Event{Path: "/vault/a.md", Operation: Write})
+	debouncer.Add(Event{Path: "/vault/b.md", Operation: Write})
+	debouncer.Add(Event{Path: "/vault/a.md", Operation: Remove})
+	
+	got := make(map[string]Operation)
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-debouncer.Events():
+			got[event.Path] = event.Operation
+		case <-time.After(time.Second):
+			t.Fatalf("Expected two events, got %d", len(got))
+		}
+	}
+	
+	if got["/vault/a.md"] != Remove {
+		t.Errorf("Expected REMOVE for a.md, got %s", got["/vault/a.md"])
+	}
+	if got["/vault/b.md"] != Write {
+		t.Errorf("Expected WRITE for b.md, got %s", got["/vault/b.md"])
+	}
+}
+
+func TestDebouncerZeroWindowPassesThrough(t *testing.T) {
+	debouncer := NewDebouncer(0)
+	defer debouncer.Stop()
+	
+	debouncer.Add(Event{Path: "/vault/note.md", Operation: Create})
+	
+	select {
+	case event := <-debouncer.Events():
+		if event.Operation != Create {
+			t.Errorf("Expected CREATE to pass through, got %s", event.Operation)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected event to pass through immediately")
+	}
+}
@@ -2,13 +2,19 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"obsidian-hugo-sync/internal/vault"
 )
 
 // Event represents a file system event
@@ -53,28 +59,66 @@ type Watcher struct {
 	errors     chan error
 	done       chan struct{}
 	fsWatcher  *fsnotify.Watcher
+	debouncer  *Debouncer
+	ignore     *vault.IgnoreMatcher
+	scan       vault.ScanOptions // Directories kept out of watching, like a nested Hugo repo, and symlink handling
 	usePolling bool
+	stopOnce   sync.Once
+
+	// Directories watched and failed to be watched through fsnotify, which
+	// gives up for polling once too many fail or the watch limit is hit
+	addWatch      func(path string) error
+	watchedDirs   int
+	failedWatches int
+	watchLimitHit bool
+}
+
+// failedWatchLimit is how many directories may fail to be watched before
+// fsnotify is given up for polling
+const failedWatchLimit = 10
+
+// errWatchesExhausted is returned when too many vault directories couldn't be watched
+var errWatchesExhausted = errors.New("too many vault directories could not be watched")
+
+// New creates a new file watcher. Bursts of events for the same path are
+// coalesced into a single event after the debounce window. Directories are
+// chosen as for vault scans: skipped ones (and any Hugo site inside the
+// vault) are not watched, and symlinked ones are when scans follow them.
+func New(vaultPath string, interval, debounce time.Duration, scan vault.ScanOptions) (*Watcher, error) {
+	w := newWatcher(vaultPath, interval, debounce, scan)
+	w.selectBackend()
+	return w, nil
 }
 
-// New creates a new file watcher
-func New(vaultPath string, interval time.Duration) (*Watcher, error) {
+// newWatcher creates a watcher that hasn't chosen between fsnotify and polling yet
+func newWatcher(vaultPath string, interval, debounce time.Duration, scan vault.ScanOptions) *Watcher {
 	w := &Watcher{
 		vaultPath: vaultPath,
 		interval:  interval,
 		events:    make(chan Event, 100),
 		errors:    make(chan error, 10),
 		done:      make(chan struct{}),
+		debouncer: NewDebouncer(debounce),
+		scan:      scan,
 	}
+	w.loadIgnoreFile()
+	return w
+}
 
-	// Try to use fsnotify first
-	if err := w.initFsnotify(); err != nil {
+// selectBackend watches the vault through fsnotify, falling back to polling
+// when it can't be set up or doesn't cover the whole vault
+func (w *Watcher) selectBackend() {
+	err := w.initFsnotify()
+	switch {
+	case errors.Is(err, errWatchesExhausted):
+		w.logWatchExhaustion()
+		w.usePolling = true
+	case err != nil:
 		slog.Warn("Failed to initialize fsnotify, falling back to polling",
 			"error", err,
-			"interval", interval)
+			"interval", w.interval)
 		w.usePolling = true
 	}
-
-	return w, nil
 }
 
 // Start begins monitoring the vault for changes
@@ -95,12 +139,15 @@ func (w *Watcher) Errors() <-chan error {
 	return w.errors
 }
 
-// Stop stops the watcher
+// Stop stops the watcher; calling it again has no effect
 func (w *Watcher) Stop() {
-	close(w.done)
-	if w.fsWatcher != nil {
-		w.fsWatcher.Close()
-	}
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.debouncer.Stop()
+		if w.fsWatcher != nil {
+			w.fsWatcher.Close()
+		}
+	})
 }
 
 // initFsnotify initializes fsnotify-based watching
@@ -110,25 +157,25 @@ func (w *Watcher) initFsnotify() error {
 	if err != nil {
 		return fmt.Errorf("creating fsnotify watcher: %w", err)
 	}
+	if w.addWatch == nil {
+		w.addWatch = w.fsWatcher.Add
+	}
 
 	// Add vault directory recursively
-	err = filepath.Walk(w.vaultPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
+	err = vault.WalkVault(w.vaultPath, w.scan.FollowSymlinks, func(path string, info os.FileInfo) error {
 		// Skip hidden directories and .git
 		if info.IsDir() {
 			name := filepath.Base(path)
 			if name[0] == '.' && name != "." {
 				return filepath.SkipDir
 			}
+			if w.isIgnored(path, true) || w.scan.SkipsDir(w.vaultPath, path) {
+				return filepath.SkipDir
+			}
 		}
 
 		if info.IsDir() {
-			if err := w.fsWatcher.Add(path); err != nil {
-				slog.Warn("Failed to watch directory", "path", path, "error", err)
-			}
+			w.watchDir(path)
 		}
 
 		return nil
@@ -138,13 +185,45 @@ func (w *Watcher) initFsnotify() error {
 		w.fsWatcher.Close()
 		return fmt.Errorf("adding paths to fsnotify: %w", err)
 	}
+	if w.watchesExhausted() {
+		w.fsWatcher.Close()
+		w.fsWatcher = nil
+		return errWatchesExhausted
+	}
 
 	return nil
 }
 
+// watchDir adds a directory to fsnotify, counting the ones that fail
+func (w *Watcher) watchDir(path string) {
+	if err := w.addWatch(path); err != nil {
+		w.failedWatches++
+		if errors.Is(err, syscall.ENOSPC) {
+			w.watchLimitHit = true // The inotify watch limit is used up
+		}
+		slog.Warn("Failed to watch directory", "path", path, "error", err)
+		return
+	}
+	w.watchedDirs++
+}
+
+// watchesExhausted reports whether fsnotify misses too much of the vault to rely on
+func (w *Watcher) watchesExhausted() bool {
+	return w.watchLimitHit || w.failedWatches > failedWatchLimit
+}
+
+// logWatchExhaustion explains why the watcher falls back to polling and how to avoid it
+func (w *Watcher) logWatchExhaustion() {
+	attrs := []any{"watched", w.watchedDirs, "failed", w.failedWatches, "interval", w.interval}
+	if runtime.GOOS == "linux" {
+		attrs = append(attrs, "fix", "raise the inotify watch limit, e.g. sudo sysctl fs.inotify.max_user_watches=524288")
+	}
+	slog.Error("Not all vault directories could be watched, falling back to polling", attrs...)
+}
+
 // startFsnotify runs the fsnotify event loop
 func (w *Watcher) startFsnotify(ctx context.Context) error {
-	slog.Info("Starting fsnotify file watcher", "vault", w.vaultPath)
+	slog.Info("Starting fsnotify file watcher", "vault", w.vaultPath, "watched", w.watchedDirs, "failed", w.failedWatches)
 
 	go func() {
 		defer close(w.events)
@@ -160,7 +239,23 @@ func (w *Watcher) startFsnotify(ctx context.Context) error {
 				if !ok {
 					return
 				}
-				w.handleFsnotifyEvent(event)
+				w.handleFsnotifyEvent(ctx, event)
+				if w.watchesExhausted() {
+					// New directories ran out of watches; leave changes to
+					// the daemon's periodic rescan from here on
+					w.logWatchExhaustion()
+					w.fsWatcher.Close()
+					w.waitForStop(ctx)
+					return
+				}
+			case event := <-w.debouncer.Events():
+				select {
+				case w.events <- event:
+				case <-ctx.Done():
+					return
+				case <-w.done:
+					return
+				}
 			case err, ok := <-w.fsWatcher.Errors:
 				if !ok {
 					return
@@ -180,7 +275,13 @@ func (w *Watcher) startFsnotify(ctx context.Context) error {
 }
 
 // handleFsnotifyEvent converts fsnotify events to our Event type
-func (w *Watcher) handleFsnotifyEvent(event fsnotify.Event) {
+func (w *Watcher) handleFsnotifyEvent(ctx context.Context, event fsnotify.Event) {
+	// Pick up edits to the ignore file for subsequent events
+	if event.Name == filepath.Join(w.vaultPath, vault.IgnoreFileName) {
+		w.loadIgnoreFile()
+		return
+	}
+
 	// Filter out events we don't care about
 	if !w.shouldProcessPath(event.Name) {
 		return
@@ -191,10 +292,8 @@ func (w *Watcher) handleFsnotifyEvent(event fsnotify.Event) {
 	case event.Op&fsnotify.Create == fsnotify.Create:
 		op = Create
 		// If a new directory was created, watch it
-		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
-			if err := w.fsWatcher.Add(event.Name); err != nil {
-				slog.Warn("Failed to watch new directory", "path", event.Name, "error", err)
-			}
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !w.isIgnored(event.Name, true) && !w.scan.SkipsDir(w.vaultPath, event.Name) {
+			w.watchDir(event.Name)
 		}
 	case event.Op&fsnotify.Write == fsnotify.Write:
 		op = Write
@@ -208,45 +307,51 @@ func (w *Watcher) handleFsnotifyEvent(event fsnotify.Event) {
 		return // Unknown operation
 	}
 
+	w.forward(ctx, Event{Path: event.Name, Operation: op})
+}
+
+// forward hands an event to the debouncer. Without a debounce window it goes
+// straight to the events channel instead: the debouncer would pass it on
+// synchronously into its own buffer, which only this goroutine drains, so a
+// burst larger than the buffer would block it for good.
+func (w *Watcher) forward(ctx context.Context, event Event) {
+	if w.debouncer.Enabled() {
+		w.debouncer.Add(event)
+		return
+	}
 	select {
-	case w.events <- Event{Path: event.Name, Operation: op}:
+	case w.events <- event:
+	case <-ctx.Done():
 	case <-w.done:
 	}
 }
 
-// startPolling runs the polling-based watcher
+// startPolling runs the watcher without fsnotify. It reports no events of its
+// own: the daemon's sync every interval rescans the vault (rescanVault) and
+// picks up whatever changed, so the watcher only waits to be stopped.
 func (w *Watcher) startPolling(ctx context.Context) error {
 	slog.Info("Starting polling file watcher",
 		"vault", w.vaultPath,
 		"interval", w.interval)
 
-	// TODO: Implement polling-based file watching
-	// For now, just log that we would be polling
 	go func() {
 		defer close(w.events)
 		defer close(w.errors)
-
-		ticker := time.NewTicker(w.interval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-w.done:
-				return
-			case <-ticker.C:
-				// TODO: Scan vault directory and detect changes
-				// This would involve comparing file modification times
-				// and checksums against the last known state
-				slog.Debug("Polling vault for changes")
-			}
-		}
+		w.waitForStop(ctx)
 	}()
 
 	return nil
 }
 
+// waitForStop blocks until the watcher stops, leaving changes to the
+// daemon's periodic rescan
+func (w *Watcher) waitForStop(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-w.done:
+	}
+}
+
 // shouldProcessPath determines if we should process events for this path
 func (w *Watcher) shouldProcessPath(path string) bool {
 	name := filepath.Base(path)
@@ -256,7 +361,31 @@ func (w *Watcher) shouldProcessPath(path string) bool {
 		return false
 	}
 
+	// Skip paths excluded by the vault's ignore file
+	if w.isIgnored(path, false) {
+		return false
+	}
+
 	// Only process markdown files and our lock file
-	ext := filepath.Ext(path)
-	return ext == ".md" || name == ".obsidian-hugo-sync.lock"
+	return w.scan.IsNoteFile(path) || name == ".obsidian-hugo-sync.lock"
+}
+
+// loadIgnoreFile (re)reads the vault's ignore file, keeping the previous
+// patterns if it can't be read
+func (w *Watcher) loadIgnoreFile() {
+	ignore, err := vault.LoadIgnoreFile(w.vaultPath)
+	if err != nil {
+		slog.Warn("Failed to load ignore file", "error", err)
+		return
+	}
+	w.ignore = ignore
+}
+
+// isIgnored reports whether an absolute vault path matches the ignore file
+func (w *Watcher) isIgnored(path string, isDir bool) bool {
+	relPath, err := filepath.Rel(w.vaultPath, path)
+	if err != nil {
+		return false
+	}
+	return w.ignore.Match(relPath, isDir)
 } 
\ No newline at end of file
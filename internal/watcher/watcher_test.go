@@ -0,0 +1,126 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"obsidian-hugo-sync/internal/vault"
+)
+
+func TestFailedWatchesFallBackToPolling(t *testing.T) {
+	vaultDir := t.TempDir()
+	for i := 0; i < 15; i++ {
+		if err := os.MkdirAll(filepath.Join(vaultDir, fmt.Sprintf("folder-%d", i)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	tests := []struct {
+		name        string
+		failAfter   int   // Directories watched before Add starts failing
+		err         error // Error returned by the failing Add calls
+		wantPolling bool
+	}{
+		{"all watched", 100, nil, false},
+		{"a few fail", 10, errors.New("permission denied"), false},
+		{"too many fail", 0, errors.New("permission denied"), true},
+		{"watch limit reached", 15, fmt.Errorf("add watch: %w", syscall.ENOSPC), true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWatcher(vaultDir, time.Minute, 0, vault.ScanOptions{})
+			t.Cleanup(w.Stop)
+			added := 0
+			w.addWatch = func(path string) error {
+				if added >= tt.failAfter {
+					return tt.err
+				}
+				added++
+				return nil
+			}
+			
+			w.selectBackend()
+			if w.usePolling != tt.wantPolling {
+				t.Errorf("Expected polling %v, got %v (watched %d, failed %d)", tt.wantPolling, w.usePolling, w.watchedDirs, w.failedWatches)
+			}
+			if w.watchedDirs+w.failedWatches != 16 {
+				t.Errorf("Expected 16 directories to be tried, got %d watched and %d failed", w.watchedDirs, w.failedWatches)
+			}
+			if tt.wantPolling && w.fsWatcher != nil {
+				t.Error("Expected the fsnotify watcher to be closed after falling back")
+			}
+			if tt.wantPolling {
+				assertLeavesChangesToRescan(t, w)
+			}
+		})
+	}
+}
+
+// assertLeavesChangesToRescan checks that a polling watcher reports nothing
+// for a changed note, leaving it to the daemon's periodic rescan, and closes
+// its channels once stopped
+func assertLeavesChangesToRescan(t *testing.T, w *Watcher) {
+	t.Helper()
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	notePath := filepath.Join(w.vaultPath, "folder-0", "note.md")
+	if err := os.WriteFile(notePath, []byte("# Note\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-w.Events():
+		t.Errorf("Expected no events while polling, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	w.Stop()
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			t.Error("Expected no events after stopping")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the events channel to close after stopping")
+	}
+}
+
+func TestBurstWithoutDebounceWindowDoesNotBlock(t *testing.T) {
+	vaultDir := t.TempDir()
+	w := newWatcher(vaultDir, time.Minute, 0, vault.ScanOptions{})
+	t.Cleanup(w.Stop)
+
+	// More events than the debouncer's buffer, handled as the fsnotify loop
+	// would while a consumer drains the events channel
+	const burst = 250
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		for i := 0; i < burst; i++ {
+			path := filepath.Join(vaultDir, fmt.Sprintf("note-%d.md", i))
+			w.handleFsnotifyEvent(context.Background(), fsnotify.Event{Name: path, Op: fsnotify.Write})
+		}
+	}()
+
+	received := 0
+	timeout := time.After(5 * time.Second)
+	for received < burst {
+		select {
+		case <-w.Events():
+			received++
+		case <-timeout:
+			t.Fatalf("Expected %d events, got %d before timing out", burst, received)
+		}
+	}
+	<-handled
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"obsidian-hugo-sync/internal/daemon"
+)
+
+// SecretHeader carries the shared secret every webhook request must present
+const SecretHeader = "X-Webhook-Secret"
+
+// Syncer runs a full sync and waits for its result
+type Syncer interface {
+	SyncNow(ctx context.Context) daemon.SyncResult
+}
+
+// Handler returns the webhook handler. POST /sync with the shared secret in
+// the X-Webhook-Secret header runs a full sync of every syncer in turn and
+// responds with their results as JSON; the status is 500 if any failed.
+func Handler(secret string, syncers ...Syncer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		given := r.Header.Get(SecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(given), []byte(secret)) != 1 {
+			slog.Warn("Rejected webhook request without a valid secret", "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		status := http.StatusOK
+		results := make([]daemon.SyncResult, 0, len(syncers))
+		for _, syncer := range syncers {
+			result := syncer.SyncNow(r.Context())
+			if result.Error != "" {
+				status = http.StatusInternalServerError
+			}
+			results = append(results, result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			slog.Warn("Failed to write webhook response", "error", err)
+		}
+	})
+	return mux
+}
+
+// Start serves the webhook handler on addr until ctx is cancelled. Unlike the
+// pprof endpoint it binds to every interface when addr has no host, as CI
+// systems deliver webhooks from elsewhere.
+func Start(ctx context.Context, addr string, handler http.Handler) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Webhook server failed", "error", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Webhook endpoint enabled", "addr", listener.Addr().String())
+	return listener.Addr(), nil
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"obsidian-hugo-sync/internal/daemon"
+)
+
+// fakeSyncer counts the syncs it was asked to run
+type fakeSyncer struct {
+	calls int
+}
+
+func (f *fakeSyncer) SyncNow(ctx context.Context) daemon.SyncResult {
+	f.calls++
+	return daemon.SyncResult{Vault: "/vault", Processed: 3, Published: 2}
+}
+
+func TestSyncRequiresSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		secret     string
+		wantStatus int
+		wantSync   bool
+	}{
+		{"valid secret", http.MethodPost, "s3cret", http.StatusOK, true},
+		{"missing secret", http.MethodPost, "", http.StatusUnauthorized, false},
+		{"wrong secret", http.MethodPost, "guess", http.StatusUnauthorized, false},
+		{"not a POST", http.MethodGet, "s3cret", http.StatusMethodNotAllowed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := &fakeSyncer{}
+			handler := Handler("s3cret", syncer)
+
+			req := httptest.NewRequest(tt.method, "/sync", nil)
+			if tt.secret != "" {
+				req.Header.Set(SecretHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if (syncer.calls == 1) != tt.wantSync {
+				t.Errorf("Expected sync triggered %v, got %d calls", tt.wantSync, syncer.calls)
+			}
+			if !tt.wantSync {
+				return
+			}
+
+			var results []daemon.SyncResult
+			if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(results) != 1 || results[0].Processed != 3 || results[0].Published != 2 {
+				t.Errorf("Expected the sync stats in the response, got %+v", results)
+			}
+		})
+	}
+}
+
+func TestEmptySecretRejectsEverything(t *testing.T) {
+	syncer := &fakeSyncer{}
+	req := httptest.NewRequest(http.MethodPost, "/sync", nil)
+	rec := httptest.NewRecorder()
+	Handler("", syncer).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized || syncer.calls != 0 {
+		t.Errorf("Expected 401 without a sync, got %d with %d calls", rec.Code, syncer.calls)
+	}
+}